@@ -4,10 +4,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"math"
-	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -16,6 +16,7 @@ import (
 
 	"github.com/talgya/mini-world/internal/gardener"
 	"github.com/talgya/mini-world/internal/llm"
+	"github.com/talgya/mini-world/pkg/worldclient"
 )
 
 func main() {
@@ -46,15 +47,18 @@ func main() {
 		"interval", interval,
 	)
 
-	observer := gardener.NewObserver(apiURL)
-	actor := gardener.NewActor(apiURL, adminKey)
+	client := worldclient.New(apiURL)
+	client.AdminKey = adminKey
+	client.Actor = "gardener"
+	observer := gardener.NewObserver(client)
+	actor := gardener.NewActor(client)
 	llmClient := llm.NewClient(anthropicKey)
 	memory := gardener.LoadMemory()
 
 	// Wait for worldsim API to be ready before first cycle.
 	// systemd After= only ensures process start, not HTTP readiness.
 	slog.Info("waiting for worldsim API...")
-	waitForAPI(apiURL)
+	waitForAPI(client)
 
 	// Run first cycle immediately.
 	runCycle(observer, actor, llmClient, memory)
@@ -139,6 +143,7 @@ func runCycle(observer *gardener.Observer, actor *gardener.Actor, llmClient *llm
 				"type", iv.Type,
 				"settlement", iv.Settlement,
 				"success", result.Success,
+				"ledger_id", result.ID,
 				"details", result.Details,
 			)
 		}
@@ -184,31 +189,14 @@ func envIntOrDefault(key string, defaultVal int) int {
 	return defaultVal
 }
 
-// waitForAPI polls the worldsim status endpoint with exponential backoff
-// until it responds. Exits after 5 minutes if the API never becomes ready.
-func waitForAPI(apiURL string) {
-	backoff := 2 * time.Second
-	maxBackoff := 30 * time.Second
-	deadline := time.Now().Add(5 * time.Minute)
-
-	for {
-		resp, err := http.Get(apiURL + "/api/v1/status")
-		if err == nil {
-			resp.Body.Close()
-			if resp.StatusCode == 200 {
-				slog.Info("worldsim API is ready")
-				return
-			}
-		}
-		if time.Now().After(deadline) {
-			slog.Error("worldsim API did not become ready within 5 minutes")
-			os.Exit(1)
-		}
-		slog.Info("worldsim not ready, retrying...", "backoff", backoff)
-		time.Sleep(backoff)
-		backoff *= 2
-		if backoff > maxBackoff {
-			backoff = maxBackoff
-		}
+// waitForAPI waits for the worldsim API to answer. Exits after 5 minutes if
+// it never becomes ready.
+func waitForAPI(client *worldclient.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := client.WaitReady(ctx); err != nil {
+		slog.Error("worldsim API did not become ready within 5 minutes", "error", err)
+		os.Exit(1)
 	}
+	slog.Info("worldsim API is ready")
 }
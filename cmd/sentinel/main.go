@@ -5,9 +5,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/talgya/mini-world/internal/sentinel"
+	"github.com/talgya/mini-world/pkg/worldclient"
 )
 
 func main() {
@@ -36,12 +37,13 @@ func main() {
 		"interval", interval,
 	)
 
-	observer := sentinel.NewObserver(apiURL)
+	client := worldclient.New(apiURL)
+	observer := sentinel.NewObserver(client)
 	state := sentinel.LoadState(dataDir)
 
 	// Wait for worldsim API to be ready before first cycle.
 	slog.Info("waiting for worldsim API...")
-	waitForAPI(apiURL)
+	waitForAPI(client)
 
 	// Run first cycle immediately.
 	runCycle(observer, state)
@@ -123,31 +125,14 @@ func envIntOrDefault(key string, defaultVal int) int {
 	return defaultVal
 }
 
-// waitForAPI polls the worldsim status endpoint with exponential backoff
-// until it responds. Exits after 5 minutes if the API never becomes ready.
-func waitForAPI(apiURL string) {
-	backoff := 2 * time.Second
-	maxBackoff := 30 * time.Second
-	deadline := time.Now().Add(5 * time.Minute)
-
-	for {
-		resp, err := http.Get(apiURL + "/api/v1/status")
-		if err == nil {
-			resp.Body.Close()
-			if resp.StatusCode == 200 {
-				slog.Info("worldsim API is ready")
-				return
-			}
-		}
-		if time.Now().After(deadline) {
-			slog.Error("worldsim API did not become ready within 5 minutes")
-			os.Exit(1)
-		}
-		slog.Info("worldsim not ready, retrying...", "backoff", backoff)
-		time.Sleep(backoff)
-		backoff *= 2
-		if backoff > maxBackoff {
-			backoff = maxBackoff
-		}
+// waitForAPI waits for the worldsim API to answer. Exits after 5 minutes if
+// it never becomes ready.
+func waitForAPI(client *worldclient.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := client.WaitReady(ctx); err != nil {
+		slog.Error("worldsim API did not become ready within 5 minutes", "error", err)
+		os.Exit(1)
 	}
+	slog.Info("worldsim API is ready")
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// renderAPI builds api.generated.ts from the API's OpenAPI document (whose
+// component schemas are derived from the Go response structs, see
+// internal/api/openapi.go) and the engine's event Meta shapes.
+func renderAPI(doc map[string]any, metas map[string]metaShape) string {
+	components := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	r := &tsRenderer{names: tsNames(components)}
+
+	var b bytes.Buffer
+	b.WriteString("// AUTO-GENERATED by mini-world/cmd/typegen -api — DO NOT EDIT BY HAND.\n")
+	b.WriteString("// Source of truth: the Go response structs behind /api/v1/openapi.json\n")
+	b.WriteString("// (mini-world/internal/api/openapi.go) and the Event Meta maps written in\n")
+	b.WriteString("// mini-world/internal/engine. Run `go generate ./...` from mini-world/ to\n")
+	b.WriteString("// regenerate; `typegen -api -check <path>` fails if this file is stale.\n")
+	b.WriteString("//\n")
+	b.WriteString("// A field marked `?` may be absent from the JSON; `| null` means it may be\n")
+	b.WriteString("// null (Go nil slices, maps and pointers).\n")
+
+	// Components, once per TypeScript name (identical schemas share one).
+	var emitted []string
+	for _, name := range slices.Sorted(maps.Keys(components)) {
+		ts := r.names[name]
+		if slices.Contains(emitted, ts) {
+			continue
+		}
+		emitted = append(emitted, ts)
+		s := components[name].(map[string]any)
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "/** Go %s. */\n", name)
+		if props, ok := s["properties"].(map[string]any); ok && s["type"] == "object" {
+			fmt.Fprintf(&b, "export interface %s {\n", ts)
+			r.fields(&b, props, s, "\t")
+			b.WriteString("}\n")
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n", ts, r.ts(s))
+		}
+	}
+
+	// Bodies per operation.
+	type opBody struct{ key, ts string }
+	var responses, requests []opBody
+	paths := doc["paths"].(map[string]any)
+	for _, p := range slices.Sorted(maps.Keys(paths)) {
+		item := paths[p].(map[string]any)
+		for _, method := range slices.Sorted(maps.Keys(item)) {
+			op := item[method].(map[string]any)
+			key := strings.ToUpper(method) + " " + p
+			content := op["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)
+			for _, mt := range slices.Sorted(maps.Keys(content)) {
+				responses = append(responses, opBody{key, r.ts(content[mt].(map[string]any)["schema"].(map[string]any))})
+			}
+			if rb, ok := op["requestBody"].(map[string]any); ok {
+				s := rb["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+				requests = append(requests, opBody{key, r.ts(s)})
+			}
+		}
+	}
+	b.WriteString("\n/** Response bodies by operation, e.g. ApiResponses['GET /api/v1/status']. */\n")
+	b.WriteString("export interface ApiResponses {\n")
+	for _, o := range responses {
+		fmt.Fprintf(&b, "\t'%s': %s;\n", o.key, o.ts)
+	}
+	b.WriteString("}\n")
+	b.WriteString("\n/** Request bodies by operation. */\n")
+	b.WriteString("export interface ApiRequests {\n")
+	for _, o := range requests {
+		fmt.Fprintf(&b, "\t'%s': %s;\n", o.key, o.ts)
+	}
+	b.WriteString("}\n")
+
+	// Event Meta by category.
+	category := r.names["eventproto.Category"]
+	event := r.names["engine.Event"]
+	categories := components["eventproto.Category"].(map[string]any)["enum"].([]any)
+	b.WriteString("\n/**\n")
+	b.WriteString(" * Event meta payloads by category: every key some emit site of that\n")
+	b.WriteString(" * category writes, optional unless all of them write it.\n")
+	b.WriteString(" */\n")
+	fmt.Fprintf(&b, "export interface EventMeta {\n")
+	for _, c := range categories {
+		c := c.(string)
+		shape := metas[c]
+		if len(shape) == 0 {
+			fmt.Fprintf(&b, "\t%s: Record<string, never>;\n", c)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s: {\n", c)
+		for _, k := range slices.Sorted(maps.Keys(shape)) {
+			opt := ""
+			if shape[k].optional {
+				opt = "?"
+			}
+			fmt.Fprintf(&b, "\t\t%s%s: %s;\n", tsKey(k), opt, shape[k].union())
+		}
+		b.WriteString("\t};\n")
+	}
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "\n/** An %s whose meta is typed by its category. */\n", event)
+	fmt.Fprintf(&b, "export type TypedEvent = {\n")
+	fmt.Fprintf(&b, "\t[C in %s]: Omit<%s, 'category' | 'meta'> & { category: C; meta?: EventMeta[C] };\n", category, event)
+	fmt.Fprintf(&b, "}[%s];\n", category)
+	return b.String()
+}
+
+// tsNames maps component names (engine.Event) to TypeScript names: the type
+// name alone, unless two packages use it for different shapes, when both
+// take their package as a prefix (EngineEvent). Unexported Go names are
+// capitalised.
+func tsNames(components map[string]any) map[string]string {
+	byBase := map[string][]string{}
+	for name := range components {
+		base := capitalise(path.Ext(name)[1:])
+		byBase[base] = append(byBase[base], name)
+	}
+	names := map[string]string{}
+	for base, group := range byBase {
+		same := true
+		for _, n := range group[1:] {
+			same = same && reflect.DeepEqual(components[n], components[group[0]])
+		}
+		for _, n := range group {
+			names[n] = base
+			if !same {
+				names[n] = capitalise(strings.TrimSuffix(n, path.Ext(n))) + base
+			}
+		}
+	}
+	return names
+}
+
+func capitalise(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+type tsRenderer struct {
+	names map[string]string
+}
+
+// fields writes an object's properties, sorted, one per line.
+func (r *tsRenderer) fields(b *bytes.Buffer, props, s map[string]any, indent string) {
+	required, _ := s["required"].([]any)
+	for _, k := range slices.Sorted(maps.Keys(props)) {
+		opt := "?"
+		if slices.Contains(required, any(k)) {
+			opt = ""
+		}
+		fmt.Fprintf(b, "%s%s%s: %s;\n", indent, tsKey(k), opt, r.ts(props[k].(map[string]any)))
+	}
+}
+
+// ts is the TypeScript type of a schema.
+func (r *tsRenderer) ts(s map[string]any) string {
+	var t string
+	switch {
+	case s["$ref"] != nil:
+		t = r.names[strings.TrimPrefix(s["$ref"].(string), "#/components/schemas/")]
+	case s["allOf"] != nil:
+		var parts []string
+		for _, sub := range s["allOf"].([]any) {
+			parts = append(parts, r.ts(sub.(map[string]any)))
+		}
+		t = strings.Join(parts, " & ")
+	case s["oneOf"] != nil:
+		var parts []string
+		for _, sub := range s["oneOf"].([]any) {
+			parts = append(parts, r.ts(sub.(map[string]any)))
+		}
+		t = strings.Join(parts, " | ")
+	default:
+		switch s["type"] {
+		case "string":
+			t = "string"
+			if enum, ok := s["enum"].([]any); ok {
+				var lits []string
+				for _, v := range enum {
+					lits = append(lits, fmt.Sprintf("'%s'", v))
+				}
+				t = strings.Join(lits, " | ")
+			}
+		case "integer", "number":
+			t = "number"
+		case "boolean":
+			t = "boolean"
+		case "array":
+			t = arrayOf(r.ts(s["items"].(map[string]any)))
+		case "object":
+			if props, ok := s["properties"].(map[string]any); ok {
+				var b bytes.Buffer
+				b.WriteString("{ ")
+				r.fields(&b, props, s, "")
+				t = strings.ReplaceAll(strings.TrimSuffix(b.String(), "\n"), "\n", " ") + " }"
+			} else if ap, ok := s["additionalProperties"].(map[string]any); ok {
+				t = "Record<string, " + r.ts(ap) + ">"
+			} else {
+				t = "Record<string, unknown>"
+			}
+		default:
+			t = "unknown"
+		}
+	}
+	if s["nullable"] == true {
+		t += " | null"
+	}
+	return t
+}
+
+// arrayOf is an array of t, parenthesising a union.
+func arrayOf(t string) string {
+	if strings.Contains(t, " ") {
+		return "(" + t + ")[]"
+	}
+	return t + "[]"
+}
+
+var identRE = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey quotes a property name that is not a TypeScript identifier.
+func tsKey(k string) string {
+	if identRE.MatchString(k) {
+		return k
+	}
+	return "'" + k + "'"
+}
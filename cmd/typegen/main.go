@@ -3,9 +3,19 @@
 // optionally the relay's synthetic-event source (when present).
 //
 // Usage:
-//   go run mini-world/cmd/typegen <output_path>
+//   go run mini-world/cmd/typegen [-check] <output_path>
+//   go run mini-world/cmd/typegen -api [-check] <output_path>
 //
-// Or via the go:generate directive in mini-world/eventproto/gen.go.
+// Or via the go:generate directives in mini-world/eventproto/gen.go and
+// mini-world/internal/api/gen.go. With -check nothing is written; typegen
+// exits 1 if the file at output_path differs from what it would generate,
+// so CI can catch a stale commit.
+//
+// -api emits api.generated.ts instead: an interface per API response
+// struct, taken from the component schemas of the OpenAPI document
+// (internal/api/openapi.go), plus the shape of each event category's Meta
+// map, found by type-checking internal/engine and reading its
+// `Event{Category: ..., Meta: ...}` literals (see meta.go).
 //
 // Implementation is deliberately small and stdlib-only. We walk the AST of
 // `internal/eventproto/categories.go`, extract every `Category*` string
@@ -26,6 +36,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -33,6 +45,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/talgya/mini-world/internal/api"
 )
 
 // relaySyntheticCategories are the categories the relay emits itself
@@ -48,11 +62,27 @@ var relaySyntheticCategories = []string{
 }
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintln(os.Stderr, "usage: typegen <output_path>")
+	apiTypes := flag.Bool("api", false, "emit API response types instead of event categories")
+	check := flag.Bool("check", false, "fail if output_path is stale instead of writing it")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: typegen [-api] [-check] <output_path>")
 		os.Exit(2)
 	}
-	outPath := os.Args[1]
+	outPath := flag.Arg(0)
+
+	if *apiTypes {
+		out, err := generateAPI()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "generate API types: %v\n", err)
+			os.Exit(1)
+		}
+		if err := emit(outPath, out, *check); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Resolve the Go source. We're called via go:generate from the eventproto
 	// package directory, so the source file is in the working directory.
@@ -74,18 +104,81 @@ func main() {
 	}
 
 	out := renderTypeScript(worldCats, relaySyntheticCategories)
+	if err := emit(outPath, out, *check); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !*check {
+		fmt.Fprintf(os.Stderr, "typegen: wrote %d worldsim + %d relay categories → %s\n",
+			len(worldCats), len(relaySyntheticCategories), outPath)
+	}
+}
 
-	// Ensure parent dir exists, then write atomically.
+// emit writes out to outPath, or with check compares it with what is there.
+func emit(outPath, out string, check bool) error {
+	if check {
+		have, err := os.ReadFile(outPath)
+		if err != nil {
+			return fmt.Errorf("check %s: %w", outPath, err)
+		}
+		if string(have) != out {
+			return fmt.Errorf("%s is stale; run `go generate ./...` from mini-world/", outPath)
+		}
+		return nil
+	}
+	// Ensure parent dir exists, then write.
 	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
-		fmt.Fprintf(os.Stderr, "mkdir: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("mkdir: %w", err)
 	}
 	if err := os.WriteFile(outPath, []byte(out), 0o644); err != nil {
-		fmt.Fprintf(os.Stderr, "write %s: %v\n", outPath, err)
-		os.Exit(1)
+		return fmt.Errorf("write %s: %w", outPath, err)
+	}
+	return nil
+}
+
+// generateAPI renders api.generated.ts. The engine source is found from the
+// module root (the nearest go.mod above the working directory), so it works
+// from go:generate in internal/api as well as from the repo root.
+func generateAPI() (string, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	var mod []byte
+	for {
+		if mod, err = os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", fmt.Errorf("no go.mod above the working directory")
+		}
+		root = parent
+	}
+	var module string
+	for _, line := range strings.Split(string(mod), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			module = strings.TrimSpace(rest)
+			break
+		}
+	}
+	if module == "" {
+		return "", fmt.Errorf("%s/go.mod has no module line", root)
+	}
+	metas, err := extractEventMetas(filepath.Join(root, "internal", "engine"), module+"/internal/engine")
+	if err != nil {
+		return "", err
+	}
+	// Render from the document as clients see it, not the Go values built.
+	raw, err := json.Marshal(api.OpenAPI())
+	if err != nil {
+		return "", err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", err
 	}
-	fmt.Fprintf(os.Stderr, "typegen: wrote %d worldsim + %d relay categories → %s\n",
-		len(worldCats), len(relaySyntheticCategories), outPath)
+	return renderAPI(doc, metas), nil
 }
 
 // extractCategoryConstants parses the Go file and returns the string values
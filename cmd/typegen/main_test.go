@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerateAPI renders api.generated.ts twice and checks the output is
+// stable and carries the response interfaces and the Meta shapes read from
+// the engine's emit sites.
+func TestGenerateAPI(t *testing.T) {
+	out, err := generateAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := generateAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != again {
+		t.Fatal("two runs rendered different output")
+	}
+
+	for _, want := range []string{
+		"export interface HexDetail {\n",
+		"\tsettlement: HexSettlement | null;\n",
+		"export type Category = 'admin' | ",
+		"\t'GET /api/v1/agent/{id}': Agent | Grave;\n",
+		"\t'GET /api/v1/metrics': string;\n",
+		"\t'POST /api/v1/tuning': TuningChange;\n",
+		// engine.Event and worldclient.Event share one interface.
+		"export interface Event {\n\tcategory: Category;\n",
+		// Written by every raid; the captured hex only when land is taken.
+		"\t\tattacker_casualties: number;\n",
+		"\t\tcaptured_hex_q?: number;\n",
+		"\t\tevent_type?: 'coming_of_age' | 'marriage' | 'mentorship';\n",
+		// governance.go builds meta up key by key.
+		"\t\tnew_governance?: string;\n",
+		"export type TypedEvent = {\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "export interface EngineEvent") {
+		t.Error("identical Event schemas rendered twice")
+	}
+}
+
+// TestEmitCheck checks -check passes on a fresh file, fails on a stale or
+// missing one, and never writes.
+func TestEmitCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lib", "api.generated.ts")
+	if err := emit(path, "a\n", true); err == nil {
+		t.Error("check passed with no file")
+	}
+	if err := emit(path, "a\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := emit(path, "a\n", true); err != nil {
+		t.Errorf("check of fresh file: %v", err)
+	}
+	err := emit(path, "b\n", true)
+	if err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("check of stale file = %v, want stale", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "a\n" {
+		t.Errorf("check wrote %q", got)
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// metaKey is one key seen in an event's Meta map.
+type metaKey struct {
+	types    map[string]bool // TypeScript types of the values written
+	optional bool            // absent from some emit sites
+}
+
+// metaShape is the union of the Meta maps of one category's emit sites.
+type metaShape map[string]*metaKey
+
+// site is the keys one emit site writes.
+type site map[string]siteKey
+
+type siteKey struct {
+	ts       string
+	required bool // written on every path to the emit
+}
+
+// extractEventMetas type-checks the engine package in dir and returns, per
+// category, the shape of Meta across every `Event{Category: ..., Meta: ...}`
+// literal. Meta is either a map literal or a local variable built up from
+// one (`meta := map[string]any{...}; meta["k"] = v`); a variable that is a
+// parameter picks up the map literals its callers pass.
+func extractEventMetas(dir, importPath string) (map[string]metaShape, error) {
+	fset := token.NewFileSet()
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, e := range ents {
+		if !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(importPath, fset, files, info)
+	if err != nil {
+		return nil, fmt.Errorf("type-check %s: %w", importPath, err)
+	}
+	eventType := pkg.Scope().Lookup("Event")
+	if eventType == nil {
+		return nil, fmt.Errorf("%s has no Event type", importPath)
+	}
+
+	m := &metaWalker{info: info, files: files}
+	shapes := map[string]metaShape{}
+	sites := map[string]int{}
+	var decls []*ast.FuncDecl
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Body != nil {
+				decls = append(decls, fd)
+			}
+		}
+	}
+	for _, fd := range decls {
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok || !types.Identical(info.TypeOf(lit), eventType.Type()) {
+				return true
+			}
+			var category string
+			var meta ast.Expr
+			for _, el := range lit.Elts {
+				kv, ok := el.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				switch kv.Key.(*ast.Ident).Name {
+				case "Category":
+					if v := info.Types[kv.Value].Value; v != nil && v.Kind() == constant.String {
+						category = constant.StringVal(v)
+					}
+				case "Meta":
+					meta = kv.Value
+				}
+			}
+			if category == "" {
+				return true
+			}
+			s := site{}
+			if meta != nil {
+				m.collect(fd, lit.Pos(), meta, s)
+			}
+			sites[category]++
+			shape := shapes[category]
+			if shape == nil {
+				shape = metaShape{}
+				shapes[category] = shape
+			}
+			for k, sk := range s {
+				mk := shape[k]
+				if mk == nil {
+					// A key first seen after other sites of the category
+					// is missing from them.
+					mk = &metaKey{types: map[string]bool{}, optional: sites[category] > 1}
+					shape[k] = mk
+				}
+				mk.types[sk.ts] = true
+				mk.optional = mk.optional || !sk.required
+			}
+			for k, mk := range shape {
+				if _, ok := s[k]; !ok {
+					mk.optional = true
+				}
+			}
+			return true
+		})
+	}
+	return shapes, nil
+}
+
+type metaWalker struct {
+	info  *types.Info
+	files []*ast.File
+}
+
+// collect adds the keys written by the Meta expression e of the event at
+// pos, in fd, to s.
+func (m *metaWalker) collect(fd *ast.FuncDecl, pos token.Pos, e ast.Expr, s site) {
+	switch e := e.(type) {
+	case *ast.CompositeLit:
+		m.literal(e, s, true)
+	case *ast.Ident:
+		obj := m.info.Uses[e]
+		if obj == nil {
+			return
+		}
+		// Assignments of map literals and of single keys. One made in a
+		// block that does not enclose the event runs only on some paths.
+		var stack []ast.Node
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+			defer func() { stack = append(stack, n) }()
+			as, ok := n.(*ast.AssignStmt)
+			if !ok {
+				return true
+			}
+			parent := stack[len(stack)-1]
+			required := parent.Pos() <= pos && pos < parent.End()
+			for i, lhs := range as.Lhs {
+				if i >= len(as.Rhs) {
+					break
+				}
+				switch lhs := lhs.(type) {
+				case *ast.Ident:
+					if m.object(lhs) == obj {
+						if lit, ok := as.Rhs[i].(*ast.CompositeLit); ok {
+							m.literal(lit, s, required)
+						}
+					}
+				case *ast.IndexExpr:
+					if id, ok := lhs.X.(*ast.Ident); ok && m.object(id) == obj {
+						m.key(lhs.Index, as.Rhs[i], s, required)
+					}
+				}
+			}
+			return true
+		})
+		// A parameter: the map literals callers pass, each on some paths.
+		if idx := paramIndex(m.info, fd, obj); idx >= 0 {
+			fn := m.info.Defs[fd.Name]
+			for _, f := range m.files {
+				ast.Inspect(f, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok || idx >= len(call.Args) || m.callee(call) != fn {
+						return true
+					}
+					if lit, ok := call.Args[idx].(*ast.CompositeLit); ok {
+						m.literal(lit, s, false)
+					}
+					return true
+				})
+			}
+		}
+	}
+}
+
+func (m *metaWalker) object(id *ast.Ident) types.Object {
+	if obj := m.info.Uses[id]; obj != nil {
+		return obj
+	}
+	return m.info.Defs[id]
+}
+
+func (m *metaWalker) callee(call *ast.CallExpr) types.Object {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return m.info.Uses[fun]
+	case *ast.SelectorExpr:
+		return m.info.Uses[fun.Sel]
+	}
+	return nil
+}
+
+func paramIndex(info *types.Info, fd *ast.FuncDecl, obj types.Object) int {
+	i := 0
+	for _, field := range fd.Type.Params.List {
+		for _, name := range field.Names {
+			if info.Defs[name] == obj {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+func (m *metaWalker) literal(lit *ast.CompositeLit, s site, required bool) {
+	for _, el := range lit.Elts {
+		if kv, ok := el.(*ast.KeyValueExpr); ok {
+			m.key(kv.Key, kv.Value, s, required)
+		}
+	}
+}
+
+func (m *metaWalker) key(k, v ast.Expr, s site, required bool) {
+	kc := m.info.Types[k].Value
+	if kc == nil || kc.Kind() != constant.String {
+		return
+	}
+	name := constant.StringVal(kc)
+	ts := tsOfGo(m.info.TypeOf(v))
+	// A constant string is a literal type: event_type: 'coming_of_age'.
+	if c := m.info.Types[v].Value; c != nil && c.Kind() == constant.String {
+		ts = fmt.Sprintf("'%s'", constant.StringVal(c))
+	}
+	prev, seen := s[name]
+	s[name] = siteKey{ts: ts, required: required || (seen && prev.required)}
+}
+
+// tsOfGo is the TypeScript type of a Go value encoded by encoding/json.
+func tsOfGo(t types.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsNumeric != 0:
+			return "number"
+		case u.Info()&types.IsString != 0:
+			return "string"
+		case u.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case u.Kind() == types.UntypedNil:
+			return "null"
+		}
+	case *types.Pointer:
+		return tsOfGo(u.Elem()) + " | null"
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "string"
+		}
+		return arrayOf(tsOfGo(u.Elem())) + " | null"
+	case *types.Array:
+		return arrayOf(tsOfGo(u.Elem()))
+	case *types.Map:
+		return "Record<string, " + tsOfGo(u.Elem()) + "> | null"
+	}
+	return "unknown"
+}
+
+// union joins a key's types, each member once, dropping string literals
+// when plain string is among them.
+func (k *metaKey) union() string {
+	members := map[string]bool{}
+	for ts := range k.types {
+		for _, m := range splitUnion(ts) {
+			members[m] = true
+		}
+	}
+	var parts []string
+	for m := range members {
+		if strings.HasPrefix(m, "'") && members["string"] {
+			continue
+		}
+		parts = append(parts, m)
+	}
+	slices.SortFunc(parts, func(a, b string) int {
+		// null reads last: number | null.
+		switch {
+		case a == b:
+			return 0
+		case a == "null":
+			return 1
+		case b == "null":
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	return strings.Join(parts, " | ")
+}
+
+// splitUnion splits ts at the " | " separators outside parentheses and
+// angle brackets.
+func splitUnion(ts string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(ts); i++ {
+		switch ts[i] {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case '|':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(ts[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(ts[start:]))
+}
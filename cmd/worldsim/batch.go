@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/entropy"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// runBatch implements `worldsim batch`: run the real tick callbacks headless,
+// without sleeping, for a fixed number of sim-days and write the daily
+// StatsRow and SettlementStatsRow series. LLM and weather are off (seasonal
+// weather defaults apply) and entropy comes from a stream seeded by the world
+// seed, so the same inputs always produce the same series. Returns the process
+// exit code.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	configPath := fs.String("config", "", "JSON config file for world generation (same format as the server's)")
+	seed := fs.Int64("seed", 0, "world seed (overrides the config file; default 42)")
+	from := fs.String("from", "", "start from this saved database or .ckpt checkpoint instead of a fresh world (never modified)")
+	days := fs.Int("days", 365, "sim-days to run")
+	out := fs.String("out", "", "output: a .csv path (settlements go to <name>_settlements.csv) or a new .db/.sqlite file")
+	verbose := fs.Bool("v", false, "keep the simulation's info-level logging")
+	knobs := knobFlag{}
+	fs.Var(knobs, "set", "override a tuning knob as name=value (repeatable; worldsim scenario -knobs lists them)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: worldsim batch [-config f] [-seed N] [-from saved.db] [-set knob=v ...] -days N -out stats.csv|stats.db")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *out == "" || *days <= 0 || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := defaultConfig()
+	if *configPath != "" {
+		if err := readConfigFile(*configPath, &cfg); err != nil {
+			slog.Error("batch: invalid configuration", "error", err)
+			return 2
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			cfg.Seed = *seed
+		}
+	})
+	for name, v := range cfg.Tuning {
+		if _, set := knobs[name]; !set {
+			knobs[name] = v
+		}
+	}
+
+	if !*verbose {
+		// The engine logs every birth wave and market swing at info level;
+		// a multi-year batch only needs warnings.
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	}
+
+	sink, err := openStatsSink(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 1
+	}
+
+	started := time.Now()
+	sim, err := simulateHeadless(cfg.GenConfig(), *from, *days, knobs, func(sim *engine.Simulation, tick uint64) error {
+		return sink.write(persistence.BuildStatsRow(sim, tick), persistence.BuildSettlementStatsRows(sim, tick))
+	})
+	if cerr := sink.close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 1
+	}
+
+	elapsed := time.Since(started)
+	fmt.Printf("batch OK: %d sim-days in %s (%.1f days/s), ended %s with %d alive in %d settlements → %s\n",
+		*days, elapsed.Round(time.Millisecond), float64(*days)/elapsed.Seconds(),
+		engine.SimTime(sim.LastTick), sim.Stats.TotalPopulation, len(sim.Settlements), *out)
+	return 0
+}
+
+// simulateHeadless boots a world — fresh from gen, or from a copy of the saved
+// database at from — into a scratch directory, applies the knob overrides and
+// steps it for days sim-days as fast as the CPU allows, calling onDay after
+// every TickDay. Integrations are replaced by deterministic stubs: no LLM, no
+// live weather, and an entropy stream seeded from gen.Seed. Nothing is written
+// back to from. Safe to call from several goroutines at once.
+func simulateHeadless(gen world.GenConfig, from string, days int, knobs map[string]float64, onDay func(*engine.Simulation, uint64) error) (*engine.Simulation, error) {
+	tmpDir, err := os.MkdirTemp("", "worldsim-batch-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	dbPath := filepath.Join(tmpDir, "world.db")
+	var ckptPath string
+	if filepath.Ext(from) == ".ckpt" {
+		// Checkpoints are only read, so no copy is needed; the scratch
+		// database starts empty and collects nothing but history.
+		ckptPath = from
+	} else if from != "" {
+		if err := copyFile(from, dbPath); err != nil {
+			return nil, fmt.Errorf("copy %s: %w", from, err)
+		}
+	}
+	db, err := persistence.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	sim, info, err := bootWorld(db, gen, ckptPath)
+	if err != nil {
+		return nil, fmt.Errorf("boot world: %w", err)
+	}
+	sim.Entropy = entropy.NewDeterministic(gen.Seed)
+	if err := sim.ApplyTuning(knobs); err != nil {
+		return nil, err
+	}
+
+	var dayErr error
+	eng := engine.NewEngine()
+	eng.Tick = info.StartTick
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = func(tick uint64) {
+		sim.TickDay(tick)
+		if dayErr == nil && onDay != nil {
+			dayErr = onDay(sim, tick)
+		}
+	}
+	eng.OnWeek = sim.TickWeek
+	eng.OnSeason = sim.TickSeason
+
+	end := info.StartTick + uint64(days)*engine.TicksPerSimDay
+	for eng.Tick < end && dayErr == nil {
+		eng.Step()
+	}
+	return sim, dayErr
+}
+
+// knobFlag collects repeated -set name=value flags.
+type knobFlag map[string]float64
+
+func (k knobFlag) String() string { return fmt.Sprint(map[string]float64(k)) }
+
+func (k knobFlag) Set(s string) error {
+	name, val, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("want name=value, got %q", s)
+	}
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fmt.Errorf("knob %s: %w", name, err)
+	}
+	if err := engine.ValidateTuning(map[string]float64{name: v}); err != nil {
+		return err
+	}
+	k[name] = v
+	return nil
+}
+
+// ── Output sinks ─────────────────────────────────────────────────────
+
+type statsSink interface {
+	write(row persistence.StatsRow, settRows []persistence.SettlementStatsRow) error
+	close() error
+}
+
+// openStatsSink picks the sink from out's extension: .db, .sqlite and
+// .sqlite3 create a fresh SQLite file with the production schema (so the
+// /stats/history queries and the projectors' SQL work unchanged); anything
+// else is written as CSV.
+func openStatsSink(out string) (statsSink, error) {
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".db", ".sqlite", ".sqlite3":
+		if _, err := os.Stat(out); err == nil {
+			return nil, fmt.Errorf("%s already exists; batch output must be a fresh file", out)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		db, err := persistence.Open(out)
+		if err != nil {
+			return nil, err
+		}
+		return dbSink{db}, nil
+	default:
+		return newCSVSink(out)
+	}
+}
+
+type dbSink struct{ db *persistence.DB }
+
+func (s dbSink) write(row persistence.StatsRow, settRows []persistence.SettlementStatsRow) error {
+	if err := s.db.SaveStatsSnapshot(row); err != nil {
+		return fmt.Errorf("save stats: %w", err)
+	}
+	if err := s.db.SaveSettlementStats(settRows); err != nil {
+		return fmt.Errorf("save settlement stats: %w", err)
+	}
+	return nil
+}
+
+func (s dbSink) close() error { return s.db.Close() }
+
+// csvSink writes two files whose columns are the rows' db tags, in the same
+// order as the SQLite tables.
+type csvSink struct {
+	files       [2]*os.File
+	stats, sett *csv.Writer
+}
+
+func newCSVSink(out string) (*csvSink, error) {
+	settPath := strings.TrimSuffix(out, filepath.Ext(out)) + "_settlements.csv"
+	s := &csvSink{}
+	for i, path := range []string{out, settPath} {
+		f, err := os.Create(path)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		s.files[i] = f
+	}
+	s.stats = csv.NewWriter(s.files[0])
+	s.sett = csv.NewWriter(s.files[1])
+	s.stats.Write(csvHeader(reflect.TypeFor[persistence.StatsRow]()))
+	s.sett.Write(csvHeader(reflect.TypeFor[persistence.SettlementStatsRow]()))
+	return s, nil
+}
+
+func (s *csvSink) write(row persistence.StatsRow, settRows []persistence.SettlementStatsRow) error {
+	if err := s.stats.Write(csvRecord(reflect.ValueOf(row))); err != nil {
+		return err
+	}
+	for _, r := range settRows {
+		if err := s.sett.Write(csvRecord(reflect.ValueOf(r))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *csvSink) close() error {
+	var errs []error
+	for _, w := range []*csv.Writer{s.stats, s.sett} {
+		if w != nil {
+			w.Flush()
+			errs = append(errs, w.Error())
+		}
+	}
+	for _, f := range s.files {
+		if f != nil {
+			errs = append(errs, f.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func csvHeader(t reflect.Type) []string {
+	cols := make([]string, t.NumField())
+	for i := range cols {
+		cols[i] = t.Field(i).Tag.Get("db")
+	}
+	return cols
+}
+
+func csvRecord(v reflect.Value) []string {
+	rec := make([]string, v.NumField())
+	for i := range rec {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int64:
+			rec[i] = strconv.FormatInt(f.Int(), 10)
+		case reflect.Uint64:
+			rec[i] = strconv.FormatUint(f.Uint(), 10)
+		case reflect.Float64:
+			rec[i] = strconv.FormatFloat(f.Float(), 'g', -1, 64)
+		default:
+			rec[i] = fmt.Sprint(f.Interface())
+		}
+	}
+	return rec
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/talgya/mini-world/internal/persistence"
+)
+
+// runCheckpoint implements `worldsim checkpoint`: boot the world saved in a
+// database the old way (from its tables) and write it out as a binary
+// checkpoint, the one-time migration to the checkpoint format. With -verify
+// the checkpoint is booted again and its state hash compared to the
+// database's. Returns the process exit code.
+func runCheckpoint(args []string) int {
+	fs := flag.NewFlagSet("checkpoint", flag.ExitOnError)
+	configPath := fs.String("config", "", "JSON config file (the server's; supplies the world parameters and -db)")
+	dbPath := fs.String("db", "", "database to read the world from (default: the config's)")
+	seed := fs.Int64("seed", 0, "world seed the database was generated with (overrides the config file; default 42)")
+	out := fs.String("out", "", "checkpoint to write (default: beside the database, where the server looks for it)")
+	verify := fs.Bool("verify", true, "boot the written checkpoint and check it matches the database")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: worldsim checkpoint [-config f] [-db world.db] [-seed N] [-out world.ckpt]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := defaultConfig()
+	if *configPath != "" {
+		if err := readConfigFile(*configPath, &cfg); err != nil {
+			slog.Error("checkpoint: invalid configuration", "error", err)
+			return 2
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "db":
+			cfg.DBPath = *dbPath
+		case "seed":
+			cfg.Seed = *seed
+		}
+	})
+	if *out == "" {
+		cfg.Checkpoints = true
+		*out = cfg.CheckpointPath()
+	}
+
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		slog.Error("checkpoint: no database", "error", err)
+		return 1
+	}
+	db, err := persistence.Open(cfg.DBPath)
+	if err != nil {
+		slog.Error("checkpoint: open database", "error", err)
+		return 1
+	}
+	defer db.Close()
+	if !db.HasWorldState() {
+		slog.Error("checkpoint: database has no saved world", "path", cfg.DBPath)
+		return 1
+	}
+
+	gen := cfg.GenConfig()
+	sim, info, err := bootWorld(db, gen, "")
+	if err != nil {
+		slog.Error("checkpoint: boot world", "error", err)
+		return 1
+	}
+	want := sim.StateHash()
+
+	started := time.Now()
+	if err := persistence.SaveCheckpoint(*out, sim, gen); err != nil {
+		slog.Error("checkpoint: save", "error", err)
+		return 1
+	}
+	slog.Info("checkpoint written", "path", *out, "tick", info.StartTick, "took", time.Since(started).Round(time.Millisecond))
+
+	if !*verify {
+		return 0
+	}
+	back, binfo, err := bootWorld(db, gen, *out)
+	if err != nil {
+		slog.Error("checkpoint: boot written checkpoint", "error", err)
+		return 1
+	}
+	if !binfo.Checkpoint {
+		slog.Error("checkpoint: written checkpoint was not used at boot", "path", *out)
+		return 1
+	}
+	if got := back.StateHash(); got != want {
+		slog.Error("checkpoint: booted checkpoint does not match the database",
+			"hash", fmt.Sprintf("%016x", got), "want", fmt.Sprintf("%016x", want))
+		return 1
+	}
+	slog.Info("checkpoint verified", "hash", fmt.Sprintf("%016x", want))
+	return 0
+}
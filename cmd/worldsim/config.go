@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// Config is the effective worldsim configuration. Precedence, lowest first:
+// built-in defaults, the JSON file named by -config, the legacy environment
+// variables (PORT, WORLDSIM_JOURNAL), then explicitly set flags. API keys stay
+// in the environment and never appear here — the effective config is logged
+// and served publicly on /api/v1/status.
+type Config struct {
+	Seed   int64  `json:"seed"`
+	DBPath string `json:"db_path"`
+	Port   int    `json:"port"`
+
+	// World generation. The map is regenerated from these on every start, so
+	// changing them for an existing database reshapes the land under it.
+	World WorldConfig `json:"world"`
+
+	Speed              float64 `json:"speed"`                // initial speed multiplier (0 = start paused)
+	AutosaveDays       int     `json:"autosave_days"`        // sim-days between world-state saves
+	EventRetentionDays int     `json:"event_retention_days"` // sim-days of events kept hot before archiving
+	PriceRetentionDays int     `json:"price_retention_days"` // sim-days of hourly prices kept before folding into daily bars
+
+	// Checkpoints saves world state to a binary checkpoint beside the database
+	// (<db without extension>.ckpt) instead of the database's world tables.
+	Checkpoints bool `json:"checkpoints"`
+
+	// WAL journals births, deaths, foundings, abandonments, treasury
+	// transfers and hex captures between saves (<db without extension>.wal),
+	// so a crash loses about a second of them instead of up to a sim-day.
+	WAL bool `json:"wal"`
+
+	Integrations Integrations `json:"integrations"`
+
+	JournalDir string `json:"journal_dir,omitempty"` // replay journal directory ("" = off)
+
+	SnapshotDir string `json:"snapshot_dir"` // where POST /api/v1/snapshot writes named snapshots
+
+	// FromSnapshot forks a new world from a named snapshot file. DBPath must
+	// not exist yet; the seed and world parameters come from the snapshot.
+	FromSnapshot string `json:"from_snapshot,omitempty"`
+
+	// Tuning knob overrides applied at startup, over any values saved in the
+	// database. `worldsim scenario -knobs` lists the knobs.
+	Tuning map[string]float64 `json:"tuning,omitempty"`
+}
+
+// WorldConfig is the file/flag form of world.GenConfig (its seed lives in
+// Config.Seed).
+type WorldConfig struct {
+	Radius        int               `json:"radius"`
+	SeaLevel      float64           `json:"sea_level"`
+	MountainLevel float64           `json:"mountain_level"`
+	Noise         world.NoiseParams `json:"noise"`
+}
+
+// Integrations switches external services on or off. An enabled integration
+// still needs its API key in the environment; disabling one ignores the key.
+type Integrations struct {
+	LLM     bool `json:"llm"`
+	Weather bool `json:"weather"`
+	Entropy bool `json:"entropy"`
+}
+
+// defaultConfig reproduces the behavior worldsim had before it was
+// configurable: seed 42, data/crossworlds.db, port 80, daily saves and 30
+// sim-days of event history.
+func defaultConfig() Config {
+	gen := world.DefaultGenConfig()
+	return Config{
+		Seed:   42,
+		DBPath: "data/crossworlds.db",
+		Port:   80,
+		World: WorldConfig{
+			Radius:        gen.Radius,
+			SeaLevel:      gen.SeaLevel,
+			MountainLevel: gen.MountainLvl,
+			Noise:         gen.Noise,
+		},
+		Speed:              1,
+		AutosaveDays:       1,
+		EventRetentionDays: 30,
+		PriceRetentionDays: 7,
+		Checkpoints:        true,
+		WAL:                true,
+		SnapshotDir:        "data/snapshots",
+		Integrations:       Integrations{LLM: true, Weather: true, Entropy: true},
+	}
+}
+
+// GenConfig returns the world generation parameters.
+func (c Config) GenConfig() world.GenConfig {
+	return world.GenConfig{
+		Radius:      c.World.Radius,
+		Seed:        c.Seed,
+		SeaLevel:    c.World.SeaLevel,
+		MountainLvl: c.World.MountainLevel,
+		Noise:       c.World.Noise,
+	}
+}
+
+// CheckpointPath returns where world checkpoints are kept, or "" when they
+// are off.
+func (c Config) CheckpointPath() string {
+	if !c.Checkpoints {
+		return ""
+	}
+	return strings.TrimSuffix(c.DBPath, filepath.Ext(c.DBPath)) + ".ckpt"
+}
+
+// WALPath returns where the write-ahead journal is kept, or "" when it is
+// off.
+func (c Config) WALPath() string {
+	if !c.WAL {
+		return ""
+	}
+	return strings.TrimSuffix(c.DBPath, filepath.Ext(c.DBPath)) + ".wal"
+}
+
+// validate rejects configurations the engine cannot run.
+func (c Config) validate() error {
+	switch {
+	case c.DBPath == "":
+		return errors.New("db_path must be set")
+	case c.Port <= 0 || c.Port > 65535:
+		return fmt.Errorf("port %d out of range", c.Port)
+	case c.World.Radius < 3:
+		return fmt.Errorf("world.radius %d too small (min 3)", c.World.Radius)
+	case c.World.SeaLevel < 0 || c.World.SeaLevel >= c.World.MountainLevel || c.World.MountainLevel > 1:
+		return fmt.Errorf("need 0 <= world.sea_level < world.mountain_level <= 1 (got %.2f, %.2f)",
+			c.World.SeaLevel, c.World.MountainLevel)
+	case c.Speed < 0:
+		return fmt.Errorf("speed %.2f must not be negative", c.Speed)
+	case c.AutosaveDays < 1:
+		return fmt.Errorf("autosave_days %d must be at least 1", c.AutosaveDays)
+	case c.EventRetentionDays < 1:
+		return fmt.Errorf("event_retention_days %d must be at least 1", c.EventRetentionDays)
+	case c.PriceRetentionDays < 1:
+		return fmt.Errorf("price_retention_days %d must be at least 1", c.PriceRetentionDays)
+	case c.SnapshotDir == "":
+		return errors.New("snapshot_dir must be set")
+	}
+	if err := engine.ValidateTuning(c.Tuning); err != nil {
+		return fmt.Errorf("tuning: %w", err)
+	}
+	return nil
+}
+
+// loadConfig builds the effective configuration from args (os.Args[1:] for
+// the server).
+func loadConfig(args []string) (Config, error) {
+	cfg := defaultConfig()
+
+	fs := flag.NewFlagSet("worldsim", flag.ContinueOnError)
+	configPath := fs.String("config", "", "JSON config file (flags override it)")
+	seed := fs.Int64("seed", cfg.Seed, "world seed")
+	dbPath := fs.String("db", cfg.DBPath, "SQLite database path")
+	port := fs.Int("port", cfg.Port, "HTTP API port (overrides $PORT)")
+	radius := fs.Int("radius", cfg.World.Radius, "hex grid radius")
+	seaLevel := fs.Float64("sea-level", cfg.World.SeaLevel, "elevation threshold for ocean (0-1)")
+	mountainLevel := fs.Float64("mountain-level", cfg.World.MountainLevel, "elevation threshold for mountains (0-1)")
+	speed := fs.Float64("speed", cfg.Speed, "initial speed multiplier (0 = paused)")
+	autosave := fs.Int("autosave-days", cfg.AutosaveDays, "sim-days between world-state saves")
+	retention := fs.Int("event-retention-days", cfg.EventRetentionDays, "sim-days of events kept in the events table before they move to the archive")
+	priceRetention := fs.Int("price-retention-days", cfg.PriceRetentionDays, "sim-days of hourly market prices kept before they are folded into daily bars")
+	checkpoints := fs.Bool("checkpoints", cfg.Checkpoints, "save world state to a binary checkpoint beside the database")
+	useWAL := fs.Bool("wal", cfg.WAL, "journal domain changes between saves and replay them after a crash")
+	useLLM := fs.Bool("llm", cfg.Integrations.LLM, "enable the LLM integration (needs ANTHROPIC_API_KEY or LLM_PROVIDERS)")
+	useWeather := fs.Bool("weather", cfg.Integrations.Weather, "enable real weather (needs WEATHER_API_KEY)")
+	useEntropy := fs.Bool("entropy", cfg.Integrations.Entropy, "enable random.org entropy (needs RANDOM_ORG_API_KEY)")
+	journal := fs.String("journal", "", "replay journal directory (overrides $WORLDSIM_JOURNAL; empty = off)")
+	snapshotDir := fs.String("snapshot-dir", cfg.SnapshotDir, "directory for named snapshots")
+	fromSnapshot := fs.String("from-snapshot", "", "fork a new world (at -db, which must not exist) from a named snapshot file")
+	knobs := knobFlag{}
+	fs.Var(knobs, "set", "override a tuning knob as name=value (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if *configPath != "" {
+		if err := readConfigFile(*configPath, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if p := os.Getenv("PORT"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil {
+			cfg.Port = v
+		}
+	}
+	if dir := os.Getenv("WORLDSIM_JOURNAL"); dir != "" {
+		cfg.JournalDir = dir
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed":
+			cfg.Seed = *seed
+		case "db":
+			cfg.DBPath = *dbPath
+		case "port":
+			cfg.Port = *port
+		case "radius":
+			cfg.World.Radius = *radius
+		case "sea-level":
+			cfg.World.SeaLevel = *seaLevel
+		case "mountain-level":
+			cfg.World.MountainLevel = *mountainLevel
+		case "speed":
+			cfg.Speed = *speed
+		case "autosave-days":
+			cfg.AutosaveDays = *autosave
+		case "event-retention-days":
+			cfg.EventRetentionDays = *retention
+		case "price-retention-days":
+			cfg.PriceRetentionDays = *priceRetention
+		case "checkpoints":
+			cfg.Checkpoints = *checkpoints
+		case "wal":
+			cfg.WAL = *useWAL
+		case "llm":
+			cfg.Integrations.LLM = *useLLM
+		case "weather":
+			cfg.Integrations.Weather = *useWeather
+		case "entropy":
+			cfg.Integrations.Entropy = *useEntropy
+		case "journal":
+			cfg.JournalDir = *journal
+		case "snapshot-dir":
+			cfg.SnapshotDir = *snapshotDir
+		case "from-snapshot":
+			cfg.FromSnapshot = *fromSnapshot
+		}
+	})
+	if len(knobs) > 0 {
+		if cfg.Tuning == nil {
+			cfg.Tuning = map[string]float64{}
+		}
+		for name, v := range knobs {
+			cfg.Tuning[name] = v
+		}
+	}
+
+	return cfg, cfg.validate()
+}
+
+// readConfigFile overlays the JSON file at path onto cfg. Keys absent from the
+// file keep cfg's values; unknown keys are an error, so a typo cannot silently
+// fall back to a default.
+func readConfigFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
@@ -3,24 +3,22 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
-	"math/rand"
 	"os"
 	"os/signal"
-	"strconv"
+	"path/filepath"
 	"syscall"
 
-	"github.com/talgya/mini-world/internal/agents"
 	"github.com/talgya/mini-world/internal/api"
 	"github.com/talgya/mini-world/internal/engine"
 	"github.com/talgya/mini-world/internal/entropy"
 	"github.com/talgya/mini-world/internal/llm"
 	"github.com/talgya/mini-world/internal/persistence"
 	"github.com/talgya/mini-world/internal/phi"
-	"github.com/talgya/mini-world/internal/social"
 	"github.com/talgya/mini-world/internal/weather"
-	"github.com/talgya/mini-world/internal/world"
 )
 
 func main() {
@@ -29,6 +27,21 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "batch":
+			os.Exit(runBatch(os.Args[2:]))
+		case "scenario":
+			os.Exit(runScenario(os.Args[2:]))
+		case "checkpoint":
+			os.Exit(runCheckpoint(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		}
+	}
+
 	slog.Info("SYNTHESIS / Crossworlds — Autonomous World Simulation")
 	slog.Info("emanation constants",
 		"phi", phi.Phi,
@@ -38,482 +51,266 @@ func main() {
 		"totality", fmt.Sprintf("%.5f", phi.Totality),
 	)
 
-	seed := int64(42)
-	dbPath := "data/crossworlds.db"
-	apiPort := 80
-	if p := os.Getenv("PORT"); p != "" {
-		if v, err := strconv.Atoi(p); err == nil {
-			apiPort = v
+	// ── Configuration ─────────────────────────────────────────────────
+	cfg, err := loadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	// A fork takes its map parameters from the snapshot, so settle them
+	// before the effective config is logged.
+	if cfg.FromSnapshot != "" {
+		if err := forkFromSnapshot(&cfg); err != nil {
+			slog.Error("cannot fork from snapshot", "error", err)
+			os.Exit(1)
 		}
 	}
+	if cfgJSON, err := json.Marshal(cfg); err == nil {
+		slog.Info("effective config", "config", string(cfgJSON))
+	}
 
 	// ── Database ──────────────────────────────────────────────────────
-	os.MkdirAll("data", 0755)
-	db, err := persistence.Open(dbPath)
+	os.MkdirAll(filepath.Dir(cfg.DBPath), 0755)
+	db, err := persistence.Open(cfg.DBPath)
 	if err != nil {
 		slog.Error("failed to open database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
-	slog.Info("database opened", "path", dbPath)
-
-	// ── World Map (always regenerated — deterministic from seed) ──────
-	slog.Info("generating world map...")
-	cfg := world.DefaultGenConfig()
-	cfg.Seed = seed
-	worldMap := world.Generate(cfg)
+	slog.Info("database opened", "path", cfg.DBPath)
 
-	counts := world.TerrainCounts(worldMap)
-	landHexes := 0
-	for t, c := range counts {
-		if t != world.TerrainOcean {
-			landHexes += c
-		}
-		slog.Info("terrain", "type", world.TerrainName(t), "count", c)
+	ckptPath := cfg.CheckpointPath()
+	sim, info, err := bootWorld(db, cfg.GenConfig(), ckptPath)
+	if err != nil {
+		slog.Error("failed to load world", "error", err)
+		os.Exit(1)
 	}
-
-	// ── Load or Generate World State ─────────────────────────────────
-	var allSettlements []*social.Settlement
-	var allAgents []*agents.Agent
-	var startTick uint64
-	var startSeason uint8
-
-	spawner := agents.NewSpawner(seed)
-
-	if db.HasWorldState() {
-		// Restore from saved state.
-		slog.Info("found saved world state, loading...")
-
-		var loadErr error
-		allAgents, loadErr = db.LoadAgents()
-		if loadErr != nil {
-			slog.Error("failed to load agents", "error", loadErr)
-			os.Exit(1)
-		}
-
-		allSettlements, loadErr = db.LoadSettlements()
-		if loadErr != nil {
-			slog.Error("failed to load settlements", "error", loadErr)
+	startTick := info.StartTick
+
+	// ── Crash Recovery ────────────────────────────────────────────────
+	// Replay the domain changes a crash left in the write-ahead journal on
+	// top of the save just booted. The world resumes at the last of them.
+	var wal *persistence.WAL
+	var recovery *persistence.RecoveryReport
+	if walPath := cfg.WALPath(); walPath != "" {
+		recovery, err = persistence.RecoverWAL(walPath, sim)
+		if err != nil {
+			slog.Error("failed to recover journal", "error", err)
 			os.Exit(1)
 		}
-
-		// One-time migration: spread existing agents across age months.
-		// Without this, all agents have AgeMonths=0 and would age together
-		// on the first monthly tick (same cliff as yearly aging).
-		// Newborns (Age=0, AgeMonths=0) are excluded — their month counter
-		// starts at 0 and increments naturally from birth.
-		// This block can be removed after one successful deploy.
-		seeded := 0
-		for _, a := range allAgents {
-			if a.AgeMonths == 0 && a.Age > 0 {
-				a.AgeMonths = uint8(a.ID % 12)
-				seeded++
-			}
-		}
-		if seeded > 0 {
-			slog.Info("seeded age months for existing agents", "count", seeded)
-		}
-
-		// Restore tick and season from metadata.
-		if tickStr, err := db.GetMeta("last_tick"); err == nil {
-			if t, err := strconv.ParseUint(tickStr, 10, 64); err == nil {
-				startTick = t
-			}
-		}
-		if seasonStr, err := db.GetMeta("season"); err == nil {
-			if s, err := strconv.ParseUint(seasonStr, 10, 8); err == nil {
-				startSeason = uint8(s)
-			}
-		}
-
-		// Update spawner next ID to be above the highest existing agent ID.
-		var maxID agents.AgentID
-		for _, a := range allAgents {
-			if a.ID > maxID {
-				maxID = a.ID
-			}
-		}
-		spawner.SetNextID(maxID + 1)
-
-		// Promote Tier 1 agents if none exist yet (backfill for existing worlds).
-		tier1Count := 0
-		for _, a := range allAgents {
-			if a.Tier == agents.Tier1 {
-				tier1Count++
-			}
-		}
-		if tier1Count == 0 {
-			agents.PromoteToTier1(allAgents, 0.04)
-			promoted := 0
-			for _, a := range allAgents {
-				if a.Tier == agents.Tier1 {
-					promoted++
-				}
-			}
-			slog.Info("backfilled Tier 1 agents", "promoted", promoted)
-		}
-
-		slog.Info("world state restored",
-			"agents", len(allAgents),
-			"settlements", len(allSettlements),
-			"tick", startTick,
-			"season", engine.SeasonName(startSeason),
-			"sim_time", engine.SimTime(startTick),
-		)
-	} else {
-		// Fresh world generation.
-		slog.Info("no saved state found, generating new world...")
-
-		settlementSeeds := world.PlaceSettlements(worldMap, seed)
-		rng := rand.New(rand.NewSource(seed + 400))
-
-		for i, ss := range settlementSeeds {
-			pop := world.PopulationForSize(ss.Size, rng)
-
-			var gov social.GovernanceType
-			switch ss.Size {
-			case world.SizeCity:
-				gov = social.GovMonarchy
-			case world.SizeTown:
-				if rng.Float32() < 0.5 {
-					gov = social.GovCouncil
-				} else {
-					gov = social.GovMerchantRepublic
-				}
-			default:
-				gov = social.GovCommune
-			}
-
-			sid := uint64(i + 1)
-			settlement := &social.Settlement{
-				ID:              sid,
-				Name:            ss.Name,
-				Position:        ss.Coord,
-				Population:      pop,
-				Governance:      gov,
-				TaxRate:         0.10,
-				Treasury:        uint64(pop) * 5,
-				GovernanceScore: 0.5 + rng.Float64()*0.3,
-				MarketLevel:     1,
-			}
-
-			hex := worldMap.Get(ss.Coord)
-			if hex != nil {
-				hex.SettlementID = &sid
-			}
-
-			allSettlements = append(allSettlements, settlement)
-
-			terrain := world.TerrainPlains
-			if hex != nil {
-				terrain = hex.Terrain
-			}
-			popAgents := spawner.SpawnPopulation(pop, ss.Coord, sid, terrain)
-			allAgents = append(allAgents, popAgents...)
-		}
-
-		agents.PromoteToTier2(allAgents, 30)
-		agents.PromoteToTier1(allAgents, 0.04) // 4% of remaining Tier 0 agents
-
-		for _, a := range allAgents {
-			if a.Tier == agents.Tier2 {
-				slog.Info("notable character",
-					"name", a.Name,
-					"age", a.Age,
-					"occupation", a.Occupation,
-					"coherence", fmt.Sprintf("%.3f", a.Soul.CittaCoherence),
-					"wealth", a.Wealth,
-				)
-			}
-		}
-	}
-
-	// Restore hex health from database (must happen before the default-to-pristine loop).
-	if startTick > 0 {
-		if healthStr, err := db.GetMeta("hex_health"); err == nil {
-			var hexHealth map[string]struct {
-				H  float64 `json:"h"`
-				T  uint64  `json:"t"`
-				Ir uint8   `json:"ir,omitempty"`
-				Co uint8   `json:"co,omitempty"`
-				Cl *uint64 `json:"cl,omitempty"`
-			}
-			if json.Unmarshal([]byte(healthStr), &hexHealth) == nil && len(hexHealth) > 0 {
-				for key, entry := range hexHealth {
-					var q, r int
-					fmt.Sscanf(key, "%d,%d", &q, &r)
-					if hex := worldMap.Get(world.HexCoord{Q: q, R: r}); hex != nil {
-						hex.Health = entry.H
-						hex.LastExtractedTick = entry.T
-						hex.IrrigationLevel = entry.Ir
-						hex.ConservationLevel = entry.Co
-						hex.ClaimedBy = entry.Cl
-					}
-				}
-				slog.Info("hex health restored", "degraded_hexes", len(hexHealth))
-			}
-		}
-	}
-
-	// Restore hex resource quantities from database. Without this, resources reset
-	// to fresh-generation values on every restart, causing an artificial work rate spike.
-	if startTick > 0 {
-		if resStr, err := db.GetMeta("hex_resources"); err == nil {
-			var hexResources map[string]map[string]float64
-			if json.Unmarshal([]byte(resStr), &hexResources) == nil && len(hexResources) > 0 {
-				restored := 0
-				for key, resMap := range hexResources {
-					var q, r int
-					fmt.Sscanf(key, "%d,%d", &q, &r)
-					if hex := worldMap.Get(world.HexCoord{Q: q, R: r}); hex != nil {
-						for resKey, qty := range resMap {
-							var resType int
-							fmt.Sscanf(resKey, "%d", &resType)
-							hex.Resources[world.ResourceType(resType)] = qty
-						}
-						restored++
-					}
-				}
-				slog.Info("hex resources restored", "hexes", restored)
-			}
+		if recovery.Records > 0 || recovery.TornBytes > 0 {
+			slog.Info("journal recovered",
+				"path", walPath,
+				"records", recovery.Records,
+				"already_saved", recovery.AlreadySaved,
+				"applied", recovery.Applied,
+				"unchanged", recovery.Unchanged,
+				"from_tick", recovery.FromTick,
+				"to_tick", recovery.ToTick,
+				"skipped_ticks", recovery.SkippedTicks,
+				"torn_bytes", recovery.TornBytes,
+				"took", recovery.Took,
+			)
 		}
-	}
-
-	// Default any hex with zero health to pristine (handles first deploy
-	// where no hex_health metadata exists yet).
-	for _, hex := range worldMap.Hexes {
-		if hex.Health == 0 && hex.LastExtractedTick == 0 {
-			hex.Health = 1.0
-		}
-	}
-
-	// Link settlement hex references (needed for both fresh and loaded worlds).
-	for _, st := range allSettlements {
-		sid := st.ID
-		hex := worldMap.Get(st.Position)
-		if hex != nil {
-			hex.SettlementID = &sid
-		}
-	}
-
-	slog.Info("world ready",
-		"agents", len(allAgents),
-		"settlements", len(allSettlements),
-		"hexes", worldMap.HexCount(),
-	)
-
-	// ── Simulation ────────────────────────────────────────────────────
-	sim := engine.NewSimulation(worldMap, allAgents, allSettlements)
-	sim.Spawner = spawner
-	sim.LastTick = startTick
-	sim.CurrentSeason = startSeason
-
-	// Initialize or load factions.
-	if startTick > 0 && db.HasFactions() {
-		factions, err := db.LoadFactions()
-		if err != nil {
-			slog.Warn("failed to load factions, re-initializing", "error", err)
-			sim.InitFactions()
-		} else {
-			sim.SetFactions(factions)
+		startTick = sim.LastTick
+		if wal, err = persistence.OpenWAL(walPath); err != nil {
+			slog.Error("failed to open journal", "error", err)
+			os.Exit(1)
 		}
-	} else {
-		sim.InitFactions()
-	}
-
-	// R76: restore all late-persisted world state via the registry. Each
-	// field's Save+Load logic is co-located in `internal/persistence/world_state.go`,
-	// so adding new persistent state is a single registry entry — no more
-	// wire-it-and-pray. The registry itself handles missing-key cases
-	// gracefully, so calling on a fresh world is a no-op.
-	if startTick > 0 {
-		db.RestoreLatePersisted(sim)
+		defer wal.Close()
 	}
 
-	// Load agent memories and relationships from database (if any exist).
-	if startTick > 0 {
-		if err := db.LoadMemories(sim.AgentIndex); err != nil {
-			slog.Warn("failed to load memories", "error", err)
+	// Startup tuning overrides win over knobs restored from world_meta.
+	// Persist them now so the journal's base snapshot (and the next restart,
+	// if the config changes) sees the effective values.
+	if len(cfg.Tuning) > 0 {
+		if err := sim.ApplyTuning(cfg.Tuning); err != nil {
+			slog.Error("invalid tuning", "error", err)
+			os.Exit(2)
 		}
-		if err := db.LoadRelationships(sim.AgentIndex); err != nil {
-			slog.Warn("failed to load relationships", "error", err)
-		}
-	}
-
-	// Load recent events from database so /api/v1/events works after restart.
-	if startTick > 0 {
-		events, err := db.RecentEvents(1000)
-		if err != nil {
-			slog.Warn("failed to load events", "error", err)
-		} else if len(events) > 0 {
-			// Reverse so oldest is first (DB returns newest first).
-			for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
-				events[i], events[j] = events[j], events[i]
-			}
-			sim.Events = events
-			slog.Info("events loaded from database", "count", len(events))
+		if err := db.SaveTuning(sim); err != nil {
+			slog.Error("failed to save tuning", "error", err)
 		}
+		slog.Info("tuning overrides applied", "knobs", cfg.Tuning)
 	}
 
 	// ── LLM Client ───────────────────────────────────────────────────
-	anthropicKey := os.Getenv("ANTHROPIC_API_KEY")
-	llmClient := llm.NewClient(anthropicKey)
+	var llmClient *llm.Client
+	if cfg.Integrations.LLM {
+		llmClient = llm.NewClient(os.Getenv("ANTHROPIC_API_KEY"))
+	}
 	if llmClient != nil {
 		slog.Info("LLM client enabled (Haiku)")
 		sim.LLM = llmClient
+	} else if !cfg.Integrations.LLM {
+		slog.Info("LLM integration disabled by config — newspaper will use fallback")
 	} else {
 		slog.Warn("ANTHROPIC_API_KEY not set — LLM features disabled (newspaper will use fallback)")
 	}
 
 	// ── Weather Client ────────────────────────────────────────────────
-	weatherKey := os.Getenv("WEATHER_API_KEY")
 	weatherLoc := os.Getenv("WEATHER_LOCATION")
-	weatherClient := weather.NewClient(weatherKey, weatherLoc)
+	var weatherClient *weather.Client
+	if cfg.Integrations.Weather {
+		weatherClient = weather.NewClient(os.Getenv("WEATHER_API_KEY"), weatherLoc)
+	}
 	if weatherClient != nil {
 		slog.Info("weather client enabled", "location", weatherLoc)
 		sim.WeatherClient = weatherClient
+	} else if !cfg.Integrations.Weather {
+		slog.Info("weather integration disabled by config — using seasonal weather defaults")
 	} else {
 		slog.Info("WEATHER_API_KEY not set — using seasonal weather defaults")
 	}
 
 	// ── Entropy Client ────────────────────────────────────────────────
-	randomOrgKey := os.Getenv("RANDOM_ORG_API_KEY")
-	entropyClient := entropy.NewClient(randomOrgKey)
+	var entropyClient *entropy.Client
+	if cfg.Integrations.Entropy {
+		entropyClient = entropy.NewClient(os.Getenv("RANDOM_ORG_API_KEY"))
+	}
 	if entropyClient != nil {
 		slog.Info("entropy client enabled (random.org)")
 		sim.Entropy = entropyClient
+	} else if !cfg.Integrations.Entropy {
+		slog.Info("entropy integration disabled by config — using crypto/rand for entropy")
 	} else {
 		slog.Info("RANDOM_ORG_API_KEY not set — using crypto/rand for entropy")
 	}
 
-	// Save on fresh generation only (loaded worlds are already saved).
-	if startTick == 0 {
-		if err := db.SaveWorldStateFull(sim); err != nil {
-			slog.Error("initial save failed", "error", err)
+	// ── Replay Journal ────────────────────────────────────────────────
+	// cfg.JournalDir (-journal / WORLDSIM_JOURNAL) names a directory; each
+	// process start opens a fresh journal segment there, paired with a base
+	// snapshot of the world as booted. `worldsim replay <journal>` re-runs the
+	// segment and verifies it.
+	if cfg.JournalDir != "" {
+		rec, err := startJournal(cfg.JournalDir, db, sim, cfg.GenConfig(), startTick)
+		if err != nil {
+			slog.Error("failed to start replay journal", "error", err)
+			os.Exit(1)
 		}
+		defer rec.Close()
 	}
 
-	// Pre-compute settlement neighbor index for O(1) proximity lookups.
-	sim.BuildSettlementNeighbors()
-	// Pre-compute diplomacy crime bonus cache from loaded agreements.
-	sim.BuildDiplomacyCrimeBonusCache()
-
-	// R86: Recompute stats after resume so producer_health.work_rate is
-	// accurate immediately. NewSimulation() runs updateStats() during
-	// construction, but at that point sim.LastTick == 0, so the
-	// `s.LastTick > 0` guard in the work_rate computation flagged every
-	// producer as idle. Surfaced 2026-05-05 by kernel reboot (W-8).
-	if startTick > 0 {
-		sim.RecomputeStats()
+	// World saves go to the checkpoint when it is on; the database then only
+	// receives history (events, the intervention ledger). If the checkpoint
+	// cannot be written the database save runs instead, so no save is lost.
+	saveWorldState := func(full bool) error {
+		if ckptPath != "" {
+			err := persistence.SaveCheckpoint(ckptPath, sim, cfg.GenConfig())
+			if err == nil {
+				return db.SaveHistory(sim)
+			}
+			slog.Error("checkpoint save failed, saving to the database instead", "error", err)
+		}
+		if full {
+			return db.SaveWorldStateFull(sim)
+		}
+		return db.SaveWorldState(sim)
+	}
+	// Every change journaled so far is in a successful save, so the journal
+	// starts over.
+	saveWorld := func(full bool) error {
+		if err := saveWorldState(full); err != nil {
+			return err
+		}
+		if wal != nil {
+			if err := wal.Reset(); err != nil {
+				slog.Error("journal reset failed", "error", err)
+			}
+		}
+		return nil
+	}
+	if wal != nil {
+		// Save what recovery replayed before anything new is journaled.
+		if recovery.Recovered() > 0 {
+			if err := saveWorld(false); err != nil {
+				slog.Error("save after journal recovery failed", "error", err)
+			}
+		} else if recovery.Records > 0 {
+			if err := wal.Reset(); err != nil {
+				slog.Error("journal reset failed", "error", err)
+			}
+		}
+		sim.ChangeLog = wal
 	}
 
 	eng := engine.NewEngine()
 	eng.Tick = startTick
-	eng.SetSpeed(1)
+	eng.SetSpeed(cfg.Speed)
 
-	// Wire tick callbacks — auto-save every sim-day.
+	// Wire tick callbacks — stats every sim-day, auto-save every
+	// cfg.AutosaveDays sim-days.
 	eng.OnTick = sim.TickMinute
-	eng.OnHour = sim.TickHour
+	eng.OnHour = func(tick uint64) {
+		sim.TickHour(tick)
+		saveHourly(db, sim)
+	}
 	eng.OnDay = func(tick uint64) {
 		sim.TickDay(tick)
-		// Build occupation JSON for stats history.
-		occData := struct {
-			Counts           [10]int     `json:"counts"`
-			Sat              [10]float32 `json:"sat"`
-			ProducersWorking int         `json:"producers_working"`
-			ProducersIdle    int         `json:"producers_idle"`
-		}{
-			Counts:           sim.Stats.OccupationCounts,
-			Sat:              sim.Stats.OccupationSat,
-			ProducersWorking: sim.Stats.ProducersWorking,
-			ProducersIdle:    sim.Stats.ProducersIdle,
-		}
-		occJSON, _ := json.Marshal(occData)
-
-		// Save daily stats snapshot.
-		bottom50, top10 := sim.WealthDistribution()
-		statsRow := persistence.StatsRow{
-			Tick:            tick,
-			Population:      sim.Stats.TotalPopulation,
-			TotalWealth:     sim.Stats.TotalWealth,
-			AvgMood:         float64(sim.Stats.AvgMood),
-			AvgSurvival:     float64(sim.Stats.AvgSurvival),
-			Births:          sim.Stats.Births,
-			Deaths:          sim.Stats.Deaths,
-			TradeVolume:     sim.Stats.TradeVolume,
-			AvgCoherence:    sim.AvgCoherence(),
-			SettlementCount: len(sim.Settlements),
-			Gini:            sim.GiniCoefficient(),
-			AvgSatisfaction: float64(sim.Stats.AvgSatisfaction),
-			AvgAlignment:    float64(sim.Stats.AvgAlignment),
-			OccupationJSON:  string(occJSON),
-			Bottom50Share:   bottom50,
-			Top10Share:      top10,
-		}
-		if err := db.SaveStatsSnapshot(statsRow); err != nil {
+		// Save daily stats snapshots.
+		if err := db.SaveStatsSnapshot(persistence.BuildStatsRow(sim, tick)); err != nil {
 			slog.Error("stats snapshot failed", "error", err)
 		}
-		// Save per-settlement stats snapshot.
-		govNames := [4]string{"Monarchy", "Council", "Merchant Republic", "Commune"}
-		var settRows []persistence.SettlementStatsRow
-		for _, sett := range sim.Settlements {
-			agents := sim.SettlementAgents[sett.ID]
-			pop := 0
-			totalSat := float64(0)
-			for _, a := range agents {
-				if a.Alive {
-					pop++
-					totalSat += float64(a.Wellbeing.Satisfaction)
-				}
-			}
-			avgSat := float64(0)
-			if pop > 0 {
-				avgSat = totalSat / float64(pop)
-			}
-			cap, pressure := sim.SettlementCarryingCapacity(sett.ID)
-			// Count trade volume from relations.
-			tradeVol := 0
-			for key, rel := range sim.Relations {
-				if key.A == sett.ID || key.B == sett.ID {
-					tradeVol += int(rel.Trade)
-				}
-			}
-			govName := "Unknown"
-			if int(sett.Governance) < len(govNames) {
-				govName = govNames[sett.Governance]
-			}
-			settRows = append(settRows, persistence.SettlementStatsRow{
-				Tick:               tick,
-				SettlementID:       sett.ID,
-				Population:         pop,
-				Treasury:           sett.Treasury,
-				AvgSatisfaction:    avgSat,
-				TradeVolume:        tradeVol,
-				Governance:         govName,
-				GovernanceScore:    sett.GovernanceScore,
-				CarryingCapacity:   cap,
-				PopulationPressure: pressure,
-			})
-		}
-		if err := db.SaveSettlementStats(settRows); err != nil {
+		if err := db.SaveSettlementStats(persistence.BuildSettlementStatsRows(sim, tick)); err != nil {
 			slog.Error("settlement stats snapshot failed", "error", err)
 		}
-		// Auto-save daily.
-		if err := db.SaveWorldState(sim); err != nil {
-			slog.Error("daily save failed", "error", err)
+		// Auto-save on the configured cadence (daily by default).
+		if (tick/engine.TicksPerSimDay)%uint64(cfg.AutosaveDays) == 0 {
+			if err := saveWorld(false); err != nil {
+				slog.Error("daily save failed", "error", err)
+			}
 		}
 	}
 	eng.OnWeek = func(tick uint64) {
 		sim.TickWeek(tick)
-		// Trim old events: keep 30 sim-days (~43,200 ticks).
-		trimmed, err := db.TrimOldEvents(tick, 30*1440)
+		// Move events beyond the retention window (default 30 sim-days)
+		// into the compressed archive.
+		archived, err := db.ArchiveOldEvents(tick, uint64(cfg.EventRetentionDays)*engine.TicksPerSimDay)
+		if err != nil {
+			slog.Error("event archive failed", "error", err)
+		} else if archived > 0 {
+			slog.Info("archived old events", "moved", archived)
+		}
+		// Fold hourly prices beyond their window (default 7 sim-days) into
+		// daily bars.
+		folded, err := db.DownsamplePrices(tick, uint64(cfg.PriceRetentionDays)*engine.TicksPerSimDay)
 		if err != nil {
-			slog.Error("event trim failed", "error", err)
-		} else if trimmed > 0 {
-			slog.Info("trimmed old events", "removed", trimmed)
+			slog.Error("price downsampling failed", "error", err)
+		} else if folded > 0 {
+			slog.Info("downsampled old prices", "hourly_bars", folded)
 		}
 	}
 	eng.OnSeason = sim.TickSeason
+	// Scheduled interventions land after their tick; the ledger (and any
+	// tuning change) is saved as they settle. The journal syncs about once a
+	// second of wall time.
+	eng.AfterTick = func(tick uint64) {
+		if wal != nil {
+			wal.MaybeSync()
+		}
+		settled := sim.ApplyDueInterventions(tick)
+		if len(settled) == 0 {
+			return
+		}
+		if err := db.SaveInterventions(settled); err != nil {
+			slog.Error("intervention ledger save failed", "error", err)
+		}
+		for _, rec := range settled {
+			if rec.Status == engine.InterventionApplied && rec.Request.Type == engine.InterventionTune {
+				if err := db.SaveTuning(sim); err != nil {
+					slog.Error("tuning save failed", "error", err)
+				}
+				break
+			}
+		}
+	}
+	// The API serves a snapshot refreshed once per sim-hour, never the live
+	// state the loop is mutating.
+	eng.AfterHour = func(uint64) { sim.PublishView() }
+	sim.PublishView()
 
 	// ── HTTP API ──────────────────────────────────────────────────────
 	adminKey := os.Getenv("WORLDSIM_ADMIN_KEY")
@@ -530,9 +327,15 @@ func main() {
 		Eng:      eng,
 		LLM:      llmClient,
 		DB:       db,
-		Port:     apiPort,
+		Port:     cfg.Port,
 		AdminKey: adminKey,
 		RelayKey: relayKey,
+		Config:   cfg,
+
+		SnapshotDir: cfg.SnapshotDir,
+		Gen:         cfg.GenConfig(),
+		SaveWorld:   func() error { return saveWorld(false) },
+		Recovery:    recovery,
 	}
 	apiServer.Start()
 
@@ -546,8 +349,8 @@ func main() {
 	}()
 
 	fmt.Printf("\nCrossworlds is alive: %d souls across %d settlements on %d land hexes.\n",
-		len(allAgents), len(allSettlements), landHexes)
-	fmt.Printf("API: http://localhost:%d/api/v1/status\n", apiPort)
+		len(sim.Agents), len(sim.Settlements), info.LandHexes)
+	fmt.Printf("API: http://localhost:%d/api/v1/status\n", cfg.Port)
 	if startTick > 0 {
 		fmt.Printf("Resuming from tick %d (%s)\n", startTick, engine.SimTime(startTick))
 	}
@@ -555,11 +358,26 @@ func main() {
 
 	eng.Run()
 
-	// Final save on shutdown — full save including memories and relationships.
+	// Final save on shutdown — full save including memories and relationships
+	// (a checkpoint always has them).
 	slog.Info("final save (full)...")
-	if err := db.SaveWorldStateFull(sim); err != nil {
+	if err := saveWorld(true); err != nil {
 		slog.Error("final save failed", "error", err)
 	}
 
 	fmt.Println("Simulation stopped. World state saved.")
 }
+
+// saveHourly writes the price bars and, in both save modes, the memories and
+// relationships that changed. Memory search reads the database, so with
+// checkpoints on it would otherwise see only what the world booted with.
+// Only the agents whose memories or relationships changed are rewritten:
+// about 0.2s at 100K agents, not a pause.
+func saveHourly(db persistence.Store, sim *engine.Simulation) {
+	if err := db.SavePriceBars(sim.PriceBars); err != nil {
+		slog.Error("price history save failed", "error", err)
+	}
+	if err := db.SaveSocial(sim.Agents); err != nil {
+		slog.Error("memory and relationship save failed", "error", err)
+	}
+}
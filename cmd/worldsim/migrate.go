@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/talgya/mini-world/internal/persistence"
+)
+
+// runMigrate implements `worldsim migrate status|up|verify`: show which
+// schema migrations a database has, apply the pending ones, or check its
+// schema against the current one. The server applies pending migrations at
+// boot anyway; this is for looking before a deploy and checking after.
+// Returns the process exit code.
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configPath := fs.String("config", "", "JSON config file (the server's; supplies -db)")
+	dbPath := fs.String("db", "", "database to migrate (default: the config's)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: worldsim migrate [-config f] [-db world.db] status|up|verify")
+		fmt.Fprintln(fs.Output(), "  status  list migrations and which are applied")
+		fmt.Fprintln(fs.Output(), "  up      apply pending migrations")
+		fmt.Fprintln(fs.Output(), "  verify  compare the schema with the current one; exit 1 on any difference")
+		fs.PrintDefaults()
+	}
+	// The action may come before or after the flags.
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	action := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 || (action != "status" && action != "up" && action != "verify") {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := defaultConfig()
+	if *configPath != "" {
+		if err := readConfigFile(*configPath, &cfg); err != nil {
+			slog.Error("migrate: invalid configuration", "error", err)
+			return 2
+		}
+	}
+	if *dbPath != "" {
+		cfg.DBPath = *dbPath
+	}
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		slog.Error("migrate: no database", "error", err)
+		return 1
+	}
+	db, err := persistence.OpenUnmigrated(cfg.DBPath)
+	if err != nil {
+		slog.Error("migrate: open database", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	switch action {
+	case "status":
+		state, err := db.Migrations()
+		if err != nil {
+			slog.Error("migrate: read migrations", "error", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, m := range state {
+			applied := m.AppliedAt
+			switch {
+			case m.Version > persistence.SchemaVersion():
+				applied += " (unknown to this build)"
+			case applied == "":
+				applied = "pending"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		tw.Flush()
+
+	case "up":
+		done, err := db.Migrate()
+		if errors.Is(err, persistence.ErrSchemaTooNew) {
+			slog.Error("migrate: refusing to touch a newer database", "error", err)
+			return 1
+		}
+		if err != nil {
+			slog.Error("migrate: failed", "error", err, "applied", len(done))
+			return 1
+		}
+		slog.Info("database is up to date", "version", persistence.SchemaVersion(), "applied", len(done))
+
+	case "verify":
+		problems, err := db.VerifySchema()
+		if err != nil {
+			slog.Error("migrate: verify", "error", err)
+			return 1
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			slog.Error("schema does not match", "differences", len(problems))
+			return 1
+		}
+		slog.Info("schema matches", "version", persistence.SchemaVersion())
+	}
+	return 0
+}
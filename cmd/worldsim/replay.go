@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/llm"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/replay"
+	"github.com/talgya/mini-world/internal/weather"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// startJournal snapshots the freshly booted world into dir and attaches a
+// recording journal to sim (and to its LLM client, if any). The snapshot is a
+// database copy plus a checkpoint of sim itself: the database alone does not
+// round-trip the in-memory state exactly, the checkpoint does, so the replay
+// boots from it whichever way the live run booted. Must be called after
+// integrations are wired — the header records which ones were live.
+func startJournal(dir string, db *persistence.DB, sim *engine.Simulation, gen world.GenConfig, startTick uint64) (*replay.Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	base := fmt.Sprintf("base-%d.db", startTick)
+	if err := db.BackupTo(filepath.Join(dir, base)); err != nil {
+		return nil, err
+	}
+	baseCkpt := fmt.Sprintf("base-%d.ckpt", startTick)
+	if err := persistence.SaveCheckpoint(filepath.Join(dir, baseCkpt), sim, gen); err != nil {
+		return nil, fmt.Errorf("write base checkpoint: %w", err)
+	}
+	journalPath := filepath.Join(dir, fmt.Sprintf("journal-%d.jsonl", startTick))
+	rec, err := replay.Create(journalPath, replay.Header{
+		StartTick:  startTick,
+		Gen:        gen,
+		Base:       base,
+		Checkpoint: baseCkpt,
+		StartHash:  fmt.Sprintf("%016x", sim.StateHash()),
+		LLM:        sim.LLM != nil,
+		Weather:    sim.WeatherClient != nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sim.Journal = rec
+	if sim.LLM != nil {
+		sim.LLM.SetRecorder(rec)
+	}
+	slog.Info("replay journal recording", "journal", journalPath, "base", base)
+	return rec, nil
+}
+
+// runReplay implements `worldsim replay [-days N] <journal>`: restore the
+// journal's base snapshot, re-run the recorded ticks headless with every
+// external input served from the journal, and verify the state hash at the end
+// of each sim-day. Returns the process exit code — non-zero on the first
+// divergence.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	days := fs.Uint64("days", 0, "verify at most this many sim-days (0 = the whole journal)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: worldsim replay [-days N] <journal.jsonl>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	res, err := replayJournal(fs.Arg(0), *days)
+	if err != nil {
+		var div *replay.DivergenceError
+		if errors.As(err, &div) {
+			fmt.Fprintf(os.Stderr, "DIVERGED after %d verified sim-days: %v\n", res.Verified, div)
+		} else {
+			fmt.Fprintf(os.Stderr, "replay failed: %v\n", err)
+		}
+		return 1
+	}
+	fmt.Printf("replay OK: %d sim-days bit-identical (%s → %s)\n",
+		res.Verified, engine.SimTime(res.Start), engine.SimTime(res.End))
+	return 0
+}
+
+// replayResult is how far a replay got and the world it left behind.
+type replayResult struct {
+	Sim        *engine.Simulation
+	Start, End uint64
+	Verified   int // sim-days whose end-of-day hash matched the recording
+}
+
+// replayJournal re-runs the journal at path from its base snapshot, stopping
+// after days sim-days (0 = the whole journal). On a divergence the error is a
+// *replay.DivergenceError and the result still counts the days verified
+// before it.
+func replayJournal(path string, days uint64) (replayResult, error) {
+	var res replayResult
+	player, err := replay.Open(path)
+	if err != nil {
+		return res, err
+	}
+
+	// Boot from a scratch copy so the base snapshot stays pristine.
+	tmpDir, err := os.MkdirTemp("", "worldsim-replay-")
+	if err != nil {
+		return res, fmt.Errorf("temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dbPath := filepath.Join(tmpDir, "replay.db")
+	if err := copyFile(player.BasePath(), dbPath); err != nil {
+		return res, fmt.Errorf("copy base snapshot: %w", err)
+	}
+	db, err := persistence.Open(dbPath)
+	if err != nil {
+		return res, fmt.Errorf("open base snapshot: %w", err)
+	}
+	defer db.Close()
+
+	sim, info, err := bootWorld(db, player.Header.Gen, player.CheckpointPath())
+	if err != nil {
+		return res, fmt.Errorf("boot world: %w", err)
+	}
+	if info.StartTick != player.Header.StartTick {
+		return res, fmt.Errorf("base snapshot is at tick %d, journal starts at %d", info.StartTick, player.Header.StartTick)
+	}
+	if got := fmt.Sprintf("%016x", sim.StateHash()); got != player.Header.StartHash {
+		return res, fmt.Errorf("booted world hashes to %s, recorded start state %s", got, player.Header.StartHash)
+	}
+
+	sim.Journal = player
+	if player.Header.LLM {
+		sim.LLM = llm.NewReplayClient(player)
+	}
+	if player.Header.Weather {
+		sim.WeatherClient = weather.NewOfflineClient()
+	}
+
+	eng := engine.NewEngine()
+	eng.Tick = info.StartTick
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = sim.TickDay
+	eng.OnWeek = sim.TickWeek
+	eng.OnSeason = sim.TickSeason
+
+	end := player.EndTick()
+	if days > 0 && info.StartTick+days*engine.TicksPerSimDay < end {
+		end = info.StartTick + days*engine.TicksPerSimDay
+	}
+	res = replayResult{Sim: sim, Start: info.StartTick, End: end}
+	slog.Info("replaying", "from", engine.SimTime(info.StartTick), "to", engine.SimTime(end),
+		"days", (end-info.StartTick)/engine.TicksPerSimDay)
+
+	applyRecorded := func(tick uint64) {
+		for _, req := range player.InterventionsAt(tick) {
+			if _, err := sim.ApplyIntervention(req); err != nil {
+				slog.Warn("replay: recorded intervention failed", "tick", tick, "type", req.Type, "error", err)
+			}
+		}
+	}
+	// Interventions applied while the live run sat paused at its start tick.
+	applyRecorded(eng.Tick)
+
+	for eng.Tick < end {
+		eng.Step()
+		applyRecorded(eng.Tick)
+		if err := player.Err(); err != nil {
+			return res, err
+		}
+		if eng.Tick%engine.TicksPerSimDay == 0 {
+			res.Verified++
+		}
+	}
+	return res, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
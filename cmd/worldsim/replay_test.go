@@ -0,0 +1,68 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/entropy"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// TestRecordThenReplay records a live run with an intervention in it, replays
+// the journal through the CLI path and checks the replayed world ends in the
+// same state as the live one.
+func TestRecordThenReplay(t *testing.T) {
+	const days = 2
+	gen := world.GenConfig{Radius: 4, Seed: 5, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()}
+	dir := t.TempDir()
+	db, err := persistence.Open(filepath.Join(dir, "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sim, info, err := bootWorld(db, gen, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Entropy = entropy.NewDeterministic(gen.Seed)
+	rec, err := startJournal(filepath.Join(dir, "journal"), db, sim, gen, info.StartTick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := engine.InterventionRequest{Type: engine.InterventionWealth, Settlement: sim.Settlements[0].Name, Amount: 500}
+	if _, err := sim.SubmitIntervention("test", req, engine.TicksPerSimDay/2); err != nil {
+		t.Fatal(err)
+	}
+
+	eng := engine.NewEngine()
+	eng.Tick = info.StartTick
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = sim.TickDay
+	eng.OnWeek = sim.TickWeek
+	eng.OnSeason = sim.TickSeason
+	eng.AfterTick = func(tick uint64) { sim.ApplyDueInterventions(tick) }
+	for end := info.StartTick + days*engine.TicksPerSimDay; eng.Tick < end; {
+		eng.Step()
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := filepath.Join(dir, "journal", "journal-0.jsonl")
+	if code := runReplay([]string{journal}); code != 0 {
+		t.Fatalf("runReplay exit code %d", code)
+	}
+	res, err := replayJournal(journal, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Verified != days {
+		t.Errorf("verified %d sim-days, want %d", res.Verified, days)
+	}
+	if got, want := res.Sim.StateHash(), sim.StateHash(); got != want {
+		t.Errorf("replayed state %016x, live %016x", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/entropy"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// runDays boots the world saved in dbPath (or the checkpoint at ckptPath, if
+// newer), calls setup if it is not nil, and steps the world headless for days
+// sim-days. Entropy comes from ent, so two segments can share one stream the
+// way a live server's random.org pool outlives a restart.
+func runDays(t *testing.T, dbPath, ckptPath string, gen world.GenConfig, ent *entropy.Client, days int, setup func(*engine.Simulation)) *engine.Simulation {
+	t.Helper()
+	db, err := persistence.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sim, info, err := bootWorld(db, gen, ckptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Entropy = ent
+	if setup != nil {
+		setup(sim)
+	}
+
+	eng := engine.NewEngine()
+	eng.Tick = info.StartTick
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = sim.TickDay
+	eng.OnWeek = sim.TickWeek
+	eng.OnSeason = sim.TickSeason
+	eng.AfterTick = func(tick uint64) { sim.ApplyDueInterventions(tick) }
+	for end := info.StartTick + uint64(days)*engine.TicksPerSimDay; eng.Tick < end; {
+		eng.Step()
+	}
+
+	// What a live server does at shutdown, with checkpoints on or off.
+	if ckptPath != "" {
+		if err := persistence.SaveCheckpoint(ckptPath, sim, gen); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SaveHistory(sim); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := db.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+// TestRestartEquivalence checks that a save and reload mid-run leaves no trace:
+// N days, restart, M days must end in the same state as N+M days straight.
+// The restart lands mid-week with a production boost active and an
+// intervention still pending, so weekly counters, caches and the ledger must
+// all carry over.
+func TestRestartEquivalence(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a world for over a sim-week")
+	}
+	const n, m = 5, 4
+	gen := world.GenConfig{Radius: 4, Seed: 3, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()}
+	schedule := func(sim *engine.Simulation) {
+		name := sim.Settlements[0].Name
+		day := uint64(engine.TicksPerSimDay)
+		for _, iv := range []struct {
+			req engine.InterventionRequest
+			at  uint64
+		}{
+			{engine.InterventionRequest{Type: engine.InterventionCultivate, Settlement: name, Multiplier: 1.5, DurationDays: 6}, day},
+			{engine.InterventionRequest{Type: engine.InterventionWealth, Settlement: name, Amount: 500}, (n + 1) * day},
+		} {
+			if _, err := sim.SubmitIntervention("test", iv.req, iv.at); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	straight := runDays(t, filepath.Join(t.TempDir(), "straight.db"), "", gen, entropy.NewDeterministic(gen.Seed), n+m, schedule)
+
+	// Checkpoints are opt-in, so the database save path must hold up alone.
+	for _, mode := range []string{"checkpoint", "database"} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			dbPath, ckptPath := filepath.Join(dir, "world.db"), ""
+			if mode == "checkpoint" {
+				ckptPath = filepath.Join(dir, "world.ckpt")
+			}
+			ent := entropy.NewDeterministic(gen.Seed)
+			first := runDays(t, dbPath, ckptPath, gen, ent, n, schedule)
+			if len(first.ActiveBoosts) == 0 {
+				t.Fatal("no production boost active at the restart; the test no longer covers it")
+			}
+			restarted := runDays(t, dbPath, ckptPath, gen, ent, m, nil)
+
+			if restarted.CurrentTick() != straight.CurrentTick() {
+				t.Fatalf("restarted run ended at tick %d, straight run at %d", restarted.CurrentTick(), straight.CurrentTick())
+			}
+			if got, want := restarted.StateHash(), straight.StateHash(); got != want {
+				t.Errorf("state after restart %016x, uninterrupted %016x", got, want)
+			}
+		})
+	}
+}
+
+// TestCheckpointModeSavesMemories boots from a checkpoint, where the
+// database's memories are as old as the last database save, and checks the
+// hourly save puts a memory formed since then in the search index.
+func TestCheckpointModeSavesMemories(t *testing.T) {
+	gen := world.GenConfig{Radius: 4, Seed: 3, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()}
+	dir := t.TempDir()
+	dbPath, ckptPath := filepath.Join(dir, "world.db"), filepath.Join(dir, "world.ckpt")
+	runDays(t, dbPath, ckptPath, gen, entropy.NewDeterministic(gen.Seed), 1, nil)
+
+	db, err := persistence.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sim, info, err := bootWorld(db, gen, ckptPath)
+	if err != nil || !info.Checkpoint {
+		t.Fatalf("boot from checkpoint: %+v, %v", info, err)
+	}
+	a := sim.Agents[0]
+	agents.AddMemory(a, sim.LastTick, "Saw a comet over the ridge", 0.9)
+	saveHourly(db, sim)
+
+	hits, _, err := db.Search(persistence.SearchQuery{Text: "comet", Type: persistence.SearchMemories})
+	if err != nil || len(hits) != 1 || *hits[0].AgentID != uint64(a.ID) {
+		t.Errorf("search after the hourly save = %+v, %v", hits, err)
+	}
+}
+
+// TestBootFromMemStore saves a running world into an in-memory store and
+// boots it back through the same path a restart takes.
+func TestBootFromMemStore(t *testing.T) {
+	gen := world.GenConfig{Radius: 4, Seed: 3, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()}
+	store := persistence.NewMemStore()
+	sim, info, err := bootWorld(store, gen, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.StartTick != 0 || !store.HasWorldState() {
+		t.Fatalf("fresh boot: start tick %d, saved %v", info.StartTick, store.HasWorldState())
+	}
+	eng := engine.NewEngine()
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = sim.TickDay
+	for eng.Tick < engine.TicksPerSimDay {
+		eng.Step()
+	}
+	if err := store.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+
+	booted, info, err := bootWorld(store, gen, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alive := func(s *engine.Simulation) (n int) {
+		for _, a := range s.Agents {
+			if a.Alive {
+				n++
+			}
+		}
+		return n
+	}
+	if info.StartTick != sim.CurrentTick() {
+		t.Errorf("booted at tick %d, saved at %d", info.StartTick, sim.CurrentTick())
+	}
+	if alive(booted) != alive(sim) || len(booted.Settlements) != len(sim.Settlements) || len(booted.Factions) != len(sim.Factions) {
+		t.Errorf("booted %d agents, %d settlements, %d factions; saved %d, %d, %d",
+			alive(booted), len(booted.Settlements), len(booted.Factions), alive(sim), len(sim.Settlements), len(sim.Factions))
+	}
+	if len(booted.Events) == 0 {
+		t.Error("no events restored")
+	}
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/persistence"
+)
+
+// Scenario is a `worldsim scenario` file: a baseline and any number of knob
+// variants, each run once per seed on a fresh world for Days sim-days.
+type Scenario struct {
+	Name            string             `json:"name"`
+	Days            int                `json:"days"`
+	Seeds           []int64            `json:"seeds"`
+	Parallel        int                `json:"parallel,omitempty"`          // concurrent runs (default: GOMAXPROCS)
+	ReportEveryDays int                `json:"report_every_days,omitempty"` // envelope checkpoint spacing (default: Days/10)
+	World           *WorldConfig       `json:"world,omitempty"`             // map parameters (default: the server's)
+	Baseline        map[string]float64 `json:"baseline,omitempty"`          // knob overrides shared by every run
+	Variants        []ScenarioVariant  `json:"variants"`
+}
+
+// ScenarioVariant overrides knobs on top of the baseline.
+type ScenarioVariant struct {
+	Name  string             `json:"name"`
+	Knobs map[string]float64 `json:"knobs"`
+}
+
+// scenarioMetrics are the reported series, in table order.
+var scenarioMetrics = []struct {
+	name string
+	get  func(cp checkpoint) float64
+}{
+	{"population", func(cp checkpoint) float64 { return float64(cp.Population) }},
+	{"gini", func(cp checkpoint) float64 { return cp.Gini }},
+	{"avg_satisfaction", func(cp checkpoint) float64 { return cp.AvgSatisfaction }},
+	{"death_birth_ratio", func(cp checkpoint) float64 { return cp.DeathBirthRatio }},
+	{"settlements", func(cp checkpoint) float64 { return float64(cp.Settlements) }},
+}
+
+// checkpoint is one run's state at a report day. DeathBirthRatio covers the
+// interval since the previous checkpoint; an interval without births counts
+// as one birth so the ratio stays finite.
+type checkpoint struct {
+	Day             int     `json:"day"`
+	Population      int     `json:"population"`
+	Gini            float64 `json:"gini"`
+	AvgSatisfaction float64 `json:"avg_satisfaction"`
+	DeathBirthRatio float64 `json:"death_birth_ratio"`
+	Settlements     int     `json:"settlements"`
+}
+
+type scenarioRun struct {
+	Variant     string       `json:"variant"`
+	Seed        int64        `json:"seed"`
+	Checkpoints []checkpoint `json:"checkpoints"`
+	Err         string       `json:"error,omitempty"`
+}
+
+// Envelope is the min/median/max of one metric across seeds.
+type Envelope struct {
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// Comparison is a variant-vs-baseline test on one metric at the horizon.
+type Comparison struct {
+	Variant     string  `json:"variant"`
+	Metric      string  `json:"metric"`
+	MeanDelta   float64 `json:"mean_delta"`   // mean of per-seed (variant − baseline)
+	MedianDelta float64 `json:"median_delta"` // variant median − baseline median
+	P           float64 `json:"p"`            // paired sign-flip permutation test, two-sided; NaN with < 2 seeds
+}
+
+const baselineName = "baseline"
+
+// runScenario implements `worldsim scenario [-json out] <scenario.json>`.
+func runScenario(args []string) int {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	jsonOut := fs.String("json", "", "also write every run's checkpoints and the comparisons to this file")
+	listKnobs := fs.Bool("knobs", false, "list the tuning knobs and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: worldsim scenario [-json results.json] <scenario.json>\n       worldsim scenario -knobs")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *listKnobs {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KNOB\tDEFAULT\tRANGE\tUNIT\tDESCRIPTION")
+		for _, k := range engine.Knobs() {
+			fmt.Fprintf(w, "%s\t%g\t%g–%g\t%s\t%s\n", k.Name, k.Default, k.Min, k.Max, k.Unit, k.Doc)
+		}
+		w.Flush()
+		return 0
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	sc, err := loadScenario(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+		return 2
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	started := time.Now()
+	runs := executeScenario(sc)
+	failed := 0
+	for _, r := range runs {
+		if r.Err != "" {
+			fmt.Fprintf(os.Stderr, "run %s seed %d failed: %s\n", r.Variant, r.Seed, r.Err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+
+	comparisons := printScenarioReport(os.Stdout, sc, runs)
+	fmt.Printf("\n%d runs in %s\n", len(runs), time.Since(started).Round(time.Second))
+
+	if *jsonOut != "" {
+		data, _ := json.MarshalIndent(map[string]any{
+			"scenario":    sc,
+			"runs":        runs,
+			"comparisons": jsonSafeComparisons(comparisons),
+		}, "", "  ")
+		if err := os.WriteFile(*jsonOut, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// loadScenario reads and validates a scenario file. Scenarios are JSON only:
+// the module carries no YAML parser, so a .yaml file is refused by name
+// rather than failing on its first line.
+func loadScenario(path string) (Scenario, error) {
+	var sc Scenario
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		return sc, fmt.Errorf("%s: scenario files are JSON, not YAML", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sc, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sc); err != nil {
+		return sc, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	switch {
+	case sc.Days <= 0:
+		return sc, fmt.Errorf("days must be positive")
+	case len(sc.Seeds) == 0:
+		return sc, fmt.Errorf("at least one seed is required")
+	}
+	if sc.ReportEveryDays <= 0 {
+		sc.ReportEveryDays = max(sc.Days/10, 1)
+	}
+	if sc.Parallel <= 0 {
+		sc.Parallel = runtime.GOMAXPROCS(0)
+	}
+	if sc.World == nil {
+		w := defaultConfig().World
+		sc.World = &w
+	}
+
+	// Reject bad knobs now rather than after the baseline has run.
+	names := map[string]bool{baselineName: true}
+	if err := engine.ValidateTuning(sc.Baseline); err != nil {
+		return sc, fmt.Errorf("%s: %w", baselineName, err)
+	}
+	for _, v := range sc.Variants {
+		if v.Name == "" || names[v.Name] {
+			return sc, fmt.Errorf("variant names must be unique and non-empty (got %q)", v.Name)
+		}
+		names[v.Name] = true
+		if err := engine.ValidateTuning(sc.variantKnobs(v)); err != nil {
+			return sc, fmt.Errorf("%s: %w", v.Name, err)
+		}
+	}
+	return sc, nil
+}
+
+// variantKnobs merges a variant's overrides over the baseline's.
+func (sc Scenario) variantKnobs(v ScenarioVariant) map[string]float64 {
+	knobs := make(map[string]float64, len(sc.Baseline)+len(v.Knobs))
+	for k, val := range sc.Baseline {
+		knobs[k] = val
+	}
+	for k, val := range v.Knobs {
+		knobs[k] = val
+	}
+	return knobs
+}
+
+// executeScenario runs every (variant, seed) pair on sc.Parallel workers.
+// Runs are independent Simulations, so they share nothing but the CPU.
+// Results come back baseline first, then variants in file order, each in seed
+// order.
+func executeScenario(sc Scenario) []scenarioRun {
+	variants := append([]ScenarioVariant{{Name: baselineName}}, sc.Variants...)
+	runs := make([]scenarioRun, 0, len(variants)*len(sc.Seeds))
+	for _, v := range variants {
+		for _, seed := range sc.Seeds {
+			runs = append(runs, scenarioRun{Variant: v.Name, Seed: seed})
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	for range min(sc.Parallel, len(runs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := &runs[i]
+				v := variants[i/len(sc.Seeds)]
+				checkpoints, err := runScenarioSeed(sc, sc.variantKnobs(v), r.Seed)
+				r.Checkpoints = checkpoints
+				if err != nil {
+					r.Err = err.Error()
+				}
+				mu.Lock()
+				done++
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s seed %d done\n", done, len(runs), r.Variant, r.Seed)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range runs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return runs
+}
+
+func runScenarioSeed(sc Scenario, knobs map[string]float64, seed int64) ([]checkpoint, error) {
+	cfg := defaultConfig()
+	cfg.Seed = seed
+	cfg.World = *sc.World
+
+	var checkpoints []checkpoint
+	var prevBirths, prevDeaths int
+	day := 0
+	_, err := simulateHeadless(cfg.GenConfig(), "", sc.Days, knobs, func(sim *engine.Simulation, tick uint64) error {
+		day++
+		if day%sc.ReportEveryDays != 0 && day != sc.Days {
+			return nil
+		}
+		row := persistence.BuildStatsRow(sim, tick)
+		births := row.Births - prevBirths
+		deaths := row.Deaths - prevDeaths
+		prevBirths, prevDeaths = row.Births, row.Deaths
+		checkpoints = append(checkpoints, checkpoint{
+			Day:             day,
+			Population:      row.Population,
+			Gini:            row.Gini,
+			AvgSatisfaction: row.AvgSatisfaction,
+			DeathBirthRatio: float64(deaths) / float64(max(births, 1)),
+			Settlements:     row.SettlementCount,
+		})
+		return nil
+	})
+	return checkpoints, err
+}
+
+// printScenarioReport writes one envelope table per metric (rows: checkpoint
+// days; columns: min/median/max per variant) and a horizon comparison of each
+// variant against the baseline. It returns the comparisons.
+func printScenarioReport(out io.Writer, sc Scenario, runs []scenarioRun) []Comparison {
+	byVariant := map[string][]scenarioRun{}
+	order := []string{baselineName}
+	for _, v := range sc.Variants {
+		order = append(order, v.Name)
+	}
+	for _, r := range runs {
+		byVariant[r.Variant] = append(byVariant[r.Variant], r)
+	}
+	days := make([]int, 0)
+	for _, cp := range runs[0].Checkpoints {
+		days = append(days, cp.Day)
+	}
+
+	title := sc.Name
+	if title == "" {
+		title = "scenario"
+	}
+	fmt.Fprintf(out, "%s — %d seeds × %d sim-days, envelope = min / median / max across seeds\n",
+		title, len(sc.Seeds), sc.Days)
+
+	for _, m := range scenarioMetrics {
+		fmt.Fprintf(out, "\n%s\n", m.name)
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprint(w, "day\t")
+		for _, name := range order {
+			fmt.Fprintf(w, "%s\t", name)
+		}
+		fmt.Fprintln(w)
+		for i, day := range days {
+			fmt.Fprintf(w, "%d\t", day)
+			for _, name := range order {
+				env := envelope(metricAt(byVariant[name], i, m.get))
+				fmt.Fprintf(w, "%s / %s / %s\t", fmtMetric(env.Min), fmtMetric(env.Median), fmtMetric(env.Max))
+			}
+			fmt.Fprintln(w)
+		}
+		w.Flush()
+	}
+
+	if len(sc.Variants) == 0 {
+		return nil
+	}
+	last := len(days) - 1
+	base := byVariant[baselineName]
+	var comparisons []Comparison
+	fmt.Fprintf(out, "\nvariant vs baseline at day %d (paired sign-flip permutation test, two-sided)\n", days[last])
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "variant\tmetric\tmedian Δ\tmean paired Δ\tp")
+	for _, name := range order[1:] {
+		for _, m := range scenarioMetrics {
+			a := metricAt(base, last, m.get)
+			b := metricAt(byVariant[name], last, m.get)
+			diffs := make([]float64, len(a))
+			for i := range a {
+				diffs[i] = b[i] - a[i]
+			}
+			c := Comparison{
+				Variant:     name,
+				Metric:      m.name,
+				MeanDelta:   mean(diffs),
+				MedianDelta: envelope(b).Median - envelope(a).Median,
+				P:           signFlipPValue(diffs),
+			}
+			comparisons = append(comparisons, c)
+			p := "n/a"
+			if !math.IsNaN(c.P) {
+				p = fmt.Sprintf("%.4f", c.P)
+				if c.P < 0.05 {
+					p += " *"
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, m.name, fmtDelta(c.MedianDelta), fmtDelta(c.MeanDelta), p)
+		}
+	}
+	w.Flush()
+	return comparisons
+}
+
+// metricAt returns one metric at checkpoint i for each run, in seed order.
+func metricAt(runs []scenarioRun, i int, get func(checkpoint) float64) []float64 {
+	vals := make([]float64, 0, len(runs))
+	for _, r := range runs {
+		if i < len(r.Checkpoints) {
+			vals = append(vals, get(r.Checkpoints[i]))
+		}
+	}
+	return vals
+}
+
+func envelope(vals []float64) Envelope {
+	if len(vals) == 0 {
+		return Envelope{math.NaN(), math.NaN(), math.NaN()}
+	}
+	s := slices.Clone(vals)
+	slices.Sort(s)
+	med := s[len(s)/2]
+	if len(s)%2 == 0 {
+		med = (s[len(s)/2-1] + s[len(s)/2]) / 2
+	}
+	return Envelope{Min: s[0], Median: med, Max: s[len(s)-1]}
+}
+
+func mean(vals []float64) float64 {
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+// signFlipPValue tests whether paired differences are centred on zero: under
+// the null each difference is equally likely to carry either sign, so the p-
+// value is the share of sign assignments whose |mean| is at least the observed
+// one. Exact for up to 16 pairs, otherwise estimated from 20,000 seeded random
+// assignments. With n pairs the smallest attainable p is 2/2^n, so runs need
+// at least 6 seeds to reach p < 0.05.
+func signFlipPValue(diffs []float64) float64 {
+	n := len(diffs)
+	if n < 2 {
+		return math.NaN()
+	}
+	observed := math.Abs(mean(diffs))
+	// Tolerate float noise so assignments equal to the observed mean count.
+	tol := 1e-9 * math.Max(observed, 1)
+
+	extreme, total := 0, 0
+	flipMean := func(signs func(i int) bool) float64 {
+		sum := 0.0
+		for i, d := range diffs {
+			if signs(i) {
+				sum -= d
+			} else {
+				sum += d
+			}
+		}
+		return math.Abs(sum / float64(n))
+	}
+	if n <= 16 {
+		for mask := 0; mask < 1<<n; mask++ {
+			if flipMean(func(i int) bool { return mask&(1<<i) != 0 }) >= observed-tol {
+				extreme++
+			}
+			total++
+		}
+	} else {
+		rng := rand.New(rand.NewSource(1))
+		for ; total < 20000; total++ {
+			if flipMean(func(int) bool { return rng.Intn(2) == 1 }) >= observed-tol {
+				extreme++
+			}
+		}
+	}
+	return float64(extreme) / float64(total)
+}
+
+func fmtMetric(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "—"
+	case math.Abs(v) >= 100:
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".")
+	}
+}
+
+func fmtDelta(v float64) string {
+	if v > 0 {
+		return "+" + fmtMetric(v)
+	}
+	return fmtMetric(v)
+}
+
+// jsonSafeComparisons replaces NaN p-values (JSON cannot encode them) with -1.
+func jsonSafeComparisons(cs []Comparison) []Comparison {
+	out := slices.Clone(cs)
+	for i := range out {
+		if math.IsNaN(out[i].P) {
+			out[i].P = -1
+		}
+	}
+	return out
+}
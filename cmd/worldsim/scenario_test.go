@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSignFlipPValue pins the permutation test against hand-computed exact
+// values.
+func TestSignFlipPValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		diffs []float64
+		want  float64
+	}{
+		// All 2^n assignments tie or beat |mean| = 0.
+		{"no difference", []float64{0, 0, 0}, 1},
+		// Only all-positive and all-negative reach the observed |mean|.
+		{"six consistent gains", []float64{1, 2, 3, 4, 5, 6}, 2.0 / 64},
+		{"five consistent gains", []float64{1, 1, 1, 1, 1}, 2.0 / 32},
+		// {+3,+1}: |±3±1|/2 ≥ 2 only for (+,+) and (−,−).
+		{"two pairs", []float64{3, 1}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signFlipPValue(tt.diffs); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("p = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if p := signFlipPValue([]float64{1}); !math.IsNaN(p) {
+		t.Errorf("single pair: p = %v, want NaN", p)
+	}
+	// Above the exact cutoff the sampled estimate should still land near
+	// the exact 2/2^20.
+	big := make([]float64, 20)
+	for i := range big {
+		big[i] = 1
+	}
+	if p := signFlipPValue(big); p > 0.001 {
+		t.Errorf("20 consistent gains: p = %v, want ≈ 0", p)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	if got := envelope([]float64{5, 1, 3}); got != (Envelope{1, 3, 5}) {
+		t.Errorf("odd: %+v", got)
+	}
+	if got := envelope([]float64{4, 1, 3, 2}); got != (Envelope{1, 2.5, 4}) {
+		t.Errorf("even: %+v", got)
+	}
+}
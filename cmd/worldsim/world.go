@@ -0,0 +1,484 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// worldInfo summarizes a booted world for the startup banner.
+type worldInfo struct {
+	StartTick  uint64
+	LandHexes  int
+	Checkpoint bool // restored from the binary checkpoint rather than the database
+}
+
+// bootWorld regenerates the map from gen and either restores the saved world
+// or generates (and saves) a fresh one. The saved world comes from the binary
+// checkpoint at ckptPath when there is one at least as new as the database's
+// world tables, otherwise from db ("" skips the checkpoint). The returned
+// simulation has no integrations attached (LLM, weather, entropy) and no
+// journal; callers wire those. Live runs and replays both boot through here,
+// so a replay starts from exactly the in-memory state the live run started
+// from.
+func bootWorld(db persistence.Store, gen world.GenConfig, ckptPath string) (*engine.Simulation, worldInfo, error) {
+	seed := gen.Seed
+
+	// ── World Map (always regenerated — deterministic from seed) ──────
+	slog.Info("generating world map...", "radius", gen.Radius, "seed", seed)
+	worldMap := world.Generate(gen)
+
+	counts := world.TerrainCounts(worldMap)
+	landHexes := 0
+	for t, c := range counts {
+		if t != world.TerrainOcean {
+			landHexes += c
+		}
+		slog.Info("terrain", "type", world.TerrainName(t), "count", c)
+	}
+
+	// ── Load or Generate World State ─────────────────────────────────
+	var allSettlements []*social.Settlement
+	var allAgents []*agents.Agent
+	var startTick uint64
+	var startSeason uint8
+
+	spawner := agents.NewSpawner(seed)
+
+	ckpt, err := newerCheckpoint(db, ckptPath)
+	if err != nil {
+		return nil, worldInfo{}, err
+	}
+
+	if ckpt != nil {
+		allAgents = ckpt.Agents
+		allSettlements = ckpt.Settlements
+		startTick = ckpt.Tick
+		startSeason = ckpt.Season
+		spawner.SetNextID(maxAgentID(allAgents) + 1)
+		slog.Info("world state restored from checkpoint",
+			"path", ckptPath,
+			"agents", len(allAgents),
+			"settlements", len(allSettlements),
+			"tick", startTick,
+			"sim_time", engine.SimTime(startTick),
+		)
+	} else if db.HasWorldState() {
+		// Restore from saved state.
+		slog.Info("found saved world state, loading...")
+
+		var loadErr error
+		allAgents, loadErr = db.LoadAgents()
+		if loadErr != nil {
+			return nil, worldInfo{}, fmt.Errorf("load agents: %w", loadErr)
+		}
+
+		allSettlements, loadErr = db.LoadSettlements()
+		if loadErr != nil {
+			return nil, worldInfo{}, fmt.Errorf("load settlements: %w", loadErr)
+		}
+
+		// One-time migration: spread existing agents across age months.
+		// Without this, all agents have AgeMonths=0 and would age together
+		// on the first monthly tick (same cliff as yearly aging).
+		// Newborns (Age=0, AgeMonths=0) are excluded — their month counter
+		// starts at 0 and increments naturally from birth.
+		// This block can be removed after one successful deploy. It only
+		// runs on a legacy save, where no agent has a month counter yet:
+		// in any later save an agent's counter is legitimately 0 one month
+		// in twelve, and reseeding it would make every load change the
+		// world (and no replay match its recording).
+		legacy := true
+		for _, a := range allAgents {
+			if a.AgeMonths != 0 {
+				legacy = false
+				break
+			}
+		}
+		seeded := 0
+		for _, a := range allAgents {
+			if legacy && a.Age > 0 {
+				a.AgeMonths = uint8(a.ID % 12)
+				seeded++
+			}
+		}
+		if seeded > 0 {
+			slog.Info("seeded age months for existing agents", "count", seeded)
+		}
+
+		// Restore tick and season from metadata.
+		if tickStr, err := db.GetMeta("last_tick"); err == nil {
+			if t, err := strconv.ParseUint(tickStr, 10, 64); err == nil {
+				startTick = t
+			}
+		}
+		if seasonStr, err := db.GetMeta("season"); err == nil {
+			if s, err := strconv.ParseUint(seasonStr, 10, 8); err == nil {
+				startSeason = uint8(s)
+			}
+		}
+
+		// Update spawner next ID to be above the highest existing agent ID.
+		spawner.SetNextID(maxAgentID(allAgents) + 1)
+
+		// Promote Tier 1 agents if none exist yet (backfill for existing worlds).
+		tier1Count := 0
+		for _, a := range allAgents {
+			if a.Tier == agents.Tier1 {
+				tier1Count++
+			}
+		}
+		if tier1Count == 0 {
+			agents.PromoteToTier1(allAgents, 0.04)
+			promoted := 0
+			for _, a := range allAgents {
+				if a.Tier == agents.Tier1 {
+					promoted++
+				}
+			}
+			slog.Info("backfilled Tier 1 agents", "promoted", promoted)
+		}
+
+		slog.Info("world state restored",
+			"agents", len(allAgents),
+			"settlements", len(allSettlements),
+			"tick", startTick,
+			"season", engine.SeasonName(startSeason),
+			"sim_time", engine.SimTime(startTick),
+		)
+	} else {
+		// Fresh world generation.
+		slog.Info("no saved state found, generating new world...")
+
+		settlementSeeds := world.PlaceSettlements(worldMap, seed)
+		rng := rand.New(rand.NewSource(seed + 400))
+
+		for i, ss := range settlementSeeds {
+			pop := world.PopulationForSize(ss.Size, rng)
+
+			var gov social.GovernanceType
+			switch ss.Size {
+			case world.SizeCity:
+				gov = social.GovMonarchy
+			case world.SizeTown:
+				if rng.Float32() < 0.5 {
+					gov = social.GovCouncil
+				} else {
+					gov = social.GovMerchantRepublic
+				}
+			default:
+				gov = social.GovCommune
+			}
+
+			sid := uint64(i + 1)
+			settlement := &social.Settlement{
+				ID:              sid,
+				Name:            ss.Name,
+				Position:        ss.Coord,
+				Population:      pop,
+				Governance:      gov,
+				TaxRate:         0.10,
+				Treasury:        uint64(pop) * 5,
+				GovernanceScore: 0.5 + rng.Float64()*0.3,
+				MarketLevel:     1,
+			}
+
+			hex := worldMap.Get(ss.Coord)
+			if hex != nil {
+				hex.SettlementID = &sid
+			}
+
+			allSettlements = append(allSettlements, settlement)
+
+			terrain := world.TerrainPlains
+			if hex != nil {
+				terrain = hex.Terrain
+			}
+			popAgents := spawner.SpawnPopulation(pop, ss.Coord, sid, terrain)
+			allAgents = append(allAgents, popAgents...)
+		}
+
+		agents.PromoteToTier2(allAgents, 30)
+		agents.PromoteToTier1(allAgents, 0.04) // 4% of remaining Tier 0 agents
+
+		for _, a := range allAgents {
+			if a.Tier == agents.Tier2 {
+				slog.Info("notable character",
+					"name", a.Name,
+					"age", a.Age,
+					"occupation", a.Occupation,
+					"coherence", fmt.Sprintf("%.3f", a.Soul.CittaCoherence),
+					"wealth", a.Wealth,
+				)
+			}
+		}
+	}
+
+	// The checkpoint holds every hex, so the database's hex blobs only apply
+	// to a database boot.
+	if ckpt != nil {
+		ckpt.RestoreHexes(worldMap)
+	}
+
+	// Restore hex health from database (must happen before the default-to-pristine loop).
+	if startTick > 0 && ckpt == nil {
+		if healthStr, err := db.GetMeta("hex_health"); err == nil {
+			var hexHealth map[string]struct {
+				H  float64 `json:"h"`
+				T  uint64  `json:"t"`
+				Ir uint8   `json:"ir,omitempty"`
+				Co uint8   `json:"co,omitempty"`
+				Cl *uint64 `json:"cl,omitempty"`
+			}
+			if json.Unmarshal([]byte(healthStr), &hexHealth) == nil && len(hexHealth) > 0 {
+				for key, entry := range hexHealth {
+					var q, r int
+					fmt.Sscanf(key, "%d,%d", &q, &r)
+					if hex := worldMap.Get(world.HexCoord{Q: q, R: r}); hex != nil {
+						hex.Health = entry.H
+						hex.LastExtractedTick = entry.T
+						hex.IrrigationLevel = entry.Ir
+						hex.ConservationLevel = entry.Co
+						hex.ClaimedBy = entry.Cl
+					}
+				}
+				slog.Info("hex health restored", "degraded_hexes", len(hexHealth))
+			}
+		}
+	}
+
+	// Restore hex resource quantities from database. Without this, resources reset
+	// to fresh-generation values on every restart, causing an artificial work rate spike.
+	if startTick > 0 && ckpt == nil {
+		if resStr, err := db.GetMeta("hex_resources"); err == nil {
+			var hexResources map[string]map[string]float64
+			if json.Unmarshal([]byte(resStr), &hexResources) == nil && len(hexResources) > 0 {
+				restored := 0
+				for key, resMap := range hexResources {
+					var q, r int
+					fmt.Sscanf(key, "%d,%d", &q, &r)
+					if hex := worldMap.Get(world.HexCoord{Q: q, R: r}); hex != nil {
+						for resKey, qty := range resMap {
+							var resType int
+							fmt.Sscanf(resKey, "%d", &resType)
+							hex.Resources[world.ResourceType(resType)] = qty
+						}
+						restored++
+					}
+				}
+				slog.Info("hex resources restored", "hexes", restored)
+			}
+		}
+	}
+
+	// Default any hex with zero health to pristine (handles first deploy
+	// where no hex_health metadata exists yet).
+	for _, hex := range worldMap.Hexes {
+		if hex.Health == 0 && hex.LastExtractedTick == 0 {
+			hex.Health = 1.0
+		}
+	}
+
+	// Link settlement hex references (needed for both fresh and loaded worlds).
+	for _, st := range allSettlements {
+		sid := st.ID
+		hex := worldMap.Get(st.Position)
+		if hex != nil {
+			hex.SettlementID = &sid
+		}
+	}
+
+	slog.Info("world ready",
+		"agents", len(allAgents),
+		"settlements", len(allSettlements),
+		"hexes", worldMap.HexCount(),
+	)
+
+	// ── Simulation ────────────────────────────────────────────────────
+	sim := engine.NewSimulation(worldMap, allAgents, allSettlements)
+	sim.Spawner = spawner
+	sim.LastTick = startTick
+	sim.CurrentSeason = startSeason
+
+	// Initialize or load factions.
+	if ckpt != nil && len(ckpt.Factions) > 0 {
+		sim.SetFactions(ckpt.Factions)
+	} else if startTick > 0 && db.HasFactions() {
+		factions, err := db.LoadFactions()
+		if err != nil {
+			slog.Warn("failed to load factions, re-initializing", "error", err)
+			sim.InitFactions()
+		} else {
+			sim.SetFactions(factions)
+		}
+	} else {
+		sim.InitFactions()
+	}
+
+	// R76: restore all late-persisted world state via the registry. Each
+	// field's Save+Load logic is co-located in `internal/persistence/world_state.go`,
+	// so adding new persistent state is a single registry entry — no more
+	// wire-it-and-pray. The registry itself handles missing-key cases
+	// gracefully, so calling on a fresh world is a no-op.
+	if ckpt != nil {
+		ckpt.RestoreLatePersisted(sim)
+	} else if startTick > 0 {
+		db.RestoreLatePersisted(sim)
+	}
+
+	// Restore the intervention ledger so pending scheduled interventions
+	// survive the restart and IDs keep counting up. The database copy is
+	// written through on every change, so it wins over the checkpoint's.
+	if startTick > 0 {
+		records, err := db.LoadInterventions(engine.MaxSettledInterventions)
+		if err != nil {
+			slog.Warn("failed to load interventions", "error", err)
+		} else {
+			sim.Interventions = records
+		}
+		if len(sim.Interventions) == 0 && ckpt != nil {
+			sim.Interventions = ckpt.Interventions
+		}
+	}
+
+	// Load agent memories and relationships from database (if any exist).
+	// Checkpointed agents already carry theirs.
+	if startTick > 0 && ckpt == nil {
+		if err := db.LoadMemories(sim.AgentIndex); err != nil {
+			slog.Warn("failed to load memories", "error", err)
+		}
+		if err := db.LoadRelationships(sim.AgentIndex); err != nil {
+			slog.Warn("failed to load relationships", "error", err)
+		}
+	}
+
+	// Load recent events from database so /api/v1/events works after restart.
+	if startTick > 0 {
+		events, err := db.RecentEvents(1000)
+		if err != nil {
+			slog.Warn("failed to load events", "error", err)
+		} else if len(events) > 0 {
+			// Reverse so oldest is first (DB returns newest first).
+			for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+				events[i], events[j] = events[j], events[i]
+			}
+			sim.Events = events
+			slog.Info("events loaded from database", "count", len(events))
+		}
+	}
+
+	// Save on fresh generation only (loaded worlds are already saved).
+	if startTick == 0 {
+		if err := db.SaveWorldStateFull(sim); err != nil {
+			slog.Error("initial save failed", "error", err)
+		}
+	}
+
+	// Pre-compute settlement neighbor index for O(1) proximity lookups.
+	sim.BuildSettlementNeighbors()
+	// Pre-compute diplomacy crime bonus cache from loaded agreements.
+	sim.BuildDiplomacyCrimeBonusCache()
+
+	// R86: Recompute stats after resume so producer_health.work_rate is
+	// accurate immediately. NewSimulation() runs updateStats() during
+	// construction, but at that point sim.LastTick == 0, so the
+	// `s.LastTick > 0` guard in the work_rate computation flagged every
+	// producer as idle. Surfaced 2026-05-05 by kernel reboot (W-8).
+	if startTick > 0 {
+		sim.RecomputeStats()
+	}
+
+	return sim, worldInfo{StartTick: startTick, LandHexes: landHexes, Checkpoint: ckpt != nil}, nil
+}
+
+// newerCheckpoint loads the checkpoint at path if it exists and is at least
+// as new as the world saved in db. The database can be newer after a crash
+// between a named snapshot (which saves the world tables) and the next
+// checkpoint. An unreadable checkpoint is an error rather than a silent fall
+// back to older state.
+func newerCheckpoint(db persistence.Store, path string) (*persistence.Checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	ckpt, err := persistence.LoadCheckpoint(path)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint: %w (move it aside to boot from the database)", err)
+	}
+	if db.HasWorldState() {
+		if s, err := db.GetMeta("last_tick"); err == nil {
+			if dbTick, err := strconv.ParseUint(s, 10, 64); err == nil && dbTick > ckpt.Tick {
+				slog.Warn("database world state is newer than the checkpoint; booting from the database",
+					"db_tick", dbTick, "checkpoint_tick", ckpt.Tick)
+				return nil, nil
+			}
+		}
+	}
+	return ckpt, nil
+}
+
+func maxAgentID(list []*agents.Agent) agents.AgentID {
+	var maxID agents.AgentID
+	for _, a := range list {
+		if a.ID > maxID {
+			maxID = a.ID
+		}
+	}
+	return maxID
+}
+
+// forkFromSnapshot starts a new world at cfg.DBPath as a copy of the named
+// snapshot cfg.FromSnapshot, and takes the seed and world parameters from the
+// snapshot so the regenerated map matches the state saved on top of it. It
+// refuses to touch an existing database. The fork then boots like any saved
+// world and runs on independently of the original.
+func forkFromSnapshot(cfg *Config) error {
+	if _, err := os.Stat(cfg.DBPath); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("database %s already exists; fork into a new path with -db", cfg.DBPath)
+	}
+	// A checkpoint left beside the new path would win over the fork at boot.
+	if p := cfg.CheckpointPath(); p != "" {
+		if _, err := os.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("checkpoint %s already exists; fork into a new path with -db", p)
+		}
+	}
+	os.MkdirAll(filepath.Dir(cfg.DBPath), 0755)
+	if err := copyFile(cfg.FromSnapshot, cfg.DBPath); err != nil {
+		return fmt.Errorf("copy snapshot: %w", err)
+	}
+	// Read the metadata from the copy; opening the original would touch it.
+	meta, err := persistence.ReadSnapshotMeta(cfg.DBPath)
+	if err != nil {
+		os.Remove(cfg.DBPath)
+		return err
+	}
+
+	gen := meta.Gen
+	if gen.Seed != cfg.Seed || gen.Radius != cfg.World.Radius {
+		slog.Info("fork uses the snapshot's world parameters", "seed", gen.Seed, "radius", gen.Radius)
+	}
+	cfg.Seed = gen.Seed
+	cfg.World = WorldConfig{
+		Radius:        gen.Radius,
+		SeaLevel:      gen.SeaLevel,
+		MountainLevel: gen.MountainLvl,
+		Noise:         gen.Noise,
+	}
+	slog.Info("forked world from snapshot", "snapshot", meta.Name, "tick", meta.Tick,
+		"sim_time", meta.SimTime, "db", cfg.DBPath)
+	return nil
+}
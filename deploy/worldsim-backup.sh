@@ -9,6 +9,7 @@
 set -euo pipefail
 
 DB="/opt/worldsim/data/crossworlds.db"
+CKPT="/opt/worldsim/data/crossworlds.ckpt"
 BACKUP_DIR="/opt/worldsim/backups"
 
 if [ ! -f "$DB" ]; then
@@ -42,6 +43,13 @@ fi
 SIZE=$(du -h "$BACKUP_FILE" | cut -f1)
 echo "Backup created: $BACKUP_FILE ($SIZE)"
 
+# The checkpoint holds the current world state when checkpoints are on.
+# worldsim replaces it by rename, so a plain copy is always a whole file.
+if [ -f "$CKPT" ]; then
+    cp "$CKPT" "$BACKUP_DIR/crossworlds-$TIMESTAMP.ckpt"
+    echo "Checkpoint copied: crossworlds-$TIMESTAMP.ckpt"
+fi
+
 # Compress previous raw backups (skip the one we just created).
 cd "$BACKUP_DIR"
 for old_raw in $(ls -1t crossworlds-*.db 2>/dev/null | tail -n +2); do
@@ -51,6 +59,7 @@ done
 
 # Remove old gzipped backups, keeping only the most recent one.
 ls -1t crossworlds-*.db.gz 2>/dev/null | tail -n +2 | xargs -r rm -f
+ls -1t crossworlds-*.ckpt 2>/dev/null | tail -n +2 | xargs -r rm -f
 
 TOTAL=$(( $(ls -1 crossworlds-*.db 2>/dev/null | wc -l) + $(ls -1 crossworlds-*.db.gz 2>/dev/null | wc -l) ))
 echo "Backups retained: $TOTAL (1 raw + 1 gzipped)"
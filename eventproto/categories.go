@@ -21,6 +21,7 @@ package eventproto
 type Category string
 
 const (
+	CategoryAdmin     Category = "admin"     // operator changes to the running world (tuning knobs)
 	CategoryAgent     Category = "agent"     // individual-agent actions surfaced to Tier 1+
 	CategoryBirth     Category = "birth"     // new agent born
 	CategoryCrime     Category = "crime"     // theft / outlaw events
@@ -42,6 +43,7 @@ const (
 // output to be deterministic.
 func Categories() []Category {
 	return []Category{
+		CategoryAdmin,
 		CategoryAgent,
 		CategoryBirth,
 		CategoryCrime,
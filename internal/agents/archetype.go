@@ -4,6 +4,8 @@
 package agents
 
 import (
+	"maps"
+
 	"github.com/talgya/mini-world/internal/phi"
 )
 
@@ -110,7 +112,7 @@ var archetypeTemplates = map[string]BehaviorTemplate{
 
 // Tier1Decide determines what a Tier 1 agent does this tick.
 // Like Tier0Decide but uses archetype template to adjust thresholds and fallback.
-func Tier1Decide(a *Agent) Action {
+func Tier1Decide(a *Agent, tick uint64) Action {
 	if !a.Alive {
 		return Action{AgentID: a.ID, Kind: ActionIdle}
 	}
@@ -123,7 +125,7 @@ func Tier1Decide(a *Agent) Action {
 	tmpl, ok := archetypeTemplates[a.Archetype]
 	if !ok {
 		// Fallback to Tier 0 if archetype is unknown.
-		return Tier0Decide(a)
+		return Tier0Decide(a, tick)
 	}
 
 	// Evaluate needs with archetype-adjusted thresholds.
@@ -203,6 +205,29 @@ func UpdateArchetypeTemplate(archetype string, overrides map[string]float32, pre
 	archetypeTemplates[archetype] = tmpl
 }
 
+// ArchetypeTemplates returns a copy of every archetype's current template, for
+// saving.
+func ArchetypeTemplates() map[string]BehaviorTemplate {
+	out := make(map[string]BehaviorTemplate, len(archetypeTemplates))
+	for name, tmpl := range archetypeTemplates {
+		tmpl.PriorityOverrides = maps.Clone(tmpl.PriorityOverrides)
+		out[name] = tmpl
+	}
+	return out
+}
+
+// SetArchetypeTemplate replaces an archetype's template with a saved one.
+// Unknown archetypes are ignored.
+func SetArchetypeTemplate(archetype string, tmpl BehaviorTemplate) {
+	if _, ok := archetypeTemplates[archetype]; !ok {
+		return
+	}
+	if tmpl.PriorityOverrides == nil {
+		tmpl.PriorityOverrides = map[NeedType]float32{}
+	}
+	archetypeTemplates[archetype] = tmpl
+}
+
 // AllArchetypes returns the list of archetype names.
 func AllArchetypes() []string {
 	return []string{
@@ -33,18 +33,18 @@ const (
 )
 
 // Decide determines what an agent does this tick, routing by cognition tier.
-func Decide(a *Agent) Action {
+func Decide(a *Agent, tick uint64) Action {
 	switch a.Tier {
 	case Tier1:
-		return Tier1Decide(a)
+		return Tier1Decide(a, tick)
 	default:
-		return Tier0Decide(a)
+		return Tier0Decide(a, tick)
 	}
 }
 
 // Tier0Decide determines what a Tier 0 agent does this tick.
 // Pure rule-based: evaluate needs bottom-up, pick the most urgent action.
-func Tier0Decide(a *Agent) Action {
+func Tier0Decide(a *Agent, tick uint64) Action {
 	if !a.Alive {
 		return Action{AgentID: a.ID, Kind: ActionIdle}
 	}
@@ -69,9 +69,10 @@ func Tier0Decide(a *Agent) Action {
 		if boost <= 0 {
 			boost = 1.0
 		}
-		// Use package-level rand for per-agent stochasticity (acceptable
-		// non-determinism — one of many stochastic sources in the sim).
-		if rand.Float64() < ContemplationProbability(a)*boost {
+		// Roll from (tick, agent ID) rather than the package-level rand so
+		// replays make the same choice. This runs per eligible agent per
+		// tick, so it hashes instead of allocating an rngForAgent source.
+		if rollForAgent(tick, a.ID) < ContemplationProbability(a)*boost {
 			return Action{AgentID: a.ID, Kind: ActionContemplate, Detail: a.Name + " sits in contemplation"}
 		}
 	}
@@ -176,6 +177,17 @@ func rngForAgent(tick uint64, id AgentID) *rand.Rand {
 	return rand.New(rand.NewSource(int64(tick) + int64(id)))
 }
 
+// rollForAgent returns a uniform float in [0, 1) derived from (tick, agent ID)
+// by a splitmix64 finalizer — a cheap, allocation-free counterpart to
+// rngForAgent for single per-tick rolls.
+func rollForAgent(tick uint64, id AgentID) float64 {
+	z := tick*0x9E3779B97F4A7C15 + uint64(id)
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
 func applyEat(a *Agent) []string {
 	// Consume one unit of food.
 	if a.Inventory[GoodFish] > 0 {
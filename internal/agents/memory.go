@@ -19,6 +19,8 @@ type Memory struct {
 // AddMemory appends a memory to the agent's stream. When full, drops the
 // lowest-importance memory to make room.
 func AddMemory(a *Agent, tick uint64, content string, importance float32) {
+	a.Dirty |= DirtyMemories
+
 	// Decay old vision importance when adding a new vision.
 	// Pushes old visions below other memory types, allowing rotation.
 	// 0.9 → 0.72 → 0.58 → 0.47 over successive visions.
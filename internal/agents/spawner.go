@@ -18,13 +18,16 @@ type SpawnConfig struct {
 // Spawner creates agents for the simulation.
 type Spawner struct {
 	rng    *rand.Rand
+	src    *countingSource
 	nextID AgentID
 }
 
 // NewSpawner creates an agent spawner with the given seed.
 func NewSpawner(seed int64) *Spawner {
+	src := &countingSource{src: rand.NewSource(seed + 300).(rand.Source64)}
 	return &Spawner{
-		rng:    rand.New(rand.NewSource(seed + 300)),
+		rng:    rand.New(src),
+		src:    src,
 		nextID: 1,
 	}
 }
@@ -34,6 +37,48 @@ func (s *Spawner) SetNextID(id AgentID) {
 	s.nextID = id
 }
 
+// NextID returns the next agent ID to be issued.
+func (s *Spawner) NextID() AgentID {
+	return s.nextID
+}
+
+// Draws returns how many values the spawner's random stream has produced.
+// Saved with the world so a restart resumes the stream instead of replaying
+// it from the seed.
+func (s *Spawner) Draws() uint64 {
+	return s.src.n
+}
+
+// Skip advances the random stream to draws, as counted by Draws. A stream
+// already past draws is left alone.
+func (s *Spawner) Skip(draws uint64) {
+	for s.src.n < draws {
+		s.src.Uint64()
+	}
+}
+
+// countingSource counts the values drawn from src. Each Int63 or Uint64 call
+// advances the underlying generator by exactly one step.
+type countingSource struct {
+	src rand.Source64
+	n   uint64
+}
+
+func (c *countingSource) Int63() int64 {
+	c.n++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.n++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.n = 0
+}
+
 // SpawnPopulation creates a batch of agents for a settlement.
 func (s *Spawner) SpawnPopulation(count uint32, position world.HexCoord, settlementID uint64, terrain world.Terrain) []*Agent {
 	agents := make([]*Agent, 0, count)
@@ -3,6 +3,8 @@
 package agents
 
 import (
+	"slices"
+
 	"github.com/talgya/mini-world/internal/world"
 )
 
@@ -119,6 +121,41 @@ type Agent struct {
 	BornTick     uint64 `json:"born_tick"`
 	LastWorkTick uint64 `json:"last_work_tick"` // Last tick agent successfully produced from hex resources
 	Alive        bool   `json:"alive"`
+
+	// Tables saved apart from the agent row whose contents changed since the
+	// database last wrote them. Not part of the agent's state.
+	Dirty Dirty `json:"-"`
+}
+
+// Dirty flags the parts of an agent the database keeps in their own tables.
+// The code that changes memories or relationships sets the flag; the save
+// that writes them clears it, and skips agents without it.
+type Dirty uint8
+
+const (
+	DirtyMemories Dirty = 1 << iota
+	DirtyRelationships
+)
+
+// Clone returns a deep copy of a that shares no mutable state with it.
+func (a *Agent) Clone() *Agent {
+	c := *a
+	c.HomeSettID = clonePtr(a.HomeSettID)
+	c.Destination = clonePtr(a.Destination)
+	c.FactionID = clonePtr(a.FactionID)
+	c.TradeDestSett = clonePtr(a.TradeDestSett)
+	c.TradePreferredDest = clonePtr(a.TradePreferredDest)
+	c.Relationships = slices.Clone(a.Relationships)
+	c.Memories = slices.Clone(a.Memories)
+	return &c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
 }
 
 // GoodType enumerates manufactured/tradeable goods.
@@ -0,0 +1,11 @@
+// gen.go declares the typegen generation directive for the API types.
+// Running `go generate ./internal/api/...` (or `go generate ./...` from the
+// worldsim repo root) regenerates `crossworlds/src/lib/api.generated.ts`
+// from the response structs in responses.go and the engine's event Meta.
+//
+// `go generate` runs the directive with cwd = the directory of THIS file
+// (internal/api/), so relative paths resolve from there.
+
+package api
+
+//go:generate go run ../../cmd/typegen -api ../../../crossworlds/src/lib/api.generated.ts
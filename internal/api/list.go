@@ -0,0 +1,453 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/pkg/worldclient"
+)
+
+// ── List queries ────────────────────────────────────────────────────
+//
+// The list endpoints (agents, settlements, factions, events, the graveyard
+// and the settlement archive) share one query grammar:
+//
+//	occupation=farmer    settlement_id=N    faction_id=N    category=death
+//	state=embodied|centered|liberated
+//	min_age=N  max_age=N    min_wealth=N  max_wealth=N    from=TICK  to=TICK
+//	sort=FIELD  order=asc|desc    limit=N  cursor=C
+//
+// Each endpoint takes the filters its rows carry and ignores any other
+// parameter, so older clients keep working; only a near miss of one it takes
+// (ocupation=, limt=) answers 400, so a misspelt filter fails rather than
+// listing everything. sort takes any numeric field of the rows, named as in their
+// JSON; order defaults to asc for id and desc otherwise, and ties are broken
+// by ID so pages never overlap. When more rows follow a page its
+// X-Next-Cursor header holds the cursor for the next; cursors are opaque
+// and only valid with the sort and order that made them.
+
+// occupationNames are the ?occupation= values, indexed by agents.Occupation.
+var occupationNames = []string{
+	"farmer", "miner", "crafter", "merchant", "soldier",
+	"scholar", "alchemist", "laborer", "fisher", "hunter",
+}
+
+// stateNames are the ?state= values, indexed by agents.StateOfBeing.
+var stateNames = []string{"embodied", "centered", "liberated"}
+
+// listFilters are the filters of the grammar and the parameters each takes.
+var listFilters = map[string][]param{
+	"occupation":    {{"occupation", "string", "Occupation, e.g. farmer"}},
+	"settlement_id": {{"settlement_id", "integer", "Settlement ID"}},
+	"faction_id":    {{"faction_id", "integer", "Faction ID"}},
+	"state":         {{"state", "string", "State of being: embodied, centered or liberated"}},
+	"category":      {catParam},
+	"age":           {{"min_age", "integer", "Minimum age"}, {"max_age", "integer", "Maximum age"}},
+	"wealth":        {{"min_wealth", "integer", "Minimum wealth"}, {"max_wealth", "integer", "Maximum wealth"}},
+	"tick":          {fromParam, toParam},
+}
+
+// listSpec describes one list endpoint's part of the grammar.
+type listSpec struct {
+	filters     []string // keys of listFilters the rows carry
+	own         []param  // the endpoint's own parameters
+	sorts       []string // numeric fields ?sort= accepts
+	sort        string   // the default sort field
+	newestFirst bool     // the stream only pages newest first
+	limit       int      // default page size; 0 lists every row
+	maxLimit    int
+	wealthOf    string // what the wealth range bounds, when not wealth
+}
+
+// params documents the spec's parameters for the OpenAPI document.
+func (spec listSpec) params() []param {
+	var out []param
+	for _, f := range spec.filters {
+		for _, p := range listFilters[f] {
+			if f == "wealth" && spec.wealthOf != "" {
+				p.desc += ", on " + spec.wealthOf
+			}
+			out = append(out, p)
+		}
+	}
+	out = append(out, spec.own...)
+	out = append(out, param{"sort", "string", "Sort field: " + strings.Join(spec.sorts, "|") + " (default " + spec.sort + ")"})
+	if spec.newestFirst {
+		out = append(out, param{"order", "string", "desc only: the list pages newest first"})
+	} else {
+		out = append(out, param{"order", "string", "asc or desc (default asc for id, else desc)"})
+	}
+	limit := fmt.Sprintf("Page size (max %d)", spec.maxLimit)
+	if spec.limit > 0 {
+		limit = fmt.Sprintf("Page size (default %d, max %d)", spec.limit, spec.maxLimit)
+	}
+	return append(out, param{"limit", "integer", limit}, cursorParam)
+}
+
+// listQuery is a parsed list request. Zero filters do not filter.
+type listQuery struct {
+	occupation   *agents.Occupation
+	settlementID uint64
+	factionID    uint64
+	state        *agents.StateOfBeing
+	category     string
+	age, wealth  persistence.Range
+	from, to     uint64 // to 0 means no upper bound
+	sort         string
+	asc          bool
+	limit        int    // 0 for every row
+	after        string // the position the cursor holds, "" on the first page
+}
+
+// parseList parses q against spec.
+func parseList(q url.Values, spec listSpec) (listQuery, error) {
+	lq := listQuery{sort: spec.sort, limit: spec.limit}
+	params := spec.params()
+	for name := range q {
+		if slices.ContainsFunc(params, func(p param) bool { return p.name == name }) {
+			continue
+		}
+		for _, p := range params {
+			if misspelt(name, p.name) {
+				return lq, fmt.Errorf("unknown parameter %q; did you mean %q?", name, p.name)
+			}
+		}
+	}
+
+	if v := q.Get("occupation"); v != "" {
+		i := slices.Index(occupationNames, strings.ToLower(v))
+		if i < 0 {
+			return lq, fmt.Errorf("invalid occupation %q; one of %s", v, strings.Join(occupationNames, ", "))
+		}
+		o := agents.Occupation(i)
+		lq.occupation = &o
+	}
+	if v := q.Get("state"); v != "" {
+		i := slices.Index(stateNames, strings.ToLower(v))
+		if i < 0 {
+			return lq, fmt.Errorf("invalid state %q; one of %s", v, strings.Join(stateNames, ", "))
+		}
+		s := agents.StateOfBeing(i)
+		lq.state = &s
+	}
+	if v := q.Get("category"); v != "" {
+		if !slices.Contains(eventproto.Categories(), eventproto.Category(v)) {
+			return lq, fmt.Errorf("invalid category %q", v)
+		}
+		lq.category = v
+	}
+	for _, f := range []struct {
+		name string
+		dst  *uint64
+	}{
+		{"settlement_id", &lq.settlementID},
+		{"faction_id", &lq.factionID},
+		{"from", &lq.from},
+		{"to", &lq.to},
+	} {
+		if v := q.Get(f.name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return lq, fmt.Errorf("invalid %s %q", f.name, v)
+			}
+			*f.dst = n
+		}
+	}
+	for _, f := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"min_age", &lq.age.Min},
+		{"max_age", &lq.age.Max},
+		{"min_wealth", &lq.wealth.Min},
+		{"max_wealth", &lq.wealth.Max},
+	} {
+		if v := q.Get(f.name); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return lq, fmt.Errorf("invalid %s %q", f.name, v)
+			}
+			*f.dst = &n
+		}
+	}
+
+	if v := q.Get("sort"); v != "" {
+		if !slices.Contains(spec.sorts, v) {
+			return lq, fmt.Errorf("invalid sort %q; one of %s", v, strings.Join(spec.sorts, ", "))
+		}
+		lq.sort = v
+	}
+	lq.asc = lq.sort == "id"
+	switch order := q.Get("order"); {
+	case order == "":
+	case order == "desc":
+		lq.asc = false
+	case order == "asc" && !spec.newestFirst:
+		lq.asc = true
+	default:
+		return lq, fmt.Errorf("invalid order %q", order)
+	}
+	if spec.newestFirst {
+		lq.asc = false
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return lq, fmt.Errorf("invalid limit %q", v)
+		}
+		lq.limit = min(n, spec.maxLimit)
+	}
+	if v := q.Get("cursor"); v != "" {
+		after, err := lq.decodeCursor(v)
+		if err != nil {
+			return lq, err
+		}
+		lq.after = after
+	}
+	return lq, nil
+}
+
+func (lq listQuery) order() string {
+	if lq.asc {
+		return "asc"
+	}
+	return "desc"
+}
+
+// cursor wraps a position in the list with the order it is a position in.
+func (lq listQuery) cursor(after string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lq.sort + " " + lq.order() + " " + after))
+}
+
+func (lq listQuery) decodeCursor(c string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	parts := strings.SplitN(string(b), " ", 3)
+	if err != nil || len(parts) != 3 || parts[2] == "" {
+		return "", fmt.Errorf("invalid cursor %q", c)
+	}
+	if parts[0] != lq.sort || parts[1] != lq.order() {
+		return "", fmt.Errorf("cursor is for sort=%s order=%s", parts[0], parts[1])
+	}
+	return parts[2], nil
+}
+
+// setNext sets X-Next-Cursor to the position after the page.
+func (lq listQuery) setNext(w http.ResponseWriter, after string) {
+	w.Header().Set("X-Next-Cursor", lq.cursor(after))
+}
+
+// keyCursor is the position the cursor holds, for the persistence queries.
+func (lq listQuery) keyCursor() (*persistence.KeyCursor, error) {
+	if lq.after == "" {
+		return nil, nil
+	}
+	c, err := persistence.ParseKeyCursor(lq.after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// agent reports whether a passes the filters agents carry.
+func (lq listQuery) agent(a *agents.Agent) bool {
+	switch {
+	case lq.occupation != nil && a.Occupation != *lq.occupation,
+		lq.settlementID != 0 && (a.HomeSettID == nil || *a.HomeSettID != lq.settlementID),
+		lq.factionID != 0 && (a.FactionID == nil || *a.FactionID != lq.factionID),
+		lq.state != nil && agents.StateFromCoherence(a.Soul.CittaCoherence) != *lq.state,
+		!lq.age.Has(float64(a.Age)),
+		!lq.wealth.Has(float64(a.Wealth)):
+		return false
+	}
+	return true
+}
+
+// ── In-memory lists ─────────────────────────────────────────────────
+
+// numericFields lists the numeric JSON fields of T, the ones rows of T sort
+// by.
+func numericFields[T any]() []string {
+	var out []string
+	for name := range fieldIndex(reflect.TypeFor[T]()) {
+		out = append(out, name)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// fieldIndex maps the numeric JSON fields of struct t to their index.
+func fieldIndex(t reflect.Type) map[string][]int {
+	out := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || f.Anonymous || name == "-" || name == "" {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			out[name] = f.Index
+		}
+	}
+	return out
+}
+
+// pageRows sorts rows by lq's field, then ID, and cuts the page after lq's
+// cursor, setting X-Next-Cursor when more rows follow.
+func pageRows[T any](w http.ResponseWriter, lq listQuery, rows []T, id func(T) uint64) ([]T, error) {
+	idx, ok := fieldIndex(reflect.TypeFor[T]())[lq.sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort %q", lq.sort)
+	}
+	pos := func(row T) []persistence.Key {
+		f := reflect.ValueOf(row).FieldByIndex(idx)
+		var key persistence.Key
+		switch {
+		case f.CanInt():
+			key = persistence.IntKey(f.Int())
+		case f.CanUint():
+			key = persistence.UintKey(f.Uint())
+		default:
+			key = persistence.FloatKey(f.Float())
+		}
+		return []persistence.Key{key, persistence.UintKey(id(row))}
+	}
+	compare := func(a, b []persistence.Key) int {
+		if lq.asc {
+			return slices.CompareFunc(a, b, persistence.Key.Compare)
+		}
+		return slices.CompareFunc(b, a, persistence.Key.Compare)
+	}
+	slices.SortFunc(rows, func(a, b T) int { return compare(pos(a), pos(b)) })
+
+	after, err := lq.keyCursor()
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		if len(after.Keys) != 2 {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		i, _ := slices.BinarySearchFunc(rows, after.Keys, func(row T, k []persistence.Key) int {
+			if compare(pos(row), k) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		rows = rows[i:]
+	}
+	if lq.limit > 0 && len(rows) > lq.limit {
+		rows = rows[:lq.limit]
+		lq.setNext(w, persistence.KeyCursor{Keys: pos(rows[len(rows)-1])}.String())
+	}
+	return rows, nil
+}
+
+// eventQuery is the history query for lq; the caller sets the agent.
+func (lq listQuery) eventQuery() (persistence.EventQuery, error) {
+	eq := persistence.EventQuery{
+		SettlementID: lq.settlementID, Category: lq.category,
+		FromTick: lq.from, ToTick: lq.to, Limit: lq.limit,
+	}
+	if lq.after != "" {
+		c, err := persistence.ParseEventCursor(lq.after)
+		if err != nil {
+			return eq, fmt.Errorf("invalid cursor")
+		}
+		eq.Cursor = &c
+	}
+	return eq, nil
+}
+
+// misspelt reports whether name looks like a typo of want: a letter off a
+// name of four to six letters, or two off a longer one, ignoring case.
+// Shorter names (q, to) are too close to everything else to tell.
+func misspelt(name, want string) bool {
+	if len(want) < 4 {
+		return false
+	}
+	limit := 1
+	if len(want) > 6 {
+		limit = 2
+	}
+	return editDistance(strings.ToLower(name), want) <= limit
+}
+
+// editDistance is the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// ── Endpoints ───────────────────────────────────────────────────────
+
+var (
+	agentList = listSpec{
+		filters: []string{"occupation", "settlement_id", "faction_id", "state", "age", "wealth"},
+		own:     []param{{"tier", "string", "0, 1 or 2, or all (default 2)"}},
+		sorts:   numericFields[worldclient.AgentSummary](), sort: "id", maxLimit: 500,
+	}
+	settlementList = listSpec{
+		filters: []string{"faction_id", "wealth"},
+		sorts:   numericFields[worldclient.SettlementSummary](), sort: "id", maxLimit: 1000,
+		wealthOf: "the treasury",
+	}
+	factionList = listSpec{
+		filters: []string{"settlement_id", "wealth"},
+		own:     []param{{"top", "integer", "Top-influence settlements per faction (default 5)"}},
+		sorts:   numericFields[worldclient.FactionSummary](), sort: "id", maxLimit: 1000,
+		wealthOf: "the treasury",
+	}
+	eventList = listSpec{
+		filters: []string{"settlement_id", "category", "tick"},
+		own: []param{{"agent", "integer", "Agent ID"},
+			{"settlement", "string", "Settlement name in the description (recent events only)"}},
+		sorts: []string{"tick"}, sort: "tick", newestFirst: true, limit: 50, maxLimit: 500,
+	}
+	timelineList = listSpec{
+		filters: []string{"category", "tick"},
+		sorts:   []string{"tick"}, sort: "tick", newestFirst: true, limit: 50, maxLimit: 500,
+	}
+	graveList = listSpec{
+		filters: []string{"occupation", "settlement_id", "faction_id", "state", "age", "wealth", "tick"},
+		own:     []param{{"cause", "string", "Cause of death"}},
+		sorts:   persistence.GraveSorts(), sort: "died_tick", limit: 50, maxLimit: 500,
+	}
+	archiveList = listSpec{
+		filters: []string{"settlement_id", "tick"},
+		own:     []param{{"cause", "string", "Abandonment cause"}},
+		sorts:   persistence.ArchiveSorts(), sort: "abandoned_tick", limit: 50, maxLimit: 500,
+	}
+	searchList = listSpec{
+		filters: []string{"tick"},
+		own: []param{{"q", "string", `Words that must all appear (required); "a phrase", a prefix*`},
+			{"type", "string", "events (default) or memories"},
+			{"settlement", "string", "Settlement ID or name: events about it, or memories of agents living there"}},
+		sorts: []string{"tick"}, sort: "tick", newestFirst: true, limit: 50, maxLimit: 200,
+	}
+)
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/pkg/worldclient"
+)
+
+// pageAll follows X-Next-Cursor from path to the last page and returns every
+// row, failing on any non-200.
+func pageAll[T any](t *testing.T, handler http.HandlerFunc, path string) []T {
+	t.Helper()
+	var all []T
+	next := ""
+	for pages := 0; ; pages++ {
+		if pages > 1000 {
+			t.Fatal("cursor never ended")
+		}
+		p := path
+		if next != "" {
+			p += "&cursor=" + next
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", p, rec.Code, rec.Body)
+		}
+		var page []T
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, page...)
+		if next = rec.Header().Get("X-Next-Cursor"); next == "" {
+			return all
+		}
+	}
+}
+
+// TestListGrammar filters, sorts and pages the in-memory lists and the
+// graveyard, and checks paging by cursor lists exactly what one unpaged
+// request does.
+func TestListGrammar(t *testing.T) {
+	sim := newTestWorld(t)
+	home := sim.Settlements[0].ID
+	store := persistence.NewMemStore()
+	var graves []engine.Grave
+	for i := range 30 {
+		graves = append(graves, engine.Grave{
+			AgentID: agents.AgentID(1_000_000 + i), Name: fmt.Sprintf("g%d", i), DiedTick: uint64(i),
+			Cause: "age", Age: uint16(60 + i%7), Wealth: uint64(i % 4), Occupation: agents.Occupation(i % 3),
+		})
+	}
+	store.SaveGraves(graves[:20])
+	sim.UnsavedGraves = graves[20:]
+	sim.PublishView()
+	srv := &Server{Sim: sim, DB: store}
+
+	// Agents: every page together is the unpaged, filtered, sorted list.
+	const farmers = "/api/v1/agents?tier=all&occupation=farmer&sort=wealth"
+	all := pageAll[worldclient.AgentSummary](t, srv.handleAgents, farmers)
+	var want []agents.AgentID
+	for _, a := range sim.Agents {
+		if a.Occupation == agents.OccupationFarmer {
+			want = append(want, a.ID)
+		}
+	}
+	if len(all) != len(want) || len(all) < 10 {
+		t.Fatalf("farmers = %d, want %d", len(all), len(want))
+	}
+	if paged := pageAll[worldclient.AgentSummary](t, srv.handleAgents, farmers+"&limit=7"); !slices.Equal(paged, all) {
+		t.Errorf("paged farmers differ from the unpaged list")
+	}
+	for i, a := range all {
+		if a.Occupation != "Farmer" {
+			t.Fatalf("agent %d is a %s", a.ID, a.Occupation)
+		}
+		if i > 0 && (a.Wealth > all[i-1].Wealth || a.Wealth == all[i-1].Wealth && a.ID > all[i-1].ID) {
+			t.Fatalf("agents %d and %d out of order", all[i-1].ID, a.ID)
+		}
+	}
+	home1 := pageAll[worldclient.AgentSummary](t, srv.handleAgents,
+		fmt.Sprintf("/api/v1/agents?tier=all&settlement_id=%d&min_age=20&max_age=30&order=asc&sort=age&limit=5", home))
+	for i, a := range home1 {
+		if a.Age < 20 || a.Age > 30 || i > 0 && a.Age < home1[i-1].Age {
+			t.Fatalf("age filter or order broken at %+v", a)
+		}
+	}
+
+	// Settlements and factions sort by any numeric field.
+	setts := pageAll[worldclient.SettlementSummary](t, srv.handleSettlements, "/api/v1/settlements?sort=population&order=asc&limit=2")
+	if len(setts) != len(sim.Settlements) || !slices.IsSortedFunc(setts, func(a, b worldclient.SettlementSummary) int {
+		return int(a.Population) - int(b.Population)
+	}) {
+		t.Errorf("settlements by population = %+v", setts)
+	}
+	facs := pageAll[worldclient.FactionSummary](t, srv.handleFactions, "/api/v1/factions?sort=members&limit=1&top=1")
+	if len(facs) != len(sim.Factions) {
+		t.Errorf("factions = %d, want %d", len(facs), len(sim.Factions))
+	}
+
+	// The graveyard sorts in the store, across saved and unsaved graves.
+	dead := pageAll[engine.Grave](t, srv.handleGraveyard, "/api/v1/graveyard?sort=wealth&occupation=miner&min_wealth=1&limit=3")
+	var got []int
+	for _, g := range dead {
+		got = append(got, int(g.AgentID)-1_000_000)
+	}
+	// Miners are i%3 == 1; wealth i%4 of 3, 2, 1 then ID, highest first.
+	if w := []int{19, 7, 22, 10, 25, 13, 1}; !slices.Equal(got, w) {
+		t.Errorf("graves by wealth = %v, want %v", got, w)
+	}
+
+	for _, tc := range []struct {
+		handler http.HandlerFunc
+		path    string
+	}{
+		{srv.handleAgents, "/api/v1/agents?ocupation=farmer"},
+		{srv.handleAgents, "/api/v1/agents?occupation=wizard"},
+		{srv.handleAgents, "/api/v1/agents?sort=name"},
+		{srv.handleSettlements, "/api/v1/settlements?Sort=population"},
+		{srv.handleEvents, "/api/v1/events?category=gossip"},
+		{srv.handleEvents, "/api/v1/events?order=asc"},
+		{srv.handleGraveyard, "/api/v1/graveyard?limit=0"},
+	} {
+		rec := httptest.NewRecorder()
+		tc.handler(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", tc.path, rec.Code)
+		}
+	}
+
+	// Parameters a list does not take are ignored, as before the grammar.
+	for _, tc := range []struct {
+		handler http.HandlerFunc
+		path    string
+	}{
+		{srv.handleAgents, "/api/v1/agents?profession=farmer"},
+		{srv.handleSettlements, "/api/v1/settlements?state=liberated&_=1712"},
+	} {
+		rec := httptest.NewRecorder()
+		tc.handler(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", tc.path, rec.Code)
+		}
+	}
+
+	// Without a limit agents are listed whole, as before the grammar.
+	rec := httptest.NewRecorder()
+	srv.handleAgents(rec, httptest.NewRequest(http.MethodGet, "/api/v1/agents?tier=all", nil))
+	var page []worldclient.AgentSummary
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	paged := pageAll[worldclient.AgentSummary](t, srv.handleAgents, "/api/v1/agents?tier=all&limit=50")
+	if len(page) != len(paged) || len(page) <= 50 || rec.Header().Get("X-Next-Cursor") != "" {
+		t.Errorf("unpaged agents = %d rows, cursor %q; paged %d", len(page), rec.Header().Get("X-Next-Cursor"), len(paged))
+	}
+
+	// A cursor only continues the order that made it.
+	rec = httptest.NewRecorder()
+	srv.handleAgents(rec, httptest.NewRequest(http.MethodGet, farmers+"&limit=3", nil))
+	rec2 := httptest.NewRecorder()
+	srv.handleAgents(rec2, httptest.NewRequest(http.MethodGet,
+		"/api/v1/agents?tier=all&sort=age&limit=3&cursor="+rec.Header().Get("X-Next-Cursor"), nil))
+	if rec2.Code != http.StatusBadRequest {
+		t.Errorf("cursor reused with another sort = %d, want 400", rec2.Code)
+	}
+}
+
+// TestPageRowsExactKeys pages rows whose keys differ only past 2^53, where
+// float64 keys would round them together and pages would repeat or skip.
+func TestPageRowsExactKeys(t *testing.T) {
+	type row struct {
+		ID   uint64 `json:"id"`
+		Tick uint64 `json:"tick"`
+	}
+	const big = 1 << 53
+	var rows []row
+	for i := range 6 {
+		rows = append(rows, row{ID: big + uint64(i), Tick: big + uint64(i%3)})
+	}
+	for _, sort := range []string{"id", "tick"} {
+		lq := listQuery{sort: sort, asc: true, limit: 1}
+		var got []uint64
+		for range len(rows) + 1 {
+			rec := httptest.NewRecorder()
+			page, err := pageRows(rec, lq, slices.Clone(rows), func(r row) uint64 { return r.ID })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range page {
+				got = append(got, r.ID)
+			}
+			next := rec.Header().Get("X-Next-Cursor")
+			if next == "" {
+				break
+			}
+			if lq.after, err = lq.decodeCursor(next); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want := []uint64{big, big + 1, big + 2, big + 3, big + 4, big + 5}
+		if sort == "tick" {
+			want = []uint64{big, big + 3, big + 1, big + 4, big + 2, big + 5}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("sort=%s paged %v, want %v", sort, got, want)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/llm"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/pkg/worldclient"
+)
+
+// operation documents one method of one route in the OpenAPI document.
+type operation struct {
+	method    string
+	path      string // OpenAPI template, e.g. /api/v1/agent/{id}
+	route     string // the mux pattern that serves it
+	summary   string
+	auth      string // "" for public, else the bearer scheme: "admin" or "relay"
+	params    []param
+	body      any    // request body, nil for none
+	response  any    // response body; a oneOf lists the alternatives
+	mediaType string // defaults to application/json
+}
+
+// param is a query parameter. Path parameters come from the template.
+type param struct {
+	name string
+	typ  string // "integer", "string" or "boolean"
+	desc string
+}
+
+// oneOf is a response that is one of several bodies, e.g. a live agent or
+// their grave.
+type oneOf []any
+
+// pathParams describes the template parameters used in operations.
+var pathParams = map[string]param{
+	"id":   {"id", "integer", "Numeric ID"},
+	"q":    {"q", "integer", "Axial hex column"},
+	"r":    {"r", "integer", "Axial hex row"},
+	"name": {"name", "string", "Snapshot name, with or without .db"},
+}
+
+var (
+	limitParam  = param{"limit", "integer", "Page size"}
+	cursorParam = param{"cursor", "string", "Opaque cursor from the previous page's X-Next-Cursor header"}
+	fromParam   = param{"from", "integer", "First tick, inclusive"}
+	toParam     = param{"to", "integer", "Last tick, inclusive"}
+	catParam    = param{"category", "string", "Event category"}
+)
+
+// operations is every documented method of every route. A route serving
+// several shapes (the map and a hex, an agent and their story) has one entry
+// per shape.
+var operations = []operation{
+	{method: "GET", path: "/api/v1/status", route: "/api/v1/status",
+		summary: "World clock, population and economy summary", response: worldclient.Status{}},
+	{method: "GET", path: "/api/v1/settlements", route: "/api/v1/settlements",
+		summary: "Settlements with governance and health", params: settlementList.params(),
+		response: []worldclient.SettlementSummary{}},
+	{method: "GET", path: "/api/v1/settlements/archive", route: "/api/v1/settlements/archive",
+		summary:  "Abandoned settlements, most recent first",
+		params:   archiveList.params(),
+		response: []engine.SettlementArchive{}},
+	{method: "GET", path: "/api/v1/settlement/{id}", route: "/api/v1/settlement/",
+		summary: "Settlement detail, or its archive entry once abandoned", response: oneOf{SettlementDetail{}, engine.SettlementArchive{}}},
+	{method: "GET", path: "/api/v1/settlement/{id}/prices", route: "/api/v1/settlement/",
+		summary: "Hourly or daily OHLC and volume per good",
+		params: []param{{"good", "string", "Good, e.g. grain or iron_ore"}, fromParam, toParam,
+			{"resolution", "string", "hour (default) or day"}},
+		response: PriceHistory{}},
+	{method: "GET", path: "/api/v1/settlement/history/{id}", route: "/api/v1/settlement/history/",
+		summary: "A settlement's daily stats, newest first", params: []param{limitParam},
+		response: []persistence.SettlementStatsRow{}},
+	{method: "GET", path: "/api/v1/agents", route: "/api/v1/agents",
+		summary: "Agents, by default the notable Tier 2 characters", params: agentList.params(),
+		response: []worldclient.AgentSummary{}},
+	{method: "GET", path: "/api/v1/liberated", route: "/api/v1/liberated",
+		summary: "The liberated cohort, paginated",
+		params: []param{{"limit", "integer", "Page size (default 100, max 1000)"}, {"offset", "integer", "Page start"},
+			{"sort", "string", "coherence|age|alignment|satisfaction|mood|memories|wealth|name"},
+			{"order", "string", "asc or desc"}, {"min_age", "integer", "Minimum age"}},
+		response: LiberatedPage{}},
+	{method: "GET", path: "/api/v1/agent/{id}", route: "/api/v1/agent/",
+		summary: "Full agent detail, or their grave once they have died", response: oneOf{agents.Agent{}, engine.Grave{}}},
+	{method: "GET", path: "/api/v1/agent/{id}/story", route: "/api/v1/agent/",
+		summary: "Biography, rate limited", params: []param{{"refresh", "boolean", "Regenerate (admin)"}},
+		response: AgentStory{}},
+	{method: "GET", path: "/api/v1/agent/{id}/family", route: "/api/v1/agent/",
+		summary: "Spouses, ancestors and descendants, the dead included",
+		params:  []param{{"generations", "integer", "Depth (default 3, max 10)"}}, response: persistence.Family{}},
+	{method: "GET", path: "/api/v1/agent/timeline/{id}", route: "/api/v1/agent/timeline/",
+		summary: "One agent's events, archive included, newest first",
+		params:  timelineList.params(), response: []engine.Event{}},
+	{method: "GET", path: "/api/v1/events", route: "/api/v1/events",
+		summary:  "Recent world events; any history filter pages through the archive",
+		params:   eventList.params(),
+		response: []engine.Event{}},
+	{method: "GET", path: "/api/v1/graveyard", route: "/api/v1/graveyard",
+		summary:  "The dead, most recent first",
+		params:   graveList.params(),
+		response: []engine.Grave{}},
+	{method: "GET", path: "/api/v1/search", route: "/api/v1/search",
+		summary:  "Saved events or memories matching words, newest first, with marked snippets",
+		params:   searchList.params(),
+		response: []persistence.SearchHit{}},
+	{method: "GET", path: "/api/v1/stats", route: "/api/v1/stats",
+		summary: "Aggregate statistics", response: engine.SimStats{}},
+	{method: "GET", path: "/api/v1/stats/history", route: "/api/v1/stats/history",
+		summary: "Time-series stats, oldest first", params: []param{fromParam, toParam, limitParam},
+		response: []worldclient.StatsRow{}},
+	{method: "GET", path: "/api/v1/newspaper", route: "/api/v1/newspaper",
+		summary: "Weekly newspaper, rate limited", response: llm.Newspaper{}},
+	{method: "GET", path: "/api/v1/factions", route: "/api/v1/factions",
+		summary: "Factions with influence and treasury", params: factionList.params(),
+		response: []worldclient.FactionSummary{}},
+	{method: "GET", path: "/api/v1/faction/{id}", route: "/api/v1/faction/",
+		summary: "Faction detail: members, influence, relations and events", response: FactionDetail{}},
+	{method: "GET", path: "/api/v1/economy", route: "/api/v1/economy",
+		summary: "Prices, trade volume, Gini coefficient", response: worldclient.Economy{}},
+	{method: "GET", path: "/api/v1/social", route: "/api/v1/social",
+		summary: "Social network overview", response: worldclient.Social{}},
+	{method: "GET", path: "/api/v1/map", route: "/api/v1/map",
+		summary: "Bulk map: all hexes and settlements", response: MapView{}},
+	{method: "GET", path: "/api/v1/map/{q}/{r}", route: "/api/v1/map/",
+		summary: "Single hex detail", response: HexDetail{}},
+	{method: "GET", path: "/api/v1/llm-usage", route: "/api/v1/llm-usage",
+		summary: "LLM calls and tokens this period", response: oneOf{llm.Usage{}, LLMDisabled{}}},
+	{method: "GET", path: "/api/v1/metrics", route: "/api/v1/metrics",
+		summary: "Prometheus text metrics", response: "", mediaType: "text/plain"},
+	{method: "GET", path: "/api/v1/interventions", route: "/api/v1/interventions",
+		summary:  "Intervention ledger, newest first",
+		params:   []param{{"status", "string", "pending|applied|failed|canceled"}, {"limit", "integer", "Cap (default 100)"}},
+		response: InterventionList{}},
+	{method: "GET", path: "/api/v1/intervention/{id}", route: "/api/v1/intervention/",
+		summary: "One ledger record", response: engine.InterventionRecord{}},
+	{method: "GET", path: "/api/v1/tuning", route: "/api/v1/tuning",
+		summary: "Tuning knobs with bounds and current values", response: []knobState{}},
+	{method: "GET", path: "/api/v1/speed", route: "/api/v1/speed",
+		summary: "Simulation speed", response: Speed{}},
+	{method: "GET", path: "/api/v1/openapi.json", route: "/api/v1/openapi.json",
+		summary: "This document", response: map[string]any{}},
+	{method: "GET", path: "/api/v1/stream", route: "/api/v1/stream", auth: "relay",
+		summary:  "Server-sent events, each data line one event, after a catch-up of recent ones",
+		response: worldclient.Event{}, mediaType: "text/event-stream"},
+
+	{method: "POST", path: "/api/v1/speed", route: "/api/v1/speed", auth: "admin",
+		summary: "Set the simulation speed (0–1000)", body: Speed{}, response: Speed{}},
+	{method: "POST", path: "/api/v1/snapshot", route: "/api/v1/snapshot", auth: "admin",
+		summary: "Save the world, or with a label write a named snapshot",
+		body:    SnapshotRequest{}, response: oneOf{SnapshotSaved{}, persistence.SnapshotMeta{}}},
+	{method: "POST", path: "/api/v1/intervention", route: "/api/v1/intervention", auth: "admin",
+		summary: "Submit an intervention, now or at a tick",
+		body:    worldclient.InterventionRequest{}, response: worldclient.InterventionResult{}},
+	{method: "POST", path: "/api/v1/intervention/{id}/cancel", route: "/api/v1/intervention/", auth: "admin",
+		summary: "Cancel a pending scheduled intervention", response: engine.InterventionRecord{}},
+	{method: "POST", path: "/api/v1/intervention/{id}/revert", route: "/api/v1/intervention/", auth: "admin",
+		summary: "Undo an applied intervention", response: engine.InterventionRecord{}},
+	{method: "POST", path: "/api/v1/tuning", route: "/api/v1/tuning", auth: "admin",
+		summary: "Change one tuning knob", body: TuningChange{}, response: []knobState{}},
+	{method: "GET", path: "/api/v1/snapshots", route: "/api/v1/snapshots", auth: "admin",
+		summary: "Named snapshots, oldest first", response: []persistence.SnapshotMeta{}},
+	{method: "GET", path: "/api/v1/snapshots/{name}", route: "/api/v1/snapshots/", auth: "admin",
+		summary: "Download a named snapshot as a SQLite file", response: "", mediaType: "application/vnd.sqlite3"},
+}
+
+// OpenAPI returns the OpenAPI 3 document for every documented operation,
+// built on first use. Callers must not modify it.
+func OpenAPI() map[string]any { return openAPI() }
+
+// openAPI builds the document once; typegen renders it as TypeScript.
+var openAPI = sync.OnceValue(func() map[string]any {
+	g := newSchemaGen()
+	paths := map[string]any{}
+	for _, op := range operations {
+		item, _ := paths[op.path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = g.operation(op)
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Crossworlds API",
+			"version":     "v1",
+			"description": "Generated from the handlers' response types. Errors are plain-text messages.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.components,
+			"securitySchemes": map[string]any{
+				"admin": map[string]any{"type": "http", "scheme": "bearer", "description": "WORLDSIM_ADMIN_KEY"},
+				"relay": map[string]any{"type": "http", "scheme": "bearer", "description": "WORLDSIM_RELAY_KEY"},
+			},
+		},
+	}
+})
+
+// handleOpenAPI serves the OpenAPI document.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, OpenAPI())
+}
+
+func (g *schemaGen) operation(op operation) map[string]any {
+	var params []any
+	for _, seg := range strings.Split(op.path, "/") {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			p := pathParams[strings.TrimSuffix(name, "}")]
+			params = append(params, map[string]any{
+				"name": p.name, "in": "path", "required": true, "description": p.desc,
+				"schema": map[string]any{"type": p.typ},
+			})
+		}
+	}
+	for _, p := range op.params {
+		params = append(params, map[string]any{
+			"name": p.name, "in": "query", "description": p.desc,
+			"schema": map[string]any{"type": p.typ},
+		})
+	}
+
+	mediaType := op.mediaType
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+	var schema map[string]any
+	if alts, ok := op.response.(oneOf); ok {
+		var list []any
+		for _, alt := range alts {
+			list = append(list, g.schema(reflect.TypeOf(alt)))
+		}
+		schema = map[string]any{"oneOf": list}
+	} else {
+		schema = g.schema(reflect.TypeOf(op.response))
+	}
+	o := map[string]any{
+		"summary":     op.summary,
+		"operationId": operationID(op),
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "OK",
+				"content":     map[string]any{mediaType: map[string]any{"schema": schema}},
+			},
+			"default": map[string]any{
+				"description": "Error",
+				"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+			},
+		},
+	}
+	if params != nil {
+		o["parameters"] = params
+	}
+	if op.auth != "" {
+		o["security"] = []any{map[string]any{op.auth: []any{}}}
+	}
+	if op.body != nil {
+		o["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.body))}},
+		}
+	}
+	return o
+}
+
+// operationID is e.g. getSettlementIdPrices for GET /api/v1/settlement/{id}/prices.
+func operationID(op operation) string {
+	id := strings.ToLower(op.method)
+	for _, seg := range strings.Split(strings.TrimPrefix(op.path, "/api/v1/"), "/") {
+		seg = strings.Trim(seg, "{}")
+		seg = strings.TrimSuffix(seg, ".json")
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// schemaGen derives JSON schemas from Go types the way encoding/json encodes
+// them. Named structs go under components, keyed by package and type name
+// (engine.Event, api.HexDetail), and are referenced from there.
+type schemaGen struct {
+	components map[string]any
+	types      map[string]reflect.Type
+}
+
+func newSchemaGen() *schemaGen {
+	return &schemaGen{components: map[string]any{}, types: map[string]reflect.Type{}}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// enums lists the values of named string types that have a closed set.
+var enums = map[reflect.Type]func() []string{
+	reflect.TypeFor[eventproto.Category](): func() []string {
+		var out []string
+		for _, c := range eventproto.Categories() {
+			out = append(out, string(c))
+		}
+		return out
+	},
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if values, ok := enums[t]; ok {
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.types[name]; !ok {
+			g.types[name] = t
+			g.components[name] = map[string]any{"type": "string", "enum": values()}
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer", "format": "int32", "minimum": 0}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem()), "nullable": true}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem()), "nullable": true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if prev, ok := g.types[name]; !ok {
+			g.types[name] = t // before recursing, so a self-reference stops here
+			g.components[name] = g.object(t)
+		} else if prev != t {
+			panic(fmt.Sprintf("openapi: %s names both %s and %s", name, prev, t))
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	panic(fmt.Sprintf("openapi: cannot describe %s", t))
+}
+
+// object describes a struct's JSON fields, flattening embedded structs.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required, false)
+	s := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		slices.Sort(required)
+		s["required"] = required
+	}
+	return s
+}
+
+// fields adds t's fields to props. Fields of an embedded struct lose to
+// same-named outer ones, and are all optional behind a pointer (a nil
+// embedded pointer writes none of them).
+func (g *schemaGen) fields(t reflect.Type, props map[string]any, required *[]string, optional bool) {
+	type embed struct {
+		t        reflect.Type
+		optional bool
+	}
+	var embedded []embed
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft, ptr := f.Type, false
+			if ft.Kind() == reflect.Pointer {
+				ft, ptr = ft.Elem(), true
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, embed{ft, optional || ptr})
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !optional && !hasOpt(opts, "omitempty") && !hasOpt(opts, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+	for _, e := range embedded {
+		inner := map[string]any{}
+		var innerReq []string
+		g.fields(e.t, inner, &innerReq, e.optional)
+		for name, s := range inner {
+			if _, ok := props[name]; !ok {
+				props[name] = s
+				if slices.Contains(innerReq, name) {
+					*required = append(*required, name)
+				}
+			}
+		}
+	}
+}
+
+func hasOpt(opts, opt string) bool {
+	return slices.Contains(strings.Split(opts, ","), opt)
+}
+
+// nullable marks s as also accepting null. OpenAPI 3.0 ignores siblings of a
+// $ref, so a reference is wrapped in allOf.
+func nullable(s map[string]any) map[string]any {
+	if _, ok := s["$ref"]; ok {
+		return map[string]any{"allOf": []any{s}, "nullable": true}
+	}
+	out := map[string]any{"nullable": true}
+	for k, v := range s {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/persistence"
+)
+
+// TestOpenAPIContract calls every documented operation against a small
+// generated world and validates each response against the served document.
+// Every registered route must be documented and every operation exercised,
+// so a new endpoint or a changed response shape fails here until the
+// operations table catches up.
+func TestOpenAPIContract(t *testing.T) {
+	sim := newTestWorld(t)
+	sim.Events = append(sim.Events,
+		engine.Event{Tick: 1, Description: "A council falls in " + sim.Settlements[0].Name, Category: eventproto.CategoryPolitical,
+			Meta: map[string]any{"settlement_id": sim.Settlements[0].ID}},
+		engine.Event{Tick: 2, Description: "A child is born", Category: eventproto.CategoryBirth})
+	home := sim.Settlements[0].ID
+	gone := agents.AgentID(sim.Spawner.NextID() + 10)
+	sim.UnsavedGraves = []engine.Grave{{AgentID: gone, Name: "Old Tam", DiedTick: 100, Cause: "age", Age: 80,
+		SettlementID: &home, SettlementName: sim.Settlements[0].Name}}
+	abandoned := uint64(len(sim.Settlements) + 100)
+	sim.UnsavedArchives = []engine.SettlementArchive{{ID: abandoned, Name: "Old Ford", Archived: true,
+		SettlementChronicle: engine.SettlementChronicle{AbandonedTick: 100, Cause: engine.AbandonDepopulated}}}
+	sim.PublishView()
+
+	db, err := persistence.Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SaveStatsSnapshot(persistence.StatsRow{Tick: 1440, Population: 812})
+	db.SaveSettlementStats([]persistence.SettlementStatsRow{{Tick: 1440, SettlementID: home, Population: 40}})
+	db.SavePriceBars([]engine.PriceBar{{SettlementID: home, Good: agents.GoodGrain, Tick: 60, Open: 1, High: 2, Low: 1, Close: 2, Volume: 3}})
+	db.SaveEvents(sim.Events[len(sim.Events)-2:])
+	agents.AddMemory(sim.Agents[0], 1, "Watched the council fall", 0.7)
+	db.SaveMemories(sim.Agents)
+
+	eng := engine.NewEngine()
+	eng.SetSpeed(0) // paused: the loop only runs submitted tasks
+	done := make(chan struct{})
+	go func() {
+		eng.Run()
+		close(done)
+	}()
+	defer func() {
+		eng.Stop()
+		<-done
+	}()
+
+	srv := &Server{Sim: sim, Eng: eng, DB: db, AdminKey: "admin", RelayKey: "relay", SnapshotDir: t.TempDir()}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	for _, rt := range srv.routes() {
+		if !slices.ContainsFunc(operations, func(op operation) bool { return op.route == rt.pattern }) {
+			t.Errorf("route %s has no documented operation", rt.pattern)
+		}
+	}
+	for _, op := range operations {
+		if !slices.ContainsFunc(srv.routes(), func(rt route) bool { return rt.pattern == op.route }) {
+			t.Errorf("%s %s: route %s is not registered", op.method, op.path, op.route)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := map[string]bool{}
+	// call requests method on url, an instance of the template path, and
+	// validates the response; it returns the decoded JSON body.
+	call := func(method, path, url string, body any) any {
+		t.Helper()
+		i := slices.IndexFunc(operations, func(op operation) bool { return op.method == method && op.path == path })
+		if i < 0 {
+			t.Fatalf("%s %s is not documented", method, path)
+		}
+		op := operations[i]
+		covered[method+" "+path] = true
+
+		var rd *bytes.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			rd = bytes.NewReader(b)
+		} else {
+			rd = bytes.NewReader(nil)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, method, ts.URL+url, rd)
+		switch op.auth {
+		case "admin":
+			req.Header.Set("Authorization", "Bearer admin")
+		case "relay":
+			req.Header.Set("Authorization", "Bearer relay")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			var msg bytes.Buffer
+			msg.ReadFrom(resp.Body)
+			t.Errorf("%s %s = %d: %s", method, url, resp.StatusCode, strings.TrimSpace(msg.String()))
+			return nil
+		}
+
+		content := doc["paths"].(map[string]any)[path].(map[string]any)[strings.ToLower(method)].(map[string]any)["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)
+		var mediaType string
+		for mediaType = range content {
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, mediaType) {
+			t.Errorf("%s %s: Content-Type %q, documented %q", method, url, ct, mediaType)
+		}
+		schema := content[mediaType].(map[string]any)["schema"].(map[string]any)
+
+		var raw []byte
+		switch mediaType {
+		case "application/json":
+			var b bytes.Buffer
+			b.ReadFrom(resp.Body)
+			raw = b.Bytes()
+		case "text/event-stream":
+			// Validate the first event of the catch-up, then hang up.
+			sc := bufio.NewScanner(resp.Body)
+			for sc.Scan() {
+				if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+					raw = []byte(data)
+					break
+				}
+			}
+			if raw == nil {
+				t.Errorf("%s %s: no event", method, url)
+				return nil
+			}
+		default:
+			return nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("%s %s: %v", method, url, err)
+			return nil
+		}
+		if err := validate(doc, schema, v, "$"); err != nil {
+			t.Errorf("%s %s: %v", method, url, err)
+		}
+		return v
+	}
+	get := func(path, url string) any { return call(http.MethodGet, path, url, nil) }
+	field := func(v any, name string) string {
+		if m, ok := v.(map[string]any); ok {
+			return fmt.Sprint(m[name])
+		}
+		return ""
+	}
+
+	var commoner, faction uint64
+	for _, a := range sim.Agents {
+		if a.Alive && a.Tier == agents.Tier0 {
+			commoner = uint64(a.ID)
+			break
+		}
+	}
+	faction = uint64(sim.Factions[0].ID)
+	hex := sim.Settlements[0].Position
+
+	for _, path := range []string{
+		"/api/v1/status", "/api/v1/settlements", "/api/v1/settlements/archive", "/api/v1/agents",
+		"/api/v1/liberated", "/api/v1/events", "/api/v1/graveyard", "/api/v1/stats", "/api/v1/stats/history",
+		"/api/v1/newspaper", "/api/v1/factions", "/api/v1/economy", "/api/v1/social", "/api/v1/map",
+		"/api/v1/llm-usage", "/api/v1/metrics", "/api/v1/interventions", "/api/v1/tuning", "/api/v1/speed",
+		"/api/v1/openapi.json", "/api/v1/stream",
+	} {
+		get(path, path)
+	}
+	get("/api/v1/events", "/api/v1/events?from=0&category=birth")
+	get("/api/v1/agents", "/api/v1/agents?tier=0")
+	get("/api/v1/search", "/api/v1/search?q=council")
+	get("/api/v1/search", "/api/v1/search?q=council&type=memories")
+	get("/api/v1/settlement/{id}", fmt.Sprintf("/api/v1/settlement/%d", home))
+	get("/api/v1/settlement/{id}", fmt.Sprintf("/api/v1/settlement/%d", abandoned))
+	get("/api/v1/settlement/{id}/prices", fmt.Sprintf("/api/v1/settlement/%d/prices", home))
+	get("/api/v1/settlement/history/{id}", fmt.Sprintf("/api/v1/settlement/history/%d", home))
+	get("/api/v1/agent/{id}", fmt.Sprintf("/api/v1/agent/%d", commoner))
+	get("/api/v1/agent/{id}", fmt.Sprintf("/api/v1/agent/%d", gone))
+	get("/api/v1/agent/{id}/story", fmt.Sprintf("/api/v1/agent/%d/story", commoner))
+	get("/api/v1/agent/{id}/story", fmt.Sprintf("/api/v1/agent/%d/story", gone))
+	get("/api/v1/agent/{id}/family", fmt.Sprintf("/api/v1/agent/%d/family", commoner))
+	get("/api/v1/agent/timeline/{id}", fmt.Sprintf("/api/v1/agent/timeline/%d", commoner))
+	get("/api/v1/faction/{id}", fmt.Sprintf("/api/v1/faction/%d", faction))
+	get("/api/v1/map/{q}/{r}", fmt.Sprintf("/api/v1/map/%d/%d", hex.Q, hex.R))
+
+	call(http.MethodPost, "/api/v1/speed", "/api/v1/speed", Speed{Speed: 0})
+	applied := call(http.MethodPost, "/api/v1/intervention", "/api/v1/intervention",
+		map[string]any{"type": "event", "description": "A comet crosses the sky"})
+	get("/api/v1/intervention/{id}", "/api/v1/intervention/"+field(applied, "id"))
+	pending := call(http.MethodPost, "/api/v1/intervention", "/api/v1/intervention",
+		map[string]any{"type": "event", "description": "An eclipse", "apply_at": sim.CurrentTick() + 10000})
+	call(http.MethodPost, "/api/v1/intervention/{id}/cancel", "/api/v1/intervention/"+field(pending, "id")+"/cancel", nil)
+	knob := engine.Knobs()[0]
+	value := knob.Min
+	call(http.MethodPost, "/api/v1/tuning", "/api/v1/tuning", TuningChange{Knob: knob.Name, Value: &value})
+	if list, ok := get("/api/v1/interventions", "/api/v1/interventions?status=applied").(map[string]any); ok {
+		if recs, _ := list["interventions"].([]any); len(recs) > 0 {
+			call(http.MethodPost, "/api/v1/intervention/{id}/revert", "/api/v1/intervention/"+field(recs[0], "id")+"/revert", nil)
+		}
+	}
+	call(http.MethodPost, "/api/v1/snapshot", "/api/v1/snapshot", SnapshotRequest{})
+	snap := call(http.MethodPost, "/api/v1/snapshot", "/api/v1/snapshot", SnapshotRequest{Label: "contract"})
+	get("/api/v1/snapshots", "/api/v1/snapshots")
+	get("/api/v1/snapshots/{name}", "/api/v1/snapshots/"+field(snap, "name"))
+
+	for _, op := range operations {
+		if !covered[op.method+" "+op.path] {
+			t.Errorf("%s %s was not exercised", op.method, op.path)
+		}
+	}
+}
+
+// validate reports the first place v does not match schema s, resolving
+// $refs against doc's components. It understands the subset of OpenAPI the
+// generator emits.
+func validate(doc map[string]any, s map[string]any, v any, at string) error {
+	if ref, ok := s["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		target, ok := doc["components"].(map[string]any)["schemas"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unresolved %s", at, ref)
+		}
+		return validate(doc, target, v, at)
+	}
+	if v == nil {
+		if s["nullable"] == true || len(s) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: unexpected null", at)
+	}
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := validate(doc, sub.(map[string]any), v, at); err != nil {
+				return err
+			}
+		}
+	}
+	if alts, ok := s["oneOf"].([]any); ok {
+		var errs []string
+		for _, sub := range alts {
+			if err := validate(doc, sub.(map[string]any), v, at); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if matched := len(alts) - len(errs); matched != 1 {
+			return fmt.Errorf("%s: matches %d of oneOf (%s)", at, matched, strings.Join(errs, "; "))
+		}
+	}
+
+	switch s["type"] {
+	case "object":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want object, got %T", at, v)
+		}
+		props, _ := s["properties"].(map[string]any)
+		required, _ := s["required"].([]any)
+		for _, name := range required {
+			if _, ok := m[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", at, name)
+			}
+		}
+		for k, fv := range m {
+			switch sub := s["additionalProperties"].(type) {
+			case bool:
+				if _, ok := props[k]; !ok && !sub {
+					return fmt.Errorf("%s: undocumented field %s", at, k)
+				}
+			case map[string]any:
+				if err := validate(doc, sub, fv, at+"."+k); err != nil {
+					return err
+				}
+			}
+			if ps, ok := props[k].(map[string]any); ok {
+				if err := validate(doc, ps, fv, at+"."+k); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want array, got %T", at, v)
+		}
+		for i, item := range arr {
+			if err := validate(doc, s["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: want string, got %T", at, v)
+		}
+		if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, any(str)) {
+			return fmt.Errorf("%s: %q is not one of %v", at, str, enum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: want boolean, got %T", at, v)
+		}
+	case "number", "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: want %s, got %T", at, s["type"], v)
+		}
+		if s["type"] == "integer" && strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Errorf("%s: want integer, got %s", at, n)
+		}
+	}
+	return nil
+}
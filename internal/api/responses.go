@@ -0,0 +1,321 @@
+package api
+
+import (
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// Request and response bodies for the endpoints whose shape is specific to
+// this package. The ones the sentinel and gardener share live in
+// pkg/worldclient; the rest are engine, persistence or llm types served
+// as-is. openapi.go describes all of them.
+
+// MapView is GET /api/v1/map: every hex for the map renderer, plus the
+// settlements on them.
+type MapView struct {
+	Radius      int             `json:"radius"`
+	Hexes       []MapHex        `json:"hexes"`
+	Settlements []MapSettlement `json:"settlements"`
+}
+
+// MapHex is one hex of the bulk map. Fields at their resting value are
+// omitted to keep the payload small.
+type MapHex struct {
+	Q                 int      `json:"q"`
+	R                 int      `json:"r"`
+	Terrain           uint8    `json:"terrain"`
+	Elevation         float64  `json:"elevation"`
+	SettlementID      *uint64  `json:"settlement_id,omitempty"`
+	Health            *float64 `json:"health,omitempty"`             // Omitted when pristine (1.0)
+	IrrigationLevel   uint8    `json:"irrigation_level,omitempty"`   // Omitted when 0
+	ConservationLevel uint8    `json:"conservation_level,omitempty"` // Omitted when 0
+	ClaimedBy         *uint64  `json:"claimed_by,omitempty"`         // Omitted when unclaimed
+}
+
+// MapSettlement places a settlement on the bulk map.
+type MapSettlement struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	Q          int    `json:"q"`
+	R          int    `json:"r"`
+	Population uint32 `json:"population"`
+}
+
+// HexDetail is GET /api/v1/map/{q}/{r}.
+type HexDetail struct {
+	Q                 int                `json:"q"`
+	R                 int                `json:"r"`
+	Terrain           string             `json:"terrain"`
+	Elevation         float64            `json:"elevation"`
+	Rainfall          float64            `json:"rainfall"`
+	Temperature       float64            `json:"temperature"`
+	Health            float64            `json:"health"`
+	LastExtractedTick uint64             `json:"last_extracted_tick"`
+	IrrigationLevel   uint8              `json:"irrigation_level"`
+	ConservationLevel uint8              `json:"conservation_level"`
+	ClaimedBy         *uint64            `json:"claimed_by"`
+	Resources         map[string]float64 `json:"resources"`
+	Settlement        *HexSettlement     `json:"settlement"`
+	AgentCount        int                `json:"agent_count"`
+	Agents            []HexAgent         `json:"agents"` // first 20
+	Neighbors         []HexNeighbor      `json:"neighbors"`
+}
+
+// HexSettlement is the settlement standing on a hex.
+type HexSettlement struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	Population uint32 `json:"population"`
+}
+
+// HexAgent is a living agent standing on a hex.
+type HexAgent struct {
+	ID   agents.AgentID `json:"id"`
+	Name string         `json:"name"`
+}
+
+// HexNeighbor is an adjacent hex.
+type HexNeighbor struct {
+	Q       int    `json:"q"`
+	R       int    `json:"r"`
+	Terrain string `json:"terrain"`
+}
+
+// LiberatedPage is GET /api/v1/liberated: one page of the liberated cohort
+// and the query that selected it.
+type LiberatedPage struct {
+	Count     int              `json:"count"` // backwards compat: total matching, pre-slice
+	Total     int              `json:"total"`
+	Threshold float32          `json:"threshold"`
+	Limit     int              `json:"limit"`
+	Offset    int              `json:"offset"`
+	Sort      string           `json:"sort"`
+	Order     string           `json:"order"`
+	MinAge    int              `json:"min_age"`
+	Agents    []LiberatedAgent `json:"agents"`
+}
+
+// LiberatedAgent is one liberated agent.
+type LiberatedAgent struct {
+	ID             agents.AgentID `json:"id"`
+	Name           string         `json:"name"`
+	Age            uint16         `json:"age"`
+	Occupation     string         `json:"occupation"`
+	Tier           int            `json:"tier"`
+	Role           string         `json:"role"`
+	SettlementID   uint64         `json:"settlement_id,omitempty"`
+	SettlementName string         `json:"settlement_name,omitempty"`
+	Coherence      float32        `json:"coherence"`
+	EffMood        float32        `json:"effective_mood"`
+	Satisfaction   float32        `json:"satisfaction"`
+	Alignment      float32        `json:"alignment"`
+	Wealth         uint64         `json:"wealth"`
+	Memories       int            `json:"memories"`
+	// R94 (2026-05-07): surface the R89 + R90 mechanics so the frontend
+	// can show "earned vs. inherited" liberation without per-agent detail
+	// lookups. WisdomEffort is the practice counter (R89); Reincarnated
+	// flags carried-over wisdom from a deceased liberated elder (R90).
+	WisdomEffort uint32 `json:"wisdom_effort"`
+	Reincarnated bool   `json:"reincarnated,omitempty"`
+}
+
+// AgentStory is GET /api/v1/agent/{id}/story. Source is "llm" for a
+// generated biography, "template" for a Tier 0 chronicle and "obituary" for
+// the dead.
+type AgentStory struct {
+	Name        string `json:"name"`
+	Biography   string `json:"biography"`
+	GeneratedAt string `json:"generated_at"` // sim time
+	Source      string `json:"source"`
+}
+
+// PriceHistory is GET /api/v1/settlement/{id}/prices: OHLC bars per good.
+type PriceHistory struct {
+	SettlementID uint64        `json:"settlement_id"`
+	Resolution   string        `json:"resolution"`
+	Series       []PriceSeries `json:"series"`
+}
+
+// PriceSeries is one good's bars, oldest first.
+type PriceSeries struct {
+	Good string        `json:"good"`
+	Bars []PriceCandle `json:"bars"`
+}
+
+// PriceCandle is one hour's or day's open, high, low and close.
+type PriceCandle struct {
+	Tick   uint64  `json:"tick"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int     `json:"volume"`
+}
+
+// SettlementDetail is GET /api/v1/settlement/{id} for a living settlement.
+type SettlementDetail struct {
+	ID                 uint64                    `json:"id"`
+	Name               string                    `json:"name"`
+	Q                  int                       `json:"q"`
+	R                  int                       `json:"r"`
+	Population         uint32                    `json:"population"`
+	Governance         string                    `json:"governance"`
+	Treasury           uint64                    `json:"treasury"`
+	Health             float64                   `json:"health"`
+	GovernanceScore    float64                   `json:"governance_score"`
+	TaxRate            float64                   `json:"tax_rate"`
+	Culture            SettlementCulture         `json:"culture"`
+	Infrastructure     SettlementInfrastructure  `json:"infrastructure"`
+	Relations          []SettlementRelation      `json:"relations"`
+	TradeRoutes        []engine.TradeRouteInfo   `json:"trade_routes"`
+	Agreements         []engine.AgreementInfo    `json:"agreements"`
+	PeaceTreaties      []engine.PeaceTreatyInfo  `json:"peace_treaties"`
+	Occupations        map[string]int            `json:"occupations"`
+	AvgMood            float64                   `json:"avg_mood"`
+	AvgSatisfaction    float64                   `json:"avg_satisfaction"`
+	AvgAlignment       float64                   `json:"avg_alignment"`
+	RecentTradeVolume  int                       `json:"recent_trade_volume"`
+	MostTradedGood     string                    `json:"most_traded_good"`
+	Market             []MarketEntry             `json:"market"`
+	TopAgents          []SettlementAgent         `json:"top_agents"` // five wealthiest
+	FactionPresence    map[string]int            `json:"faction_presence"`
+	CarryingCapacity   float64                   `json:"carrying_capacity"`
+	PopulationPressure float64                   `json:"population_pressure"`
+	RecentEvents       []engine.Event            `json:"recent_events"`
+	Terrain            string                    `json:"terrain"`
+	MoodByOccupation   map[string]OccupationMood `json:"mood_by_occupation"`
+	NeedsSummary       NeedsSummary              `json:"needs_summary"`
+	WealthMedian       uint64                    `json:"wealth_median"`
+}
+
+// SettlementCulture is a settlement's cultural leanings.
+type SettlementCulture struct {
+	Tradition  float32 `json:"tradition"`
+	Openness   float32 `json:"openness"`
+	Militarism float32 `json:"militarism"`
+	Memory     float64 `json:"memory"`
+}
+
+// SettlementInfrastructure is a settlement's build levels, 0–5.
+type SettlementInfrastructure struct {
+	WallLevel   uint8 `json:"wall_level"`
+	RoadLevel   uint8 `json:"road_level"`
+	MarketLevel uint8 `json:"market_level"`
+}
+
+// SettlementRelation is a settlement's standing with another.
+type SettlementRelation struct {
+	SettlementID   uint64  `json:"settlement_id"`
+	SettlementName string  `json:"settlement_name"`
+	Sentiment      float64 `json:"sentiment"`
+	Trade          float64 `json:"trade"`
+}
+
+// MarketEntry is one good in a settlement's market.
+type MarketEntry struct {
+	Good   string  `json:"good"`
+	Price  float64 `json:"price"`
+	Supply float64 `json:"supply"`
+	Demand float64 `json:"demand"`
+}
+
+// SettlementAgent is one of a settlement's wealthiest residents.
+type SettlementAgent struct {
+	ID           agents.AgentID `json:"id"`
+	Name         string         `json:"name"`
+	Occupation   string         `json:"occupation"`
+	Tier         int            `json:"tier"`
+	Wealth       uint64         `json:"wealth"`
+	EffMood      float32        `json:"effective_mood"`
+	Satisfaction float32        `json:"satisfaction"`
+	Alignment    float32        `json:"alignment"`
+	Coherence    float32        `json:"coherence"`
+}
+
+// OccupationMood is the average satisfaction of one occupation.
+type OccupationMood struct {
+	Satisfaction float64 `json:"satisfaction"`
+	Count        int     `json:"count"`
+}
+
+// NeedsSummary counts residents with a need below 0.3.
+type NeedsSummary struct {
+	SurvivalLow  int `json:"survival_low"`
+	BelongingLow int `json:"belonging_low"`
+	PurposeLow   int `json:"purpose_low"`
+}
+
+// FactionDetail is GET /api/v1/faction/{id}.
+type FactionDetail struct {
+	ID           social.FactionID   `json:"id"`
+	Name         string             `json:"name"`
+	Treasury     uint64             `json:"treasury"`
+	Members      []FactionMember    `json:"members"`
+	MemberCount  int                `json:"member_count"`
+	TopInfluence []FactionInfluence `json:"top_influence"` // top 10
+	Relations    []FactionRelation  `json:"relations"`
+	Policies     FactionPolicies    `json:"policies"`
+	RecentEvents []engine.Event     `json:"recent_events"`
+}
+
+// FactionMember is a living member of a faction.
+type FactionMember struct {
+	ID         agents.AgentID `json:"id"`
+	Name       string         `json:"name"`
+	Tier       int            `json:"tier"`
+	Occupation string         `json:"occupation"`
+}
+
+// FactionInfluence is a faction's influence in one settlement.
+type FactionInfluence struct {
+	Name      string  `json:"name"`
+	Influence float64 `json:"influence"`
+}
+
+// FactionRelation is a faction's standing with another.
+type FactionRelation struct {
+	Name     string  `json:"name"`
+	Relation float64 `json:"relation"`
+}
+
+// FactionPolicies are a faction's leanings, each -1 to +1.
+type FactionPolicies struct {
+	TaxPreference      float64 `json:"tax_preference"`
+	TradePreference    float64 `json:"trade_preference"`
+	MilitaryPreference float64 `json:"military_preference"`
+}
+
+// Speed is GET and POST /api/v1/speed.
+type Speed struct {
+	Speed float64 `json:"speed"`
+}
+
+// SnapshotRequest is the optional body of POST /api/v1/snapshot. A label
+// asks for a named snapshot instead of a plain save.
+type SnapshotRequest struct {
+	Label string `json:"label"`
+}
+
+// SnapshotSaved answers POST /api/v1/snapshot without a label.
+type SnapshotSaved struct {
+	Tick    uint64 `json:"tick"`
+	Message string `json:"message"`
+}
+
+// TuningChange is the body of POST /api/v1/tuning.
+type TuningChange struct {
+	Knob  string   `json:"knob"`
+	Value *float64 `json:"value"`
+}
+
+// InterventionList is GET /api/v1/interventions, newest first.
+type InterventionList struct {
+	Count         int                          `json:"count"`
+	Interventions []*engine.InterventionRecord `json:"interventions"`
+}
+
+// LLMDisabled answers GET /api/v1/llm-usage when no LLM is configured.
+type LLMDisabled struct {
+	Status string `json:"status"` // "llm disabled"
+}
@@ -6,12 +6,15 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math"
 	"net/http"
 	"os"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,13 +22,13 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/talgya/mini-world/eventproto"
 	"github.com/talgya/mini-world/internal/agents"
 	"github.com/talgya/mini-world/internal/engine"
 	"github.com/talgya/mini-world/internal/llm"
 	"github.com/talgya/mini-world/internal/persistence"
 	"github.com/talgya/mini-world/internal/social"
 	"github.com/talgya/mini-world/internal/world"
+	"github.com/talgya/mini-world/pkg/worldclient"
 )
 
 const maxSSEConns = 2
@@ -35,11 +38,28 @@ type Server struct {
 	Sim      *engine.Simulation
 	Eng      *engine.Engine
 	LLM      *llm.Client
-	DB       *persistence.DB
+	DB       persistence.Store
 	Port     int
 	AdminKey string // Bearer token for POST endpoints. Empty = POST disabled.
 	RelayKey string // Bearer token for SSE stream endpoint. Empty = streaming disabled.
 
+	// Effective worldsim configuration, served read-only on /api/v1/status.
+	// Must marshal to JSON and must not contain secrets.
+	Config any
+
+	// Named snapshots: where they are written, and the map parameters recorded
+	// in them so a fork regenerates the same land.
+	SnapshotDir string
+	Gen         world.GenConfig
+
+	// SaveWorld is the save POST /api/v1/snapshot forces, run on the tick
+	// loop. Nil means the fast database save.
+	SaveWorld func() error
+
+	// What startup replayed from the write-ahead journal, served on
+	// /api/v1/status. Nil when the journal is off.
+	Recovery *persistence.RecoveryReport
+
 	// Active SSE connection count (atomic).
 	sseConns int32
 
@@ -59,6 +79,30 @@ type cachedBio struct {
 	GeneratedAt string `json:"generated_at"`
 }
 
+// view returns the world state handlers read from: the snapshot the tick loop
+// last published (see engine.Simulation.PublishView). Before any publish —
+// tests, or a server built without an engine — it falls back to the live sim.
+func (s *Server) view() *engine.Simulation {
+	if v := s.Sim.View(); v != nil {
+		return v
+	}
+	return s.Sim
+}
+
+// setLastNewspaper records the newest edition on the live sim, where the next
+// edition threads from and the autosave persists it. The write runs on the
+// tick loop so it doesn't race a save in progress.
+func (s *Server) setLastNewspaper(content string) {
+	set := func() { s.Sim.LastNewspaperContent = content }
+	if s.Eng == nil {
+		set()
+		return
+	}
+	if !s.Eng.SubmitLoopTask(set) {
+		slog.Warn("newspaper continuity not recorded: loop task queue unavailable")
+	}
+}
+
 // Start begins serving the HTTP API in a goroutine.
 func (s *Server) Start() {
 	// Initialize newspaper cache interval from env (default 3 hours).
@@ -74,55 +118,85 @@ func (s *Server) Start() {
 	// trigger a regeneration wave on next view (docs/26 #2).
 	s.loadBiographies()
 
-	// Rate limiters for LLM-consuming endpoints.
-	storyLimiter := NewRateLimiter(10, time.Hour)
-	newspaperLimiter := NewRateLimiter(30, time.Hour)
-
-	mux := http.NewServeMux()
-
-	// Public endpoints (GET, read-only — anyone can check in on the world).
-	mux.HandleFunc("/api/v1/status", s.handleStatus)
-	mux.HandleFunc("/api/v1/settlements", s.handleSettlements)
-	mux.HandleFunc("/api/v1/agents", s.handleAgents)
-	mux.HandleFunc("/api/v1/liberated", s.handleLiberated)
-	mux.HandleFunc("/api/v1/agent/", s.handleAgentRoutes(storyLimiter))
-	mux.HandleFunc("/api/v1/events", s.handleEvents)
-	mux.HandleFunc("/api/v1/stats", s.handleStats)
-	mux.HandleFunc("/api/v1/newspaper", RateLimitMiddleware(newspaperLimiter, s.handleNewspaper))
-	mux.HandleFunc("/api/v1/factions", s.handleFactions)
-	mux.HandleFunc("/api/v1/economy", s.handleEconomy)
-	mux.HandleFunc("/api/v1/social", s.handleSocial)
-
-	// Detail endpoints.
-	mux.HandleFunc("/api/v1/settlement/", s.handleSettlementDetail)
-	mux.HandleFunc("/api/v1/faction/", s.handleFactionDetail)
-	mux.HandleFunc("/api/v1/map", s.handleMapRoutes)
-	mux.HandleFunc("/api/v1/map/", s.handleMapRoutes)
-	mux.HandleFunc("/api/v1/stats/history", s.handleStatsHistory)
-	mux.HandleFunc("/api/v1/settlement/history/", s.handleSettlementHistory)
-	mux.HandleFunc("/api/v1/agent/timeline/", s.handleAgentTimeline)
-	mux.HandleFunc("/api/v1/llm-usage", s.handleLLMUsage)
-	mux.HandleFunc("/api/v1/metrics", s.handleMetrics)
-
-	// SSE streaming endpoint (GET, requires bearer token — relay only).
-	mux.HandleFunc("/api/v1/stream", s.handleStream)
-
-	// Admin endpoints (POST, require bearer token).
-	mux.HandleFunc("/api/v1/speed", s.adminOnly(s.handleSpeed))
-	mux.HandleFunc("/api/v1/snapshot", s.adminOnly(s.handleSnapshot))
-	mux.HandleFunc("/api/v1/intervention", s.adminOnly(s.handleIntervention))
-
 	addr := fmt.Sprintf(":%d", s.Port)
 	slog.Info("HTTP API starting", "addr", addr, "admin_auth", s.AdminKey != "", "relay_auth", s.RelayKey != "")
 
 	go func() {
-		handler := corsMiddleware(mux)
+		handler := corsMiddleware(s.Handler())
 		if err := http.ListenAndServe(addr, handler); err != nil {
 			slog.Error("HTTP server error", "error", err)
 		}
 	}()
 }
 
+// Handler returns the API's routes, without the CORS layer Start adds.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range s.routes() {
+		mux.HandleFunc(rt.pattern, rt.handler)
+	}
+	return mux
+}
+
+// route is one registered mux pattern. Every pattern has its methods
+// documented in operations (openapi.go).
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+}
+
+// routes lists the API's patterns and their handlers.
+func (s *Server) routes() []route {
+	// Rate limiters for LLM-consuming endpoints.
+	storyLimiter := NewRateLimiter(10, time.Hour)
+	newspaperLimiter := NewRateLimiter(30, time.Hour)
+
+	return []route{
+		// Public endpoints (GET, read-only — anyone can check in on the world).
+		{"/api/v1/status", s.handleStatus},
+		{"/api/v1/settlements", s.handleSettlements},
+		{"/api/v1/settlements/archive", s.handleSettlementArchive},
+		{"/api/v1/agents", s.handleAgents},
+		{"/api/v1/liberated", s.handleLiberated},
+		{"/api/v1/agent/", s.handleAgentRoutes(storyLimiter)},
+		{"/api/v1/events", s.handleEvents},
+		{"/api/v1/stats", s.handleStats},
+		{"/api/v1/newspaper", RateLimitMiddleware(newspaperLimiter, s.handleNewspaper)},
+		{"/api/v1/factions", s.handleFactions},
+		{"/api/v1/economy", s.handleEconomy},
+		{"/api/v1/social", s.handleSocial},
+		{"/api/v1/openapi.json", s.handleOpenAPI},
+
+		// Detail endpoints.
+		{"/api/v1/settlement/", s.handleSettlementDetail},
+		{"/api/v1/faction/", s.handleFactionDetail},
+		{"/api/v1/map", s.handleMapRoutes},
+		{"/api/v1/map/", s.handleMapRoutes},
+		{"/api/v1/stats/history", s.handleStatsHistory},
+		{"/api/v1/settlement/history/", s.handleSettlementHistory},
+		{"/api/v1/agent/timeline/", s.handleAgentTimeline},
+		{"/api/v1/graveyard", s.handleGraveyard},
+		{"/api/v1/search", s.handleSearch},
+		{"/api/v1/llm-usage", s.handleLLMUsage},
+		{"/api/v1/metrics", s.handleMetrics},
+		{"/api/v1/interventions", s.handleInterventions},
+
+		// SSE streaming endpoint (GET, requires bearer token — relay only).
+		{"/api/v1/stream", s.handleStream},
+
+		// Admin endpoints (POST, require bearer token).
+		{"/api/v1/speed", s.adminOnly(s.handleSpeed)},
+		{"/api/v1/snapshot", s.adminOnly(s.handleSnapshot)},
+		{"/api/v1/intervention", s.adminOnly(s.handleIntervention)},
+		{"/api/v1/intervention/", s.adminOnly(s.handleInterventionRoutes)},
+		{"/api/v1/tuning", s.adminOnly(s.handleTuning)},
+
+		// Admin endpoints (every method requires the bearer token).
+		{"/api/v1/snapshots", s.adminAlways(s.handleSnapshots)},
+		{"/api/v1/snapshots/", s.adminAlways(s.handleSnapshotDownload)},
+	}
+}
+
 // corsMiddleware adds CORS headers for allowed frontend origins.
 // Set CORS_ORIGINS env var to a comma-separated list of allowed origins
 // (e.g. "https://crossworlds.example.com,https://crossworlds-ui.vercel.app").
@@ -169,29 +243,10 @@ func (s *Server) handleMapRoutes(w http.ResponseWriter, r *http.Request) {
 
 // handleBulkMap returns all hexes for the hex map renderer.
 func (s *Server) handleBulkMap(w http.ResponseWriter, r *http.Request) {
-	type hexEntry struct {
-		Q                 int      `json:"q"`
-		R                 int      `json:"r"`
-		Terrain           uint8    `json:"terrain"`
-		Elevation         float64  `json:"elevation"`
-		SettlementID      *uint64  `json:"settlement_id,omitempty"`
-		Health            *float64 `json:"health,omitempty"`             // Omitted when pristine (1.0)
-		IrrigationLevel   uint8    `json:"irrigation_level,omitempty"`   // Omitted when 0
-		ConservationLevel uint8    `json:"conservation_level,omitempty"` // Omitted when 0
-		ClaimedBy         *uint64  `json:"claimed_by,omitempty"`         // Omitted when unclaimed
-	}
-
-	type settlementEntry struct {
-		ID         uint64 `json:"id"`
-		Name       string `json:"name"`
-		Q          int    `json:"q"`
-		R          int    `json:"r"`
-		Population uint32 `json:"population"`
-	}
-
-	hexes := make([]hexEntry, 0, len(s.Sim.WorldMap.Hexes))
-	for _, h := range s.Sim.WorldMap.Hexes {
-		entry := hexEntry{
+	sim := s.view()
+	hexes := make([]MapHex, 0, len(sim.WorldMap.Hexes))
+	for _, h := range sim.WorldMap.Hexes {
+		entry := MapHex{
 			Q:                 h.Coord.Q,
 			R:                 h.Coord.R,
 			Terrain:           uint8(h.Terrain),
@@ -209,9 +264,9 @@ func (s *Server) handleBulkMap(w http.ResponseWriter, r *http.Request) {
 		hexes = append(hexes, entry)
 	}
 
-	settlements := make([]settlementEntry, 0, len(s.Sim.Settlements))
-	for _, st := range s.Sim.Settlements {
-		settlements = append(settlements, settlementEntry{
+	settlements := make([]MapSettlement, 0, len(sim.Settlements))
+	for _, st := range sim.Settlements {
+		settlements = append(settlements, MapSettlement{
 			ID:         st.ID,
 			Name:       st.Name,
 			Q:          st.Position.Q,
@@ -220,10 +275,10 @@ func (s *Server) handleBulkMap(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	writeJSON(w, map[string]any{
-		"radius":      s.Sim.WorldMap.Radius,
-		"hexes":       hexes,
-		"settlements": settlements,
+	writeJSON(w, MapView{
+		Radius:      sim.WorldMap.Radius,
+		Hexes:       hexes,
+		Settlements: settlements,
 	})
 }
 
@@ -237,42 +292,53 @@ func (s *Server) checkBearerToken(r *http.Request) bool {
 // GET requests pass through (for endpoints that support both GET and POST).
 func (s *Server) adminOnly(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			if s.AdminKey == "" {
-				http.Error(w, "admin endpoints disabled (no WORLDSIM_ADMIN_KEY set)", http.StatusForbidden)
-				return
-			}
-
-			if !s.checkBearerToken(r) {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
+		if r.Method == http.MethodPost && !s.authorizeAdmin(w, r) {
+			return
 		}
-
 		next(w, r)
 	}
 }
 
-func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
-	weatherInfo := map[string]any{
-		"description":   s.Sim.CurrentWeather.Description,
-		"temp_modifier": s.Sim.CurrentWeather.TempModifier,
+// adminAlways wraps a handler to require bearer token auth on every method,
+// for reads that are not public (snapshot files hold the whole world).
+func (s *Server) adminAlways(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authorizeAdmin(w, r) {
+			next(w, r)
+		}
 	}
+}
 
+// authorizeAdmin checks the admin bearer token, writing the error response
+// and returning false if it is missing or wrong.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.AdminKey == "" {
+		http.Error(w, "admin endpoints disabled (no WORLDSIM_ADMIN_KEY set)", http.StatusForbidden)
+		return false
+	}
+	if !s.checkBearerToken(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	occNames := [10]string{
 		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
 		"Scholar", "Alchemist", "Laborer", "Fisher", "Hunter",
 	}
-	occupations := make(map[string]map[string]any, 10)
+	occupations := make(map[string]worldclient.OccupationStats, 10)
 	for i := 0; i < 10; i++ {
-		occupations[occNames[i]] = map[string]any{
-			"count":            s.Sim.Stats.OccupationCounts[i],
-			"avg_satisfaction": s.Sim.Stats.OccupationSat[i],
-			"avg_survival":     s.Sim.Stats.OccupationSurvival[i],
-			"avg_safety":       s.Sim.Stats.OccupationSafety[i],
-			"avg_belonging":    s.Sim.Stats.OccupationBelonging[i],
-			"avg_purpose":      s.Sim.Stats.OccupationPurpose[i],
-			"avg_esteem":       s.Sim.Stats.OccupationEsteem[i],
+		occupations[occNames[i]] = worldclient.OccupationStats{
+			Count:           sim.Stats.OccupationCounts[i],
+			AvgSatisfaction: sim.Stats.OccupationSat[i],
+			AvgSurvival:     sim.Stats.OccupationSurvival[i],
+			AvgSafety:       sim.Stats.OccupationSafety[i],
+			AvgBelonging:    sim.Stats.OccupationBelonging[i],
+			AvgPurpose:      sim.Stats.OccupationPurpose[i],
+			AvgEsteem:       sim.Stats.OccupationEsteem[i],
 		}
 	}
 
@@ -282,7 +348,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	// conflated both and made observations confusing.
 	unaffAdults := 0
 	unaffChildren := 0
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if !a.Alive || a.FactionID != nil {
 			continue
 		}
@@ -293,57 +359,71 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	status := map[string]any{
-		"name":                  "Crossworlds",
-		"tick":                  s.Sim.CurrentTick(),
-		"sim_time":              engine.SimTime(s.Sim.CurrentTick()),
-		"season":                engine.SeasonName(s.Sim.CurrentSeason),
-		"speed":                 s.Eng.Speed(),
-		"running":               s.Eng.IsRunning(),
-		"population":            s.Sim.Stats.TotalPopulation,
-		"deaths":                s.Sim.Stats.Deaths,
-		"births":                s.Sim.Stats.Births,
-		"settlements":           len(s.Sim.Settlements),
-		"factions":              len(s.Sim.Factions),
-		"avg_mood":              s.Sim.Stats.AvgMood,
-		"avg_satisfaction":      s.Sim.Stats.AvgSatisfaction,
-		"avg_alignment":         s.Sim.Stats.AvgAlignment,
-		"total_wealth":          s.Sim.Stats.TotalWealth,
-		"weather":               weatherInfo,
-		"occupations":           occupations,
-		"unaffiliated_adults":   unaffAdults,
-		"unaffiliated_children": unaffChildren,
+	status := worldclient.Status{
+		Name:            "Crossworlds",
+		Tick:            sim.CurrentTick(),
+		SimTime:         engine.SimTime(sim.CurrentTick()),
+		Season:          engine.SeasonName(sim.CurrentSeason),
+		Speed:           s.Eng.Speed(),
+		Running:         s.Eng.IsRunning(),
+		Population:      sim.Stats.TotalPopulation,
+		Deaths:          sim.Stats.Deaths,
+		Births:          sim.Stats.Births,
+		Settlements:     len(sim.Settlements),
+		Factions:        len(sim.Factions),
+		AvgMood:         sim.Stats.AvgMood,
+		AvgSatisfaction: sim.Stats.AvgSatisfaction,
+		AvgAlignment:    sim.Stats.AvgAlignment,
+		TotalWealth:     sim.Stats.TotalWealth,
+		Weather: worldclient.Weather{
+			Description:  sim.CurrentWeather.Description,
+			TempModifier: sim.CurrentWeather.TempModifier,
+		},
+		Occupations:          occupations,
+		UnaffiliatedAdults:   unaffAdults,
+		UnaffiliatedChildren: unaffChildren,
+		Config:               s.Config,
+	}
+	if s.Recovery != nil {
+		status.Recovery = s.Recovery
 	}
 	writeJSON(w, status)
 }
 
+// handleSettlements lists settlements in the list grammar (see list.go):
+// ?faction_id= keeps those the faction holds influence in, and the wealth
+// range bounds the treasury.
 func (s *Server) handleSettlements(w http.ResponseWriter, r *http.Request) {
-	type settlementSummary struct {
-		ID                 uint64  `json:"id"`
-		Name               string  `json:"name"`
-		Q                  int     `json:"q"`
-		R                  int     `json:"r"`
-		Population         uint32  `json:"population"`
-		Governance         string  `json:"governance"`
-		Treasury           uint64  `json:"treasury"`
-		Health             float64 `json:"health"`
-		CarryingCapacity   float64 `json:"carrying_capacity"`
-		PopulationPressure float64 `json:"population_pressure"`
-		OccupationHHI      float64 `json:"occupation_hhi"`
+	lq, err := parseList(r.URL.Query(), settlementList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
+	sim := s.view()
 	govNames := map[uint8]string{0: "Monarchy", 1: "Council", 2: "Merchant Republic", 3: "Commune"}
+	var faction *social.Faction
+	if lq.factionID != 0 {
+		for _, f := range sim.Factions {
+			if uint64(f.ID) == lq.factionID {
+				faction = f
+			}
+		}
+	}
 
-	var result []settlementSummary
-	for _, st := range s.Sim.Settlements {
-		cc, pp := s.Sim.SettlementCarryingCapacity(st.ID)
+	var result []worldclient.SettlementSummary
+	for _, st := range sim.Settlements {
+		if lq.factionID != 0 && (faction == nil || faction.Influence[st.ID] <= 0) ||
+			!lq.wealth.Has(float64(st.Treasury)) {
+			continue
+		}
+		cc, pp := sim.SettlementCarryingCapacity(st.ID)
 
 		// Compute occupation HHI from SettlementAgents map. HHI measures
 		// occupational concentration; combined with population_pressure it
 		// identifies the "silent import-dependent" settlement class.
 		var counts [10]int
 		total := 0
-		for _, a := range s.Sim.SettlementAgents[st.ID] {
+		for _, a := range sim.SettlementAgents[st.ID] {
 			if !a.Alive {
 				continue
 			}
@@ -360,7 +440,7 @@ func (s *Server) handleSettlements(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		result = append(result, settlementSummary{
+		result = append(result, worldclient.SettlementSummary{
 			ID:                 st.ID,
 			Name:               st.Name,
 			Q:                  st.Position.Q,
@@ -374,39 +454,43 @@ func (s *Server) handleSettlements(w http.ResponseWriter, r *http.Request) {
 			OccupationHHI:      hhi,
 		})
 	}
+	result, err = pageRows(w, lq, result, func(st worldclient.SettlementSummary) uint64 { return st.ID })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	writeJSON(w, result)
 }
 
+// handleAgents lists agents in the list grammar (see list.go). ?tier= picks
+// one tier, or all of them; without it only Tier 2 characters are listed.
 func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
-	tier := r.URL.Query().Get("tier")
-
-	type agentSummary struct {
-		ID           agents.AgentID `json:"id"`
-		Name         string         `json:"name"`
-		Age          uint16         `json:"age"`
-		Occupation   string         `json:"occupation"`
-		Tier         int            `json:"tier"`
-		Coherence    float32        `json:"coherence"`
-		EffMood      float32        `json:"effective_mood"` // Effective mood (blended)
-		Satisfaction float32        `json:"satisfaction"`   // Material needs satisfaction
-		Alignment    float32        `json:"alignment"`      // Coherence-derived harmony
-		Wealth       uint64         `json:"wealth"`
-		Alive        bool           `json:"alive"`
+	lq, err := parseList(r.URL.Query(), agentList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	sim := s.view()
+	tier := r.URL.Query().Get("tier")
 
 	occNames := []string{
 		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
 		"Scholar", "Alchemist", "Laborer", "Fisher", "Hunter",
 	}
 
-	var result []agentSummary
-	for _, a := range s.Sim.Agents {
-		if tier != "" {
+	var result []worldclient.AgentSummary
+	for _, a := range sim.Agents {
+		switch {
+		case tier == "all":
+		case tier != "":
 			t, _ := strconv.Atoi(tier)
 			if int(a.Tier) != t {
 				continue
 			}
-		} else if a.Tier < agents.Tier2 {
+		case a.Tier < agents.Tier2:
+			continue
+		}
+		if !lq.agent(a) {
 			continue
 		}
 
@@ -415,8 +499,8 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 			occName = occNames[a.Occupation]
 		}
 
-		result = append(result, agentSummary{
-			ID:           a.ID,
+		result = append(result, worldclient.AgentSummary{
+			ID:           uint64(a.ID),
 			Name:         a.Name,
 			Age:          a.Age,
 			Occupation:   occName,
@@ -429,6 +513,11 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 			Alive:        a.Alive,
 		})
 	}
+	result, err = pageRows(w, lq, result, func(a worldclient.AgentSummary) uint64 { return a.ID })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	writeJSON(w, result)
 }
 
@@ -459,29 +548,7 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 // Response: {agents, count, total, threshold, limit, offset, sort, order}.
 // `count` retained for backwards compat = total matching agents (pre-slice).
 func (s *Server) handleLiberated(w http.ResponseWriter, r *http.Request) {
-	type liberatedAgent struct {
-		ID             agents.AgentID `json:"id"`
-		Name           string         `json:"name"`
-		Age            uint16         `json:"age"`
-		Occupation     string         `json:"occupation"`
-		Tier           int            `json:"tier"`
-		Role           string         `json:"role"`
-		SettlementID   uint64         `json:"settlement_id,omitempty"`
-		SettlementName string         `json:"settlement_name,omitempty"`
-		Coherence      float32        `json:"coherence"`
-		EffMood        float32        `json:"effective_mood"`
-		Satisfaction   float32        `json:"satisfaction"`
-		Alignment      float32        `json:"alignment"`
-		Wealth         uint64         `json:"wealth"`
-		Memories       int            `json:"memories"`
-		// R94 (2026-05-07): surface the R89 + R90 mechanics so the frontend
-		// can show "earned vs. inherited" liberation without per-agent detail
-		// lookups. WisdomEffort is the practice counter (R89); Reincarnated
-		// flags carried-over wisdom from a deceased liberated elder (R90).
-		WisdomEffort uint32 `json:"wisdom_effort"`
-		Reincarnated bool   `json:"reincarnated,omitempty"`
-	}
-
+	sim := s.view()
 	occNames := []string{
 		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
 		"Scholar", "Alchemist", "Laborer", "Fisher", "Hunter",
@@ -531,8 +598,8 @@ func (s *Server) handleLiberated(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var result []liberatedAgent
-	for _, a := range s.Sim.Agents {
+	var result []LiberatedAgent
+	for _, a := range sim.Agents {
 		if !a.Alive {
 			continue
 		}
@@ -555,13 +622,13 @@ func (s *Server) handleLiberated(w http.ResponseWriter, r *http.Request) {
 		var settID uint64
 		var settName string
 		if a.HomeSettID != nil {
-			if sett, ok := s.Sim.SettlementIndex[*a.HomeSettID]; ok {
+			if sett, ok := sim.SettlementIndex[*a.HomeSettID]; ok {
 				settID = sett.ID
 				settName = sett.Name
 			}
 		}
 
-		result = append(result, liberatedAgent{
+		result = append(result, LiberatedAgent{
 			ID:             a.ID,
 			Name:           a.Name,
 			Age:            a.Age,
@@ -620,16 +687,16 @@ func (s *Server) handleLiberated(w http.ResponseWriter, r *http.Request) {
 		result = result[offset:end]
 	}
 
-	writeJSON(w, map[string]any{
-		"count":     total, // backwards compat: total matching, pre-slice
-		"total":     total,
-		"threshold": liberationThreshold,
-		"limit":     limit,
-		"offset":    offset,
-		"sort":      sortKey,
-		"order":     order,
-		"min_age":   minAge,
-		"agents":    result,
+	writeJSON(w, LiberatedPage{
+		Count:     total,
+		Total:     total,
+		Threshold: liberationThreshold,
+		Limit:     limit,
+		Offset:    offset,
+		Sort:      sortKey,
+		Order:     order,
+		MinAge:    minAge,
+		Agents:    result,
 	})
 }
 
@@ -637,11 +704,12 @@ func (s *Server) handleAgentRoutes(storyLimiter *RateLimiter) http.HandlerFunc {
 	rateLimitedStory := RateLimitMiddleware(storyLimiter, func(w http.ResponseWriter, r *http.Request) {
 		parts := strings.Split(r.URL.Path, "/")
 		id, _ := strconv.ParseUint(parts[4], 10, 64)
-		agent := s.Sim.AgentIndex[agents.AgentID(id)]
+		agent := s.view().AgentIndex[agents.AgentID(id)]
 		s.handleAgentStory(w, r, agent)
 	})
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		sim := s.view()
 		parts := strings.Split(r.URL.Path, "/")
 		if len(parts) < 5 {
 			http.Error(w, "missing agent id", http.StatusBadRequest)
@@ -653,9 +721,30 @@ func (s *Server) handleAgentRoutes(storyLimiter *RateLimiter) http.HandlerFunc {
 			return
 		}
 
-		agent, ok := s.Sim.AgentIndex[agents.AgentID(id)]
+		// Route to /agent/:id/family, which also answers for the dead.
+		if len(parts) >= 6 && parts[5] == "family" {
+			s.handleAgentFamily(w, r, sim, id)
+			return
+		}
+
+		agent, ok := sim.AgentIndex[agents.AgentID(id)]
 		if !ok {
-			http.Error(w, "agent not found", http.StatusNotFound)
+			// Compacted away: answer from the graveyard.
+			grave, err := s.findGrave(sim, id)
+			if err != nil {
+				slog.Error("grave lookup failed", "error", err, "agent_id", id)
+				http.Error(w, "grave lookup failed", http.StatusInternalServerError)
+				return
+			}
+			if grave == nil {
+				http.Error(w, "agent not found", http.StatusNotFound)
+				return
+			}
+			if len(parts) >= 6 && parts[5] == "story" {
+				s.handleGraveStory(w, grave)
+				return
+			}
+			writeJSON(w, grave)
 			return
 		}
 		_ = agent
@@ -666,7 +755,364 @@ func (s *Server) handleAgentRoutes(storyLimiter *RateLimiter) http.HandlerFunc {
 			return
 		}
 
-		writeJSON(w, s.Sim.AgentIndex[agents.AgentID(id)])
+		writeJSON(w, sim.AgentIndex[agents.AgentID(id)])
+	}
+}
+
+// findGrave returns agent id's grave from sim's unsaved graves or the
+// graveyard; nil if they are not buried.
+func (s *Server) findGrave(sim *engine.Simulation, id uint64) (*engine.Grave, error) {
+	for i := range sim.UnsavedGraves {
+		if uint64(sim.UnsavedGraves[i].AgentID) == id {
+			return &sim.UnsavedGraves[i], nil
+		}
+	}
+	if s.DB == nil {
+		return nil, nil
+	}
+	return s.DB.LoadGrave(id)
+}
+
+// handleGraveStory serves /agent/:id/story for the dead: their biography if
+// one was written while they lived, otherwise an obituary from their grave.
+func (s *Server) handleGraveStory(w http.ResponseWriter, g *engine.Grave) {
+	s.bioMu.Lock()
+	cached, ok := s.bioCache[g.AgentID]
+	s.bioMu.Unlock()
+	if ok {
+		writeJSON(w, AgentStory{
+			Name:        g.Name,
+			Biography:   cached.Biography,
+			GeneratedAt: cached.GeneratedAt,
+			Source:      "llm",
+		})
+		return
+	}
+	writeJSON(w, AgentStory{
+		Name:        g.Name,
+		Biography:   obituary(g),
+		GeneratedAt: engine.SimTime(g.DiedTick),
+		Source:      "obituary",
+	})
+}
+
+// obituary is the templated life story of the dead, from their grave.
+func obituary(g *engine.Grave) string {
+	occNames := []string{
+		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
+		"Scholar", "Alchemist", "Laborer", "Fisher", "Hunter",
+	}
+	occName := "soul"
+	if int(g.Occupation) < len(occNames) {
+		occName = strings.ToLower(occNames[g.Occupation])
+	}
+	place := g.SettlementName
+	if place == "" {
+		place = "the outer wilds"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, a %s of %s, died of %s in %s, aged %d",
+		g.Name, occName, place, g.Cause, engine.SimTime(g.DiedTick), g.Age)
+	if g.FactionName != "" {
+		fmt.Fprintf(&b, ", sworn to the %s", g.FactionName)
+	}
+	fmt.Fprintf(&b, ". They left %d crowns.", g.Wealth)
+	for _, m := range g.Memories {
+		fmt.Fprintf(&b, " They remembered: %s.", strings.TrimSuffix(m.Content, "."))
+	}
+	return b.String()
+}
+
+// handleGraveyard serves GET /api/v1/graveyard: the dead, most recent first
+// unless sorted otherwise, in the list grammar (see list.go) plus ?cause=.
+// The tick range is on the tick of death.
+func (s *Server) handleGraveyard(w http.ResponseWriter, r *http.Request) {
+	if s.DB == nil {
+		http.Error(w, "database not available", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	lq, err := parseList(q, graveList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	gq := persistence.GraveQuery{
+		Cause: q.Get("cause"), SettlementID: lq.settlementID, FactionID: lq.factionID,
+		Occupation: lq.occupation, State: lq.state, Age: lq.age, Wealth: lq.wealth,
+		FromTick: lq.from, ToTick: lq.to, Sort: lq.sort, Asc: lq.asc, Limit: lq.limit,
+	}
+	if gq.Cursor, err = lq.keyCursor(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	graves, next, err := s.DB.QueryGraves(gq, s.view().UnsavedGraves)
+	if errors.Is(err, persistence.ErrBadCursor) {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("graveyard query failed", "error", err)
+		http.Error(w, "graveyard query failed", http.StatusInternalServerError)
+		return
+	}
+	if graves == nil {
+		graves = []engine.Grave{}
+	}
+	if next != nil {
+		lq.setNext(w, next.String())
+	}
+	writeJSON(w, graves)
+}
+
+// handleSearch serves GET /api/v1/search: saved events or memories matching
+// ?q=, newest first, each with a snippet marking the matches.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if s.DB == nil {
+		http.Error(w, "database not available", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	lq, err := parseList(q, searchList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sq := persistence.SearchQuery{
+		Text: q.Get("q"), Type: q.Get("type"), FromTick: lq.from, ToTick: lq.to, Limit: lq.limit,
+	}
+	if sq.Type != "" && sq.Type != persistence.SearchEvents && sq.Type != persistence.SearchMemories {
+		http.Error(w, "type must be events or memories", http.StatusBadRequest)
+		return
+	}
+	if name := q.Get("settlement"); name != "" {
+		if sq.SettlementID, err = strconv.ParseUint(name, 10, 64); err != nil {
+			for _, st := range s.view().Settlements {
+				if strings.EqualFold(st.Name, name) {
+					sq.SettlementID = st.ID
+				}
+			}
+			if sq.SettlementID == 0 {
+				http.Error(w, "unknown settlement "+strconv.Quote(name), http.StatusBadRequest)
+				return
+			}
+		}
+		// Who lives where comes from the world, not the last save: with
+		// checkpoints on, the database's agent rows are as old as the boot.
+		if sq.Type == persistence.SearchMemories {
+			sq.AgentIDs = []uint64{}
+			for _, a := range s.view().SettlementAgents[sq.SettlementID] {
+				sq.AgentIDs = append(sq.AgentIDs, uint64(a.ID))
+			}
+			sq.SettlementID = 0
+		}
+	}
+	if sq.Cursor, err = lq.keyCursor(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hits, next, err := s.DB.Search(sq)
+	switch {
+	case errors.Is(err, persistence.ErrBadSearch):
+		http.Error(w, "q has no words to search for", http.StatusBadRequest)
+		return
+	case errors.Is(err, persistence.ErrBadCursor):
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.Error("search failed", "error", err)
+		http.Error(w, "search failed", http.StatusInternalServerError)
+		return
+	}
+	if hits == nil {
+		hits = []persistence.SearchHit{}
+	}
+	if next != nil {
+		lq.setNext(w, next.String())
+	}
+	writeJSON(w, hits)
+}
+
+// findArchive returns the archive entry of the settlement most recently
+// abandoned under id, unsaved or saved, or nil if there is none.
+func (s *Server) findArchive(sim *engine.Simulation, id uint64) (*engine.SettlementArchive, error) {
+	var latest *engine.SettlementArchive
+	for i, a := range sim.UnsavedArchives {
+		if a.ID == id && (latest == nil || a.AbandonedTick > latest.AbandonedTick) {
+			latest = &sim.UnsavedArchives[i]
+		}
+	}
+	if latest != nil || s.DB == nil {
+		return latest, nil
+	}
+	return s.DB.LoadSettlementArchive(id)
+}
+
+// handleSettlementArchive serves GET /api/v1/settlements/archive: abandoned
+// settlements, most recently abandoned first unless sorted otherwise, in the
+// list grammar (see list.go) plus ?cause=. The tick range is on the tick of
+// abandonment.
+func (s *Server) handleSettlementArchive(w http.ResponseWriter, r *http.Request) {
+	if s.DB == nil {
+		http.Error(w, "database not available", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	lq, err := parseList(q, archiveList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	aq := persistence.ArchiveQuery{
+		Cause: q.Get("cause"), SettlementID: lq.settlementID, FromTick: lq.from, ToTick: lq.to,
+		Sort: lq.sort, Asc: lq.asc, Limit: lq.limit,
+	}
+	if aq.Cursor, err = lq.keyCursor(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	archives, next, err := s.DB.QuerySettlementArchives(aq, s.view().UnsavedArchives)
+	if errors.Is(err, persistence.ErrBadCursor) {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("settlement archive query failed", "error", err)
+		http.Error(w, "settlement archive query failed", http.StatusInternalServerError)
+		return
+	}
+	if archives == nil {
+		archives = []engine.SettlementArchive{}
+	}
+	if next != nil {
+		lq.setNext(w, next.String())
+	}
+	writeJSON(w, archives)
+}
+
+// handleSettlementPrices serves GET /api/v1/settlement/:id/prices: the
+// market's price bars per good, oldest first. ?good= picks one good (by name,
+// e.g. grain or iron_ore), ?from= and ?to= bound the ticks, and ?resolution=
+// is hour (the default; kept for price_retention_days) or day.
+func (s *Server) handleSettlementPrices(w http.ResponseWriter, r *http.Request, id uint64) {
+	if s.DB == nil {
+		http.Error(w, "database not available", http.StatusServiceUnavailable)
+		return
+	}
+	goodNames := map[agents.GoodType]string{
+		agents.GoodGrain: "grain", agents.GoodTimber: "timber", agents.GoodIronOre: "iron_ore",
+		agents.GoodStone: "stone", agents.GoodFish: "fish", agents.GoodHerbs: "herbs",
+		agents.GoodGems: "gems", agents.GoodFurs: "furs", agents.GoodCoal: "coal",
+		agents.GoodExotics: "exotics", agents.GoodTools: "tools", agents.GoodWeapons: "weapons",
+		agents.GoodClothing: "clothing", agents.GoodMedicine: "medicine", agents.GoodLuxuries: "luxuries",
+	}
+	q := r.URL.Query()
+	pq := persistence.PriceQuery{SettlementID: id, Resolution: persistence.PriceHourly}
+	switch res := q.Get("resolution"); res {
+	case "", persistence.PriceHourly:
+	case persistence.PriceDaily:
+		pq.Resolution = res
+	default:
+		http.Error(w, fmt.Sprintf("invalid resolution %q (hour or day)", res), http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("good"); v != "" {
+		name := strings.ReplaceAll(strings.ToLower(v), " ", "_")
+		for good, n := range goodNames {
+			if n == name {
+				pq.Good = &good
+			}
+		}
+		if pq.Good == nil {
+			http.Error(w, fmt.Sprintf("unknown good %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, f := range []struct {
+		name string
+		dst  *uint64
+	}{
+		{"from", &pq.FromTick},
+		{"to", &pq.ToTick},
+	} {
+		if v := q.Get(f.name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s %q", f.name, v), http.StatusBadRequest)
+				return
+			}
+			*f.dst = n
+		}
+	}
+
+	bars, err := s.DB.QueryPrices(pq)
+	if err != nil {
+		slog.Error("price history query failed", "error", err, "settlement_id", id)
+		http.Error(w, "price history query failed", http.StatusInternalServerError)
+		return
+	}
+
+	out := []PriceSeries{}
+	for _, b := range bars {
+		if len(out) == 0 || out[len(out)-1].Good != goodNames[b.Good] {
+			out = append(out, PriceSeries{Good: goodNames[b.Good]})
+		}
+		cur := &out[len(out)-1]
+		cur.Bars = append(cur.Bars, PriceCandle{Tick: b.Tick, Open: b.Open, High: b.High, Low: b.Low, Close: b.Close, Volume: b.Volume})
+	}
+	writeJSON(w, PriceHistory{SettlementID: id, Resolution: pq.Resolution, Series: out})
+}
+
+// handleAgentFamily serves GET /api/v1/agent/:id/family?generations=N: the
+// agent's spouses, and ancestors and descendants to N generations (default 3,
+// at most 10), from the family tree.
+func (s *Server) handleAgentFamily(w http.ResponseWriter, r *http.Request, sim *engine.Simulation, id uint64) {
+	if s.DB == nil {
+		http.Error(w, "database not available", http.StatusServiceUnavailable)
+		return
+	}
+	generations := 3
+	if g := r.URL.Query().Get("generations"); g != "" {
+		v, err := strconv.Atoi(g)
+		if err != nil || v < 1 || v > 10 {
+			http.Error(w, "generations must be 1 to 10", http.StatusBadRequest)
+			return
+		}
+		generations = v
+	}
+
+	fam, err := persistence.LoadFamily(s.DB, sim.UnsavedLineage, livingIn(sim), id, generations)
+	if err != nil {
+		slog.Error("family query failed", "error", err, "agent_id", id)
+		http.Error(w, "family query failed", http.StatusInternalServerError)
+		return
+	}
+	if fam == nil {
+		// Not in the tree yet: no children and never married.
+		a, ok := sim.AgentIndex[agents.AgentID(id)]
+		if !ok {
+			http.Error(w, "agent not found", http.StatusNotFound)
+			return
+		}
+		fam = &persistence.Family{
+			Agent:       persistence.LineagePerson{ID: id, Name: a.Name, BornTick: a.BornTick, Alive: a.Alive},
+			Generations: generations, Spouses: []persistence.LineagePerson{},
+			Ancestors: []*persistence.FamilyMember{}, Descendants: []*persistence.FamilyMember{},
+		}
+	}
+	writeJSON(w, fam)
+}
+
+// livingIn reports whether an agent is alive in sim. The family tree misses
+// deaths a crash lost; the live world does not.
+func livingIn(sim *engine.Simulation) func(id uint64) bool {
+	return func(id uint64) bool {
+		a, ok := sim.AgentIndex[agents.AgentID(id)]
+		return ok && a.Alive
 	}
 }
 
@@ -693,6 +1139,7 @@ func (s *Server) loadBiographies() {
 }
 
 func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent *agents.Agent) {
+	sim := s.view()
 	refresh := r.URL.Query().Get("refresh") == "true"
 
 	// Refresh requires admin auth (LLM-consuming operation).
@@ -713,11 +1160,11 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 	s.bioMu.Unlock()
 
 	if hasCached && !refresh {
-		writeJSON(w, map[string]any{
-			"name":         agent.Name,
-			"biography":    cached.Biography,
-			"generated_at": cached.GeneratedAt,
-			"source":       "llm",
+		writeJSON(w, AgentStory{
+			Name:        agent.Name,
+			Biography:   cached.Biography,
+			GeneratedAt: cached.GeneratedAt,
+			Source:      "llm",
 		})
 		return
 	}
@@ -750,14 +1197,14 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 
 	// Settlement name.
 	if agent.HomeSettID != nil {
-		if sett, ok := s.Sim.SettlementIndex[*agent.HomeSettID]; ok {
+		if sett, ok := sim.SettlementIndex[*agent.HomeSettID]; ok {
 			ctx.Settlement = sett.Name
 		}
 	}
 
 	// Faction name.
 	if agent.FactionID != nil {
-		for _, f := range s.Sim.Factions {
+		for _, f := range sim.Factions {
 			if uint64(f.ID) == *agent.FactionID {
 				ctx.Faction = f.Name
 				break
@@ -770,7 +1217,7 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 		if i >= 5 {
 			break
 		}
-		if target, ok := s.Sim.AgentIndex[rel.TargetID]; ok {
+		if target, ok := sim.AgentIndex[rel.TargetID]; ok {
 			sentiment := "neutral toward"
 			if rel.Sentiment > 0.5 {
 				sentiment = "close to"
@@ -787,11 +1234,11 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 	// chronicle (no LLM, not cached — it is cheap to rebuild). Only notable
 	// agents (Tier 1+) get an LLM biography, which is cached and persisted.
 	if agent.Tier == agents.Tier0 {
-		writeJSON(w, map[string]any{
-			"name":         agent.Name,
-			"biography":    templateBiography(ctx),
-			"generated_at": engine.SimTime(s.Sim.CurrentTick()),
-			"source":       "template",
+		writeJSON(w, AgentStory{
+			Name:        agent.Name,
+			Biography:   templateBiography(ctx),
+			GeneratedAt: engine.SimTime(sim.CurrentTick()),
+			Source:      "template",
 		})
 		return
 	}
@@ -816,7 +1263,7 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 		return
 	}
 
-	genTime := engine.SimTime(s.Sim.CurrentTick())
+	genTime := engine.SimTime(sim.CurrentTick())
 	s.bioMu.Lock()
 	s.bioCache[agent.ID] = cachedBio{Biography: bio, GeneratedAt: genTime}
 	s.bioMu.Unlock()
@@ -828,11 +1275,11 @@ func (s *Server) handleAgentStory(w http.ResponseWriter, r *http.Request, agent
 		}
 	}
 
-	writeJSON(w, map[string]any{
-		"name":         agent.Name,
-		"biography":    bio,
-		"generated_at": genTime,
-		"source":       "llm",
+	writeJSON(w, AgentStory{
+		Name:        agent.Name,
+		Biography:   bio,
+		GeneratedAt: genTime,
+		Source:      "llm",
 	})
 }
 
@@ -880,15 +1327,51 @@ func templateBiography(ctx llm.BiographyContext) string {
 	return b.String()
 }
 
+// handleEvents serves world events, oldest first within a page, in the list
+// grammar (see list.go), newest page first.
+//
+// With no paging parameters it returns the last ?limit= events the tick loop
+// holds in memory, optionally filtered to those mentioning ?settlement=<name>.
+// With any of cursor, agent, settlement_id, category, from or to it reads the
+// whole history instead — unsaved events, the events table and the archive —
+// filtered by agent ID, settlement ID, category and tick range. Either way,
+// when older events exist the X-Next-Cursor header holds the ?cursor= that
+// returns the page before this one.
 func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
-	limit := 50
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if n, err := strconv.Atoi(l); err == nil && n > 0 && n <= 500 {
-			limit = n
+	sim := s.view()
+	q := r.URL.Query()
+	lq, err := parseList(q, eventList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := lq.limit
+
+	if s.DB != nil && (q.Has("cursor") || q.Has("agent") || q.Has("settlement_id") ||
+		q.Has("category") || q.Has("from") || q.Has("to")) {
+		eq, err := lq.eventQuery()
+		if err == nil && q.Has("agent") {
+			eq.AgentID, err = strconv.ParseUint(q.Get("agent"), 10, 64)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events, next, err := s.DB.QueryEvents(eq, sim.UnsavedEventTail())
+		if err != nil {
+			slog.Error("event history query failed", "error", err)
+			http.Error(w, "event query failed", http.StatusInternalServerError)
+			return
 		}
+		slices.Reverse(events)
+		if next != nil {
+			lq.setNext(w, next.String())
+		}
+		writeJSON(w, append([]engine.Event{}, events...))
+		return
 	}
 
-	events := s.Sim.Events
+	events := sim.Events
 
 	// Optional settlement filter — returns only events mentioning this settlement.
 	if settlementName := r.URL.Query().Get("settlement"); settlementName != "" {
@@ -905,19 +1388,29 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	if len(events) > limit {
 		start = len(events) - limit
 	}
+	// The page continues into the saved history below its oldest event,
+	// unless it is a name-filtered one.
+	if s.DB != nil && start < len(events) && !q.Has("settlement") {
+		next := &persistence.EventCursor{Tick: events[start].Tick}
+		for _, e := range events[start:] {
+			if e.Tick == next.Tick {
+				next.Skip++
+			}
+		}
+		lq.setNext(w, next.String())
+	}
 
 	writeJSON(w, events[start:])
 }
 
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, s.Sim.Stats)
+	sim := s.view()
+	writeJSON(w, sim.Stats)
 }
 
 func (s *Server) handleSpeed(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
-		var req struct {
-			Speed float64 `json:"speed"`
-		}
+		var req Speed
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
@@ -930,7 +1423,7 @@ func (s *Server) handleSpeed(w http.ResponseWriter, r *http.Request) {
 		slog.Info("speed changed", "speed", req.Speed)
 	}
 
-	writeJSON(w, map[string]float64{"speed": s.Eng.Speed()})
+	writeJSON(w, Speed{Speed: s.Eng.Speed()})
 }
 
 func (s *Server) handleNewspaper(w http.ResponseWriter, r *http.Request) {
@@ -948,7 +1441,7 @@ func (s *Server) handleNewspaper(w http.ResponseWriter, r *http.Request) {
 
 	// Thread prior issue forward so the LLM can carry storylines across
 	// editions. Falls back to "" on first-ever generation.
-	paper, err := llm.GenerateNewspaper(s.LLM, data, s.Sim.LastNewspaperContent)
+	paper, err := llm.GenerateNewspaper(s.LLM, data, s.view().LastNewspaperContent)
 	if err != nil {
 		slog.Error("newspaper generation failed", "error", err)
 		// Return stale cache if available.
@@ -962,14 +1455,14 @@ func (s *Server) handleNewspaper(w http.ResponseWriter, r *http.Request) {
 
 	s.cachedPaper = paper
 	s.lastPaperTime = time.Now()
-	s.Sim.LastNewspaperContent = paper.Content
+	s.setLastNewspaper(paper.Content)
 	writeJSON(w, paper)
 }
 
 func (s *Server) handleLLMUsage(w http.ResponseWriter, r *http.Request) {
 	summary := s.LLM.UsageSummary()
 	if summary == nil {
-		writeJSON(w, map[string]string{"status": "llm disabled"})
+		writeJSON(w, LLMDisabled{Status: "llm disabled"})
 		return
 	}
 	writeJSON(w, summary)
@@ -977,9 +1470,10 @@ func (s *Server) handleLLMUsage(w http.ResponseWriter, r *http.Request) {
 
 // handleMetrics returns Prometheus/OpenMetrics-compatible text metrics.
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
 
-	stats := s.Sim.Stats
+	stats := sim.Stats
 	tick := s.Eng.Tick
 
 	var mem runtime.MemStats
@@ -999,7 +1493,7 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Fprintf(w, "# HELP worldsim_settlements Active settlement count.\n")
 	fmt.Fprintf(w, "# TYPE worldsim_settlements gauge\n")
-	fmt.Fprintf(w, "worldsim_settlements %d\n", len(s.Sim.Settlements))
+	fmt.Fprintf(w, "worldsim_settlements %d\n", len(sim.Settlements))
 
 	fmt.Fprintf(w, "# HELP worldsim_total_wealth Total crowns in economy.\n")
 	fmt.Fprintf(w, "# TYPE worldsim_total_wealth gauge\n")
@@ -1061,17 +1555,11 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "worldsim_go_goroutines %d\n", runtime.NumGoroutine())
 
 	// LLM usage if available.
-	if summary := s.LLM.UsageSummary(); summary != nil {
-		if tags, ok := summary["tags"].(map[string]any); ok {
-			fmt.Fprintf(w, "# HELP worldsim_llm_calls_total LLM calls by tag.\n")
-			fmt.Fprintf(w, "# TYPE worldsim_llm_calls_total counter\n")
-			for tag, data := range tags {
-				if m, ok := data.(map[string]any); ok {
-					if calls, ok := m["calls"]; ok {
-						fmt.Fprintf(w, "worldsim_llm_calls_total{tag=%q} %v\n", tag, calls)
-					}
-				}
-			}
+	if summary := s.LLM.UsageSummary(); summary != nil && len(summary.ByTag) > 0 {
+		fmt.Fprintf(w, "# HELP worldsim_llm_calls_total LLM calls by tag.\n")
+		fmt.Fprintf(w, "# TYPE worldsim_llm_calls_total counter\n")
+		for tag, u := range summary.ByTag {
+			fmt.Fprintf(w, "worldsim_llm_calls_total{tag=%q} %d\n", tag, u.Calls)
 		}
 	}
 
@@ -1126,7 +1614,9 @@ func (s *Server) handleSettlementHistory(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, rows)
 }
 
-// handleAgentTimeline returns events involving a specific agent.
+// handleAgentTimeline returns events involving a specific agent, newest
+// first, across the events table and the archive. ?cursor= pages back (see
+// handleEvents); ?category=, ?from= and ?to= filter.
 func (s *Server) handleAgentTimeline(w http.ResponseWriter, r *http.Request) {
 	if s.DB == nil {
 		http.Error(w, "database not available", http.StatusServiceUnavailable)
@@ -1145,14 +1635,19 @@ func (s *Server) handleAgentTimeline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := 50
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 500 {
-			limit = v
-		}
+	lq, err := parseList(r.URL.Query(), timelineList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	eq, err := lq.eventQuery()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	eq.AgentID = id
 
-	events, err := s.DB.LoadAgentTimeline(id, limit)
+	events, next, err := s.DB.QueryEvents(eq, s.view().UnsavedEventTail())
 	if err != nil {
 		slog.Error("agent timeline query failed", "error", err, "agent_id", id)
 		writeJSON(w, []engine.Event{})
@@ -1161,10 +1656,14 @@ func (s *Server) handleAgentTimeline(w http.ResponseWriter, r *http.Request) {
 	if events == nil {
 		events = []engine.Event{}
 	}
+	if next != nil {
+		lq.setNext(w, next.String())
+	}
 	writeJSON(w, events)
 }
 
 func (s *Server) buildNewspaperData() *llm.NewspaperData {
+	sim := s.view()
 	govNames := map[uint8]string{0: "Monarchy", 1: "Council", 2: "Merchant Republic", 3: "Commune"}
 	occNames := []string{
 		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
@@ -1182,19 +1681,19 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 	}
 
 	data := &llm.NewspaperData{
-		SimTime:     engine.SimTime(s.Sim.CurrentTick()),
-		Season:      engine.SeasonName(s.Sim.CurrentSeason),
-		Population:  s.Sim.Stats.TotalPopulation,
-		Settlements: len(s.Sim.Settlements),
-		TotalWealth: s.Sim.Stats.TotalWealth,
-		AvgMood:     s.Sim.Stats.AvgMood,
+		SimTime:     engine.SimTime(sim.CurrentTick()),
+		Season:      engine.SeasonName(sim.CurrentSeason),
+		Population:  sim.Stats.TotalPopulation,
+		Settlements: len(sim.Settlements),
+		TotalWealth: sim.Stats.TotalWealth,
+		AvgMood:     sim.Stats.AvgMood,
 	}
 
 	// Weather.
-	data.Weather = s.Sim.CurrentWeather.Description
+	data.Weather = sim.CurrentWeather.Description
 
 	// Collect recent events by category.
-	for _, e := range s.Sim.Events {
+	for _, e := range sim.Events {
 		switch e.Category {
 		case "death":
 			data.Deaths = append(data.Deaths, e.Description)
@@ -1212,8 +1711,8 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 	}
 
 	// Top 5 settlements by population (sort first).
-	sorted := make([]*social.Settlement, len(s.Sim.Settlements))
-	copy(sorted, s.Sim.Settlements)
+	sorted := make([]*social.Settlement, len(sim.Settlements))
+	copy(sorted, sim.Settlements)
 	sort.Slice(sorted, func(i, j int) bool {
 		return sorted[i].Population > sorted[j].Population
 	})
@@ -1238,7 +1737,7 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 		ratio      float64
 	}
 	var allPrices []priceEntry
-	for _, st := range s.Sim.Settlements {
+	for _, st := range sim.Settlements {
 		if st.Market == nil {
 			continue
 		}
@@ -1276,13 +1775,13 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 	}
 
 	// Faction news.
-	for _, f := range s.Sim.Factions {
+	for _, f := range sim.Factions {
 		// Find top settlement by influence.
 		var topSett string
 		var topInf float64
 		for settID, inf := range f.Influence {
 			if inf > topInf {
-				if sett, ok := s.Sim.SettlementIndex[settID]; ok {
+				if sett, ok := sim.SettlementIndex[settID]; ok {
 					topSett = sett.Name
 					topInf = inf
 				}
@@ -1300,14 +1799,14 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 		// Note any strong inter-faction tensions or alliances.
 		for otherID, rel := range f.Relations {
 			if rel > 50 {
-				for _, other := range s.Sim.Factions {
+				for _, other := range sim.Factions {
 					if other.ID == otherID {
 						line += fmt.Sprintf("; allied with %s", other.Name)
 						break
 					}
 				}
 			} else if rel < -50 {
-				for _, other := range s.Sim.Factions {
+				for _, other := range sim.Factions {
 					if other.ID == otherID {
 						line += fmt.Sprintf("; hostile toward %s", other.Name)
 						break
@@ -1322,7 +1821,7 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 	// Wheeler coherence — count states and compute average.
 	var totalCoherence float32
 	aliveCount := 0
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if !a.Alive {
 			continue
 		}
@@ -1341,8 +1840,33 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 		data.AvgCoherence = totalCoherence / float32(aliveCount)
 	}
 
+	// Dynasties, from the family tree.
+	if s.DB != nil {
+		stats, err := persistence.Dynasties(s.DB, sim.UnsavedLineage, livingIn(sim), sim.CurrentTick(), 3)
+		if err != nil {
+			slog.Warn("dynasty stats failed", "error", err)
+		} else {
+			summarize := func(list []persistence.Dynasty) []llm.DynastySummary {
+				var out []llm.DynastySummary
+				for _, d := range list {
+					out = append(out, llm.DynastySummary{
+						Founder:     d.Founder.Name,
+						Living:      d.Living,
+						Members:     d.Members,
+						Generations: d.Generations,
+						Years:       float64(d.Span()) / float64(4*engine.TicksPerSimSeason),
+						Extinct:     d.Living == 0,
+					})
+				}
+				return out
+			}
+			data.LargestFamilies = summarize(stats.Largest)
+			data.OldestLineages = summarize(stats.Longest)
+		}
+	}
+
 	// Notable agents (Tier 2) with Wheeler descriptions.
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if a.Tier >= agents.Tier2 && a.Alive {
 			occName := "Unknown"
 			if int(a.Occupation) < len(occNames) {
@@ -1366,23 +1890,24 @@ func (s *Server) buildNewspaperData() *llm.NewspaperData {
 	return data
 }
 
+// handleFactions lists factions in the list grammar (see list.go):
+// ?settlement_id= keeps those with influence there, and the wealth range
+// bounds the treasury.
 func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
-	if s.Sim.Factions == nil {
-		writeJSON(w, []any{})
+	lq, err := parseList(r.URL.Query(), factionList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	type factionSummary struct {
-		ID        uint64             `json:"id"`
-		Name      string             `json:"name"`
-		Members   int                `json:"members"`
-		Treasury  uint64             `json:"treasury"`
-		Influence map[string]float64 `json:"top_influence"` // settlement name → influence
+	sim := s.view()
+	if sim.Factions == nil {
+		writeJSON(w, []any{})
+		return
 	}
 
-	// ?limit=N controls how many top-influence settlements per faction (default 5).
+	// ?top=N controls how many top-influence settlements per faction (default 5).
 	limit := 5
-	if lStr := r.URL.Query().Get("limit"); lStr != "" {
+	if lStr := r.URL.Query().Get("top"); lStr != "" {
 		if l, err := strconv.Atoi(lStr); err == nil && l >= 0 {
 			limit = l
 		}
@@ -1390,14 +1915,17 @@ func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
 
 	// Count members per faction.
 	memberCount := make(map[uint64]int)
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if a.Alive && a.FactionID != nil {
 			memberCount[*a.FactionID]++
 		}
 	}
 
-	var result []factionSummary
-	for _, f := range s.Sim.Factions {
+	var result []worldclient.FactionSummary
+	for _, f := range sim.Factions {
+		if lq.settlementID != 0 && f.Influence[lq.settlementID] <= 0 || !lq.wealth.Has(float64(f.Treasury)) {
+			continue
+		}
 		// Collect all settlement influences, then keep only top N.
 		type settInf struct {
 			name string
@@ -1405,7 +1933,7 @@ func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
 		}
 		var all []settInf
 		for settID, inf := range f.Influence {
-			if sett, ok := s.Sim.SettlementIndex[settID]; ok {
+			if sett, ok := sim.SettlementIndex[settID]; ok {
 				all = append(all, settInf{sett.Name, inf})
 			}
 		}
@@ -1418,7 +1946,7 @@ func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
 			topInf[si.name] = si.inf
 		}
 
-		result = append(result, factionSummary{
+		result = append(result, worldclient.FactionSummary{
 			ID:        uint64(f.ID),
 			Name:      f.Name,
 			Members:   memberCount[uint64(f.ID)],
@@ -1426,10 +1954,16 @@ func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
 			Influence: topInf,
 		})
 	}
+	result, err = pageRows(w, lq, result, func(f worldclient.FactionSummary) uint64 { return f.ID })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	writeJSON(w, result)
 }
 
 func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	// Total crowns: agent wealth + settlement treasuries.
 	totalAgentWealth := uint64(0)
 	totalTreasury := uint64(0)
@@ -1437,14 +1971,14 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 
 	// Collect all agent wealths for distribution calculation.
 	var wealths []uint64
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if a.Alive {
 			aliveCount++
 			totalAgentWealth += a.Wealth
 			wealths = append(wealths, a.Wealth)
 		}
 	}
-	for _, st := range s.Sim.Settlements {
+	for _, st := range sim.Settlements {
 		totalTreasury += st.Treasury
 	}
 
@@ -1478,19 +2012,11 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 		agents.GoodClothing: "Clothing", agents.GoodMedicine: "Medicine", agents.GoodLuxuries: "Luxuries",
 	}
 
-	type priceDeviation struct {
-		Good       string  `json:"good"`
-		Settlement string  `json:"settlement"`
-		Price      float64 `json:"price"`
-		BasePrice  float64 `json:"base_price"`
-		Ratio      float64 `json:"ratio"`
-	}
-
-	var inflated, deflated []priceDeviation
+	var inflated, deflated []worldclient.PriceDeviation
 	totalHealth := 0.0
 	marketCount := 0
 
-	for _, st := range s.Sim.Settlements {
+	for _, st := range sim.Settlements {
 		if st.Market == nil {
 			continue
 		}
@@ -1510,7 +2036,7 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 				gn = fmt.Sprintf("Good#%d", goodType)
 			}
 
-			pd := priceDeviation{
+			pd := worldclient.PriceDeviation{
 				Good:       gn,
 				Settlement: st.Name,
 				Price:      entry.Price,
@@ -1549,36 +2075,26 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 		deflated = deflated[:5]
 	}
 
-	producerTotal := s.Sim.Stats.ProducersWorking + s.Sim.Stats.ProducersIdle
+	producerTotal := sim.Stats.ProducersWorking + sim.Stats.ProducersIdle
 	workRate := 0.0
 	if producerTotal > 0 {
-		workRate = float64(s.Sim.Stats.ProducersWorking) / float64(producerTotal)
+		workRate = float64(sim.Stats.ProducersWorking) / float64(producerTotal)
 	}
 
 	// Collect all established trade routes.
-	type routeSummary struct {
-		Name        string  `json:"name"`
-		Level       uint8   `json:"level"`
-		LevelName   string  `json:"level_name"`
-		WeeklyTrade float64 `json:"weekly_trade"`
-		SettAID     uint64  `json:"settlement_a_id"`
-		SettAName   string  `json:"settlement_a_name"`
-		SettBID     uint64  `json:"settlement_b_id"`
-		SettBName   string  `json:"settlement_b_name"`
-	}
-	var routes []routeSummary
-	for key, route := range s.Sim.TradeRoutes {
+	var routes []worldclient.TradeRoute
+	for key, route := range sim.TradeRoutes {
 		if route.Level == 0 {
 			continue
 		}
 		nameA, nameB := "Unknown", "Unknown"
-		if sett, ok := s.Sim.SettlementIndex[key.A]; ok {
+		if sett, ok := sim.SettlementIndex[key.A]; ok {
 			nameA = sett.Name
 		}
-		if sett, ok := s.Sim.SettlementIndex[key.B]; ok {
+		if sett, ok := sim.SettlementIndex[key.B]; ok {
 			nameB = sett.Name
 		}
-		routes = append(routes, routeSummary{
+		routes = append(routes, worldclient.TradeRoute{
 			Name:        route.Name,
 			Level:       route.Level,
 			LevelName:   engine.RouteLevelName(route.Level),
@@ -1590,27 +2106,27 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	result := map[string]any{
-		"total_crowns":      totalAgentWealth + totalTreasury,
-		"agent_wealth":      totalAgentWealth,
-		"treasury_wealth":   totalTreasury,
-		"avg_market_health": avgMarketHealth,
-		"trade_volume":      s.Sim.Stats.TradeVolume,
-		"most_inflated":     inflated,
-		"most_deflated":     deflated,
-		"wealth_distribution": map[string]any{
-			"poorest_50_pct_share": poorest50Share,
-			"richest_10_pct_share": richest10Share,
+	result := worldclient.Economy{
+		TotalCrowns:     totalAgentWealth + totalTreasury,
+		AgentWealth:     totalAgentWealth,
+		TreasuryWealth:  totalTreasury,
+		AvgMarketHealth: avgMarketHealth,
+		TradeVolume:     sim.Stats.TradeVolume,
+		MostInflated:    inflated,
+		MostDeflated:    deflated,
+		WealthDistribution: worldclient.WealthDistribution{
+			Poorest50PctShare: poorest50Share,
+			Richest10PctShare: richest10Share,
 		},
-		"producer_health": map[string]any{
-			"total":     producerTotal,
-			"working":   s.Sim.Stats.ProducersWorking,
-			"idle":      s.Sim.Stats.ProducersIdle,
-			"work_rate": workRate,
+		ProducerHealth: worldclient.ProducerHealth{
+			Total:    producerTotal,
+			Working:  sim.Stats.ProducersWorking,
+			Idle:     sim.Stats.ProducersIdle,
+			WorkRate: workRate,
 		},
-		"trade_routes": map[string]any{
-			"count":  len(routes),
-			"routes": routes,
+		TradeRoutes: worldclient.TradeRoutes{
+			Count:  len(routes),
+			Routes: routes,
 		},
 	}
 
@@ -1618,23 +2134,17 @@ func (s *Server) handleEconomy(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	// Faction summaries.
-	type factionInfo struct {
-		Name           string             `json:"name"`
-		Treasury       uint64             `json:"treasury"`
-		TotalMembers   int                `json:"total_members"`
-		TopSettlements map[string]float64 `json:"top_settlements"`
-	}
-
 	factionMembers := make(map[uint64]int)
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if a.Alive && a.FactionID != nil {
 			factionMembers[*a.FactionID]++
 		}
 	}
 
-	var factions []factionInfo
-	for _, f := range s.Sim.Factions {
+	var factions []worldclient.SocialFaction
+	for _, f := range sim.Factions {
 		topSetts := make(map[string]float64)
 		// Get top 3 settlements by influence.
 		type infEntry struct {
@@ -1643,7 +2153,7 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 		}
 		var entries []infEntry
 		for settID, inf := range f.Influence {
-			if sett, ok := s.Sim.SettlementIndex[settID]; ok {
+			if sett, ok := sim.SettlementIndex[settID]; ok {
 				entries = append(entries, infEntry{sett.Name, inf})
 			}
 		}
@@ -1655,7 +2165,7 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 			topSetts[e.name] = e.inf
 		}
 
-		factions = append(factions, factionInfo{
+		factions = append(factions, worldclient.SocialFaction{
 			Name:           f.Name,
 			Treasury:       f.Treasury,
 			TotalMembers:   factionMembers[uint64(f.ID)],
@@ -1666,15 +2176,15 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 	// Governance health.
 	totalGovScore := 0.0
 	var atRiskSettlements []string
-	for _, st := range s.Sim.Settlements {
+	for _, st := range sim.Settlements {
 		totalGovScore += st.GovernanceScore
 		if st.GovernanceScore < 0.3 {
 			atRiskSettlements = append(atRiskSettlements, st.Name)
 		}
 	}
 	avgGovScore := 0.0
-	if len(s.Sim.Settlements) > 0 {
-		avgGovScore = totalGovScore / float64(len(s.Sim.Settlements))
+	if len(sim.Settlements) > 0 {
+		avgGovScore = totalGovScore / float64(len(sim.Settlements))
 	}
 
 	// Relationship stats.
@@ -1682,7 +2192,7 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 	relCount := 0
 	families := 0
 	rivalries := 0
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if !a.Alive {
 			continue
 		}
@@ -1707,7 +2217,7 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 	// Tier distribution.
 	tier0, tier1, tier2 := 0, 0, 0
 	embodied, centered, liberated := 0, 0, 0
-	for _, a := range s.Sim.Agents {
+	for _, a := range sim.Agents {
 		if !a.Alive {
 			continue
 		}
@@ -1730,10 +2240,10 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Recent political events.
-	var politicalEvents []engine.Event
-	for _, e := range s.Sim.Events {
+	var politicalEvents []worldclient.Event
+	for _, e := range sim.Events {
 		if e.Category == "political" || e.Category == "warfare" {
-			politicalEvents = append(politicalEvents, e)
+			politicalEvents = append(politicalEvents, worldclient.Event(e))
 		}
 	}
 	// Keep only last 20.
@@ -1741,29 +2251,29 @@ func (s *Server) handleSocial(w http.ResponseWriter, r *http.Request) {
 		politicalEvents = politicalEvents[len(politicalEvents)-20:]
 	}
 
-	result := map[string]any{
-		"factions": factions,
-		"governance": map[string]any{
-			"avg_score":           avgGovScore,
-			"at_risk_settlements": atRiskSettlements,
+	result := worldclient.Social{
+		Factions: factions,
+		Governance: worldclient.GovernanceHealth{
+			AvgScore:          avgGovScore,
+			AtRiskSettlements: atRiskSettlements,
 		},
-		"relationships": map[string]any{
-			"avg_sentiment": avgSentiment,
-			"families":      families,
-			"rivalries":     rivalries,
+		Relationships: worldclient.Relationships{
+			AvgSentiment: avgSentiment,
+			Families:     families,
+			Rivalries:    rivalries,
 		},
-		"tier_distribution": map[string]int{
+		TierDistribution: map[string]int{
 			"tier_0": tier0,
 			"tier_1": tier1,
 			"tier_2": tier2,
 		},
-		"coherence_distribution": map[string]int{
+		CoherenceDistribution: map[string]int{
 			"embodied":  embodied,
 			"centered":  centered,
 			"liberated": liberated,
 		},
-		"recent_political_events": politicalEvents,
-		"diplomacy":               s.Sim.DiplomacySummary(),
+		RecentPoliticalEvents: politicalEvents,
+		Diplomacy:             sim.DiplomacySummary(),
 	}
 
 	writeJSON(w, result)
@@ -1799,16 +2309,18 @@ func (s *Server) handleStatsHistory(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		slog.Error("stats history query failed", "error", err)
 		// Return empty array instead of error — table may not have data yet.
-		writeJSON(w, []persistence.StatsRow{})
+		writeJSON(w, []worldclient.StatsRow{})
 		return
 	}
-	if rows == nil {
-		rows = []persistence.StatsRow{}
+	out := make([]worldclient.StatsRow, len(rows))
+	for i, row := range rows {
+		out[i] = worldclient.StatsRow(row)
 	}
-	writeJSON(w, rows)
+	writeJSON(w, out)
 }
 
 func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
 		http.Error(w, "missing settlement id", http.StatusBadRequest)
@@ -1820,9 +2332,25 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	sett, ok := s.Sim.SettlementIndex[id]
+	if len(parts) >= 6 && parts[5] == "prices" {
+		s.handleSettlementPrices(w, r, id)
+		return
+	}
+
+	sett, ok := sim.SettlementIndex[id]
 	if !ok {
-		http.Error(w, "settlement not found", http.StatusNotFound)
+		// Abandoned settlements resolve to their archive entry.
+		a, err := s.findArchive(sim, id)
+		if err != nil {
+			slog.Error("settlement archive lookup failed", "error", err, "settlement_id", id)
+			http.Error(w, "settlement archive lookup failed", http.StatusInternalServerError)
+			return
+		}
+		if a == nil {
+			http.Error(w, "settlement not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, a)
 		return
 	}
 
@@ -1837,20 +2365,14 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		agents.GoodClothing: "Clothing", agents.GoodMedicine: "Medicine", agents.GoodLuxuries: "Luxuries",
 	}
 
-	type marketEntry struct {
-		Good   string  `json:"good"`
-		Price  float64 `json:"price"`
-		Supply float64 `json:"supply"`
-		Demand float64 `json:"demand"`
-	}
-	var market []marketEntry
+	var market []MarketEntry
 	if sett.Market != nil {
 		for goodType, entry := range sett.Market.Entries {
 			gn := goodNames[goodType]
 			if gn == "" {
 				gn = fmt.Sprintf("Good#%d", goodType)
 			}
-			market = append(market, marketEntry{
+			market = append(market, MarketEntry{
 				Good:   gn,
 				Price:  entry.Price,
 				Supply: entry.Supply,
@@ -1864,7 +2386,7 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		"Farmer", "Miner", "Crafter", "Merchant", "Soldier",
 		"Scholar", "Alchemist", "Laborer", "Fisher", "Hunter",
 	}
-	settAgents := s.Sim.SettlementAgents[id]
+	settAgents := sim.SettlementAgents[id]
 	occupations := make(map[string]int)
 	var totalMood, totalSat, totalAlign float64
 	aliveCount := 0
@@ -1925,24 +2447,13 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Per-occupation mood averages.
-	type occMood struct {
-		Satisfaction float64 `json:"satisfaction"`
-		Count        int     `json:"count"`
-	}
-	moodByOcc := make(map[string]occMood)
+	moodByOcc := make(map[string]OccupationMood)
 	for name, os := range occSatMap {
 		avg := 0.0
 		if os.count > 0 {
 			avg = os.totalSat / float64(os.count)
 		}
-		moodByOcc[name] = occMood{Satisfaction: avg, Count: os.count}
-	}
-
-	// Needs summary.
-	needsSummary := map[string]int{
-		"survival_low":  survivalLow,
-		"belonging_low": belongingLow,
-		"purpose_low":   purposeLow,
+		moodByOcc[name] = OccupationMood{Satisfaction: avg, Count: os.count}
 	}
 
 	// Terrain lookup.
@@ -1951,7 +2462,7 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		"Desert", "Swamp", "Tundra", "Ocean",
 	}
 	terrain := "Unknown"
-	if hex := s.Sim.WorldMap.Get(sett.Position); hex != nil {
+	if hex := sim.WorldMap.Get(sett.Position); hex != nil {
 		if int(hex.Terrain) < len(terrainNames) {
 			terrain = terrainNames[hex.Terrain]
 		}
@@ -1980,19 +2491,8 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Top 5 agents by wealth.
-	type agentBrief struct {
-		ID           agents.AgentID `json:"id"`
-		Name         string         `json:"name"`
-		Occupation   string         `json:"occupation"`
-		Tier         int            `json:"tier"`
-		Wealth       uint64         `json:"wealth"`
-		EffMood      float32        `json:"effective_mood"`
-		Satisfaction float32        `json:"satisfaction"`
-		Alignment    float32        `json:"alignment"`
-		Coherence    float32        `json:"coherence"`
-	}
 	sort.Slice(aliveAgents, func(i, j int) bool { return aliveAgents[i].Wealth > aliveAgents[j].Wealth })
-	var topAgents []agentBrief
+	var topAgents []SettlementAgent
 	for i, a := range aliveAgents {
 		if i >= 5 {
 			break
@@ -2001,7 +2501,7 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		if int(a.Occupation) < len(occNames) {
 			occName = occNames[a.Occupation]
 		}
-		topAgents = append(topAgents, agentBrief{
+		topAgents = append(topAgents, SettlementAgent{
 			ID:           a.ID,
 			Name:         a.Name,
 			Occupation:   occName,
@@ -2018,7 +2518,7 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 	factionCounts := make(map[string]int)
 	for _, a := range settAgents {
 		if a.Alive && a.FactionID != nil {
-			for _, f := range s.Sim.Factions {
+			for _, f := range sim.Factions {
 				if uint64(f.ID) == *a.FactionID {
 					factionCounts[f.Name]++
 					break
@@ -2028,11 +2528,11 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Carrying capacity from hex health.
-	carryingCapacity, populationPressure := s.Sim.SettlementCarryingCapacity(id)
+	carryingCapacity, populationPressure := sim.SettlementCarryingCapacity(id)
 
 	// Recent events mentioning this settlement.
 	var recentEvents []engine.Event
-	for _, e := range s.Sim.Events {
+	for _, e := range sim.Events {
 		if strings.Contains(e.Description, sett.Name) {
 			recentEvents = append(recentEvents, e)
 		}
@@ -2042,19 +2542,13 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Inter-settlement relations.
-	type relationSummary struct {
-		SettlementID   uint64  `json:"settlement_id"`
-		SettlementName string  `json:"settlement_name"`
-		Sentiment      float64 `json:"sentiment"`
-		Trade          float64 `json:"trade"`
-	}
-	var relations []relationSummary
-	for otherID, rel := range s.Sim.GetSettlementRelations(id) {
+	var relations []SettlementRelation
+	for otherID, rel := range sim.GetSettlementRelations(id) {
 		name := "Unknown"
-		if other, ok := s.Sim.SettlementIndex[otherID]; ok {
+		if other, ok := sim.SettlementIndex[otherID]; ok {
 			name = other.Name
 		}
-		relations = append(relations, relationSummary{
+		relations = append(relations, SettlementRelation{
 			SettlementID:   otherID,
 			SettlementName: name,
 			Sentiment:      rel.Sentiment,
@@ -2062,53 +2556,58 @@ func (s *Server) handleSettlementDetail(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
-	result := map[string]any{
-		"id":               sett.ID,
-		"name":             sett.Name,
-		"q":                sett.Position.Q,
-		"r":                sett.Position.R,
-		"population":       sett.Population,
-		"governance":       govNames[uint8(sett.Governance)],
-		"treasury":         sett.Treasury,
-		"health":           sett.Health(),
-		"governance_score": sett.GovernanceScore,
-		"tax_rate":         sett.TaxRate,
-		"culture": map[string]any{
-			"tradition":  sett.CultureTradition,
-			"openness":   sett.CultureOpenness,
-			"militarism": sett.CultureMilitarism,
-			"memory":     sett.CulturalMemory,
+	result := SettlementDetail{
+		ID:              sett.ID,
+		Name:            sett.Name,
+		Q:               sett.Position.Q,
+		R:               sett.Position.R,
+		Population:      sett.Population,
+		Governance:      govNames[uint8(sett.Governance)],
+		Treasury:        sett.Treasury,
+		Health:          sett.Health(),
+		GovernanceScore: sett.GovernanceScore,
+		TaxRate:         sett.TaxRate,
+		Culture: SettlementCulture{
+			Tradition:  sett.CultureTradition,
+			Openness:   sett.CultureOpenness,
+			Militarism: sett.CultureMilitarism,
+			Memory:     sett.CulturalMemory,
+		},
+		Infrastructure: SettlementInfrastructure{
+			WallLevel:   sett.WallLevel,
+			RoadLevel:   sett.RoadLevel,
+			MarketLevel: sett.MarketLevel,
 		},
-		"infrastructure": map[string]any{
-			"wall_level":   sett.WallLevel,
-			"road_level":   sett.RoadLevel,
-			"market_level": sett.MarketLevel,
+		Relations:          relations,
+		TradeRoutes:        sim.GetSettlementRoutes(sett.ID),
+		Agreements:         sim.GetSettlementAgreements(sett.ID),
+		PeaceTreaties:      sim.GetSettlementPeace(sett.ID),
+		Occupations:        occupations,
+		AvgMood:            avgMood,
+		AvgSatisfaction:    avgSat,
+		AvgAlignment:       avgAlign,
+		RecentTradeVolume:  recentTradeVolume,
+		MostTradedGood:     mostTradedGood,
+		Market:             market,
+		TopAgents:          topAgents,
+		FactionPresence:    factionCounts,
+		CarryingCapacity:   carryingCapacity,
+		PopulationPressure: populationPressure,
+		RecentEvents:       recentEvents,
+		Terrain:            terrain,
+		MoodByOccupation:   moodByOcc,
+		NeedsSummary: NeedsSummary{
+			SurvivalLow:  survivalLow,
+			BelongingLow: belongingLow,
+			PurposeLow:   purposeLow,
 		},
-		"relations":           relations,
-		"trade_routes":        s.Sim.GetSettlementRoutes(sett.ID),
-		"agreements":          s.Sim.GetSettlementAgreements(sett.ID),
-		"peace_treaties":      s.Sim.GetSettlementPeace(sett.ID),
-		"occupations":         occupations,
-		"avg_mood":            avgMood,
-		"avg_satisfaction":    avgSat,
-		"avg_alignment":       avgAlign,
-		"recent_trade_volume": recentTradeVolume,
-		"most_traded_good":    mostTradedGood,
-		"market":              market,
-		"top_agents":          topAgents,
-		"faction_presence":    factionCounts,
-		"carrying_capacity":   carryingCapacity,
-		"population_pressure": populationPressure,
-		"recent_events":       recentEvents,
-		"terrain":             terrain,
-		"mood_by_occupation":  moodByOcc,
-		"needs_summary":       needsSummary,
-		"wealth_median":       wealthMedian,
+		WealthMedian: wealthMedian,
 	}
 	writeJSON(w, result)
 }
 
 func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
 		http.Error(w, "missing faction id", http.StatusBadRequest)
@@ -2121,7 +2620,7 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var faction *social.Faction
-	for _, f := range s.Sim.Factions {
+	for _, f := range sim.Factions {
 		if uint64(f.ID) == id {
 			faction = f
 			break
@@ -2138,20 +2637,14 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Member list.
-	type memberInfo struct {
-		ID         agents.AgentID `json:"id"`
-		Name       string         `json:"name"`
-		Tier       int            `json:"tier"`
-		Occupation string         `json:"occupation"`
-	}
-	var members []memberInfo
-	for _, a := range s.Sim.Agents {
+	var members []FactionMember
+	for _, a := range sim.Agents {
 		if a.Alive && a.FactionID != nil && *a.FactionID == id {
 			occName := "Unknown"
 			if int(a.Occupation) < len(occNames) {
 				occName = occNames[a.Occupation]
 			}
-			members = append(members, memberInfo{
+			members = append(members, FactionMember{
 				ID:         a.ID,
 				Name:       a.Name,
 				Tier:       int(a.Tier),
@@ -2161,14 +2654,10 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Top influence settlements.
-	type infEntry struct {
-		Name      string  `json:"name"`
-		Influence float64 `json:"influence"`
-	}
-	var topInfluence []infEntry
+	var topInfluence []FactionInfluence
 	for settID, inf := range faction.Influence {
-		if sett, ok := s.Sim.SettlementIndex[settID]; ok {
-			topInfluence = append(topInfluence, infEntry{Name: sett.Name, Influence: inf})
+		if sett, ok := sim.SettlementIndex[settID]; ok {
+			topInfluence = append(topInfluence, FactionInfluence{Name: sett.Name, Influence: inf})
 		}
 	}
 	sort.Slice(topInfluence, func(i, j int) bool { return topInfluence[i].Influence > topInfluence[j].Influence })
@@ -2177,15 +2666,11 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Relations.
-	type relEntry struct {
-		Name     string  `json:"name"`
-		Relation float64 `json:"relation"`
-	}
-	var relations []relEntry
+	var relations []FactionRelation
 	for otherID, rel := range faction.Relations {
-		for _, other := range s.Sim.Factions {
+		for _, other := range sim.Factions {
 			if other.ID == otherID {
-				relations = append(relations, relEntry{Name: other.Name, Relation: rel})
+				relations = append(relations, FactionRelation{Name: other.Name, Relation: rel})
 				break
 			}
 		}
@@ -2193,7 +2678,7 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 
 	// Recent faction events — match by structured Meta fields first, then description fallback.
 	var recentEvents []engine.Event
-	for _, e := range s.Sim.Events {
+	for _, e := range sim.Events {
 		matched := strings.Contains(e.Description, faction.Name)
 		if !matched && e.Meta != nil {
 			for _, key := range []string{"faction_name", "faction_1", "faction_2"} {
@@ -2213,25 +2698,26 @@ func (s *Server) handleFactionDetail(w http.ResponseWriter, r *http.Request) {
 		recentEvents = recentEvents[len(recentEvents)-20:]
 	}
 
-	result := map[string]any{
-		"id":            faction.ID,
-		"name":          faction.Name,
-		"treasury":      faction.Treasury,
-		"members":       members,
-		"member_count":  len(members),
-		"top_influence": topInfluence,
-		"relations":     relations,
-		"policies": map[string]any{
-			"tax_preference":      faction.TaxPreference,
-			"trade_preference":    faction.TradePreference,
-			"military_preference": faction.MilitaryPreference,
+	result := FactionDetail{
+		ID:           faction.ID,
+		Name:         faction.Name,
+		Treasury:     faction.Treasury,
+		Members:      members,
+		MemberCount:  len(members),
+		TopInfluence: topInfluence,
+		Relations:    relations,
+		Policies: FactionPolicies{
+			TaxPreference:      faction.TaxPreference,
+			TradePreference:    faction.TradePreference,
+			MilitaryPreference: faction.MilitaryPreference,
 		},
-		"recent_events": recentEvents,
+		RecentEvents: recentEvents,
 	}
 	writeJSON(w, result)
 }
 
 func (s *Server) handleHexDetail(w http.ResponseWriter, r *http.Request) {
+	sim := s.view()
 	parts := strings.Split(r.URL.Path, "/")
 	// /api/v1/map/:q/:r → parts[0]="" [1]="api" [2]="v1" [3]="map" [4]=q [5]=r
 	if len(parts) < 6 {
@@ -2246,7 +2732,7 @@ func (s *Server) handleHexDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	coord := world.HexCoord{Q: q, R: rr}
-	hex := s.Sim.WorldMap.Get(coord)
+	hex := sim.WorldMap.Get(coord)
 	if hex == nil {
 		http.Error(w, "hex not found", http.StatusNotFound)
 		return
@@ -2279,43 +2765,29 @@ func (s *Server) handleHexDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Settlement on hex.
-	var settlement *map[string]any
+	var settlement *HexSettlement
 	if hex.SettlementID != nil {
-		if sett, ok := s.Sim.SettlementIndex[*hex.SettlementID]; ok {
-			m := map[string]any{
-				"id":         sett.ID,
-				"name":       sett.Name,
-				"population": sett.Population,
-			}
-			settlement = &m
+		if sett, ok := sim.SettlementIndex[*hex.SettlementID]; ok {
+			settlement = &HexSettlement{ID: sett.ID, Name: sett.Name, Population: sett.Population}
 		}
 	}
 
 	// Agents on hex.
 	var agentCount int
-	type agentBrief struct {
-		ID   agents.AgentID `json:"id"`
-		Name string         `json:"name"`
-	}
-	var agentsOnHex []agentBrief
-	for _, a := range s.Sim.Agents {
+	var agentsOnHex []HexAgent
+	for _, a := range sim.Agents {
 		if a.Alive && a.Position.Q == q && a.Position.R == rr {
 			agentCount++
 			if len(agentsOnHex) < 20 {
-				agentsOnHex = append(agentsOnHex, agentBrief{ID: a.ID, Name: a.Name})
+				agentsOnHex = append(agentsOnHex, HexAgent{ID: a.ID, Name: a.Name})
 			}
 		}
 	}
 
 	// Neighbors.
-	type neighborInfo struct {
-		Q       int    `json:"q"`
-		R       int    `json:"r"`
-		Terrain string `json:"terrain"`
-	}
-	var neighbors []neighborInfo
+	var neighbors []HexNeighbor
 	for _, nc := range coord.Neighbors() {
-		nh := s.Sim.WorldMap.Get(nc)
+		nh := sim.WorldMap.Get(nc)
 		if nh == nil {
 			continue
 		}
@@ -2323,26 +2795,26 @@ func (s *Server) handleHexDetail(w http.ResponseWriter, r *http.Request) {
 		if int(nh.Terrain) < len(terrainNames) {
 			tn = terrainNames[nh.Terrain]
 		}
-		neighbors = append(neighbors, neighborInfo{Q: nc.Q, R: nc.R, Terrain: tn})
-	}
-
-	result := map[string]any{
-		"q":                   q,
-		"r":                   rr,
-		"terrain":             terrainName,
-		"elevation":           hex.Elevation,
-		"rainfall":            hex.Rainfall,
-		"temperature":         hex.Temperature,
-		"health":              hex.Health,
-		"last_extracted_tick": hex.LastExtractedTick,
-		"irrigation_level":    hex.IrrigationLevel,
-		"conservation_level":  hex.ConservationLevel,
-		"claimed_by":          hex.ClaimedBy,
-		"resources":           resources,
-		"settlement":          settlement,
-		"agent_count":         agentCount,
-		"agents":              agentsOnHex,
-		"neighbors":           neighbors,
+		neighbors = append(neighbors, HexNeighbor{Q: nc.Q, R: nc.R, Terrain: tn})
+	}
+
+	result := HexDetail{
+		Q:                 q,
+		R:                 rr,
+		Terrain:           terrainName,
+		Elevation:         hex.Elevation,
+		Rainfall:          hex.Rainfall,
+		Temperature:       hex.Temperature,
+		Health:            hex.Health,
+		LastExtractedTick: hex.LastExtractedTick,
+		IrrigationLevel:   hex.IrrigationLevel,
+		ConservationLevel: hex.ConservationLevel,
+		ClaimedBy:         hex.ClaimedBy,
+		Resources:         resources,
+		Settlement:        settlement,
+		AgentCount:        agentCount,
+		Agents:            agentsOnHex,
+		Neighbors:         neighbors,
 	}
 	writeJSON(w, result)
 }
@@ -2357,6 +2829,17 @@ func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// An empty body is the plain save below; a label asks for a named snapshot.
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Label != "" {
+		s.namedSnapshot(w, req.Label)
+		return
+	}
+
 	// Run the save inside the tick-loop goroutine (W-21 fix). Saving from this
 	// HTTP goroutine raced the running tick loop: SaveAgents iterates sim.Agents
 	// while the loop concurrently mutates it, producing a transient duplicate id
@@ -2370,9 +2853,14 @@ func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 	// froze the live sim when first deployed. The fast save (~20s) persists all
 	// critical state (agents, settlements, factions, hex, and the registry:
 	// relations/agreements/trade routes/peace/etc.) — the same set the daily
-	// save writes. Memories and relationships are still captured by the graceful
-	// shutdown save (SaveWorldStateFull).
+	// save writes. Memories and relationships are captured by the hourly
+	// incremental save (SaveSocial) and the graceful shutdown save.
+	// With checkpoints on, SaveWorld writes the checkpoint instead (well under
+	// a second for 100K agents) and the database gets only history.
 	save := func() error { return s.DB.SaveWorldState(s.Sim) }
+	if s.SaveWorld != nil {
+		save = s.SaveWorld
+	}
 
 	if s.Eng == nil {
 		// No engine wired (e.g. tests) — fall back to a direct save.
@@ -2402,183 +2890,393 @@ func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, map[string]any{
-		"tick":    s.Sim.CurrentTick(),
-		"message": "snapshot saved",
-	})
+	writeJSON(w, SnapshotSaved{Tick: s.view().CurrentTick(), Message: "snapshot saved"})
+}
+
+// namedSnapshot writes a named snapshot file (see persistence.CaptureSnapshot).
+// Only the capture holds the tick loop — the same fast save as above plus a
+// VACUUM INTO copy. Memories and relationships are written into the copy from
+// detached agent clones after the loop has moved on, so the full-save stall
+// does not come back.
+func (s *Server) namedSnapshot(w http.ResponseWriter, label string) {
+	if s.SnapshotDir == "" {
+		http.Error(w, "snapshots not configured", http.StatusServiceUnavailable)
+		return
+	}
+	db, ok := s.DB.(*persistence.DB)
+	if !ok {
+		http.Error(w, "named snapshots need the SQLite store", http.StatusNotImplemented)
+		return
+	}
+	type result struct {
+		meta persistence.SnapshotMeta
+		err  error
+	}
+	done := make(chan result, 1)
+	capture := func() {
+		p, err := db.CaptureSnapshot(s.Sim, s.SnapshotDir, label, s.Gen)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		go func() {
+			meta, err := p.Finish()
+			done <- result{meta, err}
+		}()
+	}
+	if s.Eng == nil {
+		capture()
+	} else if !s.Eng.SubmitLoopTask(capture) {
+		http.Error(w, "snapshot queue unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case res := <-done:
+		switch {
+		case errors.Is(res.err, persistence.ErrBadSnapshotLabel):
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		case res.err != nil:
+			slog.Error("named snapshot failed", "label", label, "error", res.err)
+			http.Error(w, "snapshot failed", http.StatusInternalServerError)
+		default:
+			writeJSON(w, res.meta)
+		}
+	case <-time.After(15 * time.Minute):
+		// Memories and relationships can take minutes on a large world; the
+		// snapshot still lands and shows up in GET /api/v1/snapshots.
+		http.Error(w, "snapshot still being written; check /api/v1/snapshots", http.StatusGatewayTimeout)
+	}
 }
 
+// handleSnapshots lists the named snapshots, oldest first.
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	snaps, err := persistence.ListSnapshots(s.SnapshotDir)
+	if err != nil {
+		slog.Error("list snapshots failed", "error", err)
+		http.Error(w, "cannot list snapshots", http.StatusInternalServerError)
+		return
+	}
+	if snaps == nil {
+		snaps = []persistence.SnapshotMeta{}
+	}
+	writeJSON(w, snaps)
+}
+
+// handleSnapshotDownload serves GET /api/v1/snapshots/{name} as a SQLite file,
+// ready for `worldsim -from-snapshot`.
+func (s *Server) handleSnapshotDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/snapshots/"), ".db")
+	path, err := persistence.SnapshotPath(s.SnapshotDir, name)
+	if err != nil {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".db"))
+	http.ServeFile(w, r, path)
+}
+
+// actorHeader names who is behind an admin request in the intervention
+// ledger (e.g. "gardener"). Defaults to "admin".
+const actorHeader = "X-Worldsim-Actor"
+
+// handleIntervention submits one intervention. The body is an
+// engine.InterventionRequest plus an optional "apply_at" tick; without one (or
+// with a tick already past) it applies at the next safe point between ticks.
 func (s *Server) handleIntervention(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req struct {
-		Type         string  `json:"type"`
-		Description  string  `json:"description,omitempty"`
-		Category     string  `json:"category,omitempty"`
-		Settlement   string  `json:"settlement,omitempty"`
-		Amount       int64   `json:"amount,omitempty"`
-		Count        int     `json:"count,omitempty"`
-		Good         string  `json:"good,omitempty"`
-		Quantity     int     `json:"quantity,omitempty"`
-		Multiplier   float64 `json:"multiplier,omitempty"`
-		DurationDays int     `json:"duration_days,omitempty"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var body worldclient.InterventionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
+	req := engineIntervention(body)
+	if req.Type == engine.InterventionRevert {
+		http.Error(w, "use POST /api/v1/intervention/{id}/revert", http.StatusBadRequest)
+		return
+	}
+	if err := engine.ValidateIntervention(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	tick := s.Sim.CurrentTick()
-
-	switch req.Type {
-	case "event":
-		if req.Description == "" {
-			http.Error(w, "description required for event type", http.StatusBadRequest)
-			return
-		}
-		cat := req.Category
-		if cat == "" {
-			cat = "intervention"
-		}
-		// Admin interventions can label events with any string — runtime cast
-		// to Category type. Preserves operator flexibility (e.g. "intervention",
-		// "experiment") at the cost of weakening compile-time category safety
-		// for this one ingestion path. Intentional.
-		s.Sim.EmitEvent(engine.Event{
-			Tick:        tick,
-			Description: req.Description,
-			Category:    eventproto.Category(cat),
+	rec, err := s.submitIntervention(r.Header.Get(actorHeader), req, body.ApplyAt)
+	switch {
+	case errors.Is(err, errLoopUnavailable):
+		http.Error(w, "intervention queue unavailable", http.StatusServiceUnavailable)
+	case errors.Is(err, errLoopTimeout):
+		http.Error(w, "intervention timed out", http.StatusGatewayTimeout)
+	case errors.Is(err, engine.ErrSettlementNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, engine.ErrSpawnerUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		details := rec.Result
+		if rec.Status == engine.InterventionPending {
+			details = fmt.Sprintf("scheduled for tick %d (%s)", rec.ApplyAt, engine.SimTime(rec.ApplyAt))
+		}
+		writeJSON(w, worldclient.InterventionResult{
+			Success: true,
+			Details: details,
+			ID:      rec.ID,
+			Status:  string(rec.Status),
 		})
-		writeJSON(w, map[string]any{"success": true, "details": "event injected"})
+	}
+}
+
+// engineIntervention is the engine's form of an intervention request body.
+// Reverts go through their own route, so the body carries no revert fields.
+func engineIntervention(b worldclient.InterventionRequest) engine.InterventionRequest {
+	return engine.InterventionRequest{
+		Type:         engine.InterventionType(b.Type),
+		Description:  b.Description,
+		Category:     b.Category,
+		Settlement:   b.Settlement,
+		Amount:       b.Amount,
+		Count:        b.Count,
+		Good:         b.Good,
+		Quantity:     b.Quantity,
+		Multiplier:   b.Multiplier,
+		DurationDays: b.DurationDays,
+		Knob:         b.Knob,
+		Value:        b.Value,
+	}
+}
+
+var (
+	errLoopUnavailable = errors.New("tick loop unavailable")
+	errLoopTimeout     = errors.New("tick loop timed out")
+)
 
-	case "wealth":
-		if req.Settlement == "" {
-			http.Error(w, "settlement required for wealth type", http.StatusBadRequest)
+// onLoop runs fn inside the tick-loop goroutine, between ticks, and waits for
+// it. Without an engine (tests) fn runs directly.
+func (s *Server) onLoop(fn func()) error {
+	if s.Eng == nil {
+		fn()
+		return nil
+	}
+	done := make(chan struct{})
+	if !s.Eng.SubmitLoopTask(func() {
+		fn()
+		close(done)
+	}) {
+		return errLoopUnavailable
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(30 * time.Second):
+		return errLoopTimeout
+	}
+}
+
+// submitIntervention hands req to the sim's intervention queue on the tick
+// loop. Running there keeps it from racing the loop's own mutation of sim
+// state and pins it to an exact tick, so the replay journal can re-apply it at
+// the same point. The ledger record is written through to the database, a
+// tuning change is saved straight away rather than at the next autosave, and
+// the read view is republished so the change shows up in the next GET. The
+// returned record is a copy, safe to read after the loop moves on.
+func (s *Server) submitIntervention(actor string, req engine.InterventionRequest, applyAt uint64) (engine.InterventionRecord, error) {
+	var rec engine.InterventionRecord
+	var err error
+	loopErr := s.onLoop(func() {
+		var live *engine.InterventionRecord
+		live, err = s.Sim.SubmitIntervention(actor, req, applyAt)
+		if live == nil {
 			return
 		}
-		var found *social.Settlement
-		for _, st := range s.Sim.Settlements {
-			if st.Name == req.Settlement {
-				found = st
-				break
+		rec = *live
+		if s.DB != nil {
+			if serr := s.DB.SaveInterventions([]*engine.InterventionRecord{live}); serr != nil {
+				slog.Error("intervention ledger save failed", "error", serr)
+			}
+			if live.Status == engine.InterventionApplied && req.Type == engine.InterventionTune {
+				if serr := s.DB.SaveTuning(s.Sim); serr != nil {
+					slog.Error("tuning save failed", "error", serr)
+				}
 			}
 		}
-		if found == nil {
-			http.Error(w, "settlement not found", http.StatusNotFound)
-			return
-		}
-		if req.Amount < 0 && uint64(-req.Amount) > found.Treasury {
-			found.Treasury = 0
-		} else {
-			found.Treasury = uint64(int64(found.Treasury) + req.Amount)
-		}
-		writeJSON(w, map[string]any{
-			"success": true,
-			"details": fmt.Sprintf("treasury of %s adjusted by %d (now %d)", found.Name, req.Amount, found.Treasury),
-		})
+		s.Sim.PublishView()
+	})
+	if loopErr != nil {
+		return rec, loopErr
+	}
+	return rec, err
+}
 
-	case "spawn":
-		if req.Settlement == "" || req.Count <= 0 {
-			http.Error(w, "settlement and count required for spawn type", http.StatusBadRequest)
-			return
+// handleInterventions lists the intervention ledger, newest first: every
+// pending intervention plus the most recent settled ones.
+// ?status=pending|applied|failed|canceled filters, ?limit=N caps (default 100).
+func (s *Server) handleInterventions(w http.ResponseWriter, r *http.Request) {
+	status := engine.InterventionStatus(r.URL.Query().Get("status"))
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
 		}
-		if req.Count > 100 {
-			http.Error(w, "max 100 agents per spawn", http.StatusBadRequest)
-			return
+	}
+
+	ledger := s.view().Interventions
+	out := make([]*engine.InterventionRecord, 0, min(limit, len(ledger)))
+	for i := len(ledger) - 1; i >= 0 && len(out) < limit; i-- {
+		if status == "" || ledger[i].Status == status {
+			out = append(out, ledger[i])
 		}
-		var found *social.Settlement
-		for _, st := range s.Sim.Settlements {
-			if st.Name == req.Settlement {
-				found = st
-				break
+	}
+	writeJSON(w, InterventionList{Count: len(out), Interventions: out})
+}
+
+// handleInterventionRoutes serves the per-record intervention endpoints:
+//
+//	GET  /api/v1/intervention/{id}         one ledger record
+//	POST /api/v1/intervention/{id}/cancel  cancel a pending scheduled one (admin)
+//	POST /api/v1/intervention/{id}/revert  undo an applied one (admin)
+func (s *Server) handleInterventionRoutes(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/intervention/"), "/")
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid intervention id", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		for _, rec := range s.view().Interventions {
+			if rec.ID == id {
+				writeJSON(w, rec)
+				return
 			}
 		}
-		if found == nil {
-			http.Error(w, "settlement not found", http.StatusNotFound)
-			return
-		}
-		if s.Sim.Spawner == nil {
-			http.Error(w, "spawner not available", http.StatusServiceUnavailable)
+		http.Error(w, engine.ErrInterventionNotFound.Error(), http.StatusNotFound)
+
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		s.ledgerAction(w, func() (*engine.InterventionRecord, error) {
+			return s.Sim.CancelIntervention(id)
+		}, id)
+
+	case len(parts) == 2 && parts[1] == "revert" && r.Method == http.MethodPost:
+		actor := r.Header.Get(actorHeader)
+		s.ledgerAction(w, func() (*engine.InterventionRecord, error) {
+			return s.Sim.RevertIntervention(actor, id)
+		}, id)
+
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// ledgerAction runs a cancel or revert on the tick loop, writes the touched
+// records (the result and the original, id) through to the database,
+// republishes the view and responds with the resulting record.
+func (s *Server) ledgerAction(w http.ResponseWriter, action func() (*engine.InterventionRecord, error), id uint64) {
+	var rec engine.InterventionRecord
+	var err error
+	loopErr := s.onLoop(func() {
+		var live *engine.InterventionRecord
+		live, err = action()
+		if live == nil {
 			return
 		}
-		hex := s.Sim.WorldMap.Get(found.Position)
-		terrain := world.TerrainPlains
-		if hex != nil {
-			terrain = hex.Terrain
-		}
-		immigrants := s.Sim.Spawner.SpawnPopulation(uint32(req.Count), found.Position, found.ID, terrain)
-		for _, a := range immigrants {
-			a.BornTick = tick
-			s.Sim.Agents = append(s.Sim.Agents, a)
-			s.Sim.AgentIndex[a.ID] = a
-			if a.HomeSettID != nil {
-				s.Sim.SettlementAgents[*a.HomeSettID] = append(s.Sim.SettlementAgents[*a.HomeSettID], a)
+		rec = *live
+		if s.DB != nil {
+			touched := []*engine.InterventionRecord{live}
+			if orig := s.Sim.FindIntervention(id); orig != nil && orig != live {
+				touched = append(touched, orig)
+			}
+			if serr := s.DB.SaveInterventions(touched); serr != nil {
+				slog.Error("intervention ledger save failed", "error", serr)
+			}
+			if live.Status == engine.InterventionApplied && live.Request.Compensation != nil &&
+				live.Request.Compensation.Type == engine.InterventionTune {
+				if serr := s.DB.SaveTuning(s.Sim); serr != nil {
+					slog.Error("tuning save failed", "error", serr)
+				}
 			}
 		}
-		found.Population += uint32(req.Count)
-		writeJSON(w, map[string]any{
-			"success": true,
-			"details": fmt.Sprintf("%d immigrants arrived in %s", req.Count, found.Name),
-		})
+		s.Sim.PublishView()
+	})
+	switch {
+	case errors.Is(loopErr, errLoopUnavailable):
+		http.Error(w, "intervention queue unavailable", http.StatusServiceUnavailable)
+	case errors.Is(loopErr, errLoopTimeout):
+		http.Error(w, "intervention timed out", http.StatusGatewayTimeout)
+	case errors.Is(err, engine.ErrInterventionNotFound), errors.Is(err, engine.ErrSettlementNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, engine.ErrInterventionNotPending), errors.Is(err, engine.ErrNotRevertible),
+		errors.Is(err, engine.ErrAlreadyReverted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, rec)
+	}
+}
 
-	case "provision":
-		if req.Settlement == "" || req.Good == "" || req.Quantity <= 0 {
-			http.Error(w, "settlement, good, and quantity required for provision type", http.StatusBadRequest)
-			return
-		}
-		if req.Quantity > 200 {
-			http.Error(w, "max 200 units per provision", http.StatusBadRequest)
-			return
-		}
-		desc, err := s.Sim.ProvisionSettlement(req.Settlement, req.Good, req.Quantity)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		writeJSON(w, map[string]any{"success": true, "details": desc})
+// knobState is one row of GET /api/v1/tuning.
+type knobState struct {
+	engine.Knob
+	Value float64 `json:"value"`
+}
 
-	case "cultivate":
-		if req.Settlement == "" || req.Multiplier <= 0 || req.DurationDays <= 0 {
-			http.Error(w, "settlement, multiplier, and duration_days required for cultivate type", http.StatusBadRequest)
-			return
-		}
-		if req.Multiplier > 2.0 {
-			http.Error(w, "max multiplier is 2.0", http.StatusBadRequest)
-			return
-		}
-		if req.DurationDays > 14 {
-			http.Error(w, "max duration is 14 days", http.StatusBadRequest)
+// handleTuning lists every tuning knob with its bounds and current value
+// (GET), or changes one (POST {"knob": name, "value": v}, admin only). A
+// change goes through the intervention path, so it is journaled, audited as
+// an admin event, and persisted.
+func (s *Server) handleTuning(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req TuningChange
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
-		desc, err := s.Sim.CultivateSettlement(req.Settlement, req.Multiplier, req.DurationDays)
-		if err != nil {
+		iv := engine.InterventionRequest{Type: "tune", Knob: req.Knob, Value: req.Value}
+		if err := engine.ValidateIntervention(iv); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		writeJSON(w, map[string]any{"success": true, "details": desc})
-
-	case "consolidate":
-		if req.Settlement == "" || req.Count <= 0 {
-			http.Error(w, "settlement and count required for consolidate type", http.StatusBadRequest)
+		_, err := s.submitIntervention(r.Header.Get(actorHeader), iv, 0)
+		switch {
+		case errors.Is(err, errLoopUnavailable):
+			http.Error(w, "tuning queue unavailable", http.StatusServiceUnavailable)
 			return
-		}
-		if req.Count > 100 {
-			http.Error(w, "max 100 agents per consolidate", http.StatusBadRequest)
+		case errors.Is(err, errLoopTimeout):
+			http.Error(w, "tuning change timed out", http.StatusGatewayTimeout)
 			return
-		}
-		desc, err := s.Sim.ConsolidateSettlement(req.Settlement, req.Count)
-		if err != nil {
+		case err != nil:
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		writeJSON(w, map[string]any{"success": true, "details": desc})
-
 	default:
-		http.Error(w, "unknown intervention type (use: event, wealth, spawn, provision, cultivate, consolidate)", http.StatusBadRequest)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sim := s.view()
+	knobs := engine.Knobs()
+	out := make([]knobState, len(knobs))
+	for i, k := range knobs {
+		v, _ := sim.Tuning.Get(k.Name)
+		out[i] = knobState{Knob: k, Value: v}
 	}
+	writeJSON(w, out)
 }
 
 // handleStream provides an SSE endpoint for real-time event streaming.
@@ -2620,7 +3318,7 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 	defer s.Sim.Unsubscribe(subID)
 
 	// Send recent events as catch-up (last 50).
-	events := s.Sim.Events
+	events := s.view().Events
 	start := len(events) - 50
 	if start < 0 {
 		start = 0
@@ -2656,7 +3354,7 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 
 // writeSSEEvent writes a single event in SSE format.
 func writeSSEEvent(w http.ResponseWriter, e engine.Event) {
-	data, err := json.Marshal(e)
+	data, err := json.Marshal(worldclient.Event(e))
 	if err != nil {
 		return
 	}
@@ -0,0 +1,642 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/persistence"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+	"github.com/talgya/mini-world/pkg/worldclient"
+)
+
+// newTestWorld builds a small populated world the way a fresh boot does.
+func newTestWorld(t *testing.T) *engine.Simulation {
+	t.Helper()
+	const seed = 7
+	m := world.Generate(world.GenConfig{Radius: 8, Seed: seed, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()})
+	for _, h := range m.Hexes {
+		h.Health = 1
+	}
+	spawner := agents.NewSpawner(seed)
+	rng := rand.New(rand.NewSource(seed))
+	var setts []*social.Settlement
+	var all []*agents.Agent
+	for i, ss := range world.PlaceSettlements(m, seed) {
+		sid := uint64(i + 1)
+		pop := world.PopulationForSize(ss.Size, rng)
+		setts = append(setts, &social.Settlement{
+			ID: sid, Name: ss.Name, Position: ss.Coord, Population: pop,
+			TaxRate: 0.10, Treasury: uint64(pop) * 5, GovernanceScore: 0.6, MarketLevel: 1,
+		})
+		terrain := world.TerrainPlains
+		if h := m.Get(ss.Coord); h != nil {
+			h.SettlementID = &sid
+			terrain = h.Terrain
+		}
+		all = append(all, spawner.SpawnPopulation(pop, ss.Coord, sid, terrain)...)
+	}
+	if len(setts) == 0 {
+		t.Fatal("test world has no settlements")
+	}
+	sim := engine.NewSimulation(m, all, setts)
+	sim.Spawner = spawner
+	sim.InitFactions()
+	return sim
+}
+
+// TestHandlersDoNotRaceTickLoop hammers the read endpoints while the engine
+// runs flat out. Run with -race: handlers must only touch the published view.
+func TestHandlersDoNotRaceTickLoop(t *testing.T) {
+	sim := newTestWorld(t)
+	eng := engine.NewEngine()
+	eng.Interval = time.Microsecond
+	eng.OnTick = sim.TickMinute
+	eng.OnHour = sim.TickHour
+	eng.OnDay = sim.TickDay
+	eng.OnWeek = sim.TickWeek
+	eng.OnSeason = sim.TickSeason
+	eng.AfterHour = func(uint64) { sim.PublishView() }
+	sim.PublishView()
+
+	srv := &Server{Sim: sim, Eng: eng}
+	sett := sim.Settlements[0]
+	routes := []struct {
+		path string
+		h    http.HandlerFunc
+	}{
+		{"/api/v1/status", srv.handleStatus},
+		{"/api/v1/settlements", srv.handleSettlements},
+		{"/api/v1/agents?tier=0", srv.handleAgents},
+		{"/api/v1/events", srv.handleEvents},
+		{"/api/v1/economy", srv.handleEconomy},
+		{"/api/v1/social", srv.handleSocial},
+		{"/api/v1/factions", srv.handleFactions},
+		{"/api/v1/map", srv.handleBulkMap},
+		{fmt.Sprintf("/api/v1/settlement/%d", sett.ID), srv.handleSettlementDetail},
+		{fmt.Sprintf("/api/v1/map/%d/%d", sett.Position.Q, sett.Position.R), srv.handleHexDetail},
+		{"/api/v1/tuning", srv.handleTuning},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		eng.Run()
+		close(done)
+	}()
+
+	var wg sync.WaitGroup
+	for _, rt := range routes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				rec := httptest.NewRecorder()
+				rt.h(rec, httptest.NewRequest(http.MethodGet, rt.path, nil))
+				if rec.Code != http.StatusOK {
+					t.Errorf("GET %s = %d: %s", rt.path, rec.Code, rec.Body)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The view must have advanced with the loop, not stayed at tick 0.
+	deadline := time.Now().Add(10 * time.Second)
+	for srv.view().CurrentTick() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	eng.Stop()
+	<-done
+	if srv.view().CurrentTick() == 0 {
+		t.Error("view never republished")
+	}
+}
+
+// TestEventsPageIntoHistory follows X-Next-Cursor from the in-memory page of
+// /events back through saved history and checks every event comes back once,
+// including ones emitted after the last save. It runs against both stores.
+func TestEventsPageIntoHistory(t *testing.T) {
+	db, err := persistence.Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for name, store := range map[string]persistence.Store{"sqlite": db, "memory": persistence.NewMemStore()} {
+		t.Run(name, func(t *testing.T) { testEventsPageIntoHistory(t, store) })
+	}
+}
+
+func testEventsPageIntoHistory(t *testing.T, db persistence.Store) {
+	sim := newTestWorld(t)
+	sim.Events, sim.UnsavedEvents = nil, 0
+	emit := func(from, to int) {
+		for i := from; i < to; i++ {
+			sim.EmitEvent(engine.Event{Tick: uint64(i / 3), Description: fmt.Sprintf("e%d", i), Category: "social"})
+		}
+	}
+	emit(0, 20)
+	if err := db.SaveHistory(sim); err != nil {
+		t.Fatal(err)
+	}
+	emit(20, 30)
+	sim.Events = sim.Events[12:] // older events have left memory
+	srv := &Server{Sim: sim, DB: db}
+
+	var got []string
+	path := "/api/v1/events?limit=4"
+	for pages := 0; path != ""; pages++ {
+		if pages > 20 {
+			t.Fatal("cursor never ended")
+		}
+		rec := httptest.NewRecorder()
+		srv.handleEvents(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body)
+		}
+		var page []engine.Event
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		var descs []string
+		for _, e := range page {
+			descs = append(descs, e.Description)
+		}
+		got = append(descs, got...)
+		path = ""
+		if c := rec.Header().Get("X-Next-Cursor"); c != "" {
+			path = "/api/v1/events?limit=4&cursor=" + c
+		}
+	}
+	if len(got) != 30 {
+		t.Fatalf("got %d events: %v", len(got), got)
+	}
+	for i, d := range got {
+		if d != fmt.Sprintf("e%d", i) {
+			t.Fatalf("event %d is %s: %v", i, d, got)
+		}
+	}
+}
+
+// TestHistoryEndpoints serves stats and settlement history and restored
+// biographies from an in-memory store.
+func TestHistoryEndpoints(t *testing.T) {
+	store := persistence.NewMemStore()
+	for day := range uint64(4) {
+		tick := day * engine.TicksPerSimDay
+		store.SaveStatsSnapshot(persistence.StatsRow{Tick: tick, Population: 100 + int(day)})
+		store.SaveSettlementStats([]persistence.SettlementStatsRow{
+			{Tick: tick, SettlementID: 1, Population: 10 + int(day)},
+			{Tick: tick, SettlementID: 2, Population: 50},
+		})
+	}
+	store.SaveBiography(3, "A quiet life.", "2026-01-01T00:00:00Z")
+	srv := &Server{Sim: newTestWorld(t), DB: store}
+	srv.loadBiographies()
+
+	get := func(path string, handler http.HandlerFunc, v any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var stats []persistence.StatsRow
+	get("/api/v1/stats/history?from=1440&limit=2", srv.handleStatsHistory, &stats)
+	if len(stats) != 2 || stats[0].Population != 103 || stats[1].Population != 102 {
+		t.Errorf("stats history = %+v", stats)
+	}
+	var hist []persistence.SettlementStatsRow
+	get("/api/v1/settlement/history/1", srv.handleSettlementHistory, &hist)
+	if len(hist) != 4 || hist[0].Population != 13 || hist[3].SettlementID != 1 {
+		t.Errorf("settlement history = %+v", hist)
+	}
+	if bio := srv.bioCache[3]; bio.Biography != "A quiet life." {
+		t.Errorf("restored biography = %+v", bio)
+	}
+}
+
+// TestAgentFamily serves a family from the tree, including for an agent the
+// live world has already compacted away, and for a living agent the tree
+// does not know yet.
+func TestAgentFamily(t *testing.T) {
+	sim := newTestWorld(t)
+	sim.PublishView()
+	parent, child := sim.Agents[0], sim.Agents[1]
+	gone := agents.AgentID(sim.Spawner.NextID() + 10) // compacted long ago
+	store := persistence.NewMemStore()
+	store.SaveLineage([]engine.LineageRecord{
+		{Tick: 10, Kind: engine.LineageBirth, Person: engine.Kin{ID: child.ID, Name: child.Name},
+			Kin: []engine.Kin{{ID: gone, Name: "Old Ones"}, {ID: parent.ID, Name: parent.Name}}},
+	})
+	srv := &Server{Sim: sim, DB: store}
+	handler := srv.handleAgentRoutes(NewRateLimiter(1, time.Minute))
+
+	get := func(path string) (int, persistence.Family) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var fam persistence.Family
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &fam); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, fam
+	}
+	code, fam := get(fmt.Sprintf("/api/v1/agent/%d/family?generations=2", child.ID))
+	if code != http.StatusOK || len(fam.Ancestors) != 2 || !fam.Agent.Alive {
+		t.Fatalf("child's family = %d %+v", code, fam)
+	}
+	if old := fam.Ancestors[1]; old.ID != uint64(gone) || old.Alive {
+		t.Errorf("compacted ancestor = %+v", old)
+	}
+	if code, fam := get(fmt.Sprintf("/api/v1/agent/%d/family", gone)); code != http.StatusOK || len(fam.Descendants) != 1 {
+		t.Errorf("dead agent's family = %d %+v", code, fam)
+	}
+	stranger := sim.Agents[2]
+	if code, fam := get(fmt.Sprintf("/api/v1/agent/%d/family", stranger.ID)); code != http.StatusOK || fam.Agent.Name != stranger.Name {
+		t.Errorf("family of an agent outside the tree = %d %+v", code, fam)
+	}
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/v1/agent/%d/family", gone+1): http.StatusNotFound,
+		"/api/v1/agent/1/family?generations=0":         http.StatusBadRequest,
+	} {
+		if code, _ := get(path); code != want {
+			t.Errorf("GET %s = %d, want %d", path, code, want)
+		}
+	}
+}
+
+// TestGraveyardEndpoints looks up the dead, saved and not yet saved, through
+// the agent routes and /graveyard.
+func TestGraveyardEndpoints(t *testing.T) {
+	sim := newTestWorld(t)
+	gone := agents.AgentID(sim.Spawner.NextID() + 10)
+	home := sim.Settlements[0].ID
+	store := persistence.NewMemStore()
+	store.SaveGraves([]engine.Grave{
+		{AgentID: gone, Name: "Old Tam", DiedTick: 100, Cause: "age", Age: 80, SettlementID: &home,
+			SettlementName: sim.Settlements[0].Name},
+		{AgentID: gone + 1, Name: "Bess", DiedTick: 200, Cause: "illness"},
+	})
+	sim.UnsavedGraves = []engine.Grave{{AgentID: gone + 2, Name: "Fenn", DiedTick: 300, Cause: "battle"}}
+	sim.PublishView()
+	srv := &Server{Sim: sim, DB: store}
+	agentRoutes := srv.handleAgentRoutes(NewRateLimiter(1, time.Minute))
+
+	get := func(handler http.HandlerFunc, path string, v any) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code == http.StatusOK && v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec
+	}
+	for id, name := range map[agents.AgentID]string{gone: "Old Tam", gone + 2: "Fenn"} {
+		var g engine.Grave
+		if rec := get(agentRoutes, fmt.Sprintf("/api/v1/agent/%d", id), &g); rec.Code != http.StatusOK || g.Name != name || g.Alive {
+			t.Errorf("agent %d = %d %+v", id, rec.Code, g)
+		}
+	}
+	if rec := get(agentRoutes, fmt.Sprintf("/api/v1/agent/%d", gone+3), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown agent = %d", rec.Code)
+	}
+	var story map[string]string
+	get(agentRoutes, fmt.Sprintf("/api/v1/agent/%d/story", gone), &story)
+	if story["source"] != "obituary" || !strings.Contains(story["biography"], "died of age") {
+		t.Errorf("obituary = %v", story)
+	}
+
+	var list []engine.Grave
+	rec := get(srv.handleGraveyard, "/api/v1/graveyard?limit=2", &list)
+	if len(list) != 2 || list[0].Name != "Fenn" || list[1].Name != "Bess" || rec.Header().Get("X-Next-Cursor") == "" {
+		t.Errorf("graveyard page = %+v, cursor %q", list, rec.Header().Get("X-Next-Cursor"))
+	}
+	get(srv.handleGraveyard, "/api/v1/graveyard?limit=2&cursor="+rec.Header().Get("X-Next-Cursor"), &list)
+	if len(list) != 1 || list[0].Name != "Old Tam" {
+		t.Errorf("second page = %+v", list)
+	}
+	get(srv.handleGraveyard, fmt.Sprintf("/api/v1/graveyard?settlement_id=%d&to=150", home), &list)
+	if len(list) != 1 || list[0].Name != "Old Tam" {
+		t.Errorf("by settlement = %+v", list)
+	}
+	if rec := get(srv.handleGraveyard, "/api/v1/graveyard?cursor=nope", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor = %d", rec.Code)
+	}
+}
+
+// TestSettlementArchiveEndpoints resolves an abandoned settlement by ID and
+// pages through the archive.
+func TestSettlementArchiveEndpoints(t *testing.T) {
+	sim := newTestWorld(t)
+	gone := uint64(len(sim.Settlements) + 100)
+	store := persistence.NewMemStore()
+	store.SaveSettlementArchives([]engine.SettlementArchive{
+		{ID: gone, Name: "Old Ford", Archived: true, SettlementChronicle: engine.SettlementChronicle{AbandonedTick: 100, Cause: engine.AbandonDepopulated}},
+		{ID: gone + 1, Name: "Mirewick", Archived: true, SettlementChronicle: engine.SettlementChronicle{AbandonedTick: 200, Cause: engine.AbandonExodus}},
+	})
+	sim.UnsavedArchives = []engine.SettlementArchive{
+		{ID: gone + 2, Name: "Lowmarsh", Archived: true, SettlementChronicle: engine.SettlementChronicle{AbandonedTick: 300, Cause: engine.AbandonDepopulated}},
+	}
+	sim.PublishView()
+	srv := &Server{Sim: sim, DB: store}
+
+	get := func(handler http.HandlerFunc, path string, v any) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code == http.StatusOK && v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec
+	}
+	for id, name := range map[uint64]string{gone: "Old Ford", gone + 2: "Lowmarsh"} {
+		var a engine.SettlementArchive
+		if rec := get(srv.handleSettlementDetail, fmt.Sprintf("/api/v1/settlement/%d", id), &a); rec.Code != http.StatusOK || a.Name != name || !a.Archived {
+			t.Errorf("settlement %d = %d %+v", id, rec.Code, a)
+		}
+	}
+	if rec := get(srv.handleSettlementDetail, fmt.Sprintf("/api/v1/settlement/%d", gone+3), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown settlement = %d", rec.Code)
+	}
+
+	var list []engine.SettlementArchive
+	rec := get(srv.handleSettlementArchive, "/api/v1/settlements/archive?limit=2", &list)
+	if len(list) != 2 || list[0].Name != "Lowmarsh" || list[1].Name != "Mirewick" || rec.Header().Get("X-Next-Cursor") == "" {
+		t.Errorf("archive page = %+v, cursor %q", list, rec.Header().Get("X-Next-Cursor"))
+	}
+	get(srv.handleSettlementArchive, "/api/v1/settlements/archive?limit=2&cursor="+rec.Header().Get("X-Next-Cursor"), &list)
+	if len(list) != 1 || list[0].Name != "Old Ford" {
+		t.Errorf("second page = %+v", list)
+	}
+	get(srv.handleSettlementArchive, "/api/v1/settlements/archive?cause=exodus", &list)
+	if len(list) != 1 || list[0].Name != "Mirewick" {
+		t.Errorf("by cause = %+v", list)
+	}
+	if rec := get(srv.handleSettlementArchive, "/api/v1/settlements/archive?cursor=nope", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor = %d", rec.Code)
+	}
+}
+
+// TestSearchEndpoint searches saved events and memories by words, with a
+// settlement given by name and paging by cursor.
+func TestSearchEndpoint(t *testing.T) {
+	sim := newTestWorld(t)
+	home := sim.Settlements[0]
+	for i := range 5 {
+		sim.EmitEvent(engine.Event{Tick: uint64(i), Description: fmt.Sprintf("Famine strikes %s (%d)", home.Name, i),
+			Category: eventproto.CategoryEconomy, Meta: map[string]any{"settlement_id": home.ID}})
+	}
+	sim.EmitEvent(engine.Event{Tick: 9, Description: "Famine strikes far away", Category: eventproto.CategoryEconomy})
+	var local *agents.Agent
+	for _, a := range sim.Agents {
+		a.Memories = nil
+		if local == nil && a.HomeSettID != nil && *a.HomeSettID == home.ID {
+			local = a
+		}
+	}
+	agents.AddMemory(local, 3, "Went hungry in the famine", 0.8)
+	store := persistence.NewMemStore()
+	if err := store.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+	sim.PublishView()
+	srv := &Server{Sim: sim, DB: store}
+
+	get := func(path string, v any) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.handleSearch(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code == http.StatusOK && v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec
+	}
+	var hits []persistence.SearchHit
+	get("/api/v1/search?q=famine", &hits)
+	if len(hits) != 6 || hits[0].Tick != 9 || hits[0].Text != "Famine strikes far away" {
+		t.Errorf("famine = %+v", hits)
+	}
+
+	// By settlement name, two to a page, within a tick range.
+	var ticks []uint64
+	path := "/api/v1/search?q=famine&settlement=" + strings.ToUpper(home.Name) + "&from=1&limit=2"
+	for next := ""; ; {
+		rec := get(path+next, &hits)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s = %d: %s", path, rec.Code, rec.Body)
+		}
+		for _, h := range hits {
+			ticks = append(ticks, h.Tick)
+		}
+		if next = rec.Header().Get("X-Next-Cursor"); next == "" {
+			break
+		}
+		next = "&cursor=" + next
+	}
+	if fmt.Sprint(ticks) != "[4 3 2 1]" {
+		t.Errorf("paged ticks = %v", ticks)
+	}
+
+	get(fmt.Sprintf("/api/v1/search?q=hungry&type=memories&settlement=%d", home.ID), &hits)
+	if len(hits) != 1 || hits[0].Type != "memory" || *hits[0].AgentID != uint64(local.ID) ||
+		hits[0].Text != "Went hungry in the famine" {
+		t.Errorf("memories = %+v", hits)
+	}
+	// A move counts from the next published view, before any save.
+	away := sim.Settlements[1]
+	sim.SettlementAgents[home.ID] = slices.DeleteFunc(sim.SettlementAgents[home.ID], func(a *agents.Agent) bool { return a == local })
+	sim.SettlementAgents[away.ID] = append(sim.SettlementAgents[away.ID], local)
+	local.HomeSettID = &away.ID
+	sim.PublishView()
+	for id, want := range map[uint64]int{home.ID: 0, away.ID: 1} {
+		get(fmt.Sprintf("/api/v1/search?q=hungry&type=memories&settlement=%d", id), &hits)
+		if len(hits) != want {
+			t.Errorf("memories in settlement %d after the move = %+v, want %d", id, hits, want)
+		}
+	}
+
+	for _, path := range []string{
+		"/api/v1/search",
+		"/api/v1/search?q=%21%21",
+		"/api/v1/search?q=famine&type=graves",
+		"/api/v1/search?q=famine&settlement=Atlantis",
+		"/api/v1/search?q=famine&setlement=1",
+		"/api/v1/search?q=famine&cursor=nope",
+	} {
+		if rec := get(path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", path, rec.Code)
+		}
+	}
+}
+
+// TestSettlementPrices serves a settlement's price bars by good and
+// resolution.
+func TestSettlementPrices(t *testing.T) {
+	sim := newTestWorld(t)
+	sim.PublishView()
+	store := persistence.NewMemStore()
+	id := sim.Settlements[0].ID
+	var bars []engine.PriceBar
+	for h := range uint64(30) {
+		for _, good := range []agents.GoodType{agents.GoodGrain, agents.GoodIronOre} {
+			bars = append(bars, engine.PriceBar{SettlementID: id, Good: good, Tick: h * 60, Open: 1, High: 2, Low: 1, Close: 2, Volume: 1})
+		}
+	}
+	store.SavePriceBars(bars)
+	srv := &Server{Sim: sim, DB: store}
+
+	type series struct {
+		Good string `json:"good"`
+		Bars []struct {
+			Tick   uint64 `json:"tick"`
+			Volume int    `json:"volume"`
+		} `json:"bars"`
+	}
+	get := func(query string) (int, []series) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.handleSettlementDetail(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/settlement/%d/prices%s", id, query), nil))
+		var body struct {
+			Series []series `json:"series"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, body.Series
+	}
+	if code, s := get("?good=iron_ore&from=60&to=240"); code != http.StatusOK || len(s) != 1 || s[0].Good != "iron_ore" || len(s[0].Bars) != 4 {
+		t.Errorf("hourly iron ore = %d %+v", code, s)
+	}
+	if code, s := get("?resolution=day"); code != http.StatusOK || len(s) != 2 || len(s[0].Bars) != 2 || s[0].Bars[0].Volume != 24 {
+		t.Errorf("daily = %d %+v", code, s)
+	}
+	for _, q := range []string{"?good=mithril", "?resolution=minute", "?from=x"} {
+		if code, _ := get(q); code != http.StatusBadRequest {
+			t.Errorf("%s = %d", q, code)
+		}
+	}
+}
+
+// TestWorldClient drives the shared client against the real routes: the typed
+// reads decode what the handlers write, an intervention needs the admin key,
+// and the stream delivers its catch-up with the relay key.
+func TestWorldClient(t *testing.T) {
+	sim := newTestWorld(t)
+	sim.Events = append(sim.Events,
+		engine.Event{Tick: 1, Description: "A council falls", Category: eventproto.CategoryPolitical},
+		engine.Event{Tick: 2, Description: "A child is born", Category: eventproto.CategoryBirth})
+	sim.PublishView()
+	store := persistence.NewMemStore()
+	store.SaveStatsSnapshot(persistence.StatsRow{Tick: 1440, Population: 812, Gini: 0.4})
+
+	eng := engine.NewEngine()
+	eng.SetSpeed(0) // paused: the loop only runs submitted tasks
+	done := make(chan struct{})
+	go func() {
+		eng.Run()
+		close(done)
+	}()
+	defer func() {
+		eng.Stop()
+		<-done
+	}()
+
+	srv := &Server{Sim: sim, Eng: eng, DB: store, AdminKey: "admin", RelayKey: "relay"}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	c := worldclient.New(ts.URL)
+	ctx := context.Background()
+
+	status, err := c.Status(ctx)
+	if err != nil || status.Settlements != len(sim.Settlements) || len(status.Occupations) != 10 || status.Population == 0 {
+		t.Errorf("status = %+v, %v", status, err)
+	}
+	setts, err := c.Settlements(ctx)
+	if err != nil || len(setts) != len(sim.Settlements) || setts[0].Name != sim.Settlements[0].Name {
+		t.Errorf("settlements = %d, %v", len(setts), err)
+	}
+	factions, err := c.Factions(ctx)
+	if err != nil || len(factions) != len(sim.Factions) {
+		t.Errorf("factions = %d, %v", len(factions), err)
+	}
+	if _, err := c.Economy(ctx); err != nil {
+		t.Errorf("economy: %v", err)
+	}
+	social, err := c.Social(ctx)
+	if err != nil || len(social.RecentPoliticalEvents) != 1 || social.RecentPoliticalEvents[0].Category != eventproto.CategoryPolitical {
+		t.Errorf("social = %+v, %v", social, err)
+	}
+	if _, err := c.Agents(ctx); err != nil {
+		t.Errorf("agents: %v", err)
+	}
+	// Tier 0 spans several pages; the client follows them all.
+	tier0 := 0
+	for _, a := range sim.Agents {
+		if a.Tier == agents.Tier0 {
+			tier0++
+		}
+	}
+	if all, err := c.AgentsOfTier(ctx, 0); err != nil || len(all) != tier0 || tier0 <= 500 {
+		t.Errorf("tier 0 agents = %d of %d, %v", len(all), tier0, err)
+	}
+	history, err := c.StatsHistory(ctx, 5)
+	if err != nil || len(history) != 1 || history[0].Population != 812 || history[0].Gini != 0.4 {
+		t.Errorf("history = %+v, %v", history, err)
+	}
+
+	iv := worldclient.InterventionRequest{Type: "event", Description: "A comet crosses the sky"}
+	var apiErr *worldclient.APIError
+	if _, err := c.Intervene(ctx, iv); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("intervention without the admin key: %v", err)
+	}
+	c.AdminKey, c.Actor = "admin", "test"
+	res, err := c.Intervene(ctx, iv)
+	if err != nil || !res.Success || res.ID == 0 || res.Status != string(engine.InterventionApplied) {
+		t.Errorf("intervention = %+v, %v", res, err)
+	}
+
+	// The catch-up ends with the intervention's own event.
+	c.RelayKey = "relay"
+	streamCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	errSeen := errors.New("seen the intervention")
+	var got []string
+	err = c.Subscribe(streamCtx, func(e worldclient.Event) error {
+		got = append(got, e.Description)
+		if e.Description == iv.Description {
+			return errSeen
+		}
+		return nil
+	})
+	if !errors.Is(err, errSeen) || len(got) != 3 || got[0] != "A council falls" {
+		t.Errorf("stream = %q, %v", got, err)
+	}
+}
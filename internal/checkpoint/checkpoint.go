@@ -0,0 +1,188 @@
+// Package checkpoint implements the container format for world checkpoints:
+// a short header followed by named sections. Each section carries its own
+// schema version, a length prefix and a CRC-32C checksum. The package does not
+// know what the sections hold; persistence encodes the world into them with
+// Encoder and reads it back with Decoder.
+//
+// File layout (integers little-endian):
+//
+//	header   magic "WSIMCKPT" | format uint16
+//	section  name_len uint8 | name | schema uint16 | length uint64 | payload | crc32c uint32
+//	...
+//	end      a section with an empty name and no payload
+//
+// The checksum covers the section header and the payload. The end marker
+// makes truncation detectable. A reader skips sections it does not know, so
+// new subsystems can be added without bumping the format.
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Format is the container format version written by this build.
+const Format uint16 = 1
+
+var magic = [8]byte{'W', 'S', 'I', 'M', 'C', 'K', 'P', 'T'}
+
+// maxSection bounds a single section's payload, so a corrupt length cannot
+// make Read allocate the machine's memory away.
+const maxSection = 4 << 30
+
+// Container errors.
+var (
+	ErrNotCheckpoint = errors.New("checkpoint: not a checkpoint file")
+	ErrFormat        = errors.New("checkpoint: unsupported format version")
+	ErrChecksum      = errors.New("checkpoint: checksum mismatch")
+	ErrTruncated     = errors.New("checkpoint: truncated")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Section is one decoded section.
+type Section struct {
+	Name    string
+	Version uint16 // schema version of the payload
+	Data    []byte
+}
+
+// File is a decoded checkpoint.
+type File struct {
+	Format   uint16
+	Sections []Section
+}
+
+// Section returns the named section.
+func (f *File) Section(name string) (Section, bool) {
+	for _, s := range f.Sections {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Writer writes a checkpoint. Errors are sticky: after the first one every
+// call returns it, so callers may check only Close.
+type Writer struct {
+	bw  *bufio.Writer
+	err error
+}
+
+// NewWriter writes the header to w and returns a Writer for the sections.
+func NewWriter(w io.Writer) *Writer {
+	cw := &Writer{bw: bufio.NewWriterSize(w, 1<<20)}
+	var hdr [10]byte
+	copy(hdr[:], magic[:])
+	binary.LittleEndian.PutUint16(hdr[8:], Format)
+	_, cw.err = cw.bw.Write(hdr[:])
+	return cw
+}
+
+// WriteSection appends a section. Names are 1–255 bytes and should be unique
+// within a file.
+func (w *Writer) WriteSection(name string, version uint16, data []byte) error {
+	if w.err == nil && (name == "" || len(name) > 255) {
+		w.err = fmt.Errorf("checkpoint: bad section name %q", name)
+	}
+	return w.writeSection(name, version, data)
+}
+
+func (w *Writer) writeSection(name string, version uint16, data []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	hdr := make([]byte, 0, 1+len(name)+2+8)
+	hdr = append(hdr, byte(len(name)))
+	hdr = append(hdr, name...)
+	hdr = binary.LittleEndian.AppendUint16(hdr, version)
+	hdr = binary.LittleEndian.AppendUint64(hdr, uint64(len(data)))
+	crc := crc32.Update(crc32.Checksum(hdr, crcTable), crcTable, data)
+
+	if _, w.err = w.bw.Write(hdr); w.err != nil {
+		return w.err
+	}
+	if _, w.err = w.bw.Write(data); w.err != nil {
+		return w.err
+	}
+	_, w.err = w.bw.Write(binary.LittleEndian.AppendUint32(nil, crc))
+	return w.err
+}
+
+// Close writes the end marker and flushes. It does not close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if err := w.writeSection("", 0, nil); err != nil {
+		return err
+	}
+	w.err = w.bw.Flush()
+	return w.err
+}
+
+// Read decodes a whole checkpoint, verifying every checksum.
+func Read(r io.Reader) (*File, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	var hdr [10]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, ErrNotCheckpoint
+	}
+	if [8]byte(hdr[:8]) != magic {
+		return nil, ErrNotCheckpoint
+	}
+	f := &File{Format: binary.LittleEndian.Uint16(hdr[8:])}
+	if f.Format == 0 || f.Format > Format {
+		return nil, fmt.Errorf("%w %d (this build reads up to %d)", ErrFormat, f.Format, Format)
+	}
+
+	for {
+		s, end, err := readSection(br)
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return f, nil
+		}
+		f.Sections = append(f.Sections, s)
+	}
+}
+
+func readSection(br *bufio.Reader) (s Section, end bool, err error) {
+	defer func() {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = ErrTruncated
+		}
+	}()
+	nameLen, err := br.ReadByte()
+	if err != nil {
+		return s, false, err
+	}
+	hdr := make([]byte, 1+int(nameLen)+2+8)
+	hdr[0] = nameLen
+	if _, err := io.ReadFull(br, hdr[1:]); err != nil {
+		return s, false, err
+	}
+	s.Name = string(hdr[1 : 1+nameLen])
+	s.Version = binary.LittleEndian.Uint16(hdr[1+nameLen:])
+	n := binary.LittleEndian.Uint64(hdr[3+nameLen:])
+	if n > maxSection {
+		return s, false, fmt.Errorf("%w: section %q claims %d bytes", ErrCorrupt, s.Name, n)
+	}
+	s.Data = make([]byte, n)
+	if _, err := io.ReadFull(br, s.Data); err != nil {
+		return s, false, err
+	}
+	var sum [4]byte
+	if _, err := io.ReadFull(br, sum[:]); err != nil {
+		return s, false, err
+	}
+	crc := crc32.Update(crc32.Checksum(hdr, crcTable), crcTable, s.Data)
+	if crc != binary.LittleEndian.Uint32(sum[:]) {
+		return s, false, fmt.Errorf("%w in section %q", ErrChecksum, s.Name)
+	}
+	return s, s.Name == "", nil
+}
@@ -0,0 +1,118 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func writeFile(t *testing.T, sections ...Section) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, s := range sections {
+		w.WriteSection(s.Name, s.Version, s.Data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := []Section{
+		{Name: "world", Version: 1, Data: []byte("hello")},
+		{Name: "empty", Version: 3, Data: []byte{}},
+		{Name: "agents", Version: 2, Data: bytes.Repeat([]byte{7}, 5000)},
+	}
+	f, err := Read(bytes.NewReader(writeFile(t, want...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Format != Format || len(f.Sections) != len(want) {
+		t.Fatalf("format %d, %d sections", f.Format, len(f.Sections))
+	}
+	for i, s := range f.Sections {
+		if s.Name != want[i].Name || s.Version != want[i].Version || !bytes.Equal(s.Data, want[i].Data) {
+			t.Errorf("section %d = %q v%d (%d bytes)", i, s.Name, s.Version, len(s.Data))
+		}
+	}
+	if s, ok := f.Section("agents"); !ok || s.Version != 2 {
+		t.Error("Section(agents) not found")
+	}
+	if _, ok := f.Section("missing"); ok {
+		t.Error("Section(missing) found")
+	}
+}
+
+func TestReadDamage(t *testing.T) {
+	good := writeFile(t, Section{Name: "world", Version: 1, Data: []byte("some payload")})
+
+	flipped := bytes.Clone(good)
+	flipped[len(flipped)-20] ^= 0x40 // inside the world payload
+	if _, err := Read(bytes.NewReader(flipped)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("flipped byte: %v, want ErrChecksum", err)
+	}
+
+	for _, cut := range []int{1, 5, len(good) - 12} {
+		if _, err := Read(bytes.NewReader(good[:len(good)-cut])); !errors.Is(err, ErrTruncated) {
+			t.Errorf("cut %d bytes: %v, want ErrTruncated", cut, err)
+		}
+	}
+
+	if _, err := Read(bytes.NewReader([]byte("SQLite format 3\x00"))); !errors.Is(err, ErrNotCheckpoint) {
+		t.Errorf("sqlite header: %v, want ErrNotCheckpoint", err)
+	}
+
+	future := bytes.Clone(good)
+	future[8] = 0xff
+	if _, err := Read(bytes.NewReader(future)); !errors.Is(err, ErrFormat) {
+		t.Errorf("future format: %v, want ErrFormat", err)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	seven := uint64(7)
+	e := NewEncoder(0)
+	e.Uint(300)
+	e.Int(-5)
+	e.Uint8(9)
+	e.Float32(1.5)
+	e.Float64(-2.25)
+	e.String("héllo")
+	e.RawBytes([]byte{1, 2})
+	e.Bool(true)
+	e.OptUint(nil)
+	e.OptUint(&seven)
+
+	d := NewDecoder(e.Bytes())
+	if d.Uint() != 300 || d.Int() != -5 || d.Uint8() != 9 || d.Float32() != 1.5 || d.Float64() != -2.25 {
+		t.Fatal("numbers did not round-trip")
+	}
+	if d.String() != "héllo" || !bytes.Equal(d.RawBytes(), []byte{1, 2}) || !d.Bool() {
+		t.Fatal("strings did not round-trip")
+	}
+	if d.OptUint() != nil {
+		t.Fatal("nil OptUint decoded as set")
+	}
+	if p := d.OptUint(); p == nil || *p != 7 {
+		t.Fatalf("OptUint = %v", p)
+	}
+	if err := d.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trailing bytes and over-reads both fail, and the error sticks.
+	d = NewDecoder([]byte{1, 2})
+	d.Uint()
+	if err := d.Finish(); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("trailing byte: %v, want ErrCorrupt", err)
+	}
+	d = NewDecoder([]byte{0x05, 'a'}) // claims a 5-byte string
+	if d.String() != "" || !errors.Is(d.Err(), ErrCorrupt) {
+		t.Errorf("short string: %v", d.Err())
+	}
+	if d.Uint8() != 0 || !errors.Is(d.Finish(), ErrCorrupt) {
+		t.Error("error did not stick")
+	}
+}
@@ -0,0 +1,222 @@
+package checkpoint
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrCorrupt reports a section payload that does not decode.
+var ErrCorrupt = errors.New("checkpoint: corrupt section payload")
+
+// Encoder builds a section payload. Integers are varints, floats are fixed
+// width (bit-exact), strings are length-prefixed.
+type Encoder struct {
+	buf []byte
+}
+
+// NewEncoder returns an Encoder with room for sizeHint bytes.
+func NewEncoder(sizeHint int) *Encoder {
+	return &Encoder{buf: make([]byte, 0, sizeHint)}
+}
+
+// Bytes returns the payload built so far.
+func (e *Encoder) Bytes() []byte { return e.buf }
+
+// Uint writes an unsigned varint.
+func (e *Encoder) Uint(v uint64) { e.buf = binary.AppendUvarint(e.buf, v) }
+
+// Int writes a signed (zig-zag) varint.
+func (e *Encoder) Int(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+// Uint8 writes one byte.
+func (e *Encoder) Uint8(v uint8) { e.buf = append(e.buf, v) }
+
+// Float32 writes the value's IEEE bits.
+func (e *Encoder) Float32(v float32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(v))
+}
+
+// Float64 writes the value's IEEE bits.
+func (e *Encoder) Float64(v float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+// RawBytes writes a length-prefixed byte slice.
+func (e *Encoder) RawBytes(b []byte) {
+	e.Uint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// String writes a length-prefixed string.
+func (e *Encoder) String(s string) {
+	e.Uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// Bool writes one byte, 0 or 1.
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+// OptUint writes an optional value: a presence flag, then the value.
+func (e *Encoder) OptUint(p *uint64) {
+	e.Bool(p != nil)
+	if p != nil {
+		e.Uint(*p)
+	}
+}
+
+// SliceLen writes a slice length that keeps nil apart from empty: 0 for nil,
+// n+1 otherwise.
+func (e *Encoder) SliceLen(n int, isNil bool) {
+	if isNil {
+		e.Uint(0)
+	} else {
+		e.Uint(uint64(n) + 1)
+	}
+}
+
+// Decoder reads a payload written by Encoder. Errors are sticky: once a read
+// fails every later read returns zero, and Err reports the first failure.
+type Decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// NewDecoder returns a Decoder over data.
+func NewDecoder(data []byte) *Decoder { return &Decoder{data: data} }
+
+// Err returns the first decoding error.
+func (d *Decoder) Err() error { return d.err }
+
+// Finish returns the first decoding error, or an error if bytes are left over.
+func (d *Decoder) Finish() error {
+	if d.err == nil && d.off != len(d.data) {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrCorrupt, len(d.data)-d.off)
+	}
+	return d.err
+}
+
+func (d *Decoder) fail(what string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: bad %s at offset %d", ErrCorrupt, what, d.off)
+	}
+}
+
+func (d *Decoder) take(n int, what string) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data)-d.off {
+		d.fail(what)
+		return nil
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+// Uint reads an unsigned varint.
+func (d *Decoder) Uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("uvarint")
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// Int reads a signed varint.
+func (d *Decoder) Int() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("varint")
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// Uint8 reads one byte.
+func (d *Decoder) Uint8() uint8 {
+	if b := d.take(1, "byte"); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// Bool reads a byte written by Encoder.Bool.
+func (d *Decoder) Bool() bool { return d.Uint8() != 0 }
+
+// Float32 reads a float32.
+func (d *Decoder) Float32() float32 {
+	if b := d.take(4, "float32"); b != nil {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+// Float64 reads a float64.
+func (d *Decoder) Float64() float64 {
+	if b := d.take(8, "float64"); b != nil {
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+// RawBytes reads a length-prefixed byte slice. It aliases the payload.
+func (d *Decoder) RawBytes() []byte {
+	n := d.Len("bytes")
+	return d.take(n, "bytes")
+}
+
+// String reads a length-prefixed string.
+func (d *Decoder) String() string { return string(d.RawBytes()) }
+
+// OptUint reads a value written by Encoder.OptUint.
+func (d *Decoder) OptUint() *uint64 {
+	if !d.Bool() {
+		return nil
+	}
+	v := d.Uint()
+	return &v
+}
+
+// SliceLen reads a length written by Encoder.SliceLen.
+func (d *Decoder) SliceLen(what string) (n int, isNil bool) {
+	v := d.Uint()
+	if v == 0 || d.err != nil {
+		return 0, true
+	}
+	if v-1 > uint64(len(d.data)-d.off) {
+		d.fail(what + " count")
+		return 0, true
+	}
+	return int(v - 1), false
+}
+
+// Len reads a count or length and checks it against the bytes remaining
+// (every element takes at least one byte), so a corrupt count fails here
+// instead of driving a huge allocation.
+func (d *Decoder) Len(what string) int {
+	n := d.Uint()
+	if d.err == nil && n > uint64(len(d.data)-d.off) {
+		d.fail(what + " count")
+		return 0
+	}
+	return int(n)
+}
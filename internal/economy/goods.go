@@ -14,6 +14,7 @@ type MarketEntry struct {
 	Demand    float64         `json:"demand"`     // Quantity desired
 	Price     float64         `json:"price"`      // Current price in crowns
 	BasePrice float64         `json:"base_price"` // Production cost floor
+	Traded    int             `json:"traded"`     // Units matched in the last resolution
 }
 
 // Market holds the economic state for a single settlement.
@@ -24,28 +25,32 @@ type Market struct {
 	MostTradedGood agents.GoodType                 `json:"most_traded_good"` // Good with highest volume
 }
 
+// DefaultBasePrices is the production-cost floor of each good, in crowns, as
+// originally hand-tuned. The engine exposes each as a tuning knob; NewMarket
+// always starts from these.
+var DefaultBasePrices = [agents.NumGoods]float64{
+	agents.GoodGrain:    2,
+	agents.GoodFish:     2,
+	agents.GoodTimber:   3,
+	agents.GoodIronOre:  4,
+	agents.GoodStone:    3,
+	agents.GoodCoal:     4,
+	agents.GoodHerbs:    5,
+	agents.GoodFurs:     6,
+	agents.GoodGems:     15,
+	agents.GoodExotics:  20,
+	agents.GoodTools:    10,
+	agents.GoodWeapons:  15,
+	agents.GoodClothing: 8,
+	agents.GoodMedicine: 12,
+	agents.GoodLuxuries: 25,
+}
+
 // NewMarket creates a market for a settlement with base prices for all goods.
 func NewMarket(settlementID uint64) *Market {
-	basePrices := map[agents.GoodType]float64{
-		agents.GoodGrain:    2,
-		agents.GoodFish:     2,
-		agents.GoodTimber:   3,
-		agents.GoodIronOre:  4,
-		agents.GoodStone:    3,
-		agents.GoodCoal:     4,
-		agents.GoodHerbs:    5,
-		agents.GoodFurs:     6,
-		agents.GoodGems:     15,
-		agents.GoodExotics:  20,
-		agents.GoodTools:    10,
-		agents.GoodWeapons:  15,
-		agents.GoodClothing: 8,
-		agents.GoodMedicine: 12,
-		agents.GoodLuxuries: 25,
-	}
-
-	entries := make(map[agents.GoodType]*MarketEntry, len(basePrices))
-	for good, base := range basePrices {
+	entries := make(map[agents.GoodType]*MarketEntry, len(DefaultBasePrices))
+	for i, base := range DefaultBasePrices {
+		good := agents.GoodType(i)
 		entries[good] = &MarketEntry{
 			Good:      good,
 			Supply:    1,
@@ -61,6 +66,17 @@ func NewMarket(settlementID uint64) *Market {
 	}
 }
 
+// Clone returns a deep copy of m.
+func (m *Market) Clone() *Market {
+	c := *m
+	c.Entries = make(map[agents.GoodType]*MarketEntry, len(m.Entries))
+	for good, e := range m.Entries {
+		entry := *e
+		c.Entries[good] = &entry
+	}
+	return &c
+}
+
 // ResolvePrice calculates price from supply/demand pressure mediation.
 // Price emerges from the interference pattern between conjugate pressures,
 // not from a set value. See design doc Section 16.5.1.
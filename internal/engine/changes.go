@@ -0,0 +1,252 @@
+// Domain changes: the moments between saves that reshape the world and that a
+// crash must not lose. The engine reports each one to a ChangeLog as it
+// happens; persistence journals them to disk (see persistence/wal.go) and, on
+// the next start, hands them back to ApplyChange on top of the last save.
+package engine
+
+import (
+	"slices"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// ChangeKind identifies a domain change.
+type ChangeKind uint8
+
+const (
+	ChangeAgentBorn           ChangeKind = iota + 1 // birth, refugee or immigrant
+	ChangeAgentDied                                 // any cause
+	ChangeSettlementFounded                         // diaspora founding
+	ChangeSettlementAbandoned                       // no living souls remain
+	ChangeTreasuryTransfer                          // crowns moved between treasuries
+	ChangeHexClaimed                                // a hex changed hands in battle
+	ChangeAgentRemoved                              // left the world without dying (a revert)
+)
+
+var changeKindNames = [...]string{
+	ChangeAgentBorn:           "agent_born",
+	ChangeAgentDied:           "agent_died",
+	ChangeSettlementFounded:   "settlement_founded",
+	ChangeSettlementAbandoned: "settlement_abandoned",
+	ChangeTreasuryTransfer:    "treasury_transfer",
+	ChangeHexClaimed:          "hex_claimed",
+	ChangeAgentRemoved:        "agent_removed",
+}
+
+func (k ChangeKind) String() string {
+	if int(k) < len(changeKindNames) && changeKindNames[k] != "" {
+		return changeKindNames[k]
+	}
+	return "unknown"
+}
+
+// Change is one domain change. Only the fields of its kind are set. Values are
+// the state after the change (a balance, not a delta), so applying a change
+// the world already reflects leaves it alone.
+type Change struct {
+	Seq  uint64 // position in the change stream; set by the simulation
+	Tick uint64
+	Kind ChangeKind
+
+	// ChangeAgentBorn: the agent as added. ChangeAgentDied and
+	// ChangeAgentRemoved: AgentID. Cause says why it happened ("birth",
+	// "refugee", "immigrant"; "starvation", "age", ...; "reverted").
+	Agent   *agents.Agent
+	AgentID agents.AgentID
+	Cause   string
+
+	// ChangeSettlementFounded: the settlement as founded and who moved
+	// there. ChangeSettlementAbandoned: SettlementID.
+	Settlement   *social.Settlement
+	Founders     []agents.AgentID
+	SettlementID uint64
+
+	// ChangeTreasuryTransfer: every treasury involved, with its balance
+	// after the transfer, and the crowns moved.
+	Balances []TreasuryBalance
+	Amount   uint64
+
+	// ChangeHexClaimed: the hex and its new claimant.
+	Hex       world.HexCoord
+	ClaimedBy *uint64
+}
+
+// TreasuryBalance is one treasury's balance after a transfer.
+type TreasuryBalance struct {
+	Faction bool   // a faction's treasury rather than a settlement's
+	ID      uint64 // settlement or faction ID
+	Balance uint64
+}
+
+// ChangeLog receives domain changes as they happen, on the tick-loop
+// goroutine. Record must not keep c's pointers past the call: the agent and
+// settlement go on changing.
+type ChangeLog interface {
+	Record(c Change)
+}
+
+// logChange numbers c and hands it to the change log, if one is attached.
+func (s *Simulation) logChange(c Change) {
+	if s.ChangeLog == nil {
+		return
+	}
+	s.ChangeSeq++
+	c.Seq = s.ChangeSeq
+	s.ChangeLog.Record(c)
+}
+
+// settlementBalance and factionBalance describe a treasury for a transfer.
+func settlementBalance(sett *social.Settlement) TreasuryBalance {
+	return TreasuryBalance{ID: sett.ID, Balance: sett.Treasury}
+}
+
+func factionBalance(f *social.Faction) TreasuryBalance {
+	return TreasuryBalance{Faction: true, ID: uint64(f.ID), Balance: f.Treasury}
+}
+
+// ApplyChange replays a journaled change onto the world and reports whether
+// it changed anything; a change the world already reflects is skipped. It
+// repeats what the engine did at the time, minus the events, memories and
+// side effects that are not journaled. Runs before the tick loop starts.
+func (s *Simulation) ApplyChange(c Change) bool {
+	if c.Seq > s.ChangeSeq {
+		s.ChangeSeq = c.Seq
+	}
+	switch c.Kind {
+	case ChangeAgentBorn:
+		if c.Agent == nil || s.AgentIndex[c.Agent.ID] != nil {
+			return false
+		}
+		s.indexAgent(c.Agent)
+		if s.Spawner != nil && c.Agent.ID >= s.Spawner.NextID() {
+			s.Spawner.SetNextID(c.Agent.ID + 1)
+		}
+		if c.Agent.HomeSettID != nil {
+			if sett, ok := s.SettlementIndex[*c.Agent.HomeSettID]; ok {
+				sett.Population++
+			}
+		}
+		if c.Cause == "birth" {
+			s.Stats.Births++
+		}
+		return true
+
+	case ChangeAgentDied:
+		a, ok := s.AgentIndex[c.AgentID]
+		if !ok || !a.Alive {
+			return false
+		}
+		a.Alive = false
+		a.Health = 0
+		s.Stats.Deaths++
+		s.recordDeath(a, c.Tick, c.Cause)
+		if a.HomeSettID != nil {
+			id := *a.HomeSettID
+			s.SettlementAgents[id] = slices.DeleteFunc(s.SettlementAgents[id],
+				func(b *agents.Agent) bool { return b == a })
+			if sett, ok := s.SettlementIndex[id]; ok && sett.Population > 0 {
+				sett.Population--
+			}
+		}
+		// The estate is not divided again: who inherited depends on wealth
+		// and settlement membership at the moment of death, which the
+		// journal does not carry.
+		return true
+
+	case ChangeAgentRemoved:
+		a, ok := s.AgentIndex[c.AgentID]
+		if !ok || !a.Alive {
+			return false
+		}
+		s.removeAgents(map[agents.AgentID]bool{c.AgentID: true})
+		return true
+
+	case ChangeSettlementFounded:
+		if c.Settlement == nil {
+			return false
+		}
+		if old := s.SettlementIndex[c.Settlement.ID]; old != nil {
+			if old.Position == c.Settlement.Position {
+				return false
+			}
+			// Founding reuses the ID of an abandoned settlement once the
+			// weekly compaction has dropped it; drop it here too.
+			s.compactAbandonedSettlements()
+			if s.SettlementIndex[c.Settlement.ID] != nil {
+				return false
+			}
+		}
+		founders := make([]*agents.Agent, 0, len(c.Founders))
+		for _, id := range c.Founders {
+			a, ok := s.AgentIndex[id]
+			if !ok {
+				continue
+			}
+			if a.HomeSettID != nil {
+				old := *a.HomeSettID
+				s.SettlementAgents[old] = slices.DeleteFunc(s.SettlementAgents[old],
+					func(b *agents.Agent) bool { return b == a })
+				if sett, ok := s.SettlementIndex[old]; ok && sett.Population > 0 {
+					sett.Population--
+				}
+			}
+			founders = append(founders, a)
+		}
+		sett := *c.Settlement
+		s.settleFounders(&sett, founders)
+		// The journal does not carry the parent settlement.
+		s.recordFounding(&sett, nil, founders, c.Tick)
+		return true
+
+	case ChangeSettlementAbandoned:
+		sett, ok := s.SettlementIndex[c.SettlementID]
+		if !ok {
+			return false
+		}
+		hex := s.WorldMap.Get(sett.Position)
+		if sett.Population == 0 && (hex == nil || hex.SettlementID == nil) {
+			return false
+		}
+		s.abandonSettlement(sett, c.Tick)
+		return true
+
+	case ChangeTreasuryTransfer:
+		changed := false
+		for _, b := range c.Balances {
+			var treasury *uint64
+			if b.Faction {
+				for _, f := range s.Factions {
+					if uint64(f.ID) == b.ID {
+						treasury = &f.Treasury
+					}
+				}
+			} else if sett, ok := s.SettlementIndex[b.ID]; ok {
+				treasury = &sett.Treasury
+			}
+			if treasury != nil && *treasury != b.Balance {
+				*treasury = b.Balance
+				changed = true
+			}
+		}
+		return changed
+
+	case ChangeHexClaimed:
+		hex := s.WorldMap.Get(c.Hex)
+		if hex == nil {
+			return false
+		}
+		if (hex.ClaimedBy == nil) == (c.ClaimedBy == nil) &&
+			(hex.ClaimedBy == nil || *hex.ClaimedBy == *c.ClaimedBy) {
+			return false
+		}
+		hex.ClaimedBy = nil
+		if c.ClaimedBy != nil {
+			id := *c.ClaimedBy
+			hex.ClaimedBy = &id
+		}
+		return true
+	}
+	return false
+}
@@ -280,6 +280,7 @@ func (s *Simulation) applyTier2Decision(a *agents.Agent, d llm.Tier2Decision, ti
 		for i := range a.Relationships {
 			target, ok := s.AgentIndex[a.Relationships[i].TargetID]
 			if ok && target.Name == d.Target {
+				a.Dirty |= agents.DirtyRelationships
 				a.Relationships[i].Sentiment += 0.1
 				if a.Relationships[i].Sentiment > 1.0 {
 					a.Relationships[i].Sentiment = 1.0
@@ -494,7 +495,7 @@ func (s *Simulation) processOracleVisions(tick uint64) {
 		totalOracles += len(g.members)
 		hash := s.oracleStateHash(g.settID)
 		cached, hasCached := s.LastVisions[g.settID]
-		if hasCached && cached.Hash == hash && cached.WeeksReused < oracleMaxReuseWeeks {
+		if hasCached && cached.Hash == hash && cached.WeeksReused < int(s.Tuning.OracleMaxReuseWeeks) {
 			// State-hash hit: reuse last vision without LLM call. Apply the
 			// vision's mechanics to the voice and spread the prophecy memory
 			// to all liberated agents in this settlement.
@@ -530,14 +531,9 @@ func (s *Simulation) processOracleVisions(tick uint64) {
 	)
 }
 
-// oracleMaxReuseWeeks caps how many consecutive weeks a cached vision can be
-// reused before forcing a refresh. User preference 2026-05-16: bounded reuse
-// to avoid narrative repetition while still cutting LLM cost.
-const oracleMaxReuseWeeks = 2
-
 // oracleStateHash computes a fingerprint of the load-bearing world state for
 // an oracle's settlement. Two consecutive weeks with the same hash are
-// candidates for vision reuse (subject to oracleMaxReuseWeeks cap). Fields
+// candidates for vision reuse (subject to the oracle_max_reuse_weeks knob). Fields
 // chosen to reflect oracle-relevant context without being so granular that
 // the hash never matches: season, treasury bucket (10k crowns), hostile
 // neighbor count, peace treaty count, settlement population bucket (50 pop).
@@ -926,7 +922,7 @@ func (s *Simulation) applyOracleVision(a *agents.Agent, vision *llm.OracleVision
 				// Find the claimed hex most in need.
 				var bestHex *world.Hex
 				bestHealth := 2.0 // Higher than max
-				for _, hex := range s.WorldMap.Hexes {
+				for _, hex := range sortedHexes(s.WorldMap) {
 					if hex.ClaimedBy == nil || *hex.ClaimedBy != sett.ID {
 						continue
 					}
@@ -1167,7 +1163,8 @@ func (s *Simulation) buildOracleConflicts(a *agents.Agent) string {
 	}
 
 	// Hostile relations (potential or active conflicts).
-	for key, rel := range s.Relations {
+	for _, key := range sortedRelKeys(s.Relations) {
+		rel := s.Relations[key]
 		if key.A != settID && key.B != settID {
 			continue
 		}
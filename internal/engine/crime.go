@@ -151,6 +151,7 @@ func (s *Simulation) processCrime(tick uint64) {
 
 // damageRelationship decreases sentiment and trust for a specific relationship.
 func damageRelationship(a *agents.Agent, targetID agents.AgentID, sentimentDmg, trustDmg float32) {
+	a.Dirty |= agents.DirtyRelationships
 	for i := range a.Relationships {
 		if a.Relationships[i].TargetID == targetID {
 			a.Relationships[i].Sentiment -= sentimentDmg
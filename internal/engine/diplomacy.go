@@ -93,7 +93,8 @@ func (s *Simulation) processDiplomacy(tick uint64) {
 	upgraded := 0
 	dissolved := 0
 
-	for key, rel := range s.Relations {
+	for _, key := range sortedRelKeys(s.Relations) {
+		rel := s.Relations[key]
 		agreement, exists := s.Agreements[key]
 		sentiment := rel.Sentiment
 
@@ -155,7 +156,7 @@ func (s *Simulation) processDiplomacy(tick uint64) {
 	}
 
 	// Clean up agreements for relations that no longer exist.
-	for key := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
 		if _, ok := s.Relations[key]; !ok {
 			if s.Agreements[key].Type > 0 {
 				dissolved++
@@ -240,7 +241,8 @@ func (s *Simulation) GetAgreement(settA, settB uint64) *Agreement {
 // GetSettlementAgreements returns all active agreements for a settlement.
 func (s *Simulation) GetSettlementAgreements(settID uint64) []AgreementInfo {
 	var agreements []AgreementInfo
-	for key, a := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
+		a := s.Agreements[key]
 		if a.Type == 0 {
 			continue
 		}
@@ -279,7 +281,8 @@ type AgreementInfo struct {
 // ApplyDiplomacyEffects applies agreement bonuses during the weekly cycle.
 // Called AFTER processDiplomacy, BEFORE other systems that benefit from bonuses.
 func (s *Simulation) ApplyDiplomacyEffects() {
-	for key, a := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
+		a := s.Agreements[key]
 		if a.Type == 0 {
 			continue
 		}
@@ -337,7 +340,8 @@ func (s *Simulation) BuildDiplomacyCrimeBonusCache() {
 	if s.Agreements == nil {
 		return
 	}
-	for key, a := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
+		a := s.Agreements[key]
 		if a.Type < AgreementNonAggression {
 			continue
 		}
@@ -394,7 +398,8 @@ func (s *Simulation) DiplomacySummary() map[string]any {
 	var strongestPair *AgreementInfo
 	strongestType := AgreementType(0)
 	strongestSentiment := 0.0
-	for key, a := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
+		a := s.Agreements[key]
 		if a.Type > strongestType || (a.Type == strongestType && s.Relations[key] != nil && s.Relations[key].Sentiment > strongestSentiment) {
 			nameA, nameB := "Unknown", "Unknown"
 			if sett, ok := s.SettlementIndex[key.A]; ok {
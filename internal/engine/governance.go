@@ -241,6 +241,10 @@ func (s *Simulation) checkRevolution(sett *social.Settlement, alive []*agents.Ag
 	seized := uint64(float64(sett.Treasury) * 0.3)
 	sett.Treasury -= seized
 	dominantFaction.Treasury += seized
+	if seized > 0 {
+		s.logChange(Change{Kind: ChangeTreasuryTransfer, Tick: tick, Amount: seized,
+			Balances: []TreasuryBalance{settlementBalance(sett), factionBalance(dominantFaction)}})
+	}
 
 	// Reset governance score.
 	sett.GovernanceScore = 0.5
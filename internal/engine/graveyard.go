@@ -0,0 +1,73 @@
+// The graveyard: a final snapshot of every agent who dies, taken at the moment
+// of death, before compactDeadAgents drops them. Persistence keeps them for
+// good (see persistence/graveyard.go) so the dead can still be looked up.
+package engine
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// graveMemories is how many of an agent's memories their grave keeps.
+const graveMemories = 5
+
+// Grave is a deceased agent as they were when they died.
+type Grave struct {
+	AgentID        agents.AgentID      `json:"id"`
+	Name           string              `json:"name"`
+	Sex            agents.Sex          `json:"sex"`
+	Age            uint16              `json:"age"`
+	BornTick       uint64              `json:"born_tick"`
+	DiedTick       uint64              `json:"died_tick"`
+	Cause          string              `json:"cause"`
+	Occupation     agents.Occupation   `json:"occupation"`
+	State          agents.StateOfBeing `json:"state"`
+	Coherence      float32             `json:"coherence"`
+	Wealth         uint64              `json:"wealth"`
+	SettlementID   *uint64             `json:"settlement_id,omitempty"`
+	SettlementName string              `json:"settlement_name,omitempty"`
+	FactionID      *uint64             `json:"faction_id,omitempty"`
+	FactionName    string              `json:"faction_name,omitempty"`
+	Memories       []agents.Memory     `json:"memories,omitempty"` // the most important, oldest first
+	Alive          bool                `json:"alive"`              // always false; matches the agent JSON
+}
+
+// bury queues a's grave for the next history save.
+func (s *Simulation) bury(a *agents.Agent, tick uint64, cause string) {
+	g := Grave{
+		AgentID:    a.ID,
+		Name:       a.Name,
+		Sex:        a.Sex,
+		Age:        a.Age,
+		BornTick:   a.BornTick,
+		DiedTick:   tick,
+		Cause:      cause,
+		Occupation: a.Occupation,
+		State:      a.Soul.State,
+		Coherence:  a.Soul.CittaCoherence,
+		Wealth:     a.Wealth,
+	}
+	if a.HomeSettID != nil {
+		id := *a.HomeSettID
+		g.SettlementID = &id
+		if sett, ok := s.SettlementIndex[id]; ok {
+			g.SettlementName = sett.Name
+		}
+	}
+	if a.FactionID != nil {
+		id := *a.FactionID
+		g.FactionID = &id
+		for _, f := range s.Factions {
+			if uint64(f.ID) == id {
+				g.FactionName = f.Name
+			}
+		}
+	}
+	mems := slices.Clone(a.Memories)
+	slices.SortStableFunc(mems, func(x, y agents.Memory) int { return cmp.Compare(y.Importance, x.Importance) })
+	g.Memories = mems[:min(graveMemories, len(mems))]
+	slices.SortStableFunc(g.Memories, func(x, y agents.Memory) int { return cmp.Compare(x.Tick, y.Tick) })
+	s.UnsavedGraves = append(s.UnsavedGraves, g)
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// TestDeathDigsGrave kills an agent and checks the grave and the family tree
+// record the death, with the agent's settlement, faction and their most
+// important memories in the order they happened.
+func TestDeathDigsGrave(t *testing.T) {
+	s, sett := newLedgerSim()
+	s.Factions = []*social.Faction{{ID: 4, Name: "Iron Compact"}}
+	home, faction := sett.ID, uint64(4)
+	a := &agents.Agent{ID: 7, Name: "Wren Hale", Age: 61, Alive: true, Wealth: 320,
+		HomeSettID: &home, FactionID: &faction, Occupation: agents.OccupationMerchant}
+	for i, imp := range []float32{0.2, 0.9, 0.1, 0.8, 0.7, 0.3, 0.6} {
+		agents.AddMemory(a, uint64(i), string(rune('a'+i)), imp)
+	}
+	s.addAgent(a)
+
+	a.Alive = false
+	s.handleAgentDeath(a, 500, "battle")
+
+	if len(s.UnsavedGraves) != 1 {
+		t.Fatalf("%d graves", len(s.UnsavedGraves))
+	}
+	g := s.UnsavedGraves[0]
+	if g.AgentID != 7 || g.DiedTick != 500 || g.Cause != "battle" || g.Age != 61 || g.Wealth != 320 ||
+		g.SettlementName != "Ashford" || g.FactionName != "Iron Compact" {
+		t.Errorf("grave = %+v", g)
+	}
+	var mems string
+	for _, m := range g.Memories {
+		mems += m.Content
+	}
+	if mems != "bdefg" {
+		t.Errorf("grave keeps memories %q, want the five most important in order", mems)
+	}
+	if len(s.UnsavedLineage) != 1 || s.UnsavedLineage[0].Kind != LineageDeath || s.UnsavedLineage[0].Cause != "battle" {
+		t.Errorf("lineage = %+v", s.UnsavedLineage)
+	}
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -12,9 +13,226 @@ import (
 
 // ProductionBoost is a temporary production multiplier on a settlement.
 type ProductionBoost struct {
-	SettlementID uint64
-	Multiplier   float64
-	ExpiresAt    uint64 // tick
+	SettlementID uint64  `json:"settlement_id"`
+	Multiplier   float64 `json:"multiplier"`
+	ExpiresAt    uint64  `json:"expires_at"` // tick
+}
+
+// InterventionType names an intervention command.
+type InterventionType string
+
+const (
+	InterventionEvent       InterventionType = "event"
+	InterventionWealth      InterventionType = "wealth"
+	InterventionSpawn       InterventionType = "spawn"
+	InterventionProvision   InterventionType = "provision"
+	InterventionCultivate   InterventionType = "cultivate"
+	InterventionConsolidate InterventionType = "consolidate"
+	InterventionTune        InterventionType = "tune"
+	InterventionRevert      InterventionType = "revert" // undo an earlier one; see intervention_revert.go
+)
+
+// InterventionRequest is one admin intervention, as posted to
+// /api/v1/intervention. Fields beyond Type are interpreted per type; unused
+// ones are ignored. It is also the unit the replay journal records, so the
+// JSON shape is part of the journal format.
+type InterventionRequest struct {
+	Type         InterventionType `json:"type"`
+	Description  string           `json:"description,omitempty"`
+	Category     string           `json:"category,omitempty"`
+	Settlement   string           `json:"settlement,omitempty"`
+	Amount       int64            `json:"amount,omitempty"`
+	Count        int              `json:"count,omitempty"`
+	Good         string           `json:"good,omitempty"`
+	Quantity     int              `json:"quantity,omitempty"`
+	Multiplier   float64          `json:"multiplier,omitempty"`
+	DurationDays int              `json:"duration_days,omitempty"`
+	Knob         string           `json:"knob,omitempty"`
+	Value        *float64         `json:"value,omitempty"` // pointer: zero is a valid knob value
+
+	// Revert only: the ledger ID being undone and the compensating action
+	// captured when it was applied. Carried in the request so replay can
+	// re-apply the revert without the ledger.
+	Reverts      uint64        `json:"reverts,omitempty"`
+	Compensation *Compensation `json:"compensation,omitempty"`
+}
+
+// Intervention errors callers may want to map to distinct responses.
+var (
+	ErrSettlementNotFound = errors.New("settlement not found")
+	ErrSpawnerUnavailable = errors.New("spawner not available")
+)
+
+// ValidateIntervention checks a request's required fields and operator limits
+// without touching world state, so callers can reject bad input before
+// queueing work onto the tick loop.
+func ValidateIntervention(req InterventionRequest) error {
+	switch req.Type {
+	case InterventionEvent:
+		if req.Description == "" {
+			return errors.New("description required for event type")
+		}
+	case InterventionWealth:
+		if req.Settlement == "" {
+			return errors.New("settlement required for wealth type")
+		}
+	case InterventionSpawn:
+		if req.Settlement == "" || req.Count <= 0 {
+			return errors.New("settlement and count required for spawn type")
+		}
+		if req.Count > 100 {
+			return errors.New("max 100 agents per spawn")
+		}
+	case InterventionProvision:
+		if req.Settlement == "" || req.Good == "" || req.Quantity <= 0 {
+			return errors.New("settlement, good, and quantity required for provision type")
+		}
+		if req.Quantity > 200 {
+			return errors.New("max 200 units per provision")
+		}
+	case InterventionCultivate:
+		if req.Settlement == "" || req.Multiplier <= 0 || req.DurationDays <= 0 {
+			return errors.New("settlement, multiplier, and duration_days required for cultivate type")
+		}
+		if req.Multiplier > 2.0 {
+			return errors.New("max multiplier is 2.0")
+		}
+		if req.DurationDays > 14 {
+			return errors.New("max duration is 14 days")
+		}
+	case InterventionConsolidate:
+		if req.Settlement == "" || req.Count <= 0 {
+			return errors.New("settlement and count required for consolidate type")
+		}
+		if req.Count > 100 {
+			return errors.New("max 100 agents per consolidate")
+		}
+	case InterventionTune:
+		if req.Knob == "" || req.Value == nil {
+			return errors.New("knob and value required for tune type")
+		}
+		if err := ValidateTuning(map[string]float64{req.Knob: *req.Value}); err != nil {
+			return err
+		}
+	case InterventionRevert:
+		if req.Reverts == 0 || req.Compensation == nil {
+			return errors.New("reverts and compensation required for revert type")
+		}
+	default:
+		return errors.New("unknown intervention type (use: event, wealth, spawn, provision, cultivate, consolidate, tune)")
+	}
+	return nil
+}
+
+// ApplyIntervention validates and applies one admin intervention, returning a
+// human-readable summary. It mutates world state, so it must run on the
+// tick-loop goroutine (via Engine.SubmitLoopTask) — the replay journal keys
+// each applied intervention by s.LastTick and re-applies it after the same
+// tick, which is only exact if application happens between ticks.
+func (s *Simulation) ApplyIntervention(req InterventionRequest) (string, error) {
+	desc, _, err := s.applyIntervention(req)
+	return desc, err
+}
+
+// applyIntervention is ApplyIntervention that also returns the compensating
+// action that would undo it (nil when the type cannot be reverted).
+func (s *Simulation) applyIntervention(req InterventionRequest) (string, *Compensation, error) {
+	if err := ValidateIntervention(req); err != nil {
+		return "", nil, err
+	}
+
+	capture := s.compensationCapture(req)
+	var desc string
+	var err error
+	switch req.Type {
+	case InterventionEvent:
+		cat := req.Category
+		if cat == "" {
+			cat = "intervention"
+		}
+		// Admin interventions can label events with any string — runtime cast
+		// to Category type. Preserves operator flexibility (e.g. "intervention",
+		// "experiment") at the cost of weakening compile-time category safety
+		// for this one ingestion path. Intentional.
+		s.EmitEvent(Event{
+			Tick:        s.LastTick,
+			Description: req.Description,
+			Category:    eventproto.Category(cat),
+		})
+		desc = "event injected"
+	case InterventionWealth:
+		desc, err = s.adjustTreasury(req.Settlement, req.Amount)
+	case InterventionSpawn:
+		desc, err = s.spawnImmigrants(req.Settlement, req.Count)
+	case InterventionProvision:
+		desc, err = s.ProvisionSettlement(req.Settlement, req.Good, req.Quantity)
+	case InterventionCultivate:
+		desc, err = s.CultivateSettlement(req.Settlement, req.Multiplier, req.DurationDays)
+	case InterventionConsolidate:
+		desc, err = s.ConsolidateSettlement(req.Settlement, req.Count)
+	case InterventionTune:
+		desc, err = s.SetKnob(req.Knob, *req.Value)
+	case InterventionRevert:
+		desc, err = s.applyCompensation(req.Reverts, req.Compensation)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if s.Journal != nil {
+		s.Journal.Intervention(s.LastTick, req)
+	}
+	return desc, capture(), nil
+}
+
+// adjustTreasury adds amount (possibly negative) to a settlement's treasury,
+// clamping at zero.
+func (s *Simulation) adjustTreasury(name string, amount int64) (string, error) {
+	sett := s.findSettlementByName(name)
+	if sett == nil {
+		return "", ErrSettlementNotFound
+	}
+	before := sett.Treasury
+	if amount < 0 && uint64(-amount) > sett.Treasury {
+		sett.Treasury = 0
+	} else {
+		sett.Treasury = uint64(int64(sett.Treasury) + amount)
+	}
+	s.logTreasury(sett, max(sett.Treasury, before)-min(sett.Treasury, before))
+	return fmt.Sprintf("treasury of %s adjusted by %d (now %d)", sett.Name, amount, sett.Treasury), nil
+}
+
+// logTreasury journals a settlement treasury an intervention set directly,
+// moving the given crowns in or out.
+func (s *Simulation) logTreasury(sett *social.Settlement, moved uint64) {
+	if moved > 0 {
+		s.logChange(Change{Kind: ChangeTreasuryTransfer, Tick: s.LastTick, Amount: moved,
+			Balances: []TreasuryBalance{settlementBalance(sett)}})
+	}
+}
+
+// spawnImmigrants adds count freshly spawned agents to a settlement.
+func (s *Simulation) spawnImmigrants(name string, count int) (string, error) {
+	sett := s.findSettlementByName(name)
+	if sett == nil {
+		return "", ErrSettlementNotFound
+	}
+	if s.Spawner == nil {
+		return "", ErrSpawnerUnavailable
+	}
+	hex := s.WorldMap.Get(sett.Position)
+	terrain := world.TerrainPlains
+	if hex != nil {
+		terrain = hex.Terrain
+	}
+	immigrants := s.Spawner.SpawnPopulation(uint32(count), sett.Position, sett.ID, terrain)
+	for _, a := range immigrants {
+		a.BornTick = s.LastTick
+		s.indexAgent(a)
+		s.logChange(Change{Kind: ChangeAgentBorn, Tick: s.LastTick, Agent: a, Cause: "immigrant"})
+	}
+	sett.Population += uint32(count)
+	return fmt.Sprintf("%d immigrants arrived in %s", count, sett.Name), nil
 }
 
 // ProvisionSettlement injects goods into a settlement's market supply.
@@ -92,12 +310,12 @@ func (s *Simulation) ConsolidateSettlement(name string, count int) (string, erro
 		return "", fmt.Errorf("settlement %q not found", name)
 	}
 
-	// Find nearest viable settlement (pop >= 50) within 8 hexes.
+	// Find nearest viable settlement (see viable_settlement_population) within 8 hexes.
 	var targetSett *social.Settlement
 	bestDist := 999
 
 	for _, sett := range s.Settlements {
-		if sett.ID == source.ID || sett.Population < 50 {
+		if sett.ID == source.ID || sett.Population < uint32(s.Tuning.ViableSettlementPop) {
 			continue
 		}
 		dist := world.Distance(source.Position, sett.Position)
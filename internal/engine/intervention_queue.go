@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ── Intervention queue and ledger ──────────────────────────────────
+//
+// Admin and gardener interventions are submitted as commands, not applied
+// where they arrive. SubmitIntervention runs on the tick loop (the API goes
+// through Engine.SubmitLoopTask), gives the command an ID and either applies
+// it on the spot or parks it as pending until its ApplyAt tick, when
+// ApplyDueInterventions (wired to Engine.AfterTick) picks it up. Every command
+// leaves a ledger record: who sent it, what it asked for, when it was
+// submitted and applied, what came of it, and the values it touched before
+// and after.
+
+// InterventionStatus is where a ledger record is in its lifecycle.
+type InterventionStatus string
+
+const (
+	InterventionPending  InterventionStatus = "pending"
+	InterventionApplied  InterventionStatus = "applied"
+	InterventionFailed   InterventionStatus = "failed"
+	InterventionCanceled InterventionStatus = "canceled"
+)
+
+// InterventionRecord is one ledger entry.
+type InterventionRecord struct {
+	ID            uint64              `json:"id"`
+	Actor         string              `json:"actor"`
+	Request       InterventionRequest `json:"request"`
+	Status        InterventionStatus  `json:"status"`
+	SubmittedAt   time.Time           `json:"submitted_at"`
+	SubmittedTick uint64              `json:"submitted_tick"`
+	ApplyAt       uint64              `json:"apply_at,omitempty"` // scheduled tick; 0 = at submission
+	SettledTick   uint64              `json:"settled_tick,omitempty"`
+	Result        string              `json:"result,omitempty"`
+	Error         string              `json:"error,omitempty"`
+	Before        map[string]float64  `json:"before,omitempty"`
+	After         map[string]float64  `json:"after,omitempty"`
+	Compensation  *Compensation       `json:"compensation,omitempty"` // how to undo it, if it can be
+	RevertedBy    uint64              `json:"reverted_by,omitempty"`  // ID of the revert record
+}
+
+// MaxSettledInterventions caps the settled records kept in memory (and loaded
+// on startup). Pending ones are never dropped.
+const MaxSettledInterventions = 500
+
+// Ledger lookup errors.
+var (
+	ErrInterventionNotFound   = errors.New("intervention not found")
+	ErrInterventionNotPending = errors.New("intervention is not pending")
+)
+
+// SubmitIntervention validates req and records it in the ledger. With applyAt
+// at or before the current tick it is applied immediately; otherwise it waits
+// as pending. An invalid request is rejected without a record. A request that
+// fails on application is recorded as failed, and the error is returned along
+// with the record. Must run on the tick-loop goroutine.
+func (s *Simulation) SubmitIntervention(actor string, req InterventionRequest, applyAt uint64) (*InterventionRecord, error) {
+	if err := ValidateIntervention(req); err != nil {
+		return nil, err
+	}
+	if actor == "" {
+		actor = "admin"
+	}
+	rec := &InterventionRecord{
+		ID:            s.nextInterventionID(),
+		Actor:         actor,
+		Request:       req,
+		Status:        InterventionPending,
+		SubmittedAt:   time.Now().UTC(),
+		SubmittedTick: s.LastTick,
+	}
+	s.Interventions = append(s.Interventions, rec)
+
+	if applyAt > s.LastTick {
+		rec.ApplyAt = applyAt
+		slog.Info("intervention scheduled", "id", rec.ID, "type", req.Type, "actor", actor, "apply_at", applyAt)
+		return rec, nil
+	}
+	err := s.applyRecord(rec)
+	s.trimInterventions()
+	return rec, err
+}
+
+// CancelIntervention cancels a pending intervention. Must run on the
+// tick-loop goroutine.
+func (s *Simulation) CancelIntervention(id uint64) (*InterventionRecord, error) {
+	rec := s.FindIntervention(id)
+	if rec == nil {
+		return nil, ErrInterventionNotFound
+	}
+	if rec.Status != InterventionPending {
+		return rec, ErrInterventionNotPending
+	}
+	rec.Status = InterventionCanceled
+	rec.SettledTick = s.LastTick
+	slog.Info("intervention canceled", "id", id, "type", rec.Request.Type)
+	s.trimInterventions()
+	return rec, nil
+}
+
+// ApplyDueInterventions applies pending interventions scheduled at or before
+// tick, in submission order, and returns the records it settled. Call it from
+// Engine.AfterTick so a scheduled intervention lands after its tick has fully
+// run, the same point replay re-applies journaled interventions.
+func (s *Simulation) ApplyDueInterventions(tick uint64) []*InterventionRecord {
+	var settled []*InterventionRecord
+	for _, rec := range s.Interventions {
+		if rec.Status == InterventionPending && rec.ApplyAt <= tick {
+			s.applyRecord(rec)
+			settled = append(settled, rec)
+		}
+	}
+	if len(settled) > 0 {
+		s.trimInterventions()
+	}
+	return settled
+}
+
+// FindIntervention returns the ledger record with the given ID, or nil if it
+// is unknown or has aged out of memory.
+func (s *Simulation) FindIntervention(id uint64) *InterventionRecord {
+	for _, rec := range s.Interventions {
+		if rec.ID == id {
+			return rec
+		}
+	}
+	return nil
+}
+
+// applyRecord applies a pending record's request and settles the record.
+func (s *Simulation) applyRecord(rec *InterventionRecord) error {
+	rec.Before = s.interventionProbe(rec.Request)
+	desc, comp, err := s.applyIntervention(rec.Request)
+	rec.SettledTick = s.LastTick
+	if err != nil {
+		rec.Status = InterventionFailed
+		rec.Error = err.Error()
+		slog.Warn("intervention failed", "id", rec.ID, "type", rec.Request.Type, "actor", rec.Actor, "error", err)
+		return err
+	}
+	rec.Status = InterventionApplied
+	rec.Result = desc
+	rec.Compensation = comp
+	rec.After = s.interventionProbe(rec.Request)
+	slog.Info("intervention applied", "id", rec.ID, "type", rec.Request.Type, "actor", rec.Actor)
+	return nil
+}
+
+// interventionProbe reads the values an intervention type changes, so the
+// ledger can show them before and after. Nil when there is nothing to read
+// (event injection, unknown settlement).
+func (s *Simulation) interventionProbe(req InterventionRequest) map[string]float64 {
+	if c := req.Compensation; req.Type == InterventionRevert && c != nil {
+		// A revert touches the same values as the intervention it undoes.
+		orig := InterventionRequest{Type: c.Type, Good: c.Good, Knob: c.Knob}
+		if sett, ok := s.SettlementIndex[c.SettlementID]; ok {
+			orig.Settlement = sett.Name
+		}
+		return s.interventionProbe(orig)
+	}
+	if req.Type == InterventionTune {
+		if v, ok := s.Tuning.Get(req.Knob); ok {
+			return map[string]float64{req.Knob: v}
+		}
+		return nil
+	}
+	sett := s.findSettlementByName(req.Settlement)
+	if sett == nil {
+		return nil
+	}
+	switch req.Type {
+	case InterventionWealth:
+		return map[string]float64{"treasury": float64(sett.Treasury)}
+	case InterventionSpawn:
+		return map[string]float64{
+			"population": float64(sett.Population),
+			"agents":     float64(len(s.SettlementAgents[sett.ID])),
+		}
+	case InterventionProvision:
+		good, ok := GoodTypeFromString(req.Good)
+		if !ok || sett.Market == nil || sett.Market.Entries[good] == nil {
+			return nil
+		}
+		return map[string]float64{"supply": sett.Market.Entries[good].Supply}
+	case InterventionCultivate:
+		return map[string]float64{"boost": s.GetSettlementBoost(sett.ID)}
+	case InterventionConsolidate:
+		alive := 0
+		for _, a := range s.SettlementAgents[sett.ID] {
+			if a.Alive {
+				alive++
+			}
+		}
+		return map[string]float64{"alive_agents": float64(alive)}
+	}
+	return nil
+}
+
+// nextInterventionID continues the ledger's ID sequence. The newest record is
+// never trimmed, so the last entry always carries the highest ID issued.
+func (s *Simulation) nextInterventionID() uint64 {
+	if n := len(s.Interventions); n > 0 {
+		return s.Interventions[n-1].ID + 1
+	}
+	return 1
+}
+
+// trimInterventions drops the oldest settled records beyond
+// MaxSettledInterventions. They remain in the database.
+func (s *Simulation) trimInterventions() {
+	settled := 0
+	for _, rec := range s.Interventions {
+		if rec.Status != InterventionPending {
+			settled++
+		}
+	}
+	drop := settled - MaxSettledInterventions
+	if drop <= 0 {
+		return
+	}
+	kept := s.Interventions[:0]
+	for i, rec := range s.Interventions {
+		if drop > 0 && rec.Status != InterventionPending && i < len(s.Interventions)-1 {
+			drop--
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	clear(s.Interventions[len(kept):])
+	s.Interventions = kept
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+func newLedgerSim() (*Simulation, *social.Settlement) {
+	m := world.Generate(world.GenConfig{Radius: 3, Seed: 1, SeaLevel: 0.3, MountainLvl: 0.8, Noise: world.DefaultNoiseParams()})
+	sett := &social.Settlement{ID: 1, Name: "Ashford", Treasury: 100}
+	return NewSimulation(m, nil, []*social.Settlement{sett}), sett
+}
+
+// TestInterventionLedger walks records through immediate application,
+// scheduling, cancellation and failure.
+func TestInterventionLedger(t *testing.T) {
+	s, sett := newLedgerSim()
+	s.LastTick = 1000
+	wealth := InterventionRequest{Type: InterventionWealth, Settlement: "Ashford", Amount: 50}
+
+	if _, err := s.SubmitIntervention("admin", InterventionRequest{Type: "bogus"}, 0); err == nil {
+		t.Fatal("invalid request accepted")
+	}
+	if len(s.Interventions) != 0 {
+		t.Fatal("invalid request left a ledger record")
+	}
+
+	now, err := s.SubmitIntervention("gardener", wealth, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now.ID != 1 || now.Status != InterventionApplied || now.SettledTick != 1000 || now.Actor != "gardener" {
+		t.Errorf("immediate record = %+v", now)
+	}
+	if now.Before["treasury"] != 100 || now.After["treasury"] != 150 || sett.Treasury != 150 {
+		t.Errorf("before/after = %v/%v, treasury %d", now.Before, now.After, sett.Treasury)
+	}
+
+	// A past apply_at means "now".
+	if rec, _ := s.SubmitIntervention("admin", wealth, 10); rec.Status != InterventionApplied {
+		t.Errorf("past apply_at: status %s", rec.Status)
+	}
+
+	later, _ := s.SubmitIntervention("admin", wealth, 1060)
+	doomed, _ := s.SubmitIntervention("admin", wealth, 1060)
+	if later.Status != InterventionPending || later.ApplyAt != 1060 || sett.Treasury != 200 {
+		t.Fatalf("scheduled record = %+v, treasury %d", later, sett.Treasury)
+	}
+	if _, err := s.CancelIntervention(doomed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CancelIntervention(doomed.ID); !errors.Is(err, ErrInterventionNotPending) {
+		t.Errorf("second cancel: %v", err)
+	}
+	if _, err := s.CancelIntervention(99); !errors.Is(err, ErrInterventionNotFound) {
+		t.Errorf("unknown cancel: %v", err)
+	}
+
+	if got := s.ApplyDueInterventions(1059); len(got) != 0 {
+		t.Errorf("applied %d before their tick", len(got))
+	}
+	s.LastTick = 1060
+	got := s.ApplyDueInterventions(1060)
+	if len(got) != 1 || got[0] != later || later.Status != InterventionApplied || later.SettledTick != 1060 {
+		t.Fatalf("due application settled %v, record %+v", got, later)
+	}
+	if sett.Treasury != 250 || doomed.Status != InterventionCanceled {
+		t.Errorf("treasury %d, canceled record %s", sett.Treasury, doomed.Status)
+	}
+
+	// Valid on its face, fails against world state: recorded as failed.
+	missing := InterventionRequest{Type: InterventionWealth, Settlement: "Nowhere", Amount: 1}
+	rec, err := s.SubmitIntervention("admin", missing, 0)
+	if !errors.Is(err, ErrSettlementNotFound) || rec == nil || rec.Status != InterventionFailed || rec.Error == "" {
+		t.Errorf("failed record = %+v, err %v", rec, err)
+	}
+	if rec.ID != 5 {
+		t.Errorf("ids not sequential: got %d, want 5", rec.ID)
+	}
+}
+
+// TestTrimInterventionsKeepsPending verifies the memory cap drops only the
+// oldest settled records.
+func TestTrimInterventionsKeepsPending(t *testing.T) {
+	s, _ := newLedgerSim()
+	event := InterventionRequest{Type: InterventionEvent, Description: "a comet"}
+	pending, _ := s.SubmitIntervention("admin", event, 5000)
+	for range MaxSettledInterventions + 10 {
+		s.SubmitIntervention("admin", event, 0)
+	}
+	if len(s.Interventions) != MaxSettledInterventions+1 {
+		t.Fatalf("ledger holds %d records, want %d", len(s.Interventions), MaxSettledInterventions+1)
+	}
+	if s.Interventions[0] != pending {
+		t.Error("pending record was trimmed")
+	}
+	if last := s.Interventions[len(s.Interventions)-1].ID; last != MaxSettledInterventions+11 {
+		t.Errorf("newest id = %d", last)
+	}
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// ── Reverting interventions ─────────────────────────────────────────
+//
+// When an intervention is applied, the ledger also records a Compensation:
+// the action that would undo it, worked out from what the intervention
+// actually changed (a clamped treasury delta, the IDs of the immigrants it
+// spawned, the exact boost it added). Reverting submits that compensation as
+// an intervention of its own, so the undo gets a ledger record, lands between
+// ticks, and is journaled for replay like anything else.
+//
+// Only the part that is still in the world can be taken back: crowns the
+// treasury has since spent, goods already sold and immigrants who have died
+// are gone. Consolidation and event injection have no compensation.
+
+// Compensation undoes one applied intervention.
+type Compensation struct {
+	Type          InterventionType `json:"type"` // type of the intervention it undoes
+	SettlementID  uint64           `json:"settlement_id,omitempty"`
+	TreasuryDelta int64            `json:"treasury_delta,omitempty"` // wealth: change actually applied
+	Good          string           `json:"good,omitempty"`           // provision
+	Quantity      float64          `json:"quantity,omitempty"`       // provision: units added
+	AgentIDs      []agents.AgentID `json:"agent_ids,omitempty"`      // spawn: immigrants created
+	Boost         *ProductionBoost `json:"boost,omitempty"`          // cultivate: boost added
+	Knob          string           `json:"knob,omitempty"`           // tune
+	Value         *float64         `json:"value,omitempty"`          // tune: value to restore
+}
+
+// Revert errors.
+var (
+	ErrNotRevertible   = errors.New("intervention cannot be reverted")
+	ErrAlreadyReverted = errors.New("intervention already reverted")
+)
+
+// RevertIntervention undoes an applied intervention by submitting its
+// compensation, and returns the ledger record of the revert. Must run on the
+// tick-loop goroutine.
+func (s *Simulation) RevertIntervention(actor string, id uint64) (*InterventionRecord, error) {
+	orig := s.FindIntervention(id)
+	if orig == nil {
+		return nil, ErrInterventionNotFound
+	}
+	if orig.RevertedBy != 0 {
+		return nil, fmt.Errorf("%w (by #%d)", ErrAlreadyReverted, orig.RevertedBy)
+	}
+	if orig.Status != InterventionApplied || orig.Compensation == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRevertible, orig.Status, orig.Request.Type)
+	}
+
+	req := InterventionRequest{Type: InterventionRevert, Reverts: id, Compensation: orig.Compensation}
+	rec, err := s.SubmitIntervention(actor, req, 0)
+	if err == nil {
+		orig.RevertedBy = rec.ID
+	}
+	return rec, err
+}
+
+// compensationCapture notes what applying req is about to change. The
+// returned function, called after a successful apply, builds the
+// compensation from the difference; it returns nil for types that cannot be
+// reverted.
+func (s *Simulation) compensationCapture(req InterventionRequest) func() *Compensation {
+	none := func() *Compensation { return nil }
+	if req.Type == InterventionTune {
+		old, ok := s.Tuning.Get(req.Knob)
+		if !ok {
+			return none
+		}
+		return func() *Compensation {
+			return &Compensation{Type: req.Type, Knob: req.Knob, Value: &old}
+		}
+	}
+
+	sett := s.findSettlementByName(req.Settlement)
+	if sett == nil {
+		return none
+	}
+	switch req.Type {
+	case InterventionWealth:
+		before := sett.Treasury
+		return func() *Compensation {
+			return &Compensation{Type: req.Type, SettlementID: sett.ID, TreasuryDelta: int64(sett.Treasury) - int64(before)}
+		}
+	case InterventionSpawn:
+		// spawnImmigrants appends to s.Agents, so the new ones are the tail.
+		n := len(s.Agents)
+		return func() *Compensation {
+			c := &Compensation{Type: req.Type, SettlementID: sett.ID}
+			for _, a := range s.Agents[n:] {
+				c.AgentIDs = append(c.AgentIDs, a.ID)
+			}
+			return c
+		}
+	case InterventionProvision:
+		return func() *Compensation {
+			return &Compensation{Type: req.Type, SettlementID: sett.ID, Good: req.Good, Quantity: float64(req.Quantity)}
+		}
+	case InterventionCultivate:
+		return func() *Compensation {
+			b := s.ActiveBoosts[len(s.ActiveBoosts)-1]
+			return &Compensation{Type: req.Type, SettlementID: sett.ID, Boost: &b}
+		}
+	}
+	return none
+}
+
+// applyCompensation carries out a compensation and emits a gardener event
+// saying what was taken back.
+func (s *Simulation) applyCompensation(reverts uint64, c *Compensation) (string, error) {
+	if c.Type == InterventionTune {
+		if _, err := s.SetKnob(c.Knob, *c.Value); err != nil {
+			return "", err
+		}
+		desc := fmt.Sprintf("The adjustment to %s is undone; it returns to %g", c.Knob, *c.Value)
+		s.emitRevertEvent(reverts, desc, nil)
+		return desc, nil
+	}
+
+	sett, ok := s.SettlementIndex[c.SettlementID]
+	if !ok {
+		return "", ErrSettlementNotFound
+	}
+
+	var desc string
+	switch c.Type {
+	case InterventionWealth:
+		if c.TreasuryDelta >= 0 {
+			taken := min(uint64(c.TreasuryDelta), sett.Treasury)
+			sett.Treasury -= taken
+			s.logTreasury(sett, taken)
+			desc = fmt.Sprintf("The grant to %s is recalled: %d of %d crowns leave its treasury", sett.Name, taken, c.TreasuryDelta)
+		} else {
+			sett.Treasury += uint64(-c.TreasuryDelta)
+			s.logTreasury(sett, uint64(-c.TreasuryDelta))
+			desc = fmt.Sprintf("The levy on %s is refunded: %d crowns return to its treasury", sett.Name, -c.TreasuryDelta)
+		}
+
+	case InterventionProvision:
+		good, ok := GoodTypeFromString(c.Good)
+		if !ok || sett.Market == nil || sett.Market.Entries[good] == nil {
+			return "", fmt.Errorf("good %q not in market for %q", c.Good, sett.Name)
+		}
+		entry := sett.Market.Entries[good]
+		removed := min(c.Quantity, entry.Supply)
+		entry.Supply -= removed
+		desc = fmt.Sprintf("The caravan leaves %s, taking back %.0f of the %.0f units of %s still unsold", sett.Name, removed, c.Quantity, c.Good)
+
+	case InterventionSpawn:
+		removed := s.despawn(c.AgentIDs)
+		desc = fmt.Sprintf("%d of the %d immigrants who came to %s move on", removed, len(c.AgentIDs), sett.Name)
+
+	case InterventionCultivate:
+		desc = fmt.Sprintf("The bountiful season in %s had already ended", sett.Name)
+		for i, b := range s.ActiveBoosts {
+			if b == *c.Boost {
+				s.ActiveBoosts = append(s.ActiveBoosts[:i], s.ActiveBoosts[i+1:]...)
+				desc = fmt.Sprintf("The bountiful season in %s ends early", sett.Name)
+				break
+			}
+		}
+
+	default:
+		return "", fmt.Errorf("%w: %s", ErrNotRevertible, c.Type)
+	}
+
+	s.emitRevertEvent(reverts, desc, map[string]any{"settlement_name": sett.Name})
+	return desc, nil
+}
+
+// despawn removes the given agents if they are still alive and returns how
+// many it removed. Unlike a death nothing is inherited or counted; the agents
+// simply leave the world.
+func (s *Simulation) despawn(ids []agents.AgentID) int {
+	gone := make(map[agents.AgentID]bool, len(ids))
+	for _, id := range ids {
+		if a, ok := s.AgentIndex[id]; ok && a.Alive && !gone[id] {
+			gone[id] = true
+			s.logChange(Change{Kind: ChangeAgentRemoved, Tick: s.LastTick, AgentID: id, Cause: "reverted"})
+		}
+	}
+	if len(gone) == 0 {
+		return 0
+	}
+	s.removeAgents(gone)
+	return len(gone)
+}
+
+// removeAgents drops the agents in gone from the world.
+func (s *Simulation) removeAgents(gone map[agents.AgentID]bool) {
+	kept := s.Agents[:0]
+	for _, a := range s.Agents {
+		if gone[a.ID] {
+			delete(s.AgentIndex, a.ID)
+			continue
+		}
+		kept = append(kept, a)
+	}
+	clear(s.Agents[len(kept):])
+	s.Agents = kept
+	s.rebuildSettlementAgents()
+}
+
+func (s *Simulation) emitRevertEvent(reverts uint64, desc string, meta map[string]any) {
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta["reverts"] = reverts
+	s.EmitEvent(Event{
+		Tick:        s.LastTick,
+		Description: desc,
+		Category:    eventproto.CategoryGardener,
+		Meta:        meta,
+	})
+	slog.Info("intervention reverted", "reverts", reverts, "details", desc)
+}
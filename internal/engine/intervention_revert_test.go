@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// TestRevertIntervention applies each revertible type, lets the world
+// consume part of it, and checks the revert takes back only what is left.
+func TestRevertIntervention(t *testing.T) {
+	s, sett := newLedgerSim()
+	s.Spawner = agents.NewSpawner(1)
+	grain := sett.Market.Entries[agents.GoodGrain]
+	grain.Supply = 10
+
+	submit := func(req InterventionRequest) *InterventionRecord {
+		t.Helper()
+		rec, err := s.SubmitIntervention("gardener", req, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	revert := func(id uint64) *InterventionRecord {
+		t.Helper()
+		rec, err := s.RevertIntervention("admin", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	t.Run("wealth", func(t *testing.T) {
+		orig := submit(InterventionRequest{Type: InterventionWealth, Settlement: "Ashford", Amount: 80})
+		sett.Treasury -= 150 // spent down to 30
+		rec := revert(orig.ID)
+		if sett.Treasury != 0 || rec.Before["treasury"] != 30 || rec.After["treasury"] != 0 {
+			t.Errorf("treasury %d, revert before/after %v/%v", sett.Treasury, rec.Before, rec.After)
+		}
+		if orig.RevertedBy != rec.ID || rec.Request.Reverts != orig.ID {
+			t.Errorf("records not linked: %d ↔ %d", orig.RevertedBy, rec.Request.Reverts)
+		}
+		if _, err := s.RevertIntervention("admin", orig.ID); !errors.Is(err, ErrAlreadyReverted) {
+			t.Errorf("second revert: %v", err)
+		}
+	})
+
+	t.Run("provision", func(t *testing.T) {
+		orig := submit(InterventionRequest{Type: InterventionProvision, Settlement: "Ashford", Good: "grain", Quantity: 50})
+		grain.Supply -= 40 // 60 → 20
+		revert(orig.ID)
+		if grain.Supply != 0 {
+			t.Errorf("grain supply = %g, want 0", grain.Supply)
+		}
+	})
+
+	t.Run("spawn", func(t *testing.T) {
+		orig := submit(InterventionRequest{Type: InterventionSpawn, Settlement: "Ashford", Count: 3})
+		ids := orig.Compensation.AgentIDs
+		if len(ids) != 3 || len(s.Agents) != 3 {
+			t.Fatalf("compensation ids %v, %d agents", ids, len(s.Agents))
+		}
+		s.AgentIndex[ids[0]].Alive = false
+		rec := revert(orig.ID)
+		if len(s.Agents) != 1 || s.AgentIndex[ids[1]] != nil || s.AgentIndex[ids[2]] != nil {
+			t.Errorf("%d agents remain after despawn", len(s.Agents))
+		}
+		if rec.Result == "" {
+			t.Error("empty revert result")
+		}
+	})
+
+	t.Run("cultivate", func(t *testing.T) {
+		orig := submit(InterventionRequest{Type: InterventionCultivate, Settlement: "Ashford", Multiplier: 1.5, DurationDays: 3})
+		revert(orig.ID)
+		if len(s.ActiveBoosts) != 0 || s.GetSettlementBoost(sett.ID) != 1 {
+			t.Errorf("boosts left: %+v", s.ActiveBoosts)
+		}
+	})
+
+	t.Run("tune", func(t *testing.T) {
+		v := 7.0
+		orig := submit(InterventionRequest{Type: InterventionTune, Knob: "raid_max_distance", Value: &v})
+		revert(orig.ID)
+		if got, _ := s.Tuning.Get("raid_max_distance"); got != 5 {
+			t.Errorf("raid_max_distance = %g, want 5", got)
+		}
+	})
+
+	t.Run("not revertible", func(t *testing.T) {
+		orig := submit(InterventionRequest{Type: InterventionEvent, Description: "a comet"})
+		if _, err := s.RevertIntervention("admin", orig.ID); !errors.Is(err, ErrNotRevertible) {
+			t.Errorf("event revert: %v", err)
+		}
+		rev := s.FindIntervention(1).RevertedBy
+		if _, err := s.RevertIntervention("admin", rev); !errors.Is(err, ErrNotRevertible) {
+			t.Errorf("revert of a revert: %v", err)
+		}
+	})
+
+	reversals := 0
+	for _, e := range s.Events {
+		if e.Meta["reverts"] != nil {
+			reversals++
+			if e.Category != eventproto.CategoryGardener {
+				t.Errorf("reversal event category %q", e.Category)
+			}
+		}
+	}
+	if reversals != 5 {
+		t.Errorf("%d reversal events, want 5", reversals)
+	}
+}
@@ -0,0 +1,127 @@
+// Deterministic replay support: the input-journal seam and the world state hash.
+// The journal file format and the record/replay drivers live in internal/replay;
+// the engine only knows the Journal interface below.
+package engine
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/talgya/mini-world/internal/entropy"
+	"github.com/talgya/mini-world/internal/weather"
+)
+
+// Journal records — and during replay, supplies — every input the simulation
+// takes from outside its own seeded state: entropy floats, weather conditions
+// and admin interventions. LLM completions are journaled one layer down, in
+// llm.Client (see llm.Recorder), because API handlers share the same client.
+//
+// Recording implementations call live and persist its result; replay
+// implementations ignore live and return the recorded value for the same
+// position in the stream. All methods are called from the tick-loop goroutine.
+type Journal interface {
+	// Entropy returns the next true-random float drawn at tick.
+	Entropy(tick uint64, live func() float64) float64
+	// Weather returns the real-world conditions fetched at tick.
+	Weather(tick uint64, live func() (*weather.Conditions, error)) (*weather.Conditions, error)
+	// Intervention notes an admin intervention applied at tick.
+	Intervention(tick uint64, req InterventionRequest)
+	// DayHash notes the end-of-day StateHash. Replay implementations compare
+	// it against the recording and return an error on divergence.
+	DayHash(tick uint64, hash uint64) error
+}
+
+// entropyFloat draws one true-random float, routed through the journal when
+// one is attached so replays see the same stream.
+func (s *Simulation) entropyFloat() float64 {
+	live := func() float64 { return entropy.FloatFromSource(s.Entropy) }
+	if s.Journal == nil {
+		return live()
+	}
+	return s.Journal.Entropy(s.LastTick, live)
+}
+
+// fetchWeather fetches real conditions, routed through the journal when one is
+// attached. Replays never touch the network: the recorded conditions (or the
+// recorded failure) are returned instead.
+func (s *Simulation) fetchWeather() (*weather.Conditions, error) {
+	if s.Journal == nil {
+		return s.WeatherClient.Fetch()
+	}
+	return s.Journal.Weather(s.LastTick, s.WeatherClient.Fetch)
+}
+
+// StateHash returns a 64-bit FNV-1a digest of the mutable world state: clock,
+// agents, settlements (including markets), factions, inter-settlement
+// relations, hex health/resources and the daily stats. Collections are hashed
+// in sorted-key order so the digest depends only on content, never on slice or
+// map order. Two simulations that hash equal at the end of a sim-day are, for
+// replay purposes, the same world.
+//
+// Intended for the tick-loop goroutine (or a loop task); it walks every agent.
+func (s *Simulation) StateHash() uint64 {
+	h := fnv.New64a()
+	enc := json.NewEncoder(h)
+	var buf [8]byte
+	putU64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	putF64 := func(v float64) { putU64(math.Float64bits(v)) }
+
+	putU64(s.LastTick)
+	putU64(uint64(s.CurrentSeason))
+	putU64(uint64(s.LiberatedSpiritsPool))
+
+	sortedAgents := make([]int, len(s.Agents))
+	for i := range sortedAgents {
+		sortedAgents[i] = i
+	}
+	sort.Slice(sortedAgents, func(i, j int) bool {
+		return s.Agents[sortedAgents[i]].ID < s.Agents[sortedAgents[j]].ID
+	})
+	for _, i := range sortedAgents {
+		enc.Encode(s.Agents[i])
+	}
+
+	settIdx := make([]int, len(s.Settlements))
+	for i := range settIdx {
+		settIdx[i] = i
+	}
+	sort.Slice(settIdx, func(i, j int) bool {
+		return s.Settlements[settIdx[i]].ID < s.Settlements[settIdx[j]].ID
+	})
+	for _, i := range settIdx {
+		st := s.Settlements[i]
+		enc.Encode(st)
+		if st.Market != nil {
+			enc.Encode(st.Market) // entries map is keyed by good, so JSON sorts it
+		}
+	}
+
+	for _, f := range s.Factions {
+		enc.Encode(f)
+	}
+
+	for _, k := range sortedRelKeys(s.Relations) {
+		rel := s.Relations[k]
+		putU64(k.A)
+		putU64(k.B)
+		putF64(rel.Sentiment)
+		putF64(rel.Trade)
+	}
+
+	if s.WorldMap != nil {
+		for _, hex := range sortedHexes(s.WorldMap) {
+			putF64(hex.Health)
+			putU64(hex.LastExtractedTick)
+			enc.Encode(hex.Resources)
+		}
+	}
+
+	enc.Encode(s.Stats)
+	return h.Sum64()
+}
@@ -76,6 +76,7 @@ func (s *Simulation) releaseSettlementClaims(settID uint64) {
 // Governance quality affects investment likelihood: GovernanceScore must exceed Psyche (0.382).
 func (s *Simulation) processLandInvestment(tick uint64) {
 	invested := 0
+	hexes := sortedHexes(s.WorldMap)
 	for _, sett := range s.Settlements {
 		if sett.Population == 0 || sett.Treasury < 100 {
 			continue
@@ -102,7 +103,7 @@ func (s *Simulation) processLandInvestment(tick uint64) {
 		bestScore := math.MaxFloat64
 		upgradeType := "" // "irrigation" or "conservation"
 
-		for _, hex := range s.WorldMap.Hexes {
+		for _, hex := range hexes {
 			if hex.ClaimedBy == nil || *hex.ClaimedBy != sett.ID {
 				continue
 			}
@@ -189,7 +190,8 @@ func (s *Simulation) processLandInvestment(tick uint64) {
 func (s *Simulation) processInfrastructureDecay(tick uint64) {
 	simWeek := tick / TicksPerSimWeek
 	decayed := 0
-	for coord, hex := range s.WorldMap.Hexes {
+	for _, hex := range sortedHexes(s.WorldMap) {
+		coord := hex.Coord
 		if hex.IrrigationLevel == 0 && hex.ConservationLevel == 0 {
 			continue
 		}
@@ -0,0 +1,79 @@
+// Lineage: who was born to whom, who married whom, and when each of them
+// died. The live agent slice forgets the dead at the next compaction, so the
+// engine hands these facts to persistence as they happen (see
+// persistence/lineage.go), which keeps the family tree for good.
+package engine
+
+import (
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// LineageKind identifies a lineage record.
+type LineageKind uint8
+
+const (
+	LineageBirth    LineageKind = iota + 1 // Person born to Kin
+	LineageMarriage                        // Person wed Kin[0]
+	LineageDeath                           // Person died of Cause
+)
+
+// Kin identifies a person in the family tree. Names and birth ticks are kept
+// with it because the agent itself will not outlive compaction.
+type Kin struct {
+	ID       agents.AgentID
+	Name     string
+	BornTick uint64
+}
+
+// LineageRecord is one fact for the family tree.
+type LineageRecord struct {
+	Tick   uint64
+	Kind   LineageKind
+	Person Kin
+	Kin    []Kin  // LineageBirth: the parents. LineageMarriage: the spouse.
+	Cause  string // LineageDeath only
+}
+
+func kinOf(a *agents.Agent) Kin {
+	return Kin{ID: a.ID, Name: a.Name, BornTick: a.BornTick}
+}
+
+// recordLineage queues r for the next history save.
+func (s *Simulation) recordLineage(r LineageRecord) {
+	s.UnsavedLineage = append(s.UnsavedLineage, r)
+}
+
+// recordBirth records child as born to parent and, when parent has one, to
+// their partner.
+func (s *Simulation) recordBirth(child, parent *agents.Agent, tick uint64) {
+	parents := []Kin{kinOf(parent)}
+	if partner := s.partnerOf(parent); partner != nil {
+		parents = append(parents, kinOf(partner))
+	}
+	s.recordLineage(LineageRecord{Tick: tick, Kind: LineageBirth, Person: kinOf(child), Kin: parents})
+}
+
+// recordDeath records a's death in the family tree and buries them.
+func (s *Simulation) recordDeath(a *agents.Agent, tick uint64, cause string) {
+	s.recordLineage(LineageRecord{Tick: tick, Kind: LineageDeath, Person: kinOf(a), Cause: cause})
+	s.bury(a, tick, cause)
+}
+
+// partnerOf returns a's partner: the living adult of the other sex with whom a
+// shares a family-level bond (the bond formFamilies creates), the strongest
+// if there are several. Nil if a has none.
+func (s *Simulation) partnerOf(a *agents.Agent) *agents.Agent {
+	var best *agents.Agent
+	bestSentiment := float32(0)
+	for _, rel := range a.Relationships {
+		if rel.Sentiment <= 0.7 || rel.Trust <= 0.5 || rel.Sentiment <= bestSentiment {
+			continue
+		}
+		p, ok := s.AgentIndex[rel.TargetID]
+		if !ok || !p.Alive || p.Age < 18 || p.Sex == a.Sex {
+			continue
+		}
+		best, bestSentiment = p, rel.Sentiment
+	}
+	return best
+}
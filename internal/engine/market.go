@@ -153,7 +153,10 @@ func resolveSettlementMarket(sett *social.Settlement, settAgents []*agents.Agent
 	maxTraded := 0
 
 	// Match orders per good: sells ascending, buys descending, match until prices cross.
-	for good, entry := range market.Entries {
+	// Goods clear in a fixed order: a buyer's wealth spent on one good limits
+	// what it can afford of the next.
+	for _, good := range sortedKeys(market.Entries) {
+		entry := market.Entries[good]
 		// Filter orders for this good.
 		var sells []Order
 		for _, o := range sellOrders {
@@ -167,6 +170,7 @@ func resolveSettlementMarket(sett *social.Settlement, settAgents []*agents.Agent
 				buys = append(buys, o)
 			}
 		}
+		entry.Traded = 0
 		if len(sells) == 0 || len(buys) == 0 {
 			continue
 		}
@@ -255,6 +259,7 @@ func resolveSettlementMarket(sett *social.Settlement, settAgents []*agents.Agent
 		}
 
 		// Accumulate trade stats for API consumption.
+		entry.Traded = totalTraded
 		market.TradeCount += totalTraded
 		if totalTraded > maxTraded {
 			maxTraded = totalTraded
@@ -442,7 +447,7 @@ func (s *Simulation) collectTaxes(tick uint64) {
 				continue
 			}
 			taxable := a.Wealth - taxThreshold
-			tax := uint64(float64(taxable) * sett.TaxRate * phi.Agnosis) // ~2.4% of taxable
+			tax := uint64(float64(taxable) * sett.TaxRate * phi.Agnosis * s.Tuning.TaxScale) // ~2.4% of taxable
 			if tax < 1 {
 				tax = 1
 			}
@@ -858,7 +863,8 @@ func (s *Simulation) resolveMerchantTrade(tick uint64) {
 				// welcome merchants, isolationist ones discourage them.
 				// Averages source and destination openness: +Agnosis*0.2 per point (max ±4.7%).
 				opennessMod := 1.0 + float64(sett.CultureOpenness+neighbor.CultureOpenness)*0.5*phi.Agnosis*0.2
-				for good, homeEntry := range sett.Market.Entries {
+				for _, good := range sortedKeys(sett.Market.Entries) {
+					homeEntry := sett.Market.Entries[good]
 					destEntry, ok := neighbor.Market.Entries[good]
 					if !ok {
 						continue
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// Deterministic iteration helpers. Go randomizes map iteration order, so any
+// loop over a world-state map whose iterations interact — raids draining the
+// same treasury, agreements forming against a shared cap, events emitted in
+// sequence — produces a different world on every run. Replay (journal.go)
+// needs bit-identical runs, so state-mutating loops iterate these sorted key
+// slices instead of ranging the map directly. Read-only aggregations that only
+// sum integers can keep ranging the map.
+
+// sortedKeys returns m's keys in ascending order.
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// sortedRelKeys returns the settlement-pair keys of m ordered by (A, B).
+func sortedRelKeys[V any](m map[SettRelKey]V) []SettRelKey {
+	keys := make([]SettRelKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(x, y SettRelKey) int {
+		if c := cmp.Compare(x.A, y.A); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.B, y.B)
+	})
+	return keys
+}
+
+// sortedHexes returns every hex of m ordered by (Q, R).
+func sortedHexes(m *world.Map) []*world.Hex {
+	hexes := make([]*world.Hex, 0, len(m.Hexes))
+	for _, h := range m.Hexes {
+		hexes = append(hexes, h)
+	}
+	slices.SortFunc(hexes, func(x, y *world.Hex) int {
+		if c := cmp.Compare(x.Coord.Q, y.Coord.Q); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Coord.R, y.Coord.R)
+	})
+	return hexes
+}
@@ -57,7 +57,8 @@ func (s *Simulation) processPeace(tick uint64) {
 	expired := 0
 
 	// Check for new peace treaties from raid exhaustion.
-	for key, count := range s.RaidCounts {
+	for _, key := range sortedRelKeys(s.RaidCounts) {
+		count := s.RaidCounts[key]
 		if count >= peaceRaidThreshold {
 			if _, hasPeace := s.PeaceTreaties[key]; !hasPeace {
 				s.PeaceTreaties[key] = &PeaceTreaty{
@@ -73,7 +74,8 @@ func (s *Simulation) processPeace(tick uint64) {
 	}
 
 	// Tick existing treaties and apply sentiment recovery.
-	for key, treaty := range s.PeaceTreaties {
+	for _, key := range sortedRelKeys(s.PeaceTreaties) {
+		treaty := s.PeaceTreaties[key]
 		treaty.RemainingWeeks--
 
 		// Sentiment recovery during peace: +Agnosis × 0.1 per week.
@@ -160,7 +162,8 @@ func (s *Simulation) emitPeaceEvent(tick uint64, key SettRelKey, action string)
 // GetSettlementPeace returns active peace treaties for a settlement.
 func (s *Simulation) GetSettlementPeace(settID uint64) []PeaceTreatyInfo {
 	var treaties []PeaceTreatyInfo
-	for key, treaty := range s.PeaceTreaties {
+	for _, key := range sortedRelKeys(s.PeaceTreaties) {
+		treaty := s.PeaceTreaties[key]
 		if key.A == settID || key.B == settID {
 			otherID := key.B
 			if key.B == settID {
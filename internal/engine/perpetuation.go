@@ -213,14 +213,14 @@ func (s *Simulation) processSeasonalMigration(tick uint64) {
 	}
 }
 
-// findNearestViableSettlement finds the closest settlement with pop >= 50
-// within maxDist hex distance. Returns nil if none found.
+// findNearestViableSettlement finds the closest settlement with at least
+// Tuning.ViableSettlementPop residents within maxDist hex distance. Returns nil if none found.
 func (s *Simulation) findNearestViableSettlement(from *social.Settlement, maxDist int) *social.Settlement {
 	var best *social.Settlement
 	bestDist := maxDist + 1
 
 	for _, sett := range s.Settlements {
-		if sett.ID == from.ID || sett.Population < 50 {
+		if sett.ID == from.ID || sett.Population < uint32(s.Tuning.ViableSettlementPop) {
 			continue
 		}
 		dist := world.Distance(from.Position, sett.Position)
@@ -245,7 +245,8 @@ func (s *Simulation) reassignIfMismatched(a *agents.Agent, settID uint64) {
 // to non-producer occupations when the settlement has more producers than
 // its carrying capacity can support. Runs weekly via processAntiStagnation.
 func (s *Simulation) rebalanceSettlementProducers(tick uint64) {
-	for settID, settAgents := range s.SettlementAgents {
+	for _, settID := range sortedKeys(s.SettlementAgents) {
+		settAgents := s.SettlementAgents[settID]
 		target := s.settlementProducerTarget(settID)
 
 		// Count current resource producers.
@@ -426,7 +427,8 @@ func (s *Simulation) processResourceMigration(tick uint64) {
 	twoWeeks := uint64(TicksPerSimDay * 14)
 	migrated := false
 
-	for settID, settAgents := range s.SettlementAgents {
+	for _, settID := range sortedKeys(s.SettlementAgents) {
+		settAgents := s.SettlementAgents[settID]
 		sett, ok := s.SettlementIndex[settID]
 		if !ok {
 			continue
@@ -584,7 +586,8 @@ func resourceName(r world.ResourceType) string {
 func (s *Simulation) processCrafterRecovery(tick uint64) {
 	oneWeek := uint64(TicksPerSimDay * 7)
 
-	for settID, settAgents := range s.SettlementAgents {
+	for _, settID := range sortedKeys(s.SettlementAgents) {
+		settAgents := s.SettlementAgents[settID]
 		sett, ok := s.SettlementIndex[settID]
 		if !ok {
 			continue
@@ -748,7 +751,8 @@ func (s *Simulation) processCareerTransition(tick uint64) {
 	thirtyDays := uint64(TicksPerSimDay * 30)
 	sixtyDays := uint64(TicksPerSimDay * 60)
 
-	for settID, settAgents := range s.SettlementAgents {
+	for _, settID := range sortedKeys(s.SettlementAgents) {
+		settAgents := s.SettlementAgents[settID]
 		sett, ok := s.SettlementIndex[settID]
 		if !ok {
 			continue
@@ -835,9 +839,10 @@ func skillAdjacentOccupation(occ agents.Occupation) agents.Occupation {
 // is expensive and the settlement has Coast hexes, some farmers retrain as fishers
 // (and vice versa). Cap: Agnosis fraction (~24%) of the surplus occupation per week.
 func (s *Simulation) processFoodRetraining(tick uint64) {
-	for settID, settAgents := range s.SettlementAgents {
+	for _, settID := range sortedKeys(s.SettlementAgents) {
+		settAgents := s.SettlementAgents[settID]
 		sett, ok := s.SettlementIndex[settID]
-		if !ok || sett.Market == nil || sett.Population < 50 {
+		if !ok || sett.Market == nil || sett.Population < uint32(s.Tuning.FoodRetrainingMinPop) {
 			continue
 		}
 
@@ -128,7 +128,7 @@ func (s *Simulation) processNaturalDeaths(tick uint64) {
 		}
 
 		// Coherence-scaled mortality: background entropy + age curve + overcap pressure.
-		mortalityChance := agentDailyMortalityChance(a, s.Stats.TotalPopulation)
+		mortalityChance := agentDailyMortalityChance(a, s.Stats.TotalPopulation, &s.Tuning)
 
 		if mortalityChance > 0 {
 			// Deterministic check: hash agent ID with simDay for stable daily result.
@@ -145,6 +145,8 @@ func (s *Simulation) processNaturalDeaths(tick uint64) {
 					cause = "age"
 					desc = fmt.Sprintf("%s has died of old age at %d", a.Name, a.Age)
 				}
+				s.logChange(Change{Kind: ChangeAgentDied, Tick: tick, AgentID: a.ID, Cause: cause})
+				s.recordDeath(a, tick, cause)
 
 				isLiberated := a.Soul.State == agents.Liberated
 				if isLiberated {
@@ -251,6 +253,8 @@ func (s *Simulation) processNaturalDeaths(tick uint64) {
 			if a.Health <= 0 {
 				a.Alive = false
 				s.Stats.Deaths++
+				s.logChange(Change{Kind: ChangeAgentDied, Tick: tick, AgentID: a.ID, Cause: "illness"})
+				s.recordDeath(a, tick, "illness")
 				s.EmitEvent(Event{
 					Tick:        tick,
 					Description: fmt.Sprintf("%s has died of illness", a.Name),
@@ -316,7 +320,7 @@ func (s *Simulation) processNaturalDeaths(tick uint64) {
 // Tuning: if too aggressive, reduce background by one Φ power (Agnosis⁷ base).
 // If too slow, increase age scale to Agnosis². Onset (50) and steepness (12)
 // can also be adjusted independently.
-func agentDailyMortalityChance(a *agents.Agent, population int) float64 {
+func agentDailyMortalityChance(a *agents.Agent, population int, t *Tuning) float64 {
 	var chance float64
 
 	// === Normal mortality: coherence-scaled, adults only (age >= 16) ===
@@ -328,7 +332,8 @@ func agentDailyMortalityChance(a *agents.Agent, population int) float64 {
 		// Background: scatter-driven daily death risk.
 		// Base at Agnosis⁶ (~0.000173) — entropy of embodied scatter (R98).
 		// Floor at Agnosis⁷ (~0.0000409) — even liberated agents are mortal.
-		agnosis6 := phi.Agnosis * phi.Agnosis * phi.Agnosis * phi.Agnosis * phi.Agnosis * phi.Agnosis
+		// The exponent is the mortality_background_power knob.
+		agnosis6 := math.Pow(phi.Agnosis, t.MortalityBackgroundPower)
 		agnosis7 := agnosis6 * phi.Agnosis
 		scatter := 1.0 - coherence
 		chance = agnosis7 + agnosis6*scatter
@@ -337,8 +342,8 @@ func agentDailyMortalityChance(a *agents.Agent, population int) float64 {
 		// Onset at 50, steepness 12 sim-years, scaled by Agnosis³ (~0.01315).
 		// Squared sigmoid protects younger adults while ensuring old agents
 		// face increasing mortality. At age 70: ~0.98%/day.
-		agnosis3 := phi.Agnosis * phi.Agnosis * phi.Agnosis
-		ageOffset := float64(a.Age) - 50.0
+		agnosis3 := math.Pow(phi.Agnosis, t.MortalityAgePower)
+		ageOffset := float64(a.Age) - t.MortalityAgeOnset
 		sigmoid := 1.0 / (1.0 + math.Exp(-ageOffset/12.0))
 		chance += agnosis3 * sigmoid * sigmoid
 	}
@@ -420,8 +425,9 @@ func (s *Simulation) processBirths(tick uint64) {
 		// to prevent cliff dynamics causing wild birth oscillations.
 		var eligibleParents []*agents.Agent
 		for _, a := range settAgents {
-			if a.Alive && a.Age >= 18 && a.Age <= 45 && a.Health > 0.5 &&
-				a.Needs.Survival > 0.3 && birthEligible(a, simDay) {
+			if a.Alive && float64(a.Age) >= s.Tuning.BirthMinAge && float64(a.Age) <= s.Tuning.BirthMaxAge &&
+				float64(a.Health) > s.Tuning.BirthMinHealth &&
+				float64(a.Needs.Survival) > s.Tuning.BirthMinSurvival && birthEligible(a, simDay) {
 				eligibleParents = append(eligibleParents, a)
 			}
 		}
@@ -438,7 +444,7 @@ func (s *Simulation) processBirths(tick uint64) {
 			prosperityMod = 1.0
 		}
 
-		birthChance := float64(len(eligibleParents)) / 30.0 * (0.5 + prosperityMod) * capFactor
+		birthChance := float64(len(eligibleParents)) / s.Tuning.BirthParentsPerBirth * (0.5 + prosperityMod) * capFactor
 		// Deterministic: use simDay and settlement ID for consistent births.
 		birthCount := int(birthChance)
 		fractional := birthChance - float64(birthCount)
@@ -474,6 +480,8 @@ func (s *Simulation) processBirths(tick uint64) {
 			}
 
 			s.addAgent(child)
+			s.logChange(Change{Kind: ChangeAgentBorn, Tick: tick, Agent: child, Cause: "birth"})
+			s.recordBirth(child, parent, tick)
 
 			s.EmitEvent(Event{
 				Tick:        tick,
@@ -566,6 +574,7 @@ func (s *Simulation) processAntiCollapse(tick uint64) {
 			for _, r := range refugees {
 				r.BornTick = tick
 				s.addAgent(r)
+				s.logChange(Change{Kind: ChangeAgentBorn, Tick: tick, Agent: r, Cause: "refugee"})
 			}
 			sett.Population = uint32(aliveCount + needed)
 			s.EmitEvent(Event{
@@ -821,11 +830,7 @@ func occupationLabel(occ agents.Occupation) string {
 
 // addAgent registers a new agent in all indexes and assigns a faction.
 func (s *Simulation) addAgent(a *agents.Agent) {
-	s.Agents = append(s.Agents, a)
-	s.AgentIndex[a.ID] = a
-	if a.HomeSettID != nil {
-		s.SettlementAgents[*a.HomeSettID] = append(s.SettlementAgents[*a.HomeSettID], a)
-	}
+	s.indexAgent(a)
 
 	// Assign faction based on occupation and governance type.
 	if a.FactionID == nil {
@@ -841,3 +846,12 @@ func (s *Simulation) addAgent(a *agents.Agent) {
 		}
 	}
 }
+
+// indexAgent appends a to the agent list and the ID and settlement indexes.
+func (s *Simulation) indexAgent(a *agents.Agent) {
+	s.Agents = append(s.Agents, a)
+	s.AgentIndex[a.ID] = a
+	if a.HomeSettID != nil {
+		s.SettlementAgents[*a.HomeSettID] = append(s.SettlementAgents[*a.HomeSettID], a)
+	}
+}
@@ -0,0 +1,71 @@
+// Price history: one open/high/low/close bar per good per settlement market
+// each sim-hour. A market posts one price an hour, when resolveMarkets clears
+// it, so an hourly bar opens at the price before clearing and closes at the
+// price after; persistence (see persistence/price_history.go) keeps the bars
+// and folds old ones into daily bars.
+package engine
+
+import (
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// PriceBar is one good's price in one settlement market over a period.
+type PriceBar struct {
+	SettlementID uint64          `json:"settlement_id"`
+	Good         agents.GoodType `json:"good"`
+	Tick         uint64          `json:"tick"` // the hour's clearing tick, or a day's first tick
+	Open         float64         `json:"open"`
+	High         float64         `json:"high"`
+	Low          float64         `json:"low"`
+	Close        float64         `json:"close"`
+	Volume       int             `json:"volume"` // units traded on the market
+}
+
+// priceKey identifies one good in one settlement market.
+type priceKey struct {
+	settlement uint64
+	good       agents.GoodType
+}
+
+// marketPrices returns every market's current prices.
+func (s *Simulation) marketPrices() map[priceKey]float64 {
+	prices := make(map[priceKey]float64)
+	for _, sett := range s.Settlements {
+		if sett.Market == nil {
+			continue
+		}
+		for good, entry := range sett.Market.Entries {
+			prices[priceKey{sett.ID, good}] = entry.Price
+		}
+	}
+	return prices
+}
+
+// closePriceBars sets PriceBars to the bars of the hour cleared at tick, from
+// the prices markets opened at. Markets that did not clear this hour (settlements with no
+// one left) get no bars.
+func (s *Simulation) closePriceBars(tick uint64, opens map[priceKey]float64) {
+	s.PriceBars = s.PriceBars[:0]
+	for _, sett := range s.Settlements {
+		if sett.Market == nil || len(s.SettlementAgents[sett.ID]) == 0 {
+			continue
+		}
+		for _, good := range sortedKeys(sett.Market.Entries) {
+			entry := sett.Market.Entries[good]
+			open, ok := opens[priceKey{sett.ID, good}]
+			if !ok {
+				open = entry.Price
+			}
+			s.PriceBars = append(s.PriceBars, PriceBar{
+				SettlementID: sett.ID,
+				Good:         good,
+				Tick:         tick,
+				Open:         open,
+				High:         max(open, entry.Price),
+				Low:          min(open, entry.Price),
+				Close:        entry.Price,
+				Volume:       entry.Traded,
+			})
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// TestPriceBars checks an hour's bars open at the price before clearing,
+// close at the price after, and carry the units traded.
+func TestPriceBars(t *testing.T) {
+	s, sett := newLedgerSim()
+	sett.Market = s.newMarket(sett.ID)
+	s.addAgent(&agents.Agent{ID: 1, Alive: true, HomeSettID: &sett.ID})
+	grain := sett.Market.Entries[agents.GoodGrain]
+	grain.Price = 2
+
+	opens := s.marketPrices()
+	grain.Price, grain.Traded = 2.6, 7
+	s.closePriceBars(120, opens)
+
+	if len(s.PriceBars) != len(sett.Market.Entries) {
+		t.Fatalf("%d bars for %d goods", len(s.PriceBars), len(sett.Market.Entries))
+	}
+	for _, b := range s.PriceBars {
+		if b.Good != agents.GoodGrain {
+			continue
+		}
+		want := PriceBar{SettlementID: sett.ID, Good: agents.GoodGrain, Tick: 120, Open: 2, High: 2.6, Low: 2, Close: 2.6, Volume: 7}
+		if b != want {
+			t.Errorf("grain bar = %+v, want %+v", b, want)
+		}
+	}
+}
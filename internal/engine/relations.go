@@ -210,7 +210,8 @@ func (s *Simulation) computeSettlementRelations() {
 	s.TradeTracker = make(map[SettRelKey]float64)
 
 	// Clean up relations for dead settlement pairs (both near-zero sentiment and no trade).
-	for key, rel := range s.Relations {
+	for _, key := range sortedRelKeys(s.Relations) {
+		rel := s.Relations[key]
 		if math.Abs(rel.Sentiment) < 0.001 && rel.Trade == 0 {
 			delete(s.Relations, key)
 		}
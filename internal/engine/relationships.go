@@ -69,6 +69,7 @@ func (s *Simulation) processRelationships(tick uint64) {
 
 // strengthenBond increases the relationship between two agents.
 func strengthenBond(from, to *agents.Agent) {
+	from.Dirty |= agents.DirtyRelationships
 	// Find existing relationship.
 	for i := range from.Relationships {
 		if from.Relationships[i].TargetID == to.ID {
@@ -172,6 +173,7 @@ func (s *Simulation) formFamilies(sett interface{ }, alive []*agents.Agent, tick
 			})
 			// R80: both partners gain a story-bearing memory of the union.
 			s.imprintMarriageMemories(a, bestMatch, settName, tick)
+			s.recordLineage(LineageRecord{Tick: tick, Kind: LineageMarriage, Person: kinOf(a), Kin: []Kin{kinOf(bestMatch)}})
 		}
 	}
 }
@@ -180,6 +182,7 @@ func (s *Simulation) formFamilies(sett interface{ }, alive []*agents.Agent, tick
 func boostRelationship(a *agents.Agent, targetID agents.AgentID, sentimentBoost, trustBoost float32) {
 	for i := range a.Relationships {
 		if a.Relationships[i].TargetID == targetID {
+			a.Dirty |= agents.DirtyRelationships
 			a.Relationships[i].Sentiment += sentimentBoost
 			a.Relationships[i].Trust += trustBoost
 			if a.Relationships[i].Sentiment > 1 {
@@ -155,7 +155,7 @@ func (s *Simulation) regenerateResources() {
 					// Regrow at rate proportional to Matter, scaled by hex health.
 					// Degraded land regenerates slower. Irrigation boosts regen.
 					deficit := maxQty - qty
-					regen := deficit * phi.Matter * 0.3 * hex.Health * irrFactor
+					regen := deficit * phi.Matter * s.Tuning.RegenSeasonalRate * hex.Health * irrFactor
 					hex.Resources[res] = qty + regen
 					if hex.Resources[res] > maxQty {
 						hex.Resources[res] = maxQty
@@ -259,7 +259,7 @@ func (s *Simulation) weeklyResourceRegen() {
 				maxQty := ResourceCap(hex.Terrain, res)
 				if qty < maxQty {
 					deficit := maxQty - qty
-					regen := deficit * phi.Agnosis * 0.4 * hex.Health * irrFactor2 // ~9.4% scaled by health + irrigation
+					regen := deficit * phi.Agnosis * s.Tuning.RegenWeeklyRate * hex.Health * irrFactor2 // ~9.4% scaled by health + irrigation
 					hex.Resources[res] = qty + regen
 					if hex.Resources[res] > maxQty {
 						hex.Resources[res] = maxQty
@@ -349,7 +349,7 @@ func (s *Simulation) hourlyResourceRegen() {
 				maxQty := ResourceCap(hex.Terrain, res)
 				if qty < maxQty {
 					deficit := maxQty - qty
-					regen := deficit * phi.Agnosis * 0.06 * hex.Health * factor * weatherMod * irrFactor
+					regen := deficit * phi.Agnosis * s.Tuning.RegenHourlyRate * hex.Health * factor * weatherMod * irrFactor
 					hex.Resources[res] = qty + regen
 					if hex.Resources[res] > maxQty {
 						hex.Resources[res] = maxQty
@@ -371,7 +371,7 @@ func (s *Simulation) applyWeatherHexDamage(tick uint64) {
 	if s.CurrentWeather.TravelPenalty >= 2.0 {
 		s.applyStormErosion(tick)
 	}
-	if s.HeatStreakHours >= 72 {
+	if s.HeatStreakHours >= int(s.Tuning.HeatStreakHours) {
 		s.applyDroughtDegradation(tick)
 	}
 }
@@ -416,7 +416,7 @@ func (s *Simulation) applyStormErosion(tick uint64) {
 }
 
 // applyDroughtDegradation degrades Plains and Forest hex health during sustained
-// heat. Triggered by HeatStreakHours >= 72 (the same threshold that fires crop
+// heat. Triggered at the heat_streak_hours knob (the same threshold that fires crop
 // failure, so drought arrives as a one-two punch: stored grain spoils, then the
 // land itself dries). Rate Agnosis × 0.0005 (~0.0118%/hour, ~0.28%/day). Irrigation
 // level 3+ protects fully; lower levels partial. Conservation reduces remaining damage.
@@ -488,7 +488,7 @@ func (s *Simulation) autumnHarvest(tick uint64) {
 }
 
 // checkCropFailure tracks sustained heat and triggers crop failure in vulnerable settlements.
-// When TempModifier exceeds Agnosis (~0.236) for 72+ consecutive sim-hours (3 sim-days),
+// When TempModifier exceeds Agnosis (~0.236) for heat_streak_hours (default 72: 3 sim-days),
 // settlements with low irrigation lose a fraction of stored grain. Rewards irrigation
 // investment (R42) and creates demand spikes that reward merchant arbitrage.
 func (s *Simulation) checkCropFailure(tick uint64) {
@@ -499,12 +499,14 @@ func (s *Simulation) checkCropFailure(tick uint64) {
 		return
 	}
 
-	// Fire crop failure at 72 sim-hours (3 sim-days of sustained heat).
-	// Then reset to 48 so subsequent failures fire every 24 hours of continued heat.
-	if s.HeatStreakHours < 72 {
+	// Fire crop failure at Tuning.HeatStreakHours (default 72: 3 sim-days of
+	// sustained heat). Then back off 24 so subsequent failures fire every 24
+	// hours of continued heat.
+	threshold := int(s.Tuning.HeatStreakHours)
+	if s.HeatStreakHours < threshold {
 		return
 	}
-	s.HeatStreakHours = 48
+	s.HeatStreakHours = threshold - 24
 
 	affected := 0
 	for _, sett := range s.Settlements {
@@ -0,0 +1,150 @@
+// Settlement archive: the settlements the world has lost. While a settlement
+// lives the engine keeps its chronicle (founding, founders, peak, governments,
+// where its people went); when compactAbandonedSettlements drops an abandoned
+// settlement, the chronicle becomes an archive entry that persistence keeps
+// for good (see persistence/settlement_archive.go).
+package engine
+
+import (
+	"slices"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// Causes of abandonment.
+const (
+	AbandonDepopulated = "depopulated" // its last people died
+	AbandonExodus      = "exodus"      // its last people were moved out as non-viable
+)
+
+// GovernanceEra is a stretch of a settlement's history under one government.
+type GovernanceEra struct {
+	Governance social.GovernanceType `json:"governance"`
+	FromTick   uint64                `json:"from_tick"`
+}
+
+// Successor is a settlement that took in another's people.
+type Successor struct {
+	ID     uint64 `json:"id"`
+	Name   string `json:"name"`
+	People int    `json:"people"` // how many moved there, over all migrations
+	Tick   uint64 `json:"tick"`   // the most recent
+}
+
+// SettlementChronicle is what the engine remembers of a living settlement's
+// past. Settlements the world began with, or that predate chronicles, have no
+// founding tick or founders.
+type SettlementChronicle struct {
+	FoundedTick    uint64          `json:"founded_tick"`
+	ParentID       *uint64         `json:"parent_id,omitempty"`
+	ParentName     string          `json:"parent_name,omitempty"`
+	Founders       []Kin           `json:"founders,omitempty"`
+	PeakPopulation uint32          `json:"peak_population"`
+	PeakTick       uint64          `json:"peak_tick"`
+	Governments    []GovernanceEra `json:"governments,omitempty"`
+	Successors     []Successor     `json:"successors,omitempty"`
+	Exodus         bool            `json:"exodus,omitempty"` // its people were last moved out as non-viable
+	AbandonedTick  uint64          `json:"abandoned_tick,omitempty"`
+	Cause          string          `json:"cause,omitempty"`
+}
+
+// SettlementArchive is an abandoned settlement's life story.
+type SettlementArchive struct {
+	SettlementChronicle
+	ID         uint64                `json:"id"`
+	Name       string                `json:"name"`
+	Q          int                   `json:"q"`
+	R          int                   `json:"r"`
+	Governance social.GovernanceType `json:"governance"` // the last government
+	Archived   bool                  `json:"archived"`   // always true; tells archive entries from live settlements
+}
+
+// chronicle returns sett's chronicle, starting one if it has none.
+func (s *Simulation) chronicle(sett *social.Settlement) *SettlementChronicle {
+	if s.Chronicles == nil {
+		s.Chronicles = make(map[uint64]*SettlementChronicle)
+	}
+	c, ok := s.Chronicles[sett.ID]
+	if !ok {
+		c = &SettlementChronicle{}
+		s.Chronicles[sett.ID] = c
+		s.observeSettlement(sett, s.LastTick)
+	}
+	return c
+}
+
+// recordFounding starts a newly founded settlement's chronicle. parent is the
+// settlement its founders left, nil if unknown.
+func (s *Simulation) recordFounding(sett, parent *social.Settlement, founders []*agents.Agent, tick uint64) {
+	c := &SettlementChronicle{FoundedTick: tick}
+	if parent != nil {
+		id := parent.ID
+		c.ParentID, c.ParentName = &id, parent.Name
+	}
+	for _, a := range founders {
+		c.Founders = append(c.Founders, kinOf(a))
+	}
+	if s.Chronicles == nil {
+		s.Chronicles = make(map[uint64]*SettlementChronicle)
+	}
+	s.Chronicles[sett.ID] = c
+	s.observeSettlement(sett, tick)
+}
+
+// recordEmigration notes that people moved from one settlement to another.
+func (s *Simulation) recordEmigration(from, to *social.Settlement, people int, tick uint64) {
+	c := s.chronicle(from)
+	for i := range c.Successors {
+		if c.Successors[i].ID == to.ID {
+			c.Successors[i].People += people
+			c.Successors[i].Tick = tick
+			return
+		}
+	}
+	c.Successors = append(c.Successors, Successor{ID: to.ID, Name: to.Name, People: people, Tick: tick})
+}
+
+// observeSettlement brings sett's peak population and government up to date.
+func (s *Simulation) observeSettlement(sett *social.Settlement, tick uint64) {
+	c := s.chronicle(sett)
+	if sett.Population > c.PeakPopulation {
+		c.PeakPopulation, c.PeakTick = sett.Population, tick
+	}
+	if n := len(c.Governments); n == 0 || c.Governments[n-1].Governance != sett.Governance {
+		c.Governments = append(c.Governments, GovernanceEra{Governance: sett.Governance, FromTick: tick})
+	}
+}
+
+// chronicleSettlements observes every settlement. Called weekly, before
+// abandonment empties any of them.
+func (s *Simulation) chronicleSettlements(tick uint64) {
+	for _, sett := range s.Settlements {
+		if sett.Population > 0 {
+			s.observeSettlement(sett, tick)
+		}
+	}
+}
+
+// archiveSettlement queues an abandoned settlement's archive entry for the
+// next history save and forgets its chronicle.
+func (s *Simulation) archiveSettlement(sett *social.Settlement) {
+	c := s.chronicle(sett)
+	if c.Cause == "" {
+		c.Cause = AbandonDepopulated
+	}
+	a := SettlementArchive{
+		SettlementChronicle: *c,
+		ID:                  sett.ID,
+		Name:                sett.Name,
+		Q:                   sett.Position.Q,
+		R:                   sett.Position.R,
+		Governance:          sett.Governance,
+		Archived:            true,
+	}
+	a.Founders = slices.Clone(c.Founders)
+	a.Governments = slices.Clone(c.Governments)
+	a.Successors = slices.Clone(c.Successors)
+	s.UnsavedArchives = append(s.UnsavedArchives, a)
+	delete(s.Chronicles, sett.ID)
+}
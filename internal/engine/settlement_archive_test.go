@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// TestSettlementArchive founds a daughter settlement, lets it peak and change
+// government, empties it by exodus, and checks the archive entry compaction
+// leaves behind.
+func TestSettlementArchive(t *testing.T) {
+	s, parent := newLedgerSim()
+	parent.Population = 40
+	s.WorldMap.Get(parent.Position).SettlementID = &parent.ID
+	var founders []*agents.Agent
+	for i := range 3 {
+		a := &agents.Agent{ID: agents.AgentID(10 + i), Name: "founder", Alive: true, HomeSettID: &parent.ID}
+		s.addAgent(a)
+		founders = append(founders, a)
+	}
+	var coord world.HexCoord
+	for c, h := range s.WorldMap.Hexes {
+		if h.SettlementID == nil && c != parent.Position {
+			coord = c
+			break
+		}
+	}
+	daughter := s.foundSettlement(coord, founders, 0, 100, parent)
+
+	daughter.Population = 12
+	s.chronicleSettlements(200)
+	daughter.Governance = social.GovCouncil
+	daughter.Population = 5
+	s.chronicleSettlements(300)
+
+	s.recordEmigration(daughter, parent, 5, 400)
+	s.chronicle(daughter).Exodus = true
+	daughter.Population = 0
+	s.abandonSettlement(daughter, 400)
+	s.compactAbandonedSettlements()
+
+	if _, ok := s.SettlementIndex[daughter.ID]; ok {
+		t.Fatal("abandoned settlement not compacted")
+	}
+	if len(s.UnsavedArchives) != 1 {
+		t.Fatalf("%d archive entries", len(s.UnsavedArchives))
+	}
+	a := s.UnsavedArchives[0]
+	if a.ID != daughter.ID || !a.Archived || a.FoundedTick != 100 || a.AbandonedTick != 400 || a.Cause != AbandonExodus {
+		t.Errorf("archive = %+v", a)
+	}
+	if a.ParentID == nil || *a.ParentID != parent.ID || a.ParentName != "Ashford" || len(a.Founders) != 3 {
+		t.Errorf("founding = %v %q %v", a.ParentID, a.ParentName, a.Founders)
+	}
+	if a.PeakPopulation != 12 || a.PeakTick != 200 {
+		t.Errorf("peak %d at %d", a.PeakPopulation, a.PeakTick)
+	}
+	if len(a.Governments) != 2 || a.Governments[1] != (GovernanceEra{Governance: social.GovCouncil, FromTick: 300}) {
+		t.Errorf("governments = %+v", a.Governments)
+	}
+	if len(a.Successors) != 1 || a.Successors[0].ID != parent.ID || a.Successors[0].People != 5 {
+		t.Errorf("successors = %+v", a.Successors)
+	}
+	if _, ok := s.Chronicles[daughter.ID]; ok {
+		t.Error("archived settlement kept its chronicle")
+	}
+}
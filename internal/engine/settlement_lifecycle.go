@@ -10,7 +10,6 @@ import (
 
 	"github.com/talgya/mini-world/internal/agents"
 	"github.com/talgya/mini-world/eventproto"
-	"github.com/talgya/mini-world/internal/economy"
 	"github.com/talgya/mini-world/internal/phi"
 	"github.com/talgya/mini-world/internal/social"
 	"github.com/talgya/mini-world/internal/world"
@@ -130,7 +129,8 @@ func (s *Simulation) processSettlementOvermass(tick uint64) {
 		}
 
 		// Found new settlement. Inherit governance from parent.
-		newSett := s.foundSettlement(foundingHex, emigrants, pooledWealth, tick, sett.Governance)
+		newSett := s.foundSettlement(foundingHex, emigrants, pooledWealth, tick, sett)
+		s.recordEmigration(sett, newSett, len(emigrants), tick)
 
 		// Remove emigrants from old settlement.
 		sett.Population -= uint32(len(emigrants))
@@ -198,11 +198,12 @@ func (s *Simulation) processSettlementAbandonment(tick uint64) {
 				if sett.Treasury > 0 {
 					nearest := s.nearestActiveSettlements(sett.Position, 3)
 					if len(nearest) > 0 {
-						share := sett.Treasury / uint64(len(nearest))
+						amount := sett.Treasury
+						share := amount / uint64(len(nearest))
 						for _, neighbor := range nearest {
 							neighbor.Treasury += share
 						}
-						remainder := sett.Treasury - share*uint64(len(nearest))
+						remainder := amount - share*uint64(len(nearest))
 						nearest[0].Treasury += remainder
 						s.EmitEvent(Event{
 							Tick:        tick,
@@ -215,6 +216,11 @@ func (s *Simulation) processSettlementAbandonment(tick uint64) {
 							},
 						})
 						sett.Treasury = 0
+						balances := []TreasuryBalance{settlementBalance(sett)}
+						for _, neighbor := range nearest {
+							balances = append(balances, settlementBalance(neighbor))
+						}
+						s.logChange(Change{Kind: ChangeTreasuryTransfer, Tick: tick, Amount: amount, Balances: balances})
 					}
 				}
 
@@ -230,17 +236,8 @@ func (s *Simulation) processSettlementAbandonment(tick uint64) {
 					},
 				})
 
-				// Release hex claims.
-				s.releaseSettlementClaims(sett.ID)
-
-				// Clear hex settlement reference.
-				hex := s.WorldMap.Get(sett.Position)
-				if hex != nil {
-					hex.SettlementID = nil
-				}
-
-				// Remove from active list (mark population 0, keep in DB).
-				sett.Population = 0
+				s.abandonSettlement(sett, tick)
+				s.logChange(Change{Kind: ChangeSettlementAbandoned, Tick: tick, SettlementID: sett.ID})
 			}
 		} else {
 			// Reset counter if people are alive.
@@ -249,6 +246,22 @@ func (s *Simulation) processSettlementAbandonment(tick uint64) {
 	}
 }
 
+// abandonSettlement releases an empty settlement's land: its hex claims and
+// the hex reference to it. The settlement itself stays, with population 0,
+// until compactAbandonedSettlements archives it.
+func (s *Simulation) abandonSettlement(sett *social.Settlement, tick uint64) {
+	c := s.chronicle(sett)
+	c.AbandonedTick, c.Cause = tick, AbandonDepopulated
+	if c.Exodus {
+		c.Cause = AbandonExodus
+	}
+	s.releaseSettlementClaims(sett.ID)
+	if hex := s.WorldMap.Get(sett.Position); hex != nil {
+		hex.SettlementID = nil
+	}
+	sett.Population = 0
+}
+
 // nearestActiveSettlements returns the N closest settlements with population > 0.
 func (s *Simulation) nearestActiveSettlements(from world.HexCoord, n int) []*social.Settlement {
 	type distSett struct {
@@ -272,8 +285,9 @@ func (s *Simulation) nearestActiveSettlements(from world.HexCoord, n int) []*soc
 	return result
 }
 
-// foundSettlement creates a new settlement at the given hex with founding agents.
-func (s *Simulation) foundSettlement(coord world.HexCoord, founders []*agents.Agent, treasury uint64, tick uint64, parentGov social.GovernanceType) *social.Settlement {
+// foundSettlement creates a new settlement at the given hex with founding
+// agents who left parent.
+func (s *Simulation) foundSettlement(coord world.HexCoord, founders []*agents.Agent, treasury uint64, tick uint64, parent *social.Settlement) *social.Settlement {
 	// Generate a unique settlement ID.
 	maxID := uint64(0)
 	for _, st := range s.Settlements {
@@ -291,16 +305,32 @@ func (s *Simulation) foundSettlement(coord world.HexCoord, founders []*agents.Ag
 		Name:            name,
 		Position:        coord,
 		Population:      uint32(len(founders)),
-		Governance:      parentGov, // Inherit governance from parent settlement
+		Governance:      parent.Governance, // Inherit governance from parent settlement
 		TaxRate:         0.10,
 		Treasury:        treasury,
 		GovernanceScore: 0.5,
 		MarketLevel:     1,
 		CultureOpenness: 0.3, // Founders tend to be open-minded
 	}
+	s.settleFounders(newSett, founders)
+	s.recordFounding(newSett, parent, founders, tick)
+
+	founderIDs := make([]agents.AgentID, len(founders))
+	for i, a := range founders {
+		founderIDs[i] = a.ID
+	}
+	s.logChange(Change{Kind: ChangeSettlementFounded, Tick: tick, Settlement: newSett, Founders: founderIDs})
+	return newSett
+}
+
+// settleFounders registers a newly founded settlement and moves its founders
+// in. Their old settlements' member lists are the caller's to update.
+func (s *Simulation) settleFounders(newSett *social.Settlement, founders []*agents.Agent) {
+	newID := newSett.ID
+	coord := newSett.Position
 
 	// Initialize market.
-	newSett.Market = economy.NewMarket(newID)
+	newSett.Market = s.newMarket(newID)
 
 	// Register in indexes.
 	s.Settlements = append(s.Settlements, newSett)
@@ -332,8 +362,6 @@ func (s *Simulation) foundSettlement(coord world.HexCoord, founders []*agents.Ag
 
 	// Rebuild neighbor index to include the new settlement.
 	s.BuildSettlementNeighbors()
-
-	return newSett
 }
 
 // rebalanceDaughterFounders reassigns producer founders whose required
@@ -491,6 +519,8 @@ func (s *Simulation) processViabilityCheck(tick uint64) {
 				if target == nil {
 					continue // No viable target — let them be.
 				}
+				s.recordEmigration(sett, target, aliveCount, tick)
+				s.chronicle(sett).Exodus = true
 				for _, a := range s.SettlementAgents[sett.ID] {
 					if !a.Alive {
 						continue
@@ -520,6 +550,9 @@ func (s *Simulation) processViabilityCheck(tick uint64) {
 			}
 		} else {
 			delete(s.NonViableWeeks, sett.ID)
+			if c, ok := s.Chronicles[sett.ID]; ok {
+				c.Exodus = false
+			}
 		}
 	}
 	if migrated {
@@ -527,8 +560,8 @@ func (s *Simulation) processViabilityCheck(tick uint64) {
 	}
 }
 
-// compactAbandonedSettlements removes ghost settlements (population 0, hex cleared)
-// from the Settlements slice and rebuilds SettlementIndex.
+// compactAbandonedSettlements archives ghost settlements (population 0, hex
+// cleared), removes them from the Settlements slice and rebuilds SettlementIndex.
 // Called weekly from TickWeek after processSettlementAbandonment.
 func (s *Simulation) compactAbandonedSettlements() {
 	active := make([]*social.Settlement, 0, len(s.Settlements))
@@ -538,7 +571,8 @@ func (s *Simulation) compactAbandonedSettlements() {
 		if sett.Population == 0 {
 			hex := s.WorldMap.Get(sett.Position)
 			if hex == nil || hex.SettlementID == nil {
-				// Properly abandoned — remove from memory.
+				// Properly abandoned — archive, then remove from memory.
+				s.archiveSettlement(sett)
 				delete(s.SettlementAgents, sett.ID)
 				delete(s.AbandonedWeeks, sett.ID)
 				delete(s.NonViableWeeks, sett.ID)
@@ -617,6 +651,7 @@ func (s *Simulation) cascadeMigrate(from, to *social.Settlement, emigrants []*ag
 
 	from.Population -= uint32(len(emigrants))
 	to.Population += uint32(len(emigrants))
+	s.recordEmigration(from, to, len(emigrants), tick)
 
 	desc := fmt.Sprintf("%d citizens overflow from %s to %s (cascade — no founding site)",
 		len(emigrants), from.Name, to.Name)
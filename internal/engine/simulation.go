@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/talgya/mini-world/internal/agents"
 	"github.com/talgya/mini-world/internal/economy"
@@ -29,6 +30,27 @@ type Simulation struct {
 	Events      []Event // Recent events (ring buffer in production)
 	LastTick    uint64  // Most recent tick processed
 
+	// UnsavedEvents counts the trailing entries of Events not yet written to
+	// the database. The history save writes just those and resets it.
+	UnsavedEvents int
+
+	// UnsavedLineage holds the births, marriages and deaths not yet written
+	// to the family tree (see lineage.go). The history save writes and
+	// clears it.
+	UnsavedLineage []LineageRecord
+
+	// UnsavedGraves holds the graves of agents who died since the last
+	// history save (see graveyard.go).
+	UnsavedGraves []Grave
+
+	// UnsavedArchives holds the archive entries of settlements compacted
+	// since the last history save (see settlement_archive.go).
+	UnsavedArchives []SettlementArchive
+
+	// PriceBars holds each market's bars for the last sim-hour (see
+	// price_history.go). TickHour replaces it; the caller saves it.
+	PriceBars []PriceBar
+
 	// Settlement lookups.
 	SettlementIndex  map[uint64]*social.Settlement   // ID → settlement
 	SettlementAgents map[uint64][]*agents.Agent       // settlement ID → agents
@@ -52,6 +74,20 @@ type Simulation struct {
 	// Entropy source (random.org or crypto/rand fallback).
 	Entropy *entropy.Client
 
+	// Named tuning knobs (see tuning.go). NewSimulation sets the defaults.
+	Tuning Tuning
+
+	// Input journal for deterministic record/replay (nil = not journaling).
+	// See journal.go and internal/replay.
+	Journal Journal
+
+	// Domain change log for crash recovery between saves (nil = off), and
+	// the sequence number of the last change handed to it. See changes.go.
+	// ChangeSeq is saved with the world, so a restart replays only the
+	// changes the save does not already hold.
+	ChangeLog ChangeLog
+	ChangeSeq uint64
+
 	// Settlement abandonment tracking (settlement ID → consecutive weeks with 0 pop).
 	AbandonedWeeks map[uint64]int
 
@@ -59,13 +95,21 @@ type Simulation struct {
 	// After 4 weeks, refugee spawning is disabled so the settlement can naturally decline.
 	NonViableWeeks map[uint64]int
 
+	// Settlement chronicles (settlement ID → its past), kept until the
+	// settlement is archived. See settlement_archive.go.
+	Chronicles map[uint64]*SettlementChronicle
+
 	// Faction doctrine failure tracking (agent ID → consecutive weeks failing doctrine).
-	// Transient — resets on restart, rebuilds within 4 TickWeeks.
 	DoctrineFailWeeks map[agents.AgentID]uint8
 
 	// Active production boosts from gardener "cultivate" interventions.
 	ActiveBoosts []ProductionBoost
 
+	// Intervention ledger, oldest first: every pending intervention plus the
+	// most recent settled ones (see intervention_queue.go). The full history
+	// lives in the interventions table.
+	Interventions []*InterventionRecord
+
 	// Heat streak counter: consecutive sim-hours with TempModifier > 0.3.
 	// When it reaches 72 (3 sim-days), crop failure events fire.
 	HeatStreakHours int
@@ -109,6 +153,9 @@ type Simulation struct {
 	// Per-settlement diplomacy crime bonus cache. Rebuilt weekly after processDiplomacy.
 	diplomacyCrimeBonusCache map[uint64]float64
 
+	// Latest published read model (see view.go).
+	view atomic.Pointer[Simulation]
+
 	// Event streaming support.
 	eventSubMu sync.RWMutex
 	eventSubs  map[int]chan Event
@@ -129,8 +176,7 @@ type Simulation struct {
 
 	// Per-settlement cached oracle vision. Used by processOracleVisions to
 	// skip redundant LLM calls when settlement state is unchanged across
-	// weeks. See oracleStateHash for the hash inputs. Transient — cleared
-	// on restart (visions will refresh on first post-restart TickWeek).
+	// weeks. See oracleStateHash for the hash inputs.
 	LastVisions map[uint64]CachedVision
 
 	// Season at last archetype-template refresh. Archetype updates moved
@@ -141,19 +187,33 @@ type Simulation struct {
 }
 
 // CachedVision holds a reused oracle vision and tracks how many weeks it has
-// been reused. Reuse is capped at oracleMaxReuseWeeks (2) to bound narrative
-// repetition.
+// been reused. Reuse is capped by the oracle_max_reuse_weeks knob (default 2)
+// to bound narrative repetition.
 type CachedVision struct {
 	Hash        uint64
 	Vision      *llm.OracleVision
 	WeeksReused int
 }
 
+// RestoreArchetypeSeason records that the archetype templates were restored as
+// they stood at season, so the first TickWeek after a restart does not refresh
+// them again.
+func (s *Simulation) RestoreArchetypeSeason(season uint8) {
+	s.LastArchetypeSeason = season
+	s.archetypeInitialized = true
+}
+
 // CurrentTick returns the most recently processed tick number.
 func (s *Simulation) CurrentTick() uint64 {
 	return s.LastTick
 }
 
+// UnsavedEventTail returns the events not yet written to the database,
+// oldest first.
+func (s *Simulation) UnsavedEventTail() []Event {
+	return s.Events[len(s.Events)-s.UnsavedEvents:]
+}
+
 // Subscribe returns a subscriber ID and a buffered channel that receives events.
 func (s *Simulation) Subscribe() (int, chan Event) {
 	s.eventSubMu.Lock()
@@ -181,6 +241,7 @@ func (s *Simulation) Unsubscribe(id int) {
 // EmitEvent appends an event to the stored slice and broadcasts to all subscribers.
 func (s *Simulation) EmitEvent(e Event) {
 	s.Events = append(s.Events, e)
+	s.UnsavedEvents++
 	s.eventSubMu.RLock()
 	defer s.eventSubMu.RUnlock()
 	for _, ch := range s.eventSubs {
@@ -265,7 +326,9 @@ func NewSimulation(m *world.Map, ag []*agents.Agent, setts []*social.Settlement)
 		SettlementAgents: settAgents,
 		AbandonedWeeks:    make(map[uint64]int),
 		NonViableWeeks:    make(map[uint64]int),
+		Chronicles:        make(map[uint64]*SettlementChronicle),
 		DoctrineFailWeeks: make(map[agents.AgentID]uint8),
+		Tuning:            DefaultTuning(),
 	}
 	sim.initSettlementClaims()
 	sim.updateStats()
@@ -325,7 +388,7 @@ func (s *Simulation) TickMinute(tick uint64) {
 		}
 
 		// Agent decides and acts.
-		action := agents.Decide(a)
+		action := agents.Decide(a, tick)
 
 		var events []string
 
@@ -388,6 +451,8 @@ func (s *Simulation) handleAgentDeath(a *agents.Agent, tick uint64, cause string
 	// event emission. Existing callers that already incremented have had
 	// their `s.Stats.Deaths++` removed; new callers needn't think about it.
 	s.Stats.Deaths++
+	s.logChange(Change{Kind: ChangeAgentDied, Tick: tick, AgentID: a.ID, Cause: cause})
+	s.recordDeath(a, tick, cause)
 
 	deathDesc := fmt.Sprintf("%s has died", a.Name)
 	s.EmitEvent(Event{
@@ -433,8 +498,10 @@ func (s *Simulation) handleAgentDeath(a *agents.Agent, tick uint64, cause string
 
 // TickHour runs every sim-hour: market updates, weather checks, resource regen, crop failure, storm damage.
 func (s *Simulation) TickHour(tick uint64) {
+	opens := s.marketPrices()
 	s.resolveMarkets(tick)
 	s.resolveMerchantTrade(tick)
+	s.closePriceBars(tick, opens)
 	s.decayInventories()
 	s.updateWeather()
 	s.hourlyResourceRegen()
@@ -457,7 +524,7 @@ func (s *Simulation) updateWeather() {
 		return
 	}
 
-	conditions, err := s.WeatherClient.Fetch()
+	conditions, err := s.fetchWeather()
 	if err != nil {
 		slog.Warn("weather fetch failed", "error", err)
 		return
@@ -530,6 +597,15 @@ func (s *Simulation) TickDay(tick uint64) {
 		trimmed := make([]Event, 1000)
 		copy(trimmed, s.Events[len(s.Events)-1000:])
 		s.Events = trimmed
+		s.UnsavedEvents = min(s.UnsavedEvents, len(s.Events))
+	}
+
+	// Replay checkpoint: journal (or, in a replay, verify) the end-of-day
+	// state hash. See journal.go.
+	if s.Journal != nil {
+		if err := s.Journal.DayHash(tick, s.StateHash()); err != nil {
+			slog.Error("replay journal day hash failed", "tick", tick, "error", err)
+		}
 	}
 }
 
@@ -557,6 +633,7 @@ func (s *Simulation) TickWeek(tick uint64) {
 	s.processCrafterRecovery(tick)
 	s.processCareerTransition(tick)
 	s.processFoodRetraining(tick)
+	s.chronicleSettlements(tick)
 	s.processViabilityCheck(tick)
 	s.processInfrastructureGrowth(tick)
 	s.processSettlementOvermass(tick)
@@ -599,6 +676,7 @@ func (s *Simulation) TickWeek(tick uint64) {
 		trimmed := make([]Event, 1000)
 		copy(trimmed, s.Events[len(s.Events)-1000:])
 		s.Events = trimmed
+		s.UnsavedEvents = min(s.UnsavedEvents, len(s.Events))
 	}
 }
 
@@ -663,9 +741,7 @@ func (s *Simulation) inheritWealth(a *agents.Agent, tick uint64) {
 // with damage attenuating by distance. Larger settlements are more likely targets
 // (population-weighted selection). New event types: drought and plague.
 func (s *Simulation) processRandomEvents(tick uint64) {
-	randFloat := func() float64 {
-		return entropy.FloatFromSource(s.Entropy)
-	}
+	randFloat := s.entropyFloat
 	if len(s.Settlements) == 0 {
 		return
 	}
@@ -1304,7 +1380,7 @@ func (s *Simulation) SettlementCarryingCapacity(settID uint64) (capacity float64
 	// Irrigation boosts effective capacity (same factor as regen).
 	addHexCapacity := func(h *world.Hex) {
 		irrFactor := IrrigationRegenFactor(h.IrrigationLevel)
-		for res, _ := range h.Resources {
+		for _, res := range sortedKeys(h.Resources) { // fixed order: float sums are order-sensitive
 			cap := ResourceCap(h.Terrain, res)
 			capacity += cap * h.Health * irrFactor
 		}
@@ -1558,6 +1634,9 @@ func (s *Simulation) compactDeadAgents() {
 				pruned++
 			}
 		}
+		if n < len(a.Relationships) {
+			a.Dirty |= agents.DirtyRelationships
+		}
 		a.Relationships = a.Relationships[:n]
 	}
 
@@ -37,6 +37,15 @@ type Engine struct {
 	OnWeek   func(tick uint64) // Every 10080 ticks
 	OnSeason func(tick uint64) // Every ~90000 ticks
 
+	// AfterTick runs at the end of every step, after all of that tick's
+	// callbacks (and before AfterHour).
+	AfterTick func(tick uint64)
+
+	// AfterHour runs at the end of every sim-hour step, once the hour's
+	// callbacks (and any day/week/season ones due on the same tick) have all
+	// returned — the point where world state is settled.
+	AfterHour func(tick uint64)
+
 	// loopTasks carries functions to run synchronously inside the tick-loop
 	// goroutine at a safe point between ticks (no agent mutation in flight).
 	// Used for consistent full-state snapshots that must not race the loop.
@@ -142,6 +151,14 @@ func (e *Engine) drainLoopTasks() {
 	}
 }
 
+// Step advances the simulation by exactly one tick and then services queued
+// loop tasks, without sleeping. For headless drivers (replay) that own their
+// own pacing; the live server uses Run.
+func (e *Engine) Step() {
+	e.step()
+	e.drainLoopTasks()
+}
+
 // step advances the simulation by one tick.
 func (e *Engine) step() {
 	e.Tick++
@@ -170,6 +187,13 @@ func (e *Engine) step() {
 	if e.Tick%TicksPerSimSeason == 0 && e.OnSeason != nil {
 		e.OnSeason(e.Tick)
 	}
+
+	if e.AfterTick != nil {
+		e.AfterTick(e.Tick)
+	}
+	if e.Tick%TicksPerSimHour == 0 && e.AfterHour != nil {
+		e.AfterHour(e.Tick)
+	}
 }
 
 // SimTime returns a human-readable simulation time string from a tick number.
@@ -55,7 +55,8 @@ func (s *Simulation) processTradeRoutes(tick uint64) {
 	degraded := 0
 
 	// Check all pairs with trade this week.
-	for key, vol := range s.TradeTracker {
+	for _, key := range sortedRelKeys(s.TradeTracker) {
+		vol := s.TradeTracker[key]
 		route, exists := s.TradeRoutes[key]
 
 		if vol >= routeEstablishThreshold {
@@ -109,7 +110,8 @@ func (s *Simulation) processTradeRoutes(tick uint64) {
 	}
 
 	// Check existing routes with ZERO trade this week (not in TradeTracker).
-	for key, route := range s.TradeRoutes {
+	for _, key := range sortedRelKeys(s.TradeRoutes) {
+		route := s.TradeRoutes[key]
 		if _, hasTradeThisWeek := s.TradeTracker[key]; !hasTradeThisWeek {
 			route.DormantWeeks++
 			if route.SustainedWeeks > 0 {
@@ -241,7 +243,8 @@ func (s *Simulation) GetRouteBonus(settA, settB uint64) (travelDiscount float64,
 // GetSettlementRoutes returns all trade routes involving a settlement.
 func (s *Simulation) GetSettlementRoutes(settID uint64) []TradeRouteInfo {
 	var routes []TradeRouteInfo
-	for key, route := range s.TradeRoutes {
+	for _, key := range sortedRelKeys(s.TradeRoutes) {
+		route := s.TradeRoutes[key]
 		if route.Level == 0 {
 			continue // Only expose established routes.
 		}
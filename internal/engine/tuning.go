@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/economy"
+)
+
+// Tuning holds the engine constants exposed as named knobs. Every tuning
+// round (R88, R90, R97…) used to mean editing a literal and redeploying; knobs
+// let operators change them at startup (the tuning section of the worldsim
+// config), at runtime (POST /api/v1/tuning, journaled and persisted to
+// world_meta), and per run in cmd/worldsim scenario. Defaults are the
+// hand-tuned values the engine shipped with.
+type Tuning struct {
+	// Taxes: collected = taxable × Settlement.TaxRate × Agnosis × TaxScale.
+	TaxScale float64
+
+	// Mortality (see agentDailyMortalityChance). Background risk is
+	// Agnosis^BackgroundPower × scatter with an Agnosis^(BackgroundPower+1)
+	// floor; the age curve is Agnosis^AgePower × sigmoid² centred on AgeOnset.
+	MortalityBackgroundPower float64
+	MortalityAgePower        float64
+	MortalityAgeOnset        float64
+
+	// Resource regeneration, as a fraction of each hex's deficit.
+	RegenHourlyRate   float64 // × Agnosis per sim-hour
+	RegenWeeklyRate   float64 // × Agnosis per sim-week
+	RegenSeasonalRate float64 // × Matter per season
+
+	// Birth gates (see processBirths).
+	BirthMinAge          float64
+	BirthMaxAge          float64
+	BirthMinHealth       float64
+	BirthMinSurvival     float64
+	BirthParentsPerBirth float64 // eligible parents per daily birth at baseline prosperity
+
+	// Settlement size gates and distances. Whole numbers, compared as ints.
+	RaidMinPopulation    float64 // both sides of a raid (see processWarfare)
+	RaidMaxDistance      float64 // hexes; march range
+	ViableSettlementPop  float64 // migration and consolidation targets
+	FoodRetrainingMinPop float64 // see processFoodRetraining
+
+	HeatStreakHours     float64 // sustained heat before crop failure and drought damage
+	OracleMaxReuseWeeks float64 // consecutive weeks a cached vision may be reused
+
+	// Production-cost floor per good; market prices are clamped around it.
+	BasePrices [agents.NumGoods]float64
+}
+
+// Knob describes one named Tuning field. Values outside [Min, Max] are
+// rejected, as are fractional values for Integer knobs.
+type Knob struct {
+	Name    string  `json:"name"`
+	Default float64 `json:"default"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Unit    string  `json:"unit"`
+	Integer bool    `json:"integer,omitempty"`
+	Doc     string  `json:"doc"`
+
+	field func(*Tuning) *float64
+}
+
+var knobs = append([]Knob{
+	{Name: "tax_scale", Default: 1, Min: 0, Max: 5, Unit: "×",
+		Doc:   "multiplier on settlement tax collection",
+		field: func(t *Tuning) *float64 { return &t.TaxScale }},
+	{Name: "mortality_background_power", Default: 6, Min: 2, Max: 12, Unit: "exponent",
+		Doc:   "exponent of Agnosis for adult background mortality (R98: 6)",
+		field: func(t *Tuning) *float64 { return &t.MortalityBackgroundPower }},
+	{Name: "mortality_age_power", Default: 3, Min: 1, Max: 8, Unit: "exponent",
+		Doc:   "exponent of Agnosis scaling the age mortality curve",
+		field: func(t *Tuning) *float64 { return &t.MortalityAgePower }},
+	{Name: "mortality_age_onset", Default: 50, Min: 20, Max: 90, Unit: "sim-years",
+		Doc:   "age at the midpoint of the age mortality sigmoid",
+		field: func(t *Tuning) *float64 { return &t.MortalityAgeOnset }},
+	{Name: "regen_hourly_rate", Default: 0.06, Min: 0, Max: 1, Unit: "fraction",
+		Doc:   "hourly hex regeneration, fraction of deficit × Agnosis",
+		field: func(t *Tuning) *float64 { return &t.RegenHourlyRate }},
+	{Name: "regen_weekly_rate", Default: 0.4, Min: 0, Max: 1, Unit: "fraction",
+		Doc:   "weekly hex regeneration, fraction of deficit × Agnosis",
+		field: func(t *Tuning) *float64 { return &t.RegenWeeklyRate }},
+	{Name: "regen_seasonal_rate", Default: 0.3, Min: 0, Max: 1, Unit: "fraction",
+		Doc:   "seasonal hex regeneration, fraction of deficit × Matter",
+		field: func(t *Tuning) *float64 { return &t.RegenSeasonalRate }},
+	{Name: "birth_min_age", Default: 18, Min: 12, Max: 40, Unit: "sim-years",
+		Doc:   "youngest eligible parent",
+		field: func(t *Tuning) *float64 { return &t.BirthMinAge }},
+	{Name: "birth_max_age", Default: 45, Min: 20, Max: 80, Unit: "sim-years",
+		Doc:   "oldest eligible parent",
+		field: func(t *Tuning) *float64 { return &t.BirthMaxAge }},
+	{Name: "birth_min_health", Default: 0.5, Min: 0, Max: 1, Unit: "fraction",
+		Doc:   "minimum Health of an eligible parent",
+		field: func(t *Tuning) *float64 { return &t.BirthMinHealth }},
+	{Name: "birth_min_survival", Default: 0.3, Min: 0, Max: 1, Unit: "fraction",
+		Doc:   "minimum Survival need of an eligible parent",
+		field: func(t *Tuning) *float64 { return &t.BirthMinSurvival }},
+	{Name: "birth_parents_per_birth", Default: 30, Min: 1, Max: 1000, Unit: "agents",
+		Doc:   "eligible parents per daily birth before prosperity and cap scaling",
+		field: func(t *Tuning) *float64 { return &t.BirthParentsPerBirth }},
+	{Name: "raid_min_population", Default: 50, Min: 0, Max: 10000, Unit: "agents", Integer: true,
+		Doc:   "smallest settlement that raids or is raided",
+		field: func(t *Tuning) *float64 { return &t.RaidMinPopulation }},
+	{Name: "raid_max_distance", Default: 5, Min: 1, Max: 50, Unit: "hexes", Integer: true,
+		Doc:   "march range: hostile settlements farther apart never raid",
+		field: func(t *Tuning) *float64 { return &t.RaidMaxDistance }},
+	{Name: "viable_settlement_population", Default: 50, Min: 0, Max: 10000, Unit: "agents", Integer: true,
+		Doc:   "smallest settlement accepted as a migration or consolidation target",
+		field: func(t *Tuning) *float64 { return &t.ViableSettlementPop }},
+	{Name: "food_retraining_min_population", Default: 50, Min: 0, Max: 10000, Unit: "agents", Integer: true,
+		Doc:   "smallest settlement whose farmers and fishers retrain toward the cheaper food",
+		field: func(t *Tuning) *float64 { return &t.FoodRetrainingMinPop }},
+	{Name: "heat_streak_hours", Default: 72, Min: 24, Max: 720, Unit: "sim-hours", Integer: true,
+		Doc:   "sustained heat before crop failure and drought land damage",
+		field: func(t *Tuning) *float64 { return &t.HeatStreakHours }},
+	// User preference 2026-05-16: bounded reuse to avoid narrative
+	// repetition while still cutting LLM cost.
+	{Name: "oracle_max_reuse_weeks", Default: 2, Min: 0, Max: 52, Unit: "sim-weeks", Integer: true,
+		Doc:   "consecutive weeks an unchanged oracle may reuse its cached vision",
+		field: func(t *Tuning) *float64 { return &t.OracleMaxReuseWeeks }},
+}, basePriceKnobs()...)
+
+// basePriceKnobs declares one base_price_<good> knob per good, defaulting to
+// economy.DefaultBasePrices.
+func basePriceKnobs() []Knob {
+	ks := make([]Knob, agents.NumGoods)
+	for i := range ks {
+		good := agents.GoodType(i)
+		ks[i] = Knob{
+			Name:    "base_price_" + strings.ReplaceAll(goodName(good), " ", "_"),
+			Default: economy.DefaultBasePrices[i],
+			Min:     0.1,
+			Max:     1000,
+			Unit:    "crowns",
+			Doc:     "production-cost floor of " + goodName(good) + "; bounds its market price",
+			field:   func(t *Tuning) *float64 { return &t.BasePrices[good] },
+		}
+	}
+	return ks
+}
+
+// DefaultTuning returns every knob at its default.
+func DefaultTuning() Tuning {
+	var t Tuning
+	for _, k := range knobs {
+		*k.field(&t) = k.Default
+	}
+	return t
+}
+
+// Knobs lists the available knobs in declaration order.
+func Knobs() []Knob {
+	return slices.Clone(knobs)
+}
+
+func findKnob(name string) (Knob, bool) {
+	for _, k := range knobs {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Knob{}, false
+}
+
+// Check reports whether v is an acceptable value for the knob.
+func (k Knob) Check(v float64) error {
+	switch {
+	case math.IsNaN(v) || v < k.Min || v > k.Max:
+		return fmt.Errorf("knob %s: %g is outside [%g, %g] %s", k.Name, v, k.Min, k.Max, k.Unit)
+	case k.Integer && v != math.Trunc(v):
+		return fmt.Errorf("knob %s: %g is not a whole number", k.Name, v)
+	}
+	return nil
+}
+
+// Get returns the current value of the named knob.
+func (t *Tuning) Get(name string) (float64, bool) {
+	k, ok := findKnob(name)
+	if !ok {
+		return 0, false
+	}
+	return *k.field(t), true
+}
+
+// Set changes the named knob. The value must also agree with the knobs it
+// pairs with (see consistent).
+func (t *Tuning) Set(name string, v float64) error {
+	return t.Apply(map[string]float64{name: v})
+}
+
+// Apply sets every knob in overrides, or none of them if any name is unknown,
+// any value out of bounds, or the result inconsistent.
+func (t *Tuning) Apply(overrides map[string]float64) error {
+	next := *t
+	for _, name := range sortedKeys(overrides) {
+		k, ok := findKnob(name)
+		if !ok {
+			return fmt.Errorf("unknown knob %q", name)
+		}
+		if err := k.Check(overrides[name]); err != nil {
+			return err
+		}
+		*k.field(&next) = overrides[name]
+	}
+	if err := next.consistent(); err != nil {
+		return err
+	}
+	*t = next
+	return nil
+}
+
+// consistent checks the combinations of knobs that each knob's own bounds
+// allow but the engine cannot run with.
+func (t *Tuning) consistent() error {
+	if t.BirthMinAge > t.BirthMaxAge {
+		// processBirths would find no eligible parent and stop all births.
+		return fmt.Errorf("knob birth_min_age: %g is above birth_max_age %g", t.BirthMinAge, t.BirthMaxAge)
+	}
+	return nil
+}
+
+// Overrides returns the knobs whose value differs from the default, by name.
+// Persisting only these lets a later release retune a default without a saved
+// world pinning the old one.
+func (t *Tuning) Overrides() map[string]float64 {
+	vals := map[string]float64{}
+	for _, k := range knobs {
+		if v := *k.field(t); v != k.Default {
+			vals[k.Name] = v
+		}
+	}
+	return vals
+}
+
+// ValidateTuning checks overrides against the registry, on top of the
+// defaults, without applying them.
+func ValidateTuning(overrides map[string]float64) error {
+	t := DefaultTuning()
+	return t.Apply(overrides)
+}
+
+// ApplyTuning sets knobs on the running world. Unlike Tuning.Apply it also
+// carries base-price changes into every existing market, so it is the entry
+// point for anything but a freshly constructed Simulation. Mutates world
+// state: call it on the tick-loop goroutine.
+func (s *Simulation) ApplyTuning(overrides map[string]float64) error {
+	if err := s.Tuning.Apply(overrides); err != nil {
+		return err
+	}
+	s.syncBasePrices()
+	return nil
+}
+
+// syncBasePrices copies the base-price knobs into every market entry.
+func (s *Simulation) syncBasePrices() {
+	for _, sett := range s.Settlements {
+		if sett.Market == nil {
+			continue
+		}
+		for good, entry := range sett.Market.Entries {
+			if int(good) < len(s.Tuning.BasePrices) {
+				entry.BasePrice = s.Tuning.BasePrices[good]
+			}
+		}
+	}
+}
+
+// newMarket creates a market priced from the current base-price knobs.
+func (s *Simulation) newMarket(settlementID uint64) *economy.Market {
+	m := economy.NewMarket(settlementID)
+	for good, entry := range m.Entries {
+		entry.BasePrice = s.Tuning.BasePrices[good]
+		entry.Price = entry.BasePrice
+	}
+	return m
+}
+
+// ErrUnknownKnob is returned by SetKnob for a name not in the registry.
+var ErrUnknownKnob = errors.New("unknown knob")
+
+// SetKnob changes one knob at runtime and emits an audit event recording the
+// old and new values. Used by the "tune" intervention, so changes made through
+// the admin API are journaled like any other intervention.
+func (s *Simulation) SetKnob(name string, v float64) (string, error) {
+	old, ok := s.Tuning.Get(name)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKnob, name)
+	}
+	if err := s.ApplyTuning(map[string]float64{name: v}); err != nil {
+		return "", err
+	}
+
+	desc := fmt.Sprintf("Tuning knob %s changed from %g to %g", name, old, v)
+	s.EmitEvent(Event{
+		Tick:        s.LastTick,
+		Description: desc,
+		Category:    eventproto.CategoryAdmin,
+		Meta: map[string]any{
+			"knob": name,
+			"old":  old,
+			"new":  v,
+		},
+	})
+	slog.Info("tuning knob changed", "knob", name, "old", old, "new", v)
+	return desc, nil
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// TestTuningSetRejectsBadValues verifies Set enforces each knob's bounds and
+// integrality and leaves the old value in place on rejection.
+func TestTuningSetRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		knob  string
+		value float64
+		ok    bool
+	}{
+		{"in range", "tax_scale", 0.5, true},
+		{"lower bound", "tax_scale", 0, true},
+		{"above max", "tax_scale", 5.01, false},
+		{"below min", "raid_max_distance", 0, false},
+		{"fractional integer knob", "heat_streak_hours", 72.5, false},
+		{"whole integer knob", "heat_streak_hours", 96, true},
+		{"unknown knob", "no_such_knob", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tun := DefaultTuning()
+			before, _ := tun.Get(tt.knob)
+			err := tun.Set(tt.knob, tt.value)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Set(%s, %g) error = %v, want ok=%v", tt.knob, tt.value, err, tt.ok)
+			}
+			got, _ := tun.Get(tt.knob)
+			if tt.ok && got != tt.value {
+				t.Errorf("value = %g, want %g", got, tt.value)
+			}
+			if !tt.ok && got != before {
+				t.Errorf("rejected Set changed value %g → %g", before, got)
+			}
+		})
+	}
+
+	tun := DefaultTuning()
+	if o := tun.Overrides(); len(o) != 0 {
+		t.Errorf("defaults report overrides: %v", o)
+	}
+}
+
+// TestTuningRejectsInvertedBirthAges checks a birth age window with its
+// minimum above its maximum is refused however it is reached, and that a
+// pair moved together may pass through one.
+func TestTuningRejectsInvertedBirthAges(t *testing.T) {
+	tun := DefaultTuning()
+	if err := tun.Set("birth_max_age", 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := tun.Set("birth_min_age", 40); err == nil || tun.BirthMinAge != 18 {
+		t.Errorf("min above max: %v, min now %g", err, tun.BirthMinAge)
+	}
+	if err := ValidateTuning(map[string]float64{"birth_min_age": 40, "birth_max_age": 20}); err == nil {
+		t.Error("inverted overrides validated")
+	}
+	// Applied one at a time in name order, max=22 would land first, below
+	// min=25; together they are fine.
+	tun.Set("birth_min_age", 25)
+	if err := tun.Apply(map[string]float64{"birth_min_age": 12, "birth_max_age": 22}); err != nil {
+		t.Errorf("consistent pair refused: %v", err)
+	}
+	if err := tun.Apply(map[string]float64{"birth_min_age": 38, "birth_max_age": 22, "tax_scale": 2}); err == nil || tun.TaxScale != 1 {
+		t.Errorf("inverted batch: %v, tax_scale now %g", err, tun.TaxScale)
+	}
+}
+
+// TestSetKnobSyncsMarketsAndAudits verifies a runtime base-price change
+// reaches existing markets and leaves an admin audit event.
+func TestSetKnobSyncsMarketsAndAudits(t *testing.T) {
+	m := world.Generate(world.GenConfig{Radius: 3, Seed: 1, SeaLevel: 0.3, MountainLvl: 0.8, Noise: world.DefaultNoiseParams()})
+	sett := &social.Settlement{ID: 1, Name: "Ashford"}
+	s := NewSimulation(m, nil, []*social.Settlement{sett})
+
+	if _, err := s.SetKnob("base_price_grain", 3.5); err != nil {
+		t.Fatal(err)
+	}
+	if got := sett.Market.Entries[agents.GoodGrain].BasePrice; got != 3.5 {
+		t.Errorf("grain base price = %g, want 3.5", got)
+	}
+	if o := s.Tuning.Overrides(); len(o) != 1 || o["base_price_grain"] != 3.5 {
+		t.Errorf("overrides = %v", o)
+	}
+	if len(s.Events) != 1 || s.Events[0].Category != eventproto.CategoryAdmin {
+		t.Fatalf("want one admin event, got %+v", s.Events)
+	}
+	if meta := s.Events[0].Meta; meta["old"] != 2.0 || meta["new"] != 3.5 {
+		t.Errorf("audit meta = %v", meta)
+	}
+
+	if _, err := s.SetKnob("base_price_grain", -1); err == nil {
+		t.Error("out-of-bounds SetKnob accepted")
+	}
+	if len(s.Events) != 1 {
+		t.Error("rejected SetKnob emitted an event")
+	}
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// ── Read model ──────────────────────────────────────────────────────
+//
+// HTTP handlers used to read Sim.Agents, Settlements and the relation maps
+// straight from their own goroutines while the tick loop mutated them — a
+// data race that could serve half-updated state or crash on a concurrent map
+// read. Instead the loop publishes a detached copy of the world at a safe
+// point (Engine.AfterHour, and after admin interventions), and readers load
+// the latest one through an atomic pointer. A view is never mutated after it
+// is published, so any number of readers may share it without locking, and a
+// slow request can never hold up a tick.
+
+// PublishView snapshots the world and makes it the current view. Must run on
+// the tick-loop goroutine (or before the loop starts).
+func (s *Simulation) PublishView() {
+	s.view.Store(s.snapshot())
+}
+
+// View returns the most recently published snapshot, or nil if none has been
+// published. It is a *Simulation so read-only helpers (SettlementCarryingCapacity,
+// GetSettlementRoutes, …) work on it unchanged, but it is a read model only:
+// callers must not modify it, and integrations, the journal, the spawner and
+// the tick-internal caches are nil.
+func (s *Simulation) View() *Simulation {
+	return s.view.Load()
+}
+
+// snapshot deep-copies the state the API reads. Agents are copied once and
+// AgentIndex/SettlementAgents are rebuilt over the copies, so pointers within
+// the view stay consistent with each other.
+func (s *Simulation) snapshot() *Simulation {
+	v := &Simulation{
+		LastTick:             s.LastTick,
+		CurrentSeason:        s.CurrentSeason,
+		CurrentWeather:       s.CurrentWeather,
+		Tuning:               s.Tuning,
+		Stats:                s.Stats,
+		HeatStreakHours:      s.HeatStreakHours,
+		LiberatedSpiritsPool: s.LiberatedSpiritsPool,
+		LastNewspaperContent: s.LastNewspaperContent,
+		WorldMap:             s.WorldMap.Clone(),
+		Events:               slices.Clone(s.Events),
+		UnsavedEvents:        s.UnsavedEvents,
+		UnsavedLineage:       slices.Clone(s.UnsavedLineage),
+		UnsavedGraves:        slices.Clone(s.UnsavedGraves),
+		UnsavedArchives:      slices.Clone(s.UnsavedArchives),
+	}
+
+	v.Agents = make([]*agents.Agent, len(s.Agents))
+	v.AgentIndex = make(map[agents.AgentID]*agents.Agent, len(s.AgentIndex))
+	for i, a := range s.Agents {
+		c := a.Clone()
+		v.Agents[i] = c
+		v.AgentIndex[c.ID] = c
+	}
+	// AgentIndex can hold agents no longer in Agents (between death and
+	// compaction); copy those too so lookups by ID behave as on the live sim.
+	for id, a := range s.AgentIndex {
+		if _, ok := v.AgentIndex[id]; !ok {
+			v.AgentIndex[id] = a.Clone()
+		}
+	}
+	v.SettlementAgents = make(map[uint64][]*agents.Agent, len(s.SettlementAgents))
+	for id, list := range s.SettlementAgents {
+		copies := make([]*agents.Agent, len(list))
+		for i, a := range list {
+			if c, ok := v.AgentIndex[a.ID]; ok {
+				copies[i] = c
+			} else {
+				copies[i] = a.Clone()
+			}
+		}
+		v.SettlementAgents[id] = copies
+	}
+
+	v.Settlements = make([]*social.Settlement, len(s.Settlements))
+	v.SettlementIndex = make(map[uint64]*social.Settlement, len(s.SettlementIndex))
+	for i, sett := range s.Settlements {
+		c := sett.Clone()
+		v.Settlements[i] = c
+		v.SettlementIndex[c.ID] = c
+	}
+	for id, sett := range s.SettlementIndex {
+		if _, ok := v.SettlementIndex[id]; !ok {
+			v.SettlementIndex[id] = sett.Clone()
+		}
+	}
+
+	v.Factions = make([]*social.Faction, len(s.Factions))
+	for i, f := range s.Factions {
+		v.Factions[i] = f.Clone()
+	}
+
+	v.Relations = cloneValues(s.Relations)
+	v.TradeRoutes = cloneValues(s.TradeRoutes)
+	v.Agreements = cloneValues(s.Agreements)
+	v.PeaceTreaties = cloneValues(s.PeaceTreaties)
+	v.RaidCounts = maps.Clone(s.RaidCounts)
+
+	// Records are settled in place; their Before/After maps are written once
+	// and never modified, so a shallow copy is enough.
+	v.Interventions = make([]*InterventionRecord, len(s.Interventions))
+	for i, rec := range s.Interventions {
+		c := *rec
+		v.Interventions[i] = &c
+	}
+	return v
+}
+
+// cloneValues copies a map of pointers to flat structs, pointee and all.
+func cloneValues[K comparable, V any](m map[K]*V) map[K]*V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]*V, len(m))
+	for k, p := range m {
+		v := *p
+		c[k] = &v
+	}
+	return c
+}
@@ -41,7 +41,8 @@ func (s *Simulation) processWarfare(tick uint64) {
 	victories := 0
 	defeats := 0
 
-	for key, rel := range s.Relations {
+	for _, key := range sortedRelKeys(s.Relations) {
+		rel := s.Relations[key]
 		// Only consider hostile pairs.
 		if rel.Sentiment >= -phi.Agnosis {
 			continue
@@ -49,12 +50,13 @@ func (s *Simulation) processWarfare(tick uint64) {
 
 		settA, okA := s.SettlementIndex[key.A]
 		settB, okB := s.SettlementIndex[key.B]
-		if !okA || !okB || settA.Population < 50 || settB.Population < 50 {
+		minPop := uint32(s.Tuning.RaidMinPopulation)
+		if !okA || !okB || settA.Population < minPop || settB.Population < minPop {
 			continue
 		}
 
 		dist := world.Distance(settA.Position, settB.Position)
-		if dist > 5 {
+		if dist > int(s.Tuning.RaidMaxDistance) {
 			continue // Beyond march range.
 		}
 
@@ -187,7 +189,8 @@ func (s *Simulation) resolveRaid(tick uint64, attacker, defender *social.Settlem
 	defenseStrength *= (1.0 + defender.GovernanceScore)
 
 	// Alliance reinforcements: mutual defense allies contribute.
-	for key, agreement := range s.Agreements {
+	for _, key := range sortedRelKeys(s.Agreements) {
+		agreement := s.Agreements[key]
 		if agreement.Type < AgreementAlliance {
 			continue
 		}
@@ -267,6 +270,10 @@ func (s *Simulation) resolveRaid(tick uint64, attacker, defender *social.Settlem
 	}
 	loserSett.Treasury -= plunder
 	winnerSett.Treasury += plunder
+	if plunder > 0 {
+		s.logChange(Change{Kind: ChangeTreasuryTransfer, Tick: tick, Amount: plunder,
+			Balances: []TreasuryBalance{settlementBalance(loserSett), settlementBalance(winnerSett)}})
+	}
 
 	// Hex capture: victorious attacker takes one border hex if available.
 	var capturedHex *world.HexCoord
@@ -390,7 +397,7 @@ func (s *Simulation) captureHex(tick uint64, winner, loser *social.Settlement) *
 
 	// Find defender hexes adjacent to attacker territory. Pick highest health.
 	var bestHex *world.Hex
-	for _, h := range s.WorldMap.Hexes {
+	for _, h := range sortedHexes(s.WorldMap) {
 		if h.ClaimedBy == nil || *h.ClaimedBy != loser.ID {
 			continue
 		}
@@ -423,6 +430,7 @@ func (s *Simulation) captureHex(tick uint64, winner, loser *social.Settlement) *
 	winnerID := winner.ID
 	bestHex.ClaimedBy = &winnerID
 	coord := bestHex.Coord
+	s.logChange(Change{Kind: ChangeHexClaimed, Tick: tick, Hex: coord, ClaimedBy: &winnerID})
 
 	slog.Info("hex captured",
 		"winner", winner.Name,
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"io"
 	"log/slog"
+	mrand "math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -22,6 +23,8 @@ type Client struct {
 
 	mu   sync.Mutex
 	pool []float64
+
+	seeded *mrand.Rand // set by NewDeterministic; replaces both random.org and crypto/rand
 }
 
 // NewClient creates a random.org client. Returns nil if apiKey is empty.
@@ -35,6 +38,13 @@ func NewClient(apiKey string) *Client {
 	}
 }
 
+// NewDeterministic returns an offline client whose stream is a pure function
+// of seed. Batch and scenario runs use it so the same seed reproduces the same
+// world without touching the network.
+func NewDeterministic(seed int64) *Client {
+	return &Client{seeded: mrand.New(mrand.NewSource(seed))}
+}
+
 // Float returns a random float64 in [0, 1). Uses the pool, refilling from
 // random.org when low. Falls back to crypto/rand on API failure.
 func (c *Client) Float() float64 {
@@ -45,6 +55,10 @@ func (c *Client) Float() float64 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.seeded != nil {
+		return c.seeded.Float64()
+	}
+
 	if len(c.pool) < 10 {
 		c.refill()
 	}
@@ -133,9 +147,9 @@ func CryptoFloat() float64 {
 	return cryptoRandFloat()
 }
 
-// Enabled returns true if the client has a valid API key.
+// Enabled returns true if the client has a valid API key or is deterministic.
 func (c *Client) Enabled() bool {
-	return c != nil && c.apiKey != ""
+	return c != nil && (c.apiKey != "" || c.seeded != nil)
 }
 
 // FloatFromSource returns a random float from the client if available, or crypto/rand.
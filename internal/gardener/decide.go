@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/talgya/mini-world/internal/llm"
+	"github.com/talgya/mini-world/pkg/worldclient"
 )
 
 const systemPrompt = `You are the Gardener, an autonomous steward of Crossworlds — a persistent simulated world with tens of thousands of agents living across hundreds of settlements.
@@ -404,7 +405,7 @@ func formatSnapshot(snap *WorldSnapshot, health *WorldHealth, memory *CycleMemor
 	return b.String()
 }
 
-func treasuryPct(e EconomyData) float64 {
+func treasuryPct(e worldclient.Economy) float64 {
 	total := e.AgentWealth + e.TreasuryWealth
 	if total == 0 {
 		return 0
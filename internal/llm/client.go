@@ -51,6 +51,43 @@ type Client struct {
 	providers  []*provider
 	cooldownMu sync.Mutex
 	cooldowns  map[string]time.Time // provider name → skip-until time
+
+	// Replay journal hook (nil = call providers directly). See Recorder.
+	recorder Recorder
+}
+
+// Recorder journals completions for deterministic replay (internal/replay).
+// Completion receives the call's tag and a live func that performs the real
+// provider dispatch: a recording implementation calls live and persists the
+// result (text or error); a replaying one returns the recorded result for the
+// next call under that tag without calling live. Completions are keyed by tag
+// because API handlers (newspaper, biography) share this client from their
+// own goroutines — per-tag ordering stays deterministic for the tick loop's
+// tags even though the interleaving across tags does not. Implementations
+// must be safe for concurrent use.
+type Recorder interface {
+	Completion(tag string, live func() (string, error)) (string, error)
+}
+
+// SetRecorder attaches a replay journal recorder to the client. Must be
+// called before the client is shared with other goroutines.
+func (c *Client) SetRecorder(r Recorder) {
+	c.recorder = r
+}
+
+// NewReplayClient returns a client with no providers that answers every
+// completion from r. Enabled reports true so the simulation takes the same
+// LLM-dependent branches it took when the journal was recorded.
+func NewReplayClient(r Recorder) *Client {
+	return &Client{
+		recorder:           r,
+		callsByTag:         make(map[string]int64),
+		tokensByTag:        make(map[string][2]int64),
+		cacheTokensByTag:   make(map[string][2]int64),
+		trackStart:         time.Now(),
+		cooldowns:          make(map[string]time.Time),
+		failuresByProvider: make(map[string]int),
+	}
 }
 
 // NewClient creates an LLM client backed by a provider chain resolved from the
@@ -85,9 +122,10 @@ func NewClient(apiKey string) *Client {
 	return c
 }
 
-// Enabled returns true if the client has at least one configured provider.
+// Enabled returns true if the client has at least one configured provider,
+// or is a replay client answering from a journal.
 func (c *Client) Enabled() bool {
-	return c != nil && len(c.providers) > 0
+	return c != nil && (len(c.providers) > 0 || c.recorder != nil)
 }
 
 // Message represents a chat message.
@@ -266,48 +304,58 @@ func (c *Client) doRequest(body []byte, tag, providerName string) (string, error
 	return apiResp.Content[0].Text, nil
 }
 
-// UsageSummary returns current tracking period counters.
-func (c *Client) UsageSummary() map[string]any {
+// Usage is the LLM spend in the current tracking period.
+type Usage struct {
+	PeriodStart           string              `json:"period_start"`    // RFC 3339
+	PeriodDuration        string              `json:"period_duration"` // e.g. "3h2m0s"
+	TotalCalls            int64               `json:"total_calls"`
+	TotalInputTokens      int64               `json:"total_input_tokens"`
+	TotalOutputTokens     int64               `json:"total_output_tokens"`
+	TotalCacheWriteTokens int64               `json:"total_cache_write_tokens"`
+	TotalCacheReadTokens  int64               `json:"total_cache_read_tokens"`
+	ByTag                 map[string]TagUsage `json:"by_tag"`
+}
+
+// TagUsage is the spend of one call tag (biography, newspaper, ...).
+type TagUsage struct {
+	Calls            int64 `json:"calls"`
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	CacheWriteTokens int64 `json:"cache_write_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens"`
+}
+
+// UsageSummary returns current tracking period counters, or nil when the
+// client is nil (LLM disabled).
+func (c *Client) UsageSummary() *Usage {
 	if c == nil {
 		return nil
 	}
 	c.trackMu.Lock()
 	defer c.trackMu.Unlock()
 
-	totalCalls := int64(0)
-	totalInput := int64(0)
-	totalOutput := int64(0)
-	totalCacheWrite := int64(0)
-	totalCacheRead := int64(0)
-	perTag := make(map[string]map[string]int64)
-
+	u := &Usage{
+		PeriodStart:    c.trackStart.UTC().Format(time.RFC3339),
+		PeriodDuration: time.Since(c.trackStart).Truncate(time.Second).String(),
+		ByTag:          make(map[string]TagUsage),
+	}
 	for tag, calls := range c.callsByTag {
-		totalCalls += calls
 		tok := c.tokensByTag[tag]
 		cacheTok := c.cacheTokensByTag[tag]
-		totalInput += tok[0]
-		totalOutput += tok[1]
-		totalCacheWrite += cacheTok[0]
-		totalCacheRead += cacheTok[1]
-		perTag[tag] = map[string]int64{
-			"calls":              calls,
-			"input_tokens":       tok[0],
-			"output_tokens":      tok[1],
-			"cache_write_tokens": cacheTok[0],
-			"cache_read_tokens":  cacheTok[1],
+		u.TotalCalls += calls
+		u.TotalInputTokens += tok[0]
+		u.TotalOutputTokens += tok[1]
+		u.TotalCacheWriteTokens += cacheTok[0]
+		u.TotalCacheReadTokens += cacheTok[1]
+		u.ByTag[tag] = TagUsage{
+			Calls:            calls,
+			InputTokens:      tok[0],
+			OutputTokens:     tok[1],
+			CacheWriteTokens: cacheTok[0],
+			CacheReadTokens:  cacheTok[1],
 		}
 	}
-
-	return map[string]any{
-		"period_start":             c.trackStart.UTC().Format(time.RFC3339),
-		"period_duration":          time.Since(c.trackStart).Truncate(time.Second).String(),
-		"total_calls":              totalCalls,
-		"total_input_tokens":       totalInput,
-		"total_output_tokens":      totalOutput,
-		"total_cache_write_tokens": totalCacheWrite,
-		"total_cache_read_tokens":  totalCacheRead,
-		"by_tag":                   perTag,
-	}
+	return u
 }
 
 // logUsagePeriodically logs a usage summary every hour.
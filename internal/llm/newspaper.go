@@ -38,6 +38,11 @@ type NewspaperData struct {
 	// Faction dynamics.
 	FactionNews []string
 
+	// Dynasties from the family tree: most living members, and the lines
+	// that have lasted longest.
+	LargestFamilies []DynastySummary
+	OldestLineages  []DynastySummary
+
 	// Wheeler coherence state of the world.
 	AvgCoherence    float32
 	CoherenceCounts CoherenceDistribution
@@ -63,6 +68,25 @@ type AgentSummary struct {
 	Coherence   float32
 }
 
+// DynastySummary describes a founder's line of descent.
+type DynastySummary struct {
+	Founder     string
+	Living      int
+	Members     int
+	Generations int
+	Years       float64 // since the first birth in the line
+	Extinct     bool
+}
+
+func (d DynastySummary) describe() string {
+	if d.Extinct {
+		return fmt.Sprintf("the line of %s: %d members over %d generations, %.1f years, now extinct",
+			d.Founder, d.Members, d.Generations, d.Years)
+	}
+	return fmt.Sprintf("the line of %s: %d living of %d members, %d generations, %.1f years",
+		d.Founder, d.Living, d.Members, d.Generations, d.Years)
+}
+
 // MarketPriceSummary describes a notable market price.
 type MarketPriceSummary struct {
 	Good       string
@@ -230,6 +254,18 @@ func buildNewspaperPrompt(data *NewspaperData, priorContent string) string {
 		b.WriteString("\n")
 	}
 
+	if len(data.LargestFamilies) > 0 || len(data.OldestLineages) > 0 {
+		fmt.Fprintf(&b, "GREAT FAMILIES (largest living):\n")
+		for _, d := range data.LargestFamilies {
+			fmt.Fprintf(&b, "- %s\n", d.describe())
+		}
+		fmt.Fprintf(&b, "OLDEST LINEAGES:\n")
+		for _, d := range data.OldestLineages {
+			fmt.Fprintf(&b, "- %s\n", d.describe())
+		}
+		b.WriteString("\n")
+	}
+
 	if len(data.TopSettlements) > 0 {
 		fmt.Fprintf(&b, "TOP SETTLEMENTS:\n")
 		for _, s := range data.TopSettlements {
@@ -304,6 +340,14 @@ func generateFallbackNewspaper(data *NewspaperData) string {
 		b.WriteString("\n")
 	}
 
+	if len(data.LargestFamilies) > 0 {
+		fmt.Fprintf(&b, "GREAT FAMILIES\n")
+		for _, d := range data.LargestFamilies {
+			fmt.Fprintf(&b, "- %s\n", d.describe())
+		}
+		b.WriteString("\n")
+	}
+
 	if data.Weather != "" {
 		fmt.Fprintf(&b, "WEATHER REPORT\n")
 		fmt.Fprintf(&b, "%s\n\n", data.Weather)
@@ -185,8 +185,19 @@ func providerNames(chain []*provider) []string {
 // dispatch runs one logical completion across the provider chain in priority
 // order, skipping providers that are in a post-cap cooldown. A usage-cap error
 // arms that provider's cooldown. `cached` requests Anthropic prompt caching;
-// it is ignored by OpenAI-compatible providers.
+// it is ignored by OpenAI-compatible providers. With a replay Recorder
+// attached, the recorder decides whether the chain is consulted at all.
 func (c *Client) dispatch(system, userPrompt string, maxTokens int, tag string, cached bool) (string, error) {
+	if c.recorder != nil {
+		return c.recorder.Completion(tag, func() (string, error) {
+			return c.dispatchLive(system, userPrompt, maxTokens, tag, cached)
+		})
+	}
+	return c.dispatchLive(system, userPrompt, maxTokens, tag, cached)
+}
+
+// dispatchLive is dispatch without the replay hook.
+func (c *Client) dispatchLive(system, userPrompt string, maxTokens int, tag string, cached bool) (string, error) {
 	if len(c.providers) == 0 {
 		return "", fmt.Errorf("no LLM providers configured")
 	}
@@ -0,0 +1,611 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/checkpoint"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// ── Binary checkpoints ──────────────────────────────────────────────
+//
+// A checkpoint is the world's primary save: one file, written whole and
+// renamed into place, in the container format of internal/checkpoint. Unlike
+// the SQL save it includes memories and relationships and every agent field,
+// and it writes 100K agents in well under a second (see BenchmarkCheckpoint).
+// SQLite stays the home of queryable history: events, stats, the
+// intervention ledger.
+//
+// Each subsystem is one section with its own schema version. Changing what a
+// section holds means bumping its version and keeping a decoder for the old
+// one. Readers reject versions newer than they know.
+//
+// The registry (world_state.go) is reused unchanged. Its fields are saved
+// into an in-memory MetaStore, and that map becomes the world_meta section.
+
+// Section schema versions.
+const (
+	ckptWorldV         = 1
+	ckptAgentsV        = 2 // v2: nil and empty relationship/memory slices differ
+	ckptSettlementsV   = 1
+	ckptFactionsV      = 1
+	ckptHexesV         = 1
+	ckptMetaV          = 1
+	ckptInterventionsV = 1
+)
+
+// Checkpoint is a decoded world checkpoint.
+type Checkpoint struct {
+	Tick          uint64
+	Season        uint8
+	Gen           world.GenConfig
+	SavedAt       time.Time
+	Agents        []*agents.Agent // in sim order, dead ones not yet compacted included
+	Settlements   []*social.Settlement
+	Factions      []*social.Faction
+	Interventions []*engine.InterventionRecord
+
+	hexes []hexState
+	meta  metaMap
+}
+
+type hexState struct {
+	coord             world.HexCoord
+	health            float64
+	lastExtractedTick uint64
+	irrigation        uint8
+	conservation      uint8
+	claimedBy         *uint64
+	resources         map[world.ResourceType]float64
+}
+
+// SaveCheckpoint writes sim to a checkpoint at path. The file is written
+// beside path and renamed over it, so a crash mid-save leaves the previous
+// checkpoint intact. Must run on the tick-loop goroutine.
+func SaveCheckpoint(path string, sim *engine.Simulation, gen world.GenConfig) error {
+	start := time.Now()
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp) // no-op after the rename
+
+	w := checkpoint.NewWriter(f)
+	if err := writeCheckpoint(w, sim, gen); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("install checkpoint: %w", err)
+	}
+
+	var size int64
+	if fi, err := os.Stat(path); err == nil {
+		size = fi.Size()
+	}
+	slog.Info("checkpoint saved", "path", path, "tick", sim.CurrentTick(),
+		"agents", len(sim.Agents), "bytes", size, "took", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func writeCheckpoint(w *checkpoint.Writer, sim *engine.Simulation, gen world.GenConfig) error {
+	meta := metaMap{}
+	if err := saveLatePersisted(sim, meta); err != nil {
+		return err
+	}
+	ledger, err := json.Marshal(sim.Interventions)
+	if err != nil {
+		return fmt.Errorf("encode interventions: %w", err)
+	}
+
+	w.WriteSection("world", ckptWorldV, encodeWorldSection(sim, gen))
+	w.WriteSection("agents", ckptAgentsV, encodeAgents(sim.Agents))
+	w.WriteSection("settlements", ckptSettlementsV, encodeSettlements(sim.Settlements))
+	w.WriteSection("factions", ckptFactionsV, encodeFactions(sim.Factions))
+	w.WriteSection("hexes", ckptHexesV, encodeHexes(sim.WorldMap))
+	w.WriteSection("world_meta", ckptMetaV, encodeMeta(meta))
+	return w.WriteSection("interventions", ckptInterventionsV, ledger)
+}
+
+// LoadCheckpoint reads and verifies the checkpoint at path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	file, err := checkpoint.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c := &Checkpoint{}
+	decoders := []struct {
+		name    string
+		version uint16
+		decode  func(*checkpoint.Decoder, uint16)
+	}{
+		{"world", ckptWorldV, func(d *checkpoint.Decoder, _ uint16) { c.decodeWorldSection(d) }},
+		{"agents", ckptAgentsV, func(d *checkpoint.Decoder, v uint16) { c.Agents = decodeAgents(d, v) }},
+		{"settlements", ckptSettlementsV, func(d *checkpoint.Decoder, _ uint16) { c.Settlements = decodeSettlements(d) }},
+		{"factions", ckptFactionsV, func(d *checkpoint.Decoder, _ uint16) { c.Factions = decodeFactions(d) }},
+		{"hexes", ckptHexesV, func(d *checkpoint.Decoder, _ uint16) { c.hexes = decodeHexes(d) }},
+		{"world_meta", ckptMetaV, func(d *checkpoint.Decoder, _ uint16) { c.meta = decodeMeta(d) }},
+	}
+	for _, dec := range decoders {
+		s, ok := file.Section(dec.name)
+		if !ok {
+			return nil, fmt.Errorf("%s: checkpoint has no %s section", path, dec.name)
+		}
+		if s.Version > dec.version {
+			return nil, fmt.Errorf("%s: %s section schema %d is newer than this build (%d)", path, dec.name, s.Version, dec.version)
+		}
+		d := checkpoint.NewDecoder(s.Data)
+		dec.decode(d, s.Version)
+		if err := d.Finish(); err != nil {
+			return nil, fmt.Errorf("%s: %s section: %w", path, dec.name, err)
+		}
+	}
+	if s, ok := file.Section("interventions"); ok {
+		if err := json.Unmarshal(s.Data, &c.Interventions); err != nil {
+			return nil, fmt.Errorf("%s: interventions section: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+// RestoreHexes applies the saved hex state to a map regenerated from Gen.
+func (c *Checkpoint) RestoreHexes(m *world.Map) {
+	for _, h := range c.hexes {
+		hex := m.Get(h.coord)
+		if hex == nil {
+			continue
+		}
+		hex.Health = h.health
+		hex.LastExtractedTick = h.lastExtractedTick
+		hex.IrrigationLevel = h.irrigation
+		hex.ConservationLevel = h.conservation
+		hex.ClaimedBy = h.claimedBy
+		hex.Resources = h.resources
+	}
+}
+
+// RestoreLatePersisted loads every registry field from the checkpoint.
+func (c *Checkpoint) RestoreLatePersisted(sim *engine.Simulation) {
+	restoreLatePersisted(sim, c.meta)
+}
+
+// ── Section codecs ──────────────────────────────────────────────────
+
+func encodeWorldSection(sim *engine.Simulation, gen world.GenConfig) []byte {
+	e := checkpoint.NewEncoder(256)
+	e.Uint(sim.CurrentTick())
+	e.Uint8(sim.CurrentSeason)
+	e.Int(time.Now().Unix())
+	genJSON, _ := json.Marshal(gen)
+	e.RawBytes(genJSON)
+	return e.Bytes()
+}
+
+func (c *Checkpoint) decodeWorldSection(d *checkpoint.Decoder) {
+	c.Tick = d.Uint()
+	c.Season = d.Uint8()
+	c.SavedAt = time.Unix(d.Int(), 0).UTC()
+	if genJSON := d.RawBytes(); d.Err() == nil {
+		if err := json.Unmarshal(genJSON, &c.Gen); err != nil {
+			slog.Warn("checkpoint: unreadable world parameters", "error", err)
+		}
+	}
+}
+
+// encodeAgents writes every field of every agent. The slice order is kept
+// (the engine shuffles it hourly from a tick seed), and so are the dead not
+// yet compacted. A field added to agents.Agent must be added here and in
+// decodeAgent, with a version bump — TestCheckpointAgentRoundTrip fails until
+// it is.
+func encodeAgents(list []*agents.Agent) []byte {
+	e := checkpoint.NewEncoder(len(list) * 256)
+	e.Uint(uint64(len(list)))
+	for _, a := range list {
+		encodeAgent(e, a)
+	}
+	return e.Bytes()
+}
+
+func encodeAgent(e *checkpoint.Encoder, a *agents.Agent) {
+	e.Uint(uint64(a.ID))
+	e.String(a.Name)
+	e.Uint(uint64(a.Age))
+	e.Uint8(a.AgeMonths)
+	e.Uint8(uint8(a.Sex))
+	e.Float32(a.Health)
+
+	encodeCoord(e, a.Position)
+	e.OptUint(a.HomeSettID)
+	e.Bool(a.Destination != nil)
+	if a.Destination != nil {
+		encodeCoord(e, *a.Destination)
+	}
+
+	e.Uint8(uint8(a.Occupation))
+	encodeInventory(e, a.Inventory)
+	e.Uint(a.Wealth)
+	e.Float32(a.Skills.Farming)
+	e.Float32(a.Skills.Mining)
+	e.Float32(a.Skills.Crafting)
+	e.Float32(a.Skills.Combat)
+	e.Float32(a.Skills.Trade)
+
+	e.SliceLen(len(a.Relationships), a.Relationships == nil)
+	for _, r := range a.Relationships {
+		e.Uint(uint64(r.TargetID))
+		e.Float32(r.Sentiment)
+		e.Float32(r.Trust)
+	}
+	e.OptUint(a.FactionID)
+	e.Uint8(uint8(a.Role))
+
+	e.Uint8(uint8(a.Tier))
+	e.String(a.Archetype)
+	e.Float32(a.Wellbeing.Satisfaction)
+	e.Float32(a.Wellbeing.Alignment)
+	e.Float32(a.Wellbeing.EffectiveMood)
+
+	e.Float32(a.Soul.CittaCoherence)
+	e.Float32(a.Soul.Mass)
+	e.Float32(a.Soul.Gauss)
+	e.Uint8(uint8(a.Soul.State))
+	e.Uint8(uint8(a.Soul.Class))
+	e.Float32(a.Soul.WisdomScore)
+	e.Uint(uint64(a.Soul.WisdomEffort))
+	e.Bool(a.Soul.Reincarnated)
+
+	e.Float32(a.Needs.Survival)
+	e.Float32(a.Needs.Safety)
+	e.Float32(a.Needs.Belonging)
+	e.Float32(a.Needs.Esteem)
+	e.Float32(a.Needs.Purpose)
+
+	e.OptUint(a.TradeDestSett)
+	encodeInventory(e, a.TradeCargo)
+	e.Uint(uint64(a.TravelTicksLeft))
+	e.Uint(a.ConsignmentDebt)
+	e.OptUint(a.TradePreferredDest)
+
+	e.SliceLen(len(a.Memories), a.Memories == nil)
+	for _, m := range a.Memories {
+		e.Uint(m.Tick)
+		e.String(m.Content)
+		e.Float32(m.Importance)
+	}
+
+	e.Float32(a.ProductionProgress)
+	e.Float32(a.PracticeBoost)
+	e.Uint(a.BornTick)
+	e.Uint(a.LastWorkTick)
+	e.Bool(a.Alive)
+}
+
+func decodeAgents(d *checkpoint.Decoder, version uint16) []*agents.Agent {
+	n := d.Len("agents")
+	list := make([]*agents.Agent, 0, n)
+	for range n {
+		if d.Err() != nil {
+			return nil
+		}
+		list = append(list, decodeAgent(d, version))
+	}
+	return list
+}
+
+func decodeAgent(d *checkpoint.Decoder, version uint16) *agents.Agent {
+	sliceLen := d.SliceLen
+	if version < 2 {
+		sliceLen = func(what string) (int, bool) {
+			n := d.Len(what)
+			return n, n == 0
+		}
+	}
+
+	a := &agents.Agent{}
+	a.ID = agents.AgentID(d.Uint())
+	a.Name = d.String()
+	a.Age = uint16(d.Uint())
+	a.AgeMonths = d.Uint8()
+	a.Sex = agents.Sex(d.Uint8())
+	a.Health = d.Float32()
+
+	a.Position = decodeCoord(d)
+	a.HomeSettID = d.OptUint()
+	if d.Bool() {
+		dest := decodeCoord(d)
+		a.Destination = &dest
+	}
+
+	a.Occupation = agents.Occupation(d.Uint8())
+	a.Inventory = decodeInventory(d)
+	a.Wealth = d.Uint()
+	a.Skills.Farming = d.Float32()
+	a.Skills.Mining = d.Float32()
+	a.Skills.Crafting = d.Float32()
+	a.Skills.Combat = d.Float32()
+	a.Skills.Trade = d.Float32()
+
+	if n, isNil := sliceLen("relationships"); !isNil {
+		a.Relationships = make([]agents.Relationship, n)
+		for i := range a.Relationships {
+			a.Relationships[i] = agents.Relationship{
+				TargetID:  agents.AgentID(d.Uint()),
+				Sentiment: d.Float32(),
+				Trust:     d.Float32(),
+			}
+		}
+	}
+	a.FactionID = d.OptUint()
+	a.Role = agents.SocialRole(d.Uint8())
+
+	a.Tier = agents.CognitionTier(d.Uint8())
+	a.Archetype = d.String()
+	a.Wellbeing.Satisfaction = d.Float32()
+	a.Wellbeing.Alignment = d.Float32()
+	a.Wellbeing.EffectiveMood = d.Float32()
+
+	a.Soul.CittaCoherence = d.Float32()
+	a.Soul.Mass = d.Float32()
+	a.Soul.Gauss = d.Float32()
+	a.Soul.State = agents.StateOfBeing(d.Uint8())
+	a.Soul.Class = agents.AgentClass(d.Uint8())
+	a.Soul.WisdomScore = d.Float32()
+	a.Soul.WisdomEffort = uint32(d.Uint())
+	a.Soul.Reincarnated = d.Bool()
+
+	a.Needs.Survival = d.Float32()
+	a.Needs.Safety = d.Float32()
+	a.Needs.Belonging = d.Float32()
+	a.Needs.Esteem = d.Float32()
+	a.Needs.Purpose = d.Float32()
+
+	a.TradeDestSett = d.OptUint()
+	a.TradeCargo = decodeInventory(d)
+	a.TravelTicksLeft = uint16(d.Uint())
+	a.ConsignmentDebt = d.Uint()
+	a.TradePreferredDest = d.OptUint()
+
+	if n, isNil := sliceLen("memories"); !isNil {
+		a.Memories = make([]agents.Memory, n)
+		for i := range a.Memories {
+			a.Memories[i] = agents.Memory{Tick: d.Uint(), Content: d.String(), Importance: d.Float32()}
+		}
+	}
+
+	a.ProductionProgress = d.Float32()
+	a.PracticeBoost = d.Float32()
+	a.BornTick = d.Uint()
+	a.LastWorkTick = d.Uint()
+	a.Alive = d.Bool()
+	return a
+}
+
+// Inventories carry their length, so adding a good does not break old files.
+func encodeInventory(e *checkpoint.Encoder, inv agents.GoodInventory) {
+	e.Uint(uint64(len(inv)))
+	for _, q := range inv {
+		e.Int(int64(q))
+	}
+}
+
+func decodeInventory(d *checkpoint.Decoder) agents.GoodInventory {
+	var inv agents.GoodInventory
+	n := d.Len("inventory")
+	for i := range n {
+		q := int(d.Int())
+		if i < len(inv) {
+			inv[i] = q
+		}
+	}
+	return inv
+}
+
+func encodeCoord(e *checkpoint.Encoder, c world.HexCoord) {
+	e.Int(int64(c.Q))
+	e.Int(int64(c.R))
+}
+
+func decodeCoord(d *checkpoint.Decoder) world.HexCoord {
+	return world.HexCoord{Q: int(d.Int()), R: int(d.Int())}
+}
+
+// Markets are saved by the registry ("markets" in world_meta), not here.
+func encodeSettlements(list []*social.Settlement) []byte {
+	e := checkpoint.NewEncoder(len(list) * 96)
+	e.Uint(uint64(len(list)))
+	for _, s := range list {
+		e.Uint(s.ID)
+		e.String(s.Name)
+		encodeCoord(e, s.Position)
+		e.Uint(uint64(s.Population))
+		e.Uint8(uint8(s.Governance))
+		e.OptUint(s.LeaderID)
+		e.Float64(s.TaxRate)
+		e.Uint(s.Treasury)
+		e.Float32(s.CultureTradition)
+		e.Float32(s.CultureOpenness)
+		e.Float32(s.CultureMilitarism)
+		e.Uint8(s.WallLevel)
+		e.Uint8(s.RoadLevel)
+		e.Uint8(s.MarketLevel)
+		e.Float64(s.GovernanceScore)
+		e.Float64(s.CulturalMemory)
+	}
+	return e.Bytes()
+}
+
+func decodeSettlements(d *checkpoint.Decoder) []*social.Settlement {
+	n := d.Len("settlements")
+	list := make([]*social.Settlement, 0, n)
+	for range n {
+		s := &social.Settlement{}
+		s.ID = d.Uint()
+		s.Name = d.String()
+		s.Position = decodeCoord(d)
+		s.Population = uint32(d.Uint())
+		s.Governance = social.GovernanceType(d.Uint8())
+		s.LeaderID = d.OptUint()
+		s.TaxRate = d.Float64()
+		s.Treasury = d.Uint()
+		s.CultureTradition = d.Float32()
+		s.CultureOpenness = d.Float32()
+		s.CultureMilitarism = d.Float32()
+		s.WallLevel = d.Uint8()
+		s.RoadLevel = d.Uint8()
+		s.MarketLevel = d.Uint8()
+		s.GovernanceScore = d.Float64()
+		s.CulturalMemory = d.Float64()
+		list = append(list, s)
+	}
+	return list
+}
+
+func encodeFactions(list []*social.Faction) []byte {
+	e := checkpoint.NewEncoder(len(list) * 256)
+	e.Uint(uint64(len(list)))
+	for _, f := range list {
+		e.Uint(uint64(f.ID))
+		e.String(f.Name)
+		e.Uint(uint64(len(f.Influence)))
+		for _, k := range slices.Sorted(maps.Keys(f.Influence)) {
+			e.Uint(k)
+			e.Float64(f.Influence[k])
+		}
+		e.Uint(uint64(len(f.Relations)))
+		for _, k := range slices.Sorted(maps.Keys(f.Relations)) {
+			e.Uint(uint64(k))
+			e.Float64(f.Relations[k])
+		}
+		e.OptUint(f.LeaderID)
+		e.Uint(f.Treasury)
+		e.Float64(f.TaxPreference)
+		e.Float64(f.TradePreference)
+		e.Float64(f.MilitaryPreference)
+	}
+	return e.Bytes()
+}
+
+func decodeFactions(d *checkpoint.Decoder) []*social.Faction {
+	n := d.Len("factions")
+	list := make([]*social.Faction, 0, n)
+	for range n {
+		f := &social.Faction{}
+		f.ID = social.FactionID(d.Uint())
+		f.Name = d.String()
+		k := d.Len("influence")
+		f.Influence = make(map[uint64]float64, k)
+		for range k {
+			id := d.Uint()
+			f.Influence[id] = d.Float64()
+		}
+		k = d.Len("relations")
+		f.Relations = make(map[social.FactionID]float64, k)
+		for range k {
+			id := social.FactionID(d.Uint())
+			f.Relations[id] = d.Float64()
+		}
+		f.LeaderID = d.OptUint()
+		f.Treasury = d.Uint()
+		f.TaxPreference = d.Float64()
+		f.TradePreference = d.Float64()
+		f.MilitaryPreference = d.Float64()
+		list = append(list, f)
+	}
+	return list
+}
+
+// Every hex is saved (a radius-22 map is ~2,000), so restoring needs no
+// "pristine unless listed" rule.
+func encodeHexes(m *world.Map) []byte {
+	coords := slices.SortedFunc(maps.Keys(m.Hexes), func(a, b world.HexCoord) int {
+		if a.Q != b.Q {
+			return a.Q - b.Q
+		}
+		return a.R - b.R
+	})
+	e := checkpoint.NewEncoder(len(coords) * 48)
+	e.Uint(uint64(len(coords)))
+	for _, c := range coords {
+		hex := m.Hexes[c]
+		encodeCoord(e, c)
+		e.Float64(hex.Health)
+		e.Uint(hex.LastExtractedTick)
+		e.Uint8(hex.IrrigationLevel)
+		e.Uint8(hex.ConservationLevel)
+		e.OptUint(hex.ClaimedBy)
+		e.Uint(uint64(len(hex.Resources)))
+		for _, r := range slices.Sorted(maps.Keys(hex.Resources)) {
+			e.Uint8(uint8(r))
+			e.Float64(hex.Resources[r])
+		}
+	}
+	return e.Bytes()
+}
+
+func decodeHexes(d *checkpoint.Decoder) []hexState {
+	n := d.Len("hexes")
+	hexes := make([]hexState, 0, n)
+	for range n {
+		h := hexState{coord: decodeCoord(d)}
+		h.health = d.Float64()
+		h.lastExtractedTick = d.Uint()
+		h.irrigation = d.Uint8()
+		h.conservation = d.Uint8()
+		h.claimedBy = d.OptUint()
+		k := d.Len("resources")
+		h.resources = make(map[world.ResourceType]float64, k)
+		for range k {
+			r := world.ResourceType(d.Uint8())
+			h.resources[r] = d.Float64()
+		}
+		hexes = append(hexes, h)
+	}
+	return hexes
+}
+
+func encodeMeta(meta metaMap) []byte {
+	e := checkpoint.NewEncoder(4096)
+	e.Uint(uint64(len(meta)))
+	for _, k := range slices.Sorted(maps.Keys(meta)) {
+		e.String(k)
+		e.String(meta[k])
+	}
+	return e.Bytes()
+}
+
+func decodeMeta(d *checkpoint.Decoder) metaMap {
+	n := d.Len("world_meta")
+	meta := make(metaMap, n)
+	for range n {
+		k := d.String()
+		meta[k] = d.String()
+	}
+	return meta
+}
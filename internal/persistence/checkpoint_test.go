@@ -0,0 +1,206 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/checkpoint"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// fill sets every exported field reachable from v to a distinct non-zero
+// value, so a field the codec forgets shows up as a mismatch.
+func fill(v reflect.Value, n *int) {
+	*n++
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(*n%100 + 1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(*n%100 + 1))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(*n) + 0.25)
+	case reflect.String:
+		v.SetString(fmt.Sprintf("s%d", *n))
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), n)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i), n)
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			fill(v.Index(i), n)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+		for i := range 2 {
+			fill(v.Index(i), n)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for range 2 {
+			k := reflect.New(v.Type().Key()).Elem()
+			e := reflect.New(v.Type().Elem()).Elem()
+			fill(k, n)
+			fill(e, n)
+			v.SetMapIndex(k, e)
+		}
+	}
+}
+
+// TestCheckpointAgentRoundTrip fails when a field is added to an agent,
+// settlement or faction without being added to its checkpoint codec.
+func TestCheckpointAgentRoundTrip(t *testing.T) {
+	n := 0
+	a := &agents.Agent{}
+	fill(reflect.ValueOf(a).Elem(), &n)
+	a.Dirty = 0 // which tables a database owes a write, not agent state
+	s := &social.Settlement{}
+	fill(reflect.ValueOf(s).Elem(), &n)
+	s.Market = nil // saved by the registry, not the settlement codec
+	f := &social.Faction{}
+	fill(reflect.ValueOf(f).Elem(), &n)
+
+	// The engine leaves empty, non-nil slices behind (compaction prunes
+	// relationships in place); they must not come back as nil.
+	bare := &agents.Agent{ID: 2, Relationships: []agents.Relationship{}}
+
+	d := checkpoint.NewDecoder(encodeAgents([]*agents.Agent{a, bare}))
+	gotAgents := decodeAgents(d, ckptAgentsV)
+	if err := d.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotAgents) != 2 || !reflect.DeepEqual(gotAgents[0], a) {
+		t.Errorf("agent did not round-trip:\n got %+v\nwant %+v", gotAgents, a)
+	} else if gotAgents[1].Relationships == nil || gotAgents[1].Memories != nil {
+		t.Errorf("nil/empty slices swapped: relationships %#v, memories %#v", gotAgents[1].Relationships, gotAgents[1].Memories)
+	}
+
+	d = checkpoint.NewDecoder(encodeSettlements([]*social.Settlement{s}))
+	gotSetts := decodeSettlements(d)
+	if err := d.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotSetts) != 1 || !reflect.DeepEqual(gotSetts[0], s) {
+		t.Errorf("settlement did not round-trip:\n got %+v\nwant %+v", gotSetts, s)
+	}
+
+	d = checkpoint.NewDecoder(encodeFactions([]*social.Faction{f}))
+	gotFactions := decodeFactions(d)
+	if err := d.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotFactions) != 1 || !reflect.DeepEqual(gotFactions[0], f) {
+		t.Errorf("faction did not round-trip:\n got %+v\nwant %+v", gotFactions, f)
+	}
+}
+
+// checkpointWorld builds a populated world of roughly n agents without a
+// database, spread over the generated settlements.
+func checkpointWorld(tb testing.TB, radius, n int) (*engine.Simulation, world.GenConfig) {
+	tb.Helper()
+	gen := world.GenConfig{Radius: radius, Seed: 11, SeaLevel: 0.25, MountainLvl: 0.56, Noise: world.DefaultNoiseParams()}
+	m := world.Generate(gen)
+	spawner := agents.NewSpawner(gen.Seed)
+	placed := world.PlaceSettlements(m, gen.Seed)
+	if len(placed) == 0 {
+		tb.Fatal("world has no settlements")
+	}
+	var setts []*social.Settlement
+	var all []*agents.Agent
+	for i, ss := range placed {
+		sid := uint64(i + 1)
+		pop := uint32(n / len(placed))
+		setts = append(setts, &social.Settlement{
+			ID: sid, Name: ss.Name, Position: ss.Coord, Population: pop,
+			TaxRate: 0.10, Treasury: uint64(pop) * 5, GovernanceScore: 0.6, MarketLevel: 1,
+		})
+		all = append(all, spawner.SpawnPopulation(pop, ss.Coord, sid, world.TerrainPlains)...)
+	}
+	for i, a := range all {
+		if i%3 == 0 {
+			agents.AddMemory(a, uint64(i), "remembered the harvest", 0.5)
+		}
+	}
+	sim := engine.NewSimulation(m, all, setts)
+	sim.Spawner = spawner
+	sim.InitFactions()
+	sim.CurrentSeason = 2
+	return sim, gen
+}
+
+// TestCheckpointRoundTrip saves a whole world and checks each section reads
+// back as written.
+func TestCheckpointRoundTrip(t *testing.T) {
+	sim, gen := checkpointWorld(t, 8, 300)
+	for _, h := range sim.WorldMap.Hexes {
+		h.Health = 0.5
+		h.IrrigationLevel = 2
+	}
+	path := filepath.Join(t.TempDir(), "world.ckpt")
+	if err := SaveCheckpoint(path, sim, gen); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Season != 2 || c.Gen != gen {
+		t.Errorf("world section: season %d gen %+v", c.Season, c.Gen)
+	}
+	for _, a := range sim.Agents {
+		a.Dirty = 0
+	}
+	if !reflect.DeepEqual(c.Agents, sim.Agents) {
+		t.Error("agents differ after round trip")
+	}
+	if len(c.Settlements) != len(sim.Settlements) || c.Settlements[0].Name != sim.Settlements[0].Name {
+		t.Errorf("settlements: got %d, want %d", len(c.Settlements), len(sim.Settlements))
+	}
+	if !reflect.DeepEqual(c.Factions, sim.Factions) {
+		t.Error("factions differ after round trip")
+	}
+
+	fresh := world.Generate(gen)
+	c.RestoreHexes(fresh)
+	for coord, h := range sim.WorldMap.Hexes {
+		got := fresh.Get(coord)
+		if got.Health != h.Health || got.IrrigationLevel != h.IrrigationLevel || !reflect.DeepEqual(got.Resources, h.Resources) {
+			t.Fatalf("hex %v not restored", coord)
+		}
+	}
+}
+
+// BenchmarkCheckpoint measures a full save and load at the populations the
+// format is meant for. Numbers are recorded in docs/02-operations.md.
+func BenchmarkCheckpoint(b *testing.B) {
+	for _, n := range []int{100_000, 500_000} {
+		sim, gen := checkpointWorld(b, 22, n)
+		path := filepath.Join(b.TempDir(), "world.ckpt")
+		b.Run(fmt.Sprintf("save/%dK", n/1000), func(b *testing.B) {
+			for range b.N {
+				if err := SaveCheckpoint(path, sim, gen); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("load/%dK", n/1000), func(b *testing.B) {
+			for range b.N {
+				if _, err := LoadCheckpoint(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
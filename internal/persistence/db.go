@@ -5,6 +5,7 @@ package persistence
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
 
 	"github.com/jmoiron/sqlx"
@@ -18,11 +19,28 @@ import (
 
 // DB wraps a SQLite connection for world state persistence.
 type DB struct {
-	conn *sqlx.DB
+	conn     *sqlx.DB
+	segments segmentCache // decompressed event archive segments
+	dirty    dirtyState   // what the agent tables hold, for incremental saves
 }
 
-// Open opens or creates a SQLite database at the given path.
+// Open opens or creates a SQLite database at the given path and brings its
+// schema up to date (see migrations.go).
 func Open(path string) (*DB, error) {
+	db, err := OpenUnmigrated(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	return db, nil
+}
+
+// OpenUnmigrated opens or creates a SQLite database without touching its
+// schema, for inspecting the migration state.
+func OpenUnmigrated(path string) (*DB, error) {
 	conn, err := sqlx.Open("sqlite", path+"?_journal_mode=WAL&_busy_timeout=5000")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
@@ -45,13 +63,7 @@ func Open(path string) (*DB, error) {
 		conn.Exec(p)
 	}
 
-	db := &DB{conn: conn}
-	if err := db.migrate(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("migrate: %w", err)
-	}
-
-	return db, nil
+	return &DB{conn: conn}, nil
 }
 
 // Close closes the database connection.
@@ -59,170 +71,12 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-func (db *DB) migrate() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS agents (
-		id INTEGER PRIMARY KEY,
-		name TEXT NOT NULL,
-		age INTEGER NOT NULL,
-		sex INTEGER NOT NULL,
-		health REAL NOT NULL,
-		pos_q INTEGER NOT NULL,
-		pos_r INTEGER NOT NULL,
-		home_settlement_id INTEGER,
-		occupation INTEGER NOT NULL,
-		wealth INTEGER NOT NULL,
-		tier INTEGER NOT NULL,
-		mood REAL NOT NULL,
-		alive INTEGER NOT NULL,
-		born_tick INTEGER NOT NULL,
-		role INTEGER NOT NULL,
-		faction_id INTEGER,
-		archetype TEXT,
-		skills_json TEXT NOT NULL,
-		needs_json TEXT NOT NULL,
-		soul_json TEXT NOT NULL,
-		inventory_json TEXT NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS settlements (
-		id INTEGER PRIMARY KEY,
-		name TEXT NOT NULL,
-		pos_q INTEGER NOT NULL,
-		pos_r INTEGER NOT NULL,
-		population INTEGER NOT NULL,
-		governance INTEGER NOT NULL,
-		tax_rate REAL NOT NULL,
-		treasury INTEGER NOT NULL,
-		governance_score REAL NOT NULL,
-		cultural_memory REAL NOT NULL,
-		culture_tradition REAL NOT NULL,
-		culture_openness REAL NOT NULL,
-		culture_militarism REAL NOT NULL,
-		wall_level INTEGER NOT NULL,
-		road_level INTEGER NOT NULL,
-		market_level INTEGER NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		tick INTEGER NOT NULL,
-		description TEXT NOT NULL,
-		category TEXT NOT NULL,
-		narrated TEXT NOT NULL DEFAULT ''
-	);
-
-	CREATE TABLE IF NOT EXISTS world_meta (
-		key TEXT PRIMARY KEY,
-		value TEXT NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS biographies (
-		agent_id INTEGER PRIMARY KEY,
-		biography TEXT NOT NULL,
-		generated_at TEXT NOT NULL DEFAULT ''
-	);
-
-	CREATE TABLE IF NOT EXISTS memories (
-		agent_id INTEGER NOT NULL,
-		tick INTEGER NOT NULL,
-		content TEXT NOT NULL,
-		importance REAL NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS stats_history (
-		tick INTEGER PRIMARY KEY,
-		population INTEGER NOT NULL,
-		total_wealth INTEGER NOT NULL,
-		avg_mood REAL NOT NULL,
-		avg_survival REAL NOT NULL,
-		births INTEGER NOT NULL,
-		deaths INTEGER NOT NULL,
-		trade_volume INTEGER NOT NULL,
-		avg_coherence REAL NOT NULL,
-		settlement_count INTEGER NOT NULL,
-		gini REAL NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS relationships (
-		agent_id INTEGER NOT NULL,
-		target_id INTEGER NOT NULL,
-		sentiment REAL NOT NULL,
-		trust REAL NOT NULL
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_events_tick ON events(tick);
-	CREATE INDEX IF NOT EXISTS idx_agents_settlement ON agents(home_settlement_id);
-	CREATE INDEX IF NOT EXISTS idx_agents_alive ON agents(alive);
-	CREATE INDEX IF NOT EXISTS idx_memories_agent ON memories(agent_id);
-	CREATE INDEX IF NOT EXISTS idx_relationships_agent ON relationships(agent_id);
-	`
-	_, err := db.conn.Exec(schema)
-	if err != nil {
-		return err
-	}
-
-	// Factions table (added in Phase 5 tuning).
-	_, err = db.conn.Exec(`
-	CREATE TABLE IF NOT EXISTS factions (
-		id INTEGER PRIMARY KEY,
-		name TEXT NOT NULL,
-		kind INTEGER NOT NULL,
-		leader_id INTEGER,
-		treasury INTEGER NOT NULL,
-		tax_preference REAL NOT NULL,
-		trade_preference REAL NOT NULL,
-		military_preference REAL NOT NULL,
-		influence_json TEXT NOT NULL,
-		relations_json TEXT NOT NULL
-	)`)
-	if err != nil {
-		return err
+// BackupTo writes a consistent copy of the whole database to path using
+// SQLite's VACUUM INTO. path must not already exist.
+func (db *DB) BackupTo(path string) error {
+	if _, err := db.conn.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("backup to %s: %w", path, err)
 	}
-
-	// Settlement stats history table.
-	_, err = db.conn.Exec(`
-	CREATE TABLE IF NOT EXISTS settlement_stats_history (
-		tick INTEGER NOT NULL,
-		settlement_id INTEGER NOT NULL,
-		population INTEGER NOT NULL,
-		treasury INTEGER NOT NULL,
-		avg_satisfaction REAL NOT NULL,
-		trade_volume INTEGER NOT NULL,
-		governance TEXT NOT NULL,
-		governance_score REAL NOT NULL,
-		carrying_capacity REAL NOT NULL,
-		population_pressure REAL NOT NULL,
-		PRIMARY KEY (tick, settlement_id)
-	)`)
-	if err != nil {
-		return err
-	}
-	db.conn.Exec("CREATE INDEX IF NOT EXISTS idx_settlement_stats_id ON settlement_stats_history(settlement_id)")
-
-	// Add columns that may not exist in older databases.
-	migrations := []string{
-		"ALTER TABLE events ADD COLUMN narrated TEXT NOT NULL DEFAULT ''",
-		"ALTER TABLE settlements ADD COLUMN abandoned INTEGER NOT NULL DEFAULT 0",
-		"ALTER TABLE agents ADD COLUMN satisfaction REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE agents ADD COLUMN alignment REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE stats_history ADD COLUMN avg_satisfaction REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE stats_history ADD COLUMN avg_alignment REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE agents ADD COLUMN last_work_tick INTEGER NOT NULL DEFAULT 0",
-		"ALTER TABLE agents ADD COLUMN production_progress REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE stats_history ADD COLUMN occupation_json TEXT NOT NULL DEFAULT ''",
-		"ALTER TABLE events ADD COLUMN agent_id INTEGER",
-		"ALTER TABLE events ADD COLUMN settlement_id INTEGER",
-		"ALTER TABLE agents ADD COLUMN age_months INTEGER NOT NULL DEFAULT 0",
-		"ALTER TABLE stats_history ADD COLUMN bottom_50_share REAL NOT NULL DEFAULT 0",
-		"ALTER TABLE stats_history ADD COLUMN top_10_share REAL NOT NULL DEFAULT 0",
-	}
-	for _, m := range migrations {
-		db.conn.Exec(m) // Ignore errors — column may already exist.
-	}
-	db.conn.Exec("CREATE INDEX IF NOT EXISTS idx_events_agent ON events(agent_id)")
-	db.conn.Exec("CREATE INDEX IF NOT EXISTS idx_events_settlement ON events(settlement_id)")
-
 	return nil
 }
 
@@ -230,6 +84,7 @@ func (db *DB) migrate() error {
 // Only saves alive agents — dead agents (those that died since last load) are
 // not written. On load, WHERE alive = 1 filters them out anyway.
 // The old approach wrote ALL agents (alive + dead) which was wasteful.
+// This rewrites the whole table; SaveAgentsIncremental writes only changes.
 func (db *DB) SaveAgents(agentList []*agents.Agent) error {
 	tx, err := db.conn.Beginx()
 	if err != nil {
@@ -241,45 +96,81 @@ func (db *DB) SaveAgents(agentList []*agents.Agent) error {
 		return err
 	}
 
-	stmt, err := tx.Preparex(`INSERT INTO agents
-		(id, name, age, age_months, sex, health, pos_q, pos_r, home_settlement_id,
-		 occupation, wealth, tier, mood, alive, born_tick, role, faction_id, archetype,
-		 skills_json, needs_json, soul_json, inventory_json, satisfaction, alignment, last_work_tick,
-		 production_progress)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	stmt, err := tx.Preparex("INSERT" + agentInsert)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
+	db.dirty.reset(rowOf)
+	db.dirty.rowsKnown = false
 	saved := 0
 	for _, a := range agentList {
 		if !a.Alive {
 			continue
 		}
-
-		skillsJSON, _ := json.Marshal(a.Skills)
-		needsJSON, _ := json.Marshal(a.Needs)
-		soulJSON, _ := json.Marshal(a.Soul)
-		invJSON, _ := json.Marshal(a.Inventory)
-
-		_, err := stmt.Exec(
-			a.ID, a.Name, a.Age, a.AgeMonths, a.Sex, a.Health,
-			a.Position.Q, a.Position.R, a.HomeSettID,
-			a.Occupation, a.Wealth, a.Tier, a.Wellbeing.EffectiveMood,
-			1, a.BornTick, a.Role, a.FactionID, a.Archetype,
-			string(skillsJSON), string(needsJSON), string(soulJSON), string(invJSON),
-			a.Wellbeing.Satisfaction, a.Wellbeing.Alignment, a.LastWorkTick,
-			a.ProductionProgress,
-		)
-		if err != nil {
-			return fmt.Errorf("insert agent %d: %w", a.ID, err)
+		if err := insertAgent(stmt, a); err != nil {
+			return err
 		}
+		db.dirty.record(a.ID, rowOf, rowPrint(a))
 		saved++
 	}
 
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.dirty.rowsKnown = true
 	slog.Info("agents saved", "alive", saved, "total_in_memory", len(agentList))
-	return tx.Commit()
+	return nil
+}
+
+// agentInsert is the body of an agents INSERT; prefix it with the verb.
+const agentInsert = ` INTO agents
+		(id, name, age, age_months, sex, health, pos_q, pos_r, home_settlement_id,
+		 occupation, wealth, tier, mood, alive, born_tick, role, faction_id, archetype,
+		 skills_json, needs_json, soul_json, inventory_json, satisfaction, alignment, last_work_tick,
+		 production_progress, trade_json, practice_boost)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// agentTrade is the trade_json column: where an agent is headed and what it
+// carries. Mostly merchants; everyone else stores an empty cargo.
+type agentTrade struct {
+	Destination        *world.HexCoord      `json:"destination,omitempty"`
+	TradeDestSett      *uint64              `json:"dest_sett,omitempty"`
+	TradeCargo         agents.GoodInventory `json:"cargo"`
+	TravelTicksLeft    uint16               `json:"ticks_left,omitempty"`
+	ConsignmentDebt    uint64               `json:"debt,omitempty"`
+	TradePreferredDest *uint64              `json:"preferred_dest,omitempty"`
+}
+
+func tradeOf(a *agents.Agent) agentTrade {
+	return agentTrade{
+		Destination: a.Destination, TradeDestSett: a.TradeDestSett, TradeCargo: a.TradeCargo,
+		TravelTicksLeft: a.TravelTicksLeft, ConsignmentDebt: a.ConsignmentDebt, TradePreferredDest: a.TradePreferredDest,
+	}
+}
+
+// insertAgent writes a's row with stmt, a prepared agentInsert.
+func insertAgent(stmt *sqlx.Stmt, a *agents.Agent) error {
+	skillsJSON, _ := json.Marshal(a.Skills)
+	needsJSON, _ := json.Marshal(a.Needs)
+	soulJSON, _ := json.Marshal(a.Soul)
+	invJSON, _ := json.Marshal(a.Inventory)
+	tradeJSON, _ := json.Marshal(tradeOf(a))
+
+	_, err := stmt.Exec(
+		a.ID, a.Name, a.Age, a.AgeMonths, a.Sex, a.Health,
+		a.Position.Q, a.Position.R, a.HomeSettID,
+		a.Occupation, a.Wealth, a.Tier, a.Wellbeing.EffectiveMood,
+		1, a.BornTick, a.Role, a.FactionID, a.Archetype,
+		string(skillsJSON), string(needsJSON), string(soulJSON), string(invJSON),
+		a.Wellbeing.Satisfaction, a.Wellbeing.Alignment, a.LastWorkTick,
+		a.ProductionProgress, string(tradeJSON), a.PracticeBoost,
+	)
+	if err != nil {
+		return fmt.Errorf("insert agent %d: %w", a.ID, err)
+	}
+	return nil
 }
 
 // SaveSettlements writes all settlements to the database.
@@ -298,12 +189,12 @@ func (db *DB) SaveSettlements(settlements []*social.Settlement) error {
 		_, err := tx.Exec(`INSERT INTO settlements
 			(id, name, pos_q, pos_r, population, governance, tax_rate, treasury,
 			 governance_score, cultural_memory, culture_tradition, culture_openness,
-			 culture_militarism, wall_level, road_level, market_level)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			 culture_militarism, wall_level, road_level, market_level, leader_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			s.ID, s.Name, s.Position.Q, s.Position.R, s.Population,
 			s.Governance, s.TaxRate, s.Treasury, s.GovernanceScore,
 			s.CulturalMemory, s.CultureTradition, s.CultureOpenness,
-			s.CultureMilitarism, s.WallLevel, s.RoadLevel, s.MarketLevel,
+			s.CultureMilitarism, s.WallLevel, s.RoadLevel, s.MarketLevel, s.LeaderID,
 		)
 		if err != nil {
 			return fmt.Errorf("insert settlement %d: %w", s.ID, err)
@@ -422,20 +313,7 @@ func (db *DB) SaveEvents(events []engine.Event) error {
 	defer tx.Rollback()
 
 	for _, e := range events {
-		// Extract agent_id and settlement_id from Meta for queryability.
-		var agentID, settlementID *uint64
-		if e.Meta != nil {
-			if v, ok := e.Meta["agent_id"]; ok {
-				if id := metaToUint64(v); id != 0 {
-					agentID = &id
-				}
-			}
-			if v, ok := e.Meta["settlement_id"]; ok {
-				if id := metaToUint64(v); id != 0 {
-					settlementID = &id
-				}
-			}
-		}
+		agentID, settlementID := eventSubjects(e)
 		_, err := tx.Exec(
 			"INSERT INTO events (tick, description, category, narrated, agent_id, settlement_id) VALUES (?, ?, ?, ?, ?, ?)",
 			e.Tick, e.Description, e.Category, e.NarratedDescription, agentID, settlementID,
@@ -448,19 +326,6 @@ func (db *DB) SaveEvents(events []engine.Event) error {
 	return tx.Commit()
 }
 
-// TrimOldEvents removes events older than keepTicks from the database.
-func (db *DB) TrimOldEvents(currentTick uint64, keepTicks uint64) (int64, error) {
-	if currentTick <= keepTicks {
-		return 0, nil
-	}
-	cutoff := currentTick - keepTicks
-	result, err := db.conn.Exec("DELETE FROM events WHERE tick < ?", cutoff)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected()
-}
-
 // SaveMeta stores a key-value pair in world metadata.
 func (db *DB) SaveMeta(key, value string) error {
 	_, err := db.conn.Exec(
@@ -478,13 +343,13 @@ func (db *DB) GetMeta(key string) (string, error) {
 }
 
 // SaveWorldState performs a fast daily save: agents, settlements, factions,
-// events, and world_meta. Skips memories and relationships to avoid I/O
-// thrashing on memory-constrained servers (~5 min instead of 50+ min).
+// events, and world_meta. Agent rows are saved incrementally. Skips memories
+// and relationships, which SaveSocial keeps up to date on its own cadence.
 // Use SaveWorldStateFull for shutdown saves that include everything.
 func (db *DB) SaveWorldState(sim *engine.Simulation) error {
 	slog.Info("saving world state", "agents", len(sim.Agents), "settlements", len(sim.Settlements))
 
-	if err := db.SaveAgents(sim.Agents); err != nil {
+	if err := db.SaveAgentsIncremental(sim.Agents); err != nil {
 		return fmt.Errorf("save agents: %w", err)
 	}
 	if err := db.SaveSettlements(sim.Settlements); err != nil {
@@ -493,9 +358,20 @@ func (db *DB) SaveWorldState(sim *engine.Simulation) error {
 	if err := db.SaveFactions(sim.Factions); err != nil {
 		return fmt.Errorf("save factions: %w", err)
 	}
-	if err := db.SaveEvents(sim.Events); err != nil {
-		return fmt.Errorf("save events: %w", err)
+	if err := db.SaveHistory(sim); err != nil {
+		return err
+	}
+	if err := saveWorldMeta(sim, db); err != nil {
+		return err
 	}
+
+	slog.Info("world state saved")
+	return nil
+}
+
+// saveWorldMeta writes the world_meta half of a world save: the clock, hex
+// state and every registry field.
+func saveWorldMeta(sim *engine.Simulation, db MetaStore) error {
 	if err := db.SaveMeta("last_tick", fmt.Sprintf("%d", sim.CurrentTick())); err != nil {
 		return fmt.Errorf("save meta: %w", err)
 	}
@@ -553,26 +429,47 @@ func (db *DB) SaveWorldState(sim *engine.Simulation) error {
 	// All other persistent world state flows through the registry in
 	// world_state.go. Save and Load are co-located per field — no more
 	// wire-it-and-pray when adding new state.
-	if err := db.SaveLatePersisted(sim); err != nil {
-		return err
-	}
+	return saveLatePersisted(sim, db)
+}
 
-	slog.Info("world state saved")
+// SaveHistory writes the queryable history SaveWorldState includes: events
+// emitted since the last save, the family tree, the graveyard, the settlement
+// archive and the intervention ledger. Saves whose world
+// state goes to a checkpoint call it on its own. Must run on the tick loop.
+func (db *DB) SaveHistory(sim *engine.Simulation) error {
+	if err := db.SaveEvents(sim.UnsavedEventTail()); err != nil {
+		return fmt.Errorf("save events: %w", err)
+	}
+	sim.UnsavedEvents = 0
+	if err := db.SaveLineage(sim.UnsavedLineage); err != nil {
+		return fmt.Errorf("save lineage: %w", err)
+	}
+	sim.UnsavedLineage = nil
+	if err := db.SaveGraves(sim.UnsavedGraves); err != nil {
+		return fmt.Errorf("save graves: %w", err)
+	}
+	sim.UnsavedGraves = nil
+	if err := db.SaveSettlementArchives(sim.UnsavedArchives); err != nil {
+		return fmt.Errorf("save settlement archive: %w", err)
+	}
+	sim.UnsavedArchives = nil
+	if err := db.SaveInterventions(sim.Interventions); err != nil {
+		return fmt.Errorf("save interventions: %w", err)
+	}
 	return nil
 }
 
 // SaveWorldStateFull saves everything including memories and relationships.
-// Use for shutdown saves where completeness matters more than speed.
+// Use for shutdown saves where completeness matters. Since memories and
+// relationships are saved incrementally it costs little more than
+// SaveWorldState once they have been written in full.
 func (db *DB) SaveWorldStateFull(sim *engine.Simulation) error {
 	if err := db.SaveWorldState(sim); err != nil {
 		return err
 	}
 	slog.Info("saving memories and relationships (full save)...")
-	if err := db.SaveMemories(sim.Agents); err != nil {
-		return fmt.Errorf("save memories: %w", err)
-	}
-	if err := db.SaveRelationships(sim.Agents); err != nil {
-		return fmt.Errorf("save relationships: %w", err)
+	if err := db.SaveSocial(sim.Agents); err != nil {
+		return err
 	}
 	slog.Info("full save complete")
 	return nil
@@ -614,6 +511,8 @@ func (db *DB) LoadAgents() ([]*agents.Agent, error) {
 		Alignment          float32 `db:"alignment"`
 		LastWorkTick       uint64  `db:"last_work_tick"`
 		ProductionProgress float32 `db:"production_progress"`
+		TradeJSON          string  `db:"trade_json"`
+		PracticeBoost      float32 `db:"practice_boost"`
 	}
 
 	var rows []agentRow
@@ -650,6 +549,7 @@ func (db *DB) LoadAgents() ([]*agents.Agent, error) {
 			BornTick:           r.BornTick,
 			LastWorkTick:       r.LastWorkTick,
 			ProductionProgress: r.ProductionProgress,
+			PracticeBoost:      r.PracticeBoost,
 			Role:               agents.SocialRole(r.Role),
 			FactionID:          r.FactionID,
 		}
@@ -662,10 +562,19 @@ func (db *DB) LoadAgents() ([]*agents.Agent, error) {
 		json.Unmarshal([]byte(r.SoulJSON), &a.Soul)
 
 		json.Unmarshal([]byte(r.InventoryJSON), &a.Inventory)
+		var trade agentTrade
+		json.Unmarshal([]byte(r.TradeJSON), &trade)
+		a.Destination, a.TradeDestSett, a.TradeCargo = trade.Destination, trade.TradeDestSett, trade.TradeCargo
+		a.TravelTicksLeft, a.ConsignmentDebt, a.TradePreferredDest = trade.TravelTicksLeft, trade.ConsignmentDebt, trade.TradePreferredDest
 
 		result = append(result, a)
 	}
 
+	db.dirty.reset(rowOf)
+	for _, a := range result {
+		db.dirty.record(a.ID, rowOf, rowPrint(a))
+	}
+	db.dirty.rowsKnown = true
 	return result, nil
 }
 
@@ -689,6 +598,7 @@ func (db *DB) LoadSettlements() ([]*social.Settlement, error) {
 		RoadLevel         uint8   `db:"road_level"`
 		MarketLevel       uint8   `db:"market_level"`
 		Abandoned         int     `db:"abandoned"`
+		LeaderID          *uint64 `db:"leader_id"`
 	}
 
 	var rows []settRow
@@ -714,6 +624,7 @@ func (db *DB) LoadSettlements() ([]*social.Settlement, error) {
 			WallLevel:         r.WallLevel,
 			RoadLevel:         r.RoadLevel,
 			MarketLevel:       r.MarketLevel,
+			LeaderID:          r.LeaderID,
 		})
 	}
 
@@ -733,24 +644,51 @@ func (db *DB) SaveMemories(agentList []*agents.Agent) error {
 		return err
 	}
 
-	stmt, err := tx.Preparex("INSERT INTO memories (agent_id, tick, content, importance) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Preparex(memoryInsert)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
+	db.dirty.reset(memoriesOf)
+	db.dirty.memoriesKnown = false
 	for _, a := range agentList {
 		if !a.Alive || len(a.Memories) == 0 {
 			continue
 		}
-		for _, m := range a.Memories {
-			if _, err := stmt.Exec(a.ID, m.Tick, m.Content, m.Importance); err != nil {
-				return fmt.Errorf("insert memory for agent %d: %w", a.ID, err)
-			}
+		if err := insertMemories(stmt, a); err != nil {
+			return err
 		}
+		db.dirty.record(a.ID, memoriesOf, memoryPrint(a))
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.dirty.memoriesKnown = true
+	clean(agentList, agents.DirtyMemories)
+	return nil
+}
+
+const memoryInsert = "INSERT INTO memories (agent_id, tick, content, importance, content_key) VALUES (?, ?, ?, ?, ?)"
+
+// memoryKey is a hash of a memory's content, cut to the 53 bits a cursor key
+// holds exactly. With the agent and tick it names a memory across saves,
+// which rewrite memory rows under new IDs.
+func memoryKey(content string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(content))
+	return h.Sum64() & (1<<53 - 1)
+}
+
+// insertMemories writes a's memories with stmt, a prepared memoryInsert.
+func insertMemories(stmt *sqlx.Stmt, a *agents.Agent) error {
+	for _, m := range a.Memories {
+		if _, err := stmt.Exec(a.ID, m.Tick, m.Content, m.Importance, memoryKey(m.Content)); err != nil {
+			return fmt.Errorf("insert memory for agent %d: %w", a.ID, err)
+		}
+	}
+	return nil
 }
 
 // LoadMemories reads all memories and attaches them to agents by ID.
@@ -789,6 +727,8 @@ func (db *DB) LoadMemories(agentIndex map[agents.AgentID]*agents.Agent) error {
 		}
 	}
 
+	db.dirty.learnLoaded(agentIndex, memoriesOf, memoryPrint)
+	db.dirty.memoriesKnown = true
 	return nil
 }
 
@@ -805,24 +745,43 @@ func (db *DB) SaveRelationships(agentList []*agents.Agent) error {
 		return err
 	}
 
-	stmt, err := tx.Preparex("INSERT INTO relationships (agent_id, target_id, sentiment, trust) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Preparex(relationshipInsert)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
+	db.dirty.reset(relationshipsOf)
+	db.dirty.relationshipsKnown = false
 	for _, a := range agentList {
 		if !a.Alive || len(a.Relationships) == 0 {
 			continue
 		}
-		for _, r := range a.Relationships {
-			if _, err := stmt.Exec(a.ID, r.TargetID, r.Sentiment, r.Trust); err != nil {
-				return fmt.Errorf("insert relationship for agent %d: %w", a.ID, err)
-			}
+		if err := insertRelationships(stmt, a); err != nil {
+			return err
 		}
+		db.dirty.record(a.ID, relationshipsOf, relationshipPrint(a))
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.dirty.relationshipsKnown = true
+	clean(agentList, agents.DirtyRelationships)
+	return nil
+}
+
+const relationshipInsert = "INSERT INTO relationships (agent_id, target_id, sentiment, trust) VALUES (?, ?, ?, ?)"
+
+// insertRelationships writes a's relationships with stmt, a prepared
+// relationshipInsert.
+func insertRelationships(stmt *sqlx.Stmt, a *agents.Agent) error {
+	for _, r := range a.Relationships {
+		if _, err := stmt.Exec(a.ID, r.TargetID, r.Sentiment, r.Trust); err != nil {
+			return fmt.Errorf("insert relationship for agent %d: %w", a.ID, err)
+		}
+	}
+	return nil
 }
 
 // LoadRelationships reads all relationships and attaches them to agents by ID.
@@ -863,6 +822,8 @@ func (db *DB) LoadRelationships(agentIndex map[agents.AgentID]*agents.Agent) err
 		}
 	}
 
+	db.dirty.learnLoaded(agentIndex, relationshipsOf, relationshipPrint)
+	db.dirty.relationshipsKnown = true
 	return nil
 }
 
@@ -1014,19 +975,20 @@ func (db *DB) LoadSettlementHistory(settlementID uint64, limit int) ([]Settlemen
 	return rows, err
 }
 
-// LoadAgentTimeline returns events involving a specific agent.
-func (db *DB) LoadAgentTimeline(agentID uint64, limit int) ([]engine.Event, error) {
-	if limit <= 0 {
-		limit = 50
+// eventSubjects extracts agent_id and settlement_id from an event's Meta, the
+// columns events are queried by.
+func eventSubjects(e engine.Event) (agentID, settlementID *uint64) {
+	if v, ok := e.Meta["agent_id"]; ok {
+		if id := metaToUint64(v); id != 0 {
+			agentID = &id
+		}
 	}
-	var events []engine.Event
-	err := db.conn.Select(&events,
-		`SELECT tick, description, category, narrated
-		 FROM events WHERE agent_id = ?
-		 ORDER BY tick DESC LIMIT ?`,
-		agentID, limit,
-	)
-	return events, err
+	if v, ok := e.Meta["settlement_id"]; ok {
+		if id := metaToUint64(v); id != 0 {
+			settlementID = &id
+		}
+	}
+	return agentID, settlementID
 }
 
 // metaToUint64 extracts a uint64 from Meta values which may be int, uint64, float64, etc.
@@ -0,0 +1,400 @@
+package persistence
+
+import (
+	"fmt"
+	"hash/maphash"
+	"log/slog"
+	"math"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/talgya/mini-world/internal/agents"
+)
+
+// Incremental saves. The DB remembers a fingerprint of what it last wrote for
+// each agent (row, memories and relationships separately) and the
+// incremental saves rewrite only the agents whose fingerprint moved, instead
+// of deleting and reinserting every table.
+//
+// Memories and relationships change in a handful of places (AddMemory and
+// the engine's bond helpers), which set agents.Dirty, so the hourly SaveSocial
+// fingerprints only the flagged agents, the newly stored and the dead. Rows
+// have no flag: needs, wealth, inventories and positions are written all over
+// the engine and needs decay every tick, so a flag would be set on nearly
+// every agent by the daily save anyway; the row save fingerprints everyone
+// and the fingerprint decides what is material.
+//
+// Floats are compared at two decimal places: a need drifting by a thousandth
+// is not a material change, and the stored value never strays more than 0.01
+// from the live one.
+
+// agentPrint is what the database holds for one agent. A zero fingerprint
+// means nothing stored: no row, no memories, no relationships.
+type agentPrint struct {
+	row, memories, relationships uint64
+	seen                         uint32 // last sweep that found the agent in the list
+}
+
+// dirtyState is the database's record of its own agent tables. Only saves
+// touch it, and saves run on the tick loop.
+type dirtyState struct {
+	prints map[agents.AgentID]agentPrint
+	sweeps uint32
+
+	// Whether the prints describe each table: set by a full save or load of
+	// it. Until then an incremental save falls back to the full one.
+	rowsKnown, memoriesKnown, relationshipsKnown bool
+}
+
+// The three columns of an agentPrint, for code shared between them.
+func rowOf(p *agentPrint) *uint64           { return &p.row }
+func memoriesOf(p *agentPrint) *uint64      { return &p.memories }
+func relationshipsOf(p *agentPrint) *uint64 { return &p.relationships }
+
+// put stores p, dropping agents with nothing stored.
+func (d *dirtyState) put(id agents.AgentID, p agentPrint) {
+	if p.row == 0 && p.memories == 0 && p.relationships == 0 {
+		delete(d.prints, id)
+		return
+	}
+	if d.prints == nil {
+		d.prints = make(map[agents.AgentID]agentPrint)
+	}
+	d.prints[id] = p
+}
+
+// reset forgets one column for every agent, ahead of a full rewrite of its
+// table.
+func (d *dirtyState) reset(field func(*agentPrint) *uint64) {
+	for id, p := range d.prints {
+		*field(&p) = 0
+		d.put(id, p)
+	}
+}
+
+// record notes that the table behind field now holds print for id.
+func (d *dirtyState) record(id agents.AgentID, field func(*agentPrint) *uint64, print uint64) {
+	p := d.prints[id]
+	*field(&p) = print
+	d.put(id, p)
+}
+
+// sweep finds the agents whose stored column differs from their current
+// fingerprint and calls write for each: with the agent, or with nil when
+// what is stored should go (the agent died, or is no longer in the list at
+// all). The new fingerprints are recorded as it goes. With a flag, a living
+// agent already stored is only fingerprinted when it carries the flag, which
+// sweep clears. It returns how many agents were written and deleted.
+func (d *dirtyState) sweep(list []*agents.Agent, field func(*agentPrint) *uint64, flag agents.Dirty,
+	print func(*agents.Agent) uint64, write func(agents.AgentID, *agents.Agent) error) (written, deleted int, err error) {
+	d.sweeps++
+	for _, a := range list {
+		p, stored := d.prints[a.ID]
+		if flag != 0 && stored && a.Alive && a.Dirty&flag == 0 {
+			p.seen = d.sweeps
+			d.prints[a.ID] = p
+			continue
+		}
+		a.Dirty &^= flag
+		var want uint64
+		if a.Alive {
+			want = print(a)
+		}
+		if !stored && want == 0 {
+			continue
+		}
+		p.seen = d.sweeps
+		if col := field(&p); *col != want {
+			target := a
+			if want == 0 {
+				target = nil
+			}
+			if err := write(a.ID, target); err != nil {
+				return written, deleted, err
+			}
+			if target == nil {
+				deleted++
+			} else {
+				written++
+			}
+			*col = want
+		}
+		d.put(a.ID, p)
+	}
+	for id, p := range d.prints {
+		if p.seen == d.sweeps || *field(&p) == 0 {
+			continue
+		}
+		if err := write(id, nil); err != nil {
+			return written, deleted, err
+		}
+		deleted++
+		*field(&p) = 0
+		d.put(id, p)
+	}
+	return written, deleted, nil
+}
+
+// SaveAgentsIncremental writes the rows of agents that changed since the
+// last save and deletes the rows of agents that died. The first save after
+// opening, unless the agents were loaded from this database, is a full
+// SaveAgents.
+func (db *DB) SaveAgentsIncremental(agentList []*agents.Agent) error {
+	if !db.dirty.rowsKnown {
+		return db.SaveAgents(agentList)
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	upsert, err := tx.Preparex("INSERT OR REPLACE" + agentInsert)
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+	del, err := tx.Preparex("DELETE FROM agents WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer del.Close()
+
+	written, deleted, err := db.dirty.sweep(agentList, rowOf, 0, rowPrint, func(id agents.AgentID, a *agents.Agent) error {
+		if a == nil {
+			_, err := del.Exec(id)
+			return err
+		}
+		return insertAgent(upsert, a)
+	})
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		// The prints may be ahead of the table now; start over with a full save.
+		db.dirty.rowsKnown = false
+		return err
+	}
+	slog.Info("agents saved", "changed", written, "removed", deleted, "total_in_memory", len(agentList))
+	return nil
+}
+
+// SaveSocial brings the memories and relationships tables up to date,
+// rewriting only the agents whose memories or relationships changed since
+// the last save. Falls back to SaveMemories and SaveRelationships for a
+// table the database does not know the contents of yet.
+func (db *DB) SaveSocial(agentList []*agents.Agent) error {
+	if !db.dirty.memoriesKnown {
+		if err := db.SaveMemories(agentList); err != nil {
+			return fmt.Errorf("save memories: %w", err)
+		}
+	}
+	if !db.dirty.relationshipsKnown {
+		if err := db.SaveRelationships(agentList); err != nil {
+			return fmt.Errorf("save relationships: %w", err)
+		}
+	}
+
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var stmts [4]*sqlx.Stmt
+	for i, q := range []string{
+		"DELETE FROM memories WHERE agent_id = ?", memoryInsert,
+		"DELETE FROM relationships WHERE agent_id = ?", relationshipInsert,
+	} {
+		if stmts[i], err = tx.Preparex(q); err != nil {
+			return err
+		}
+		defer stmts[i].Close()
+	}
+	// replace returns a sweep writer that clears an agent's rows with del
+	// and, for a live agent, inserts the current ones with insert.
+	replace := func(del *sqlx.Stmt, insert func(*sqlx.Stmt, *agents.Agent) error, ins *sqlx.Stmt) func(agents.AgentID, *agents.Agent) error {
+		return func(id agents.AgentID, a *agents.Agent) error {
+			if _, err := del.Exec(id); err != nil || a == nil {
+				return err
+			}
+			return insert(ins, a)
+		}
+	}
+	var memWritten, memDeleted, relWritten, relDeleted int
+	memWritten, memDeleted, err = db.dirty.sweep(agentList, memoriesOf, agents.DirtyMemories, memoryPrint,
+		replace(stmts[0], insertMemories, stmts[1]))
+	if err == nil {
+		relWritten, relDeleted, err = db.dirty.sweep(agentList, relationshipsOf, agents.DirtyRelationships, relationshipPrint,
+			replace(stmts[2], insertRelationships, stmts[3]))
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		db.dirty.memoriesKnown, db.dirty.relationshipsKnown = false, false
+		return fmt.Errorf("save memories and relationships: %w", err)
+	}
+	if memWritten+memDeleted+relWritten+relDeleted > 0 {
+		slog.Debug("memories and relationships saved",
+			"memories_changed", memWritten, "memories_removed", memDeleted,
+			"relationships_changed", relWritten, "relationships_removed", relDeleted)
+	}
+	return nil
+}
+
+var printSeed = maphash.MakeSeed()
+
+// fingerprint hashes values the way the incremental saves compare them.
+type fingerprint struct{ h maphash.Hash }
+
+func newFingerprint() *fingerprint {
+	f := &fingerprint{}
+	f.h.SetSeed(printSeed)
+	return f
+}
+
+func (f *fingerprint) num(v uint64) {
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(v >> (8 * i))
+	}
+	f.h.Write(b[:])
+}
+
+func (f *fingerprint) float(v float64) { f.num(uint64(int64(math.Round(v * 100)))) }
+
+func (f *fingerprint) str(s string) {
+	f.num(uint64(len(s)))
+	f.h.WriteString(s)
+}
+
+func (f *fingerprint) optional(v *uint64) {
+	if v == nil {
+		f.num(0)
+		return
+	}
+	f.num(1)
+	f.num(*v)
+}
+
+// value hashes any of the plain structs and arrays stored as JSON columns,
+// so a field added to one of them is covered without touching this file.
+func (f *fingerprint) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			f.value(v.Field(i))
+		}
+	case reflect.Array, reflect.Slice:
+		f.num(uint64(v.Len()))
+		for i := range v.Len() {
+			f.value(v.Index(i))
+		}
+	case reflect.Float32, reflect.Float64:
+		f.float(v.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.num(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.num(v.Uint())
+	case reflect.Bool:
+		if v.Bool() {
+			f.num(1)
+		} else {
+			f.num(0)
+		}
+	case reflect.String:
+		f.str(v.String())
+	case reflect.Pointer:
+		if v.IsNil() {
+			f.num(0)
+		} else {
+			f.num(1)
+			f.value(v.Elem())
+		}
+	default:
+		panic("persistence: cannot fingerprint " + v.Type().String())
+	}
+}
+
+// sum returns the fingerprint, never zero (zero means nothing stored).
+func (f *fingerprint) sum() uint64 { return f.h.Sum64() | 1 }
+
+// rowPrint fingerprints the columns SaveAgents writes for a.
+func rowPrint(a *agents.Agent) uint64 {
+	f := newFingerprint()
+	f.num(uint64(a.ID))
+	f.str(a.Name)
+	f.num(uint64(a.Age))
+	f.num(uint64(a.AgeMonths))
+	f.num(uint64(a.Sex))
+	f.float(float64(a.Health))
+	f.num(uint64(a.Position.Q))
+	f.num(uint64(a.Position.R))
+	f.optional(a.HomeSettID)
+	f.num(uint64(a.Occupation))
+	f.num(a.Wealth)
+	f.num(uint64(a.Tier))
+	f.float(float64(a.Wellbeing.EffectiveMood))
+	f.num(a.BornTick)
+	f.num(uint64(a.Role))
+	f.optional(a.FactionID)
+	f.str(a.Archetype)
+	f.value(reflect.ValueOf(&a.Skills).Elem())
+	f.value(reflect.ValueOf(&a.Needs).Elem())
+	f.value(reflect.ValueOf(&a.Soul).Elem())
+	f.value(reflect.ValueOf(&a.Inventory).Elem())
+	f.float(float64(a.Wellbeing.Satisfaction))
+	f.float(float64(a.Wellbeing.Alignment))
+	f.num(a.LastWorkTick)
+	f.float(float64(a.ProductionProgress))
+	f.value(reflect.ValueOf(tradeOf(a)))
+	f.float(float64(a.PracticeBoost))
+	return f.sum()
+}
+
+// memoryPrint fingerprints a's memories; zero when it has none.
+func memoryPrint(a *agents.Agent) uint64 {
+	if len(a.Memories) == 0 {
+		return 0
+	}
+	f := newFingerprint()
+	for _, m := range a.Memories {
+		f.num(m.Tick)
+		f.str(m.Content)
+		f.float(float64(m.Importance))
+	}
+	return f.sum()
+}
+
+// relationshipPrint fingerprints a's relationships; zero when it has none.
+func relationshipPrint(a *agents.Agent) uint64 {
+	if len(a.Relationships) == 0 {
+		return 0
+	}
+	f := newFingerprint()
+	for _, r := range a.Relationships {
+		f.num(uint64(r.TargetID))
+		f.float(float64(r.Sentiment))
+		f.float(float64(r.Trust))
+	}
+	return f.sum()
+}
+
+// clean clears flag on every agent in list, after a full save of its table.
+func clean(list []*agents.Agent, flag agents.Dirty) {
+	for _, a := range list {
+		a.Dirty &^= flag
+	}
+}
+
+// learnLoaded records the memories and relationships just loaded into
+// agentIndex as the tables' contents.
+func (d *dirtyState) learnLoaded(agentIndex map[agents.AgentID]*agents.Agent, field func(*agentPrint) *uint64,
+	print func(*agents.Agent) uint64) {
+	d.reset(field)
+	for id, a := range agentIndex {
+		if p := print(a); p != 0 {
+			d.record(id, field, p)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// befriend gives every agent a few relationships with its neighbours in the
+// list, so the relationships table has rows to keep.
+func befriend(list []*agents.Agent) {
+	for i, a := range list {
+		for k := 1; k <= 3; k++ {
+			b := list[(i+k)%len(list)]
+			a.Relationships = append(a.Relationships, agents.Relationship{TargetID: b.ID, Sentiment: 0.2, Trust: 0.4})
+		}
+	}
+}
+
+// TestIncrementalSave changes a handful of agents after a full save and
+// checks the incremental saves write exactly those: rows planted in the
+// tables for untouched agents survive, and a reload matches the world.
+func TestIncrementalSave(t *testing.T) {
+	sim, _ := checkpointWorld(t, 8, 300)
+	befriend(sim.Agents)
+	db, err := Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows that only a rewrite of the untouched agents would fix.
+	quiet := sim.Agents[10]
+	db.conn.MustExec("UPDATE agents SET name = 'planted' WHERE id = ?", quiet.ID)
+	db.conn.MustExec("UPDATE relationships SET trust = 0.99 WHERE agent_id = ?", quiet.ID)
+
+	a := sim.Agents
+	a[0].Wealth += 50
+	a[1].Alive = false
+	gone := a[2].ID
+	a[3].Relationships[0].Sentiment = -0.8
+	a[3].Dirty |= agents.DirtyRelationships // as the engine's bond helpers do
+	agents.AddMemory(a[4], 9000, "saw a comet", 0.9)
+	a[5].Needs.Survival += 0.001 // not material
+	a[6].Relationships = nil
+	a[6].Dirty |= agents.DirtyRelationships
+	list := slices.Delete(slices.Clone(a), 2, 3) // compacted away between saves
+
+	if err := db.SaveAgentsIncremental(list); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveSocial(list); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range a[3:7] {
+		if x.Dirty != 0 {
+			t.Errorf("agent %d still flagged %b after the save", x.ID, x.Dirty)
+		}
+	}
+
+	loaded, err := db.LoadAgents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := make(map[agents.AgentID]*agents.Agent)
+	for _, l := range loaded {
+		index[l.ID] = l
+	}
+	if err := db.LoadMemories(index); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.LoadRelationships(index); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != len(a)-2 {
+		t.Errorf("%d agents stored, want %d", len(loaded), len(a)-2)
+	}
+	for _, id := range []agents.AgentID{a[1].ID, gone} {
+		if index[id] != nil {
+			t.Errorf("agent %d still stored", id)
+		}
+	}
+	var orphans int
+	db.conn.Get(&orphans, "SELECT count(*) FROM relationships WHERE agent_id IN (?, ?)", a[1].ID, gone)
+	if orphans != 0 {
+		t.Errorf("%d relationships left for removed agents", orphans)
+	}
+	if got := index[a[0].ID].Wealth; got != a[0].Wealth {
+		t.Errorf("wealth %d, want %d", got, a[0].Wealth)
+	}
+	if got := index[a[3].ID].Relationships[0].Sentiment; got != -0.8 {
+		t.Errorf("sentiment %v, want -0.8", got)
+	}
+	if got := index[a[4].ID].Memories; len(got) == 0 || got[len(got)-1].Content != "saw a comet" {
+		t.Errorf("memories %v", got)
+	}
+	if got := index[a[6].ID].Relationships; len(got) != 0 {
+		t.Errorf("cleared relationships stored: %v", got)
+	}
+	if got := index[a[5].ID].Needs.Survival; got == a[5].Needs.Survival {
+		t.Error("immaterial need change was written")
+	}
+	if l := index[quiet.ID]; l.Name != "planted" || l.Relationships[0].Trust != 0.99 {
+		t.Errorf("untouched agent rewritten: %q, %+v", l.Name, l.Relationships)
+	}
+
+	// Agents just loaded from the database are what it holds: saving them
+	// back writes nothing.
+	db.conn.MustExec("UPDATE agents SET name = 'planted' WHERE id = ?", a[0].ID)
+	if err := db.SaveAgentsIncremental(loaded); err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	db.conn.Get(&name, "SELECT name FROM agents WHERE id = ?", a[0].ID)
+	if name != "planted" {
+		t.Errorf("reloaded agent rewritten: %q", name)
+	}
+}
+
+// TestRowPrintCoversColumns changes each value SaveAgents stores and checks
+// the fingerprint notices, so a column added to the agents table without
+// extending rowPrint fails here.
+func TestRowPrintCoversColumns(t *testing.T) {
+	sim, _ := checkpointWorld(t, 8, 300)
+	other := uint64(999)
+	changes := map[string]func(a *agents.Agent){
+		"id":                  func(a *agents.Agent) { a.ID++ },
+		"name":                func(a *agents.Agent) { a.Name += "x" },
+		"age":                 func(a *agents.Agent) { a.Age++ },
+		"age_months":          func(a *agents.Agent) { a.AgeMonths++ },
+		"sex":                 func(a *agents.Agent) { a.Sex ^= 1 },
+		"health":              func(a *agents.Agent) { a.Health += 0.1 },
+		"pos_q":               func(a *agents.Agent) { a.Position.Q++ },
+		"pos_r":               func(a *agents.Agent) { a.Position.R++ },
+		"home_settlement_id":  func(a *agents.Agent) { a.HomeSettID = nil },
+		"occupation":          func(a *agents.Agent) { a.Occupation++ },
+		"wealth":              func(a *agents.Agent) { a.Wealth++ },
+		"tier":                func(a *agents.Agent) { a.Tier++ },
+		"mood":                func(a *agents.Agent) { a.Wellbeing.EffectiveMood += 0.1 },
+		"born_tick":           func(a *agents.Agent) { a.BornTick++ },
+		"role":                func(a *agents.Agent) { a.Role++ },
+		"faction_id":          func(a *agents.Agent) { a.FactionID = &other },
+		"archetype":           func(a *agents.Agent) { a.Archetype += "x" },
+		"skills_json":         func(a *agents.Agent) { a.Skills.Trade += 0.1 },
+		"needs_json":          func(a *agents.Agent) { a.Needs.Purpose += 0.1 },
+		"soul_json":           func(a *agents.Agent) { a.Soul.WisdomScore += 0.1 },
+		"inventory_json":      func(a *agents.Agent) { a.Inventory[0]++ },
+		"satisfaction":        func(a *agents.Agent) { a.Wellbeing.Satisfaction += 0.1 },
+		"alignment":           func(a *agents.Agent) { a.Wellbeing.Alignment += 0.1 },
+		"last_work_tick":      func(a *agents.Agent) { a.LastWorkTick++ },
+		"production_progress": func(a *agents.Agent) { a.ProductionProgress += 0.1 },
+		"trade_json":          func(a *agents.Agent) { a.TravelTicksLeft++ },
+		"practice_boost":      func(a *agents.Agent) { a.PracticeBoost += 0.1 },
+		"alive":               nil, // always 1; dead agents have no row
+	}
+
+	db, err := Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	cols, err := tableColumns(db.conn, "agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range cols {
+		change, ok := changes[name]
+		if !ok {
+			t.Errorf("agents.%s has no case here; add one, and add the column to rowPrint", name)
+			continue
+		}
+		if change == nil {
+			continue
+		}
+		a := sim.Agents[0].Clone()
+		before := rowPrint(a)
+		change(a)
+		if rowPrint(a) == before {
+			t.Errorf("changing %s leaves the fingerprint alone", name)
+		}
+	}
+}
+
+// BenchmarkIncrementalSave compares rewriting 100K agents with saving the
+// 1% that changed. social/hourly is the hourly memories and relationships
+// save when a few hundred agents made friends or memories. The unchanged
+// cases are the cost of finding nothing to write: rows fingerprints every
+// agent, social only looks at the flags.
+func BenchmarkIncrementalSave(b *testing.B) {
+	sim, _ := checkpointWorld(b, 22, 100_000)
+	befriend(sim.Agents)
+	db, err := Open(filepath.Join(b.TempDir(), "world.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.SaveWorldStateFull(sim); err != nil {
+		b.Fatal(err)
+	}
+	n := len(sim.Agents)
+
+	b.Run("full/100K", func(b *testing.B) {
+		for range b.N {
+			if err := db.SaveAgents(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+			if err := db.SaveMemories(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+			if err := db.SaveRelationships(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("incremental/100K/1pct", func(b *testing.B) {
+		for i := range b.N {
+			for j := 0; j < n; j += 100 {
+				a := sim.Agents[(j+i)%n]
+				a.Wealth++
+				a.Relationships[0].Trust += 0.05
+				a.Dirty |= agents.DirtyRelationships
+				agents.AddMemory(a, uint64(i), fmt.Sprintf("bench %d", i), 0.5)
+			}
+			if err := db.SaveAgentsIncremental(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+			if err := db.SaveSocial(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("memories/100K", func(b *testing.B) {
+		// A full rewrite of every memory, through the search index triggers.
+		for range b.N {
+			if err := db.SaveMemories(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("rows/unchanged", func(b *testing.B) {
+		for range b.N {
+			if err := db.SaveAgentsIncremental(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("social/unchanged", func(b *testing.B) {
+		for range b.N {
+			if err := db.SaveSocial(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("social/hourly", func(b *testing.B) {
+		for i := range b.N {
+			for j := 0; j < n; j += 250 {
+				a := sim.Agents[(j+i)%n]
+				a.Relationships[1].Sentiment += 0.05
+				a.Dirty |= agents.DirtyRelationships
+				agents.AddMemory(a, engine.TicksPerSimHour*uint64(i), "met a stranger", 0.4)
+			}
+			if err := db.SaveSocial(sim.Agents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,419 @@
+package persistence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// ── Event archive ───────────────────────────────────────────────────
+//
+// The events table holds the last EventRetentionDays of history. The weekly
+// archive pass moves anything older into event_archive_segments: one gzipped
+// segment per sim-season per pass, holding the events' text. Beside it,
+// event_archive keeps one small row per archived event (original id, tick,
+// category, agent, settlement, and where its text is) so filtered queries
+// are answered from indexes and only the segments with hits are decompressed.
+// event_archive_fts indexes the archived text for search without keeping a
+// copy of it.
+//
+// QueryEvents reads across the tick loop's unsaved events, the events table
+// and the archive as one newest-first stream, paged with an EventCursor.
+
+// segmentCacheSize is how many decompressed segments QueryEvents keeps.
+// Paging through one agent's history touches the same few segments.
+const segmentCacheSize = 8
+
+// archivedText is an event's text as stored in a segment.
+type archivedText struct {
+	Description string `json:"d"`
+	Narrated    string `json:"n,omitempty"`
+}
+
+type segmentCache struct {
+	mu    sync.Mutex
+	segs  map[int64][]archivedText
+	order []int64 // least recently used first
+}
+
+type hotEvent struct {
+	ID           int64   `db:"id"`
+	Tick         uint64  `db:"tick"`
+	Description  string  `db:"description"`
+	Category     string  `db:"category"`
+	Narrated     string  `db:"narrated"`
+	AgentID      *uint64 `db:"agent_id"`
+	SettlementID *uint64 `db:"settlement_id"`
+}
+
+// ArchiveOldEvents moves events more than keepTicks older than currentTick
+// from the events table into the archive, in one transaction, and returns how
+// many it moved.
+func (db *DB) ArchiveOldEvents(currentTick, keepTicks uint64) (int64, error) {
+	if currentTick <= keepTicks {
+		return 0, nil
+	}
+	cutoff := currentTick - keepTicks
+
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var rows []hotEvent
+	if err := tx.Select(&rows, `SELECT id, tick, description, category, narrated, agent_id, settlement_id
+		FROM events WHERE tick < ? ORDER BY id`, cutoff); err != nil {
+		return 0, fmt.Errorf("read events to archive: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	bySeason := make(map[uint64][]hotEvent)
+	for _, r := range rows {
+		season := r.Tick / engine.TicksPerSimSeason
+		bySeason[season] = append(bySeason[season], r)
+	}
+	seasons := make([]uint64, 0, len(bySeason))
+	for season := range bySeason {
+		seasons = append(seasons, season)
+	}
+	slices.Sort(seasons)
+
+	for _, season := range seasons {
+		events := bySeason[season]
+		texts := make([]archivedText, len(events))
+		first, last := events[0].Tick, events[0].Tick
+		for i, e := range events {
+			texts[i] = archivedText{Description: e.Description, Narrated: e.Narrated}
+			first, last = min(first, e.Tick), max(last, e.Tick)
+		}
+		data, err := compressSegment(texts)
+		if err != nil {
+			return 0, err
+		}
+		res, err := tx.Exec(`INSERT INTO event_archive_segments (season, first_tick, last_tick, events, data)
+			VALUES (?, ?, ?, ?, ?)`, season, first, last, len(events), data)
+		if err != nil {
+			return 0, fmt.Errorf("write archive segment: %w", err)
+		}
+		segID, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		for i, e := range events {
+			if _, err := tx.Exec(`INSERT INTO event_archive (event_id, segment_id, seq, tick, category, agent_id, settlement_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`, e.ID, segID, i, e.Tick, e.Category, e.AgentID, e.SettlementID); err != nil {
+				return 0, fmt.Errorf("index archived event %d: %w", e.ID, err)
+			}
+			if _, err := tx.Exec(`INSERT INTO event_archive_fts (rowid, description, narrated) VALUES (?, ?, ?)`,
+				e.ID, e.Description, e.Narrated); err != nil {
+				return 0, fmt.Errorf("index archived event %d: %w", e.ID, err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM events WHERE tick < ?", cutoff); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
+
+func compressSegment(texts []archivedText) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(texts); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressSegment(data []byte) ([]archivedText, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var texts []archivedText
+	if err := json.Unmarshal(raw, &texts); err != nil {
+		return nil, err
+	}
+	return texts, nil
+}
+
+// segment returns an archive segment's texts, decompressing it on a cache
+// miss.
+func (db *DB) segment(id int64) ([]archivedText, error) {
+	c := &db.segments
+	c.mu.Lock()
+	if texts, ok := c.segs[id]; ok {
+		c.order = append(slices.DeleteFunc(c.order, func(v int64) bool { return v == id }), id)
+		c.mu.Unlock()
+		return texts, nil
+	}
+	c.mu.Unlock()
+
+	var data []byte
+	if err := db.conn.Get(&data, "SELECT data FROM event_archive_segments WHERE id = ?", id); err != nil {
+		return nil, fmt.Errorf("read archive segment %d: %w", id, err)
+	}
+	texts, err := decompressSegment(data)
+	if err != nil {
+		return nil, fmt.Errorf("archive segment %d: %w", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.segs == nil {
+		c.segs = make(map[int64][]archivedText)
+	}
+	if _, ok := c.segs[id]; !ok {
+		c.segs[id] = texts
+		c.order = append(c.order, id)
+		if len(c.order) > segmentCacheSize {
+			delete(c.segs, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	return texts, nil
+}
+
+// EventCursor is a position in the newest-first event stream: the next page
+// starts at Tick, after the first Skip events at that tick.
+type EventCursor struct {
+	Tick uint64
+	Skip int
+}
+
+// String encodes the cursor for use in a URL.
+func (c EventCursor) String() string {
+	return fmt.Sprintf("%d.%d", c.Tick, c.Skip)
+}
+
+// ErrBadCursor is returned by ParseEventCursor for a malformed cursor.
+var ErrBadCursor = errors.New("malformed cursor")
+
+// ParseEventCursor decodes a cursor made by EventCursor.String.
+func ParseEventCursor(s string) (EventCursor, error) {
+	t, k, ok := strings.Cut(s, ".")
+	tick, err1 := strconv.ParseUint(t, 10, 64)
+	skip, err2 := strconv.Atoi(k)
+	if !ok || err1 != nil || err2 != nil || skip < 0 {
+		return EventCursor{}, ErrBadCursor
+	}
+	return EventCursor{Tick: tick, Skip: skip}, nil
+}
+
+// EventQuery selects events for QueryEvents. Zero fields do not filter.
+type EventQuery struct {
+	AgentID      uint64
+	SettlementID uint64
+	Category     string
+	FromTick     uint64       // inclusive
+	ToTick       uint64       // inclusive; 0 means no upper bound
+	Cursor       *EventCursor // nil starts at the newest event
+	Limit        int          // default 50
+}
+
+func (q EventQuery) matches(e engine.Event) bool {
+	agentID, settlementID := eventSubjects(e)
+	switch {
+	case q.AgentID != 0 && (agentID == nil || *agentID != q.AgentID),
+		q.SettlementID != 0 && (settlementID == nil || *settlementID != q.SettlementID),
+		q.Category != "" && string(e.Category) != q.Category,
+		e.Tick < q.FromTick,
+		e.Tick > q.upperTick():
+		return false
+	}
+	return true
+}
+
+func (q EventQuery) upperTick() uint64 {
+	upper := q.ToTick
+	if upper == 0 {
+		upper = math.MaxInt64 // SQLite integers are signed
+	}
+	if q.Cursor != nil {
+		upper = min(upper, q.Cursor.Tick)
+	}
+	return upper
+}
+
+// where returns the SQL filter for q over the columns the events table and
+// the archive index share.
+func (q EventQuery) where() (string, []any) {
+	conds := []string{"tick >= ?", "tick <= ?"}
+	args := []any{q.FromTick, q.upperTick()}
+	if q.AgentID != 0 {
+		conds = append(conds, "agent_id = ?")
+		args = append(args, q.AgentID)
+	}
+	if q.SettlementID != 0 {
+		conds = append(conds, "settlement_id = ?")
+		args = append(args, q.SettlementID)
+	}
+	if q.Category != "" {
+		conds = append(conds, "category = ?")
+		args = append(args, q.Category)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// QueryEvents returns one page of events matching q, newest first, from
+// unsaved (the tick loop's events not yet written, see
+// engine.Simulation.UnsavedEventTail), the events table and the archive.
+// The returned cursor fetches the next page; it is nil on the last one.
+func (db *DB) QueryEvents(q EventQuery, unsaved []engine.Event) ([]engine.Event, *EventCursor, error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	need := q.need()
+	found := q.newest(nil, unsaved, need)
+
+	where, args := q.where()
+	var hot []hotEvent
+	if err := db.conn.Select(&hot, `SELECT id, tick, description, category, narrated, agent_id, settlement_id
+		FROM events WHERE `+where+` ORDER BY tick DESC, id DESC LIMIT ?`, append(args, need)...); err != nil {
+		return nil, nil, fmt.Errorf("query events: %w", err)
+	}
+	for _, h := range hot {
+		found = append(found, h.event())
+	}
+
+	if len(found) < need {
+		archived, err := db.queryArchive(where, args, need)
+		if err != nil {
+			return nil, nil, err
+		}
+		found = append(found, archived...)
+	}
+	page, next := q.page(found)
+	return page, next, nil
+}
+
+// need is how many candidates, newest first, a page of q needs: the events
+// the cursor skips (they all sit at its tick, at the front), the page, and
+// one extra that tells whether there is another page.
+func (q EventQuery) need() int {
+	skip := 0
+	if q.Cursor != nil {
+		skip = q.Cursor.Skip
+	}
+	return skip + q.Limit + 1
+}
+
+// newest appends the events of list (oldest first) that match q to found,
+// newest first, until found holds n.
+func (q EventQuery) newest(found, list []engine.Event, n int) []engine.Event {
+	for i := len(list) - 1; i >= 0 && len(found) < n; i-- {
+		if q.matches(list[i]) {
+			found = append(found, list[i])
+		}
+	}
+	return found
+}
+
+// page cuts q's page out of found: every candidate, each source newest
+// first with the unsaved events ahead of the stored ones. It returns the
+// cursor for the next page, nil on the last.
+func (q EventQuery) page(found []engine.Event) ([]engine.Event, *EventCursor) {
+	skip := 0
+	if q.Cursor != nil {
+		skip = q.Cursor.Skip
+	}
+	// Each source is already newest first and the unsaved events are the
+	// newest; a stable sort only interleaves any that share a tick.
+	slices.SortStableFunc(found, func(a, b engine.Event) int {
+		switch {
+		case a.Tick > b.Tick:
+			return -1
+		case a.Tick < b.Tick:
+			return 1
+		}
+		return 0
+	})
+
+	if q.Cursor != nil {
+		n := 0
+		for n < len(found) && n < skip && found[n].Tick == q.Cursor.Tick {
+			n++
+		}
+		found = found[n:]
+	}
+	if len(found) <= q.Limit {
+		return found, nil
+	}
+	page := found[:q.Limit]
+	last := page[len(page)-1].Tick
+	next := &EventCursor{Tick: last}
+	for _, e := range page {
+		if e.Tick == last {
+			next.Skip++
+		}
+	}
+	if q.Cursor != nil && q.Cursor.Tick == last {
+		next.Skip += skip
+	}
+	return page, next
+}
+
+func (db *DB) queryArchive(where string, args []any, limit int) ([]engine.Event, error) {
+	var rows []struct {
+		hotEvent
+		SegmentID int64 `db:"segment_id"`
+		Seq       int   `db:"seq"`
+	}
+	if err := db.conn.Select(&rows, `SELECT event_id AS id, segment_id, seq, tick, category, agent_id, settlement_id,
+		'' AS description, '' AS narrated
+		FROM event_archive WHERE `+where+` ORDER BY tick DESC, event_id DESC LIMIT ?`, append(args, limit)...); err != nil {
+		return nil, fmt.Errorf("query event archive: %w", err)
+	}
+	out := make([]engine.Event, 0, len(rows))
+	for _, r := range rows {
+		texts, err := db.segment(r.SegmentID)
+		if err != nil {
+			return nil, err
+		}
+		if r.Seq >= len(texts) {
+			return nil, fmt.Errorf("archived event %d: segment %d has %d events, want index %d", r.ID, r.SegmentID, len(texts), r.Seq)
+		}
+		r.Description, r.Narrated = texts[r.Seq].Description, texts[r.Seq].Narrated
+		out = append(out, r.event())
+	}
+	return out, nil
+}
+
+func (h hotEvent) event() engine.Event {
+	e := engine.Event{Tick: h.Tick, Description: h.Description, NarratedDescription: h.Narrated, Category: eventproto.Category(h.Category)}
+	if h.AgentID != nil || h.SettlementID != nil {
+		e.Meta = make(map[string]any, 2)
+		if h.AgentID != nil {
+			e.Meta["agent_id"] = *h.AgentID
+		}
+		if h.SettlementID != nil {
+			e.Meta["settlement_id"] = *h.SettlementID
+		}
+	}
+	return e
+}
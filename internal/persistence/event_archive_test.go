@@ -0,0 +1,118 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// pageAll follows QueryEvents cursors to the end and returns the
+// descriptions in order.
+func pageAll(t *testing.T, db *DB, q EventQuery, unsaved []engine.Event) []string {
+	t.Helper()
+	var got []string
+	for pages := 0; ; pages++ {
+		if pages > 1000 {
+			t.Fatal("cursor never ended")
+		}
+		events, next, err := db.QueryEvents(q, unsaved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) > q.Limit {
+			t.Fatalf("page of %d, limit %d", len(events), q.Limit)
+		}
+		for _, e := range events {
+			got = append(got, e.Description)
+		}
+		if next == nil {
+			return got
+		}
+		c, err := ParseEventCursor(next.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Cursor = &c
+	}
+}
+
+// TestEventArchive archives the older part of a history spanning three
+// sim-seasons, then pages through unsaved, hot and archived events as one
+// stream.
+func TestEventArchive(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Two events per tick, so page boundaries split ticks.
+	var all []engine.Event
+	for i := range 200 {
+		cat := eventproto.Category("birth")
+		if i%2 == 1 {
+			cat = "death"
+		}
+		all = append(all, engine.Event{
+			Tick:        uint64(i/2) * 2000,
+			Description: fmt.Sprintf("event %d", i),
+			Category:    cat,
+			Meta:        map[string]any{"agent_id": uint64(i%3 + 1)},
+		})
+	}
+	saved, unsaved := all[:150], all[150:]
+	if err := db.SaveEvents(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := db.ArchiveOldEvents(200_000, 100_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 100 { // ticks below 100,000
+		t.Errorf("archived %d events, want 100", moved)
+	}
+	var segments, hot int
+	db.conn.Get(&segments, "SELECT count(*) FROM event_archive_segments")
+	db.conn.Get(&hot, "SELECT count(*) FROM events")
+	if segments != 2 || hot != 50 {
+		t.Errorf("%d segments and %d hot events, want 2 and 50", segments, hot)
+	}
+	if moved, _ := db.ArchiveOldEvents(200_000, 100_000); moved != 0 {
+		t.Errorf("second pass archived %d", moved)
+	}
+
+	want := func(keep func(i int, e engine.Event) bool) []string {
+		var out []string
+		for i, e := range all {
+			if keep(i, e) {
+				out = append(out, e.Description)
+			}
+		}
+		slices.Reverse(out)
+		return out
+	}
+	cases := []struct {
+		name string
+		q    EventQuery
+		keep func(i int, e engine.Event) bool
+	}{
+		{"all", EventQuery{Limit: 7}, func(int, engine.Event) bool { return true }},
+		{"agent", EventQuery{AgentID: 2, Limit: 5}, func(i int, _ engine.Event) bool { return i%3 == 1 }},
+		{"category and range", EventQuery{Category: "death", FromTick: 50_000, ToTick: 160_000, Limit: 4},
+			func(_ int, e engine.Event) bool {
+				return e.Category == "death" && e.Tick >= 50_000 && e.Tick <= 160_000
+			}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, want := pageAll(t, db, c.q, unsaved), want(c.keep); !slices.Equal(got, want) {
+				t.Errorf("got %d events %v\nwant %d %v", len(got), got, len(want), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// ── Graveyard ───────────────────────────────────────────────────────
+//
+// One row per deceased agent: the snapshot engine.Simulation takes at the
+// moment of death, written in SaveHistory and never pruned. Queries read the
+// tick loop's unsaved graves and the table as one stream, newest death
+// first unless sorted by another column, paged with a KeyCursor.
+
+// graveSorts are the Grave fields QueryGraves sorts by, named as in its
+// JSON. Nullable columns are left out: NULLs do not compare.
+var graveSorts = map[string]sortKey[engine.Grave]{
+	"id":         {"agent_id", func(g engine.Grave) Key { return UintKey(uint64(g.AgentID)) }},
+	"died_tick":  {"died_tick", func(g engine.Grave) Key { return UintKey(g.DiedTick) }},
+	"born_tick":  {"born_tick", func(g engine.Grave) Key { return UintKey(g.BornTick) }},
+	"age":        {"age", func(g engine.Grave) Key { return UintKey(uint64(g.Age)) }},
+	"wealth":     {"wealth", func(g engine.Grave) Key { return UintKey(g.Wealth) }},
+	"coherence":  {"coherence", func(g engine.Grave) Key { return FloatKey(float64(g.Coherence)) }},
+	"occupation": {"occupation", func(g engine.Grave) Key { return UintKey(uint64(g.Occupation)) }},
+	"state":      {"state", func(g engine.Grave) Key { return UintKey(uint64(g.State)) }},
+	"sex":        {"sex", func(g engine.Grave) Key { return UintKey(uint64(g.Sex)) }},
+}
+
+// GraveSorts lists the fields GraveQuery.Sort accepts.
+func GraveSorts() []string { return sortFields(graveSorts) }
+
+// GraveQuery selects graves for QueryGraves. Zero fields do not filter.
+type GraveQuery struct {
+	Cause        string
+	SettlementID uint64
+	FactionID    uint64
+	Occupation   *agents.Occupation
+	State        *agents.StateOfBeing
+	Age          Range
+	Wealth       Range
+	FromTick     uint64     // inclusive, on the tick of death
+	ToTick       uint64     // inclusive; 0 means no upper bound
+	Sort         string     // a GraveSorts field; "" is died_tick
+	Asc          bool       // lowest first; the default is highest first
+	Cursor       *KeyCursor // nil starts at the first grave in sort order
+	Limit        int        // default 50
+}
+
+func (q GraveQuery) keyset() (keyset[engine.Grave], error) {
+	return newKeyset(graveSorts, q.Sort, "died_tick", q.Asc, q.Cursor, graveSorts["id"])
+}
+
+func (q GraveQuery) upperTick() uint64 {
+	if q.ToTick == 0 {
+		return math.MaxInt64 // SQLite integers are signed
+	}
+	return q.ToTick
+}
+
+func (q GraveQuery) matches(g engine.Grave, k keyset[engine.Grave]) bool {
+	switch {
+	case q.Cause != "" && g.Cause != q.Cause,
+		q.SettlementID != 0 && (g.SettlementID == nil || *g.SettlementID != q.SettlementID),
+		q.FactionID != 0 && (g.FactionID == nil || *g.FactionID != q.FactionID),
+		q.Occupation != nil && g.Occupation != *q.Occupation,
+		q.State != nil && g.State != *q.State,
+		!q.Age.Has(float64(g.Age)),
+		!q.Wealth.Has(float64(g.Wealth)),
+		g.DiedTick < q.FromTick,
+		g.DiedTick > q.upperTick(),
+		q.Cursor != nil && !k.after(g, *q.Cursor):
+		return false
+	}
+	return true
+}
+
+// page merges graves found in the store with matching unsaved ones and cuts
+// the first page. found must hold every stored match up to one past a page.
+func (q GraveQuery) page(found, unsaved []engine.Grave, k keyset[engine.Grave]) ([]engine.Grave, *KeyCursor) {
+	seen := make(map[agents.AgentID]bool, len(found))
+	for _, g := range found {
+		seen[g.AgentID] = true
+	}
+	for _, g := range unsaved {
+		// The API's view of unsaved graves can trail a save by up to a
+		// sim-hour; those are in found already.
+		if q.matches(g, k) && !seen[g.AgentID] {
+			found = append(found, g)
+		}
+	}
+	return k.page(found, q.Limit)
+}
+
+// ── SQLite ──────────────────────────────────────────────────────────
+
+// graveRow is a graveyard row.
+type graveRow struct {
+	AgentID        uint64  `db:"agent_id"`
+	Name           string  `db:"name"`
+	Sex            uint8   `db:"sex"`
+	Age            uint16  `db:"age"`
+	BornTick       uint64  `db:"born_tick"`
+	DiedTick       uint64  `db:"died_tick"`
+	Cause          string  `db:"cause"`
+	Occupation     uint8   `db:"occupation"`
+	State          uint8   `db:"state"`
+	Coherence      float32 `db:"coherence"`
+	Wealth         uint64  `db:"wealth"`
+	SettlementID   *uint64 `db:"settlement_id"`
+	SettlementName string  `db:"settlement_name"`
+	FactionID      *uint64 `db:"faction_id"`
+	FactionName    string  `db:"faction_name"`
+	MemoriesJSON   string  `db:"memories_json"`
+}
+
+const graveColumns = `agent_id, name, sex, age, born_tick, died_tick, cause, occupation, state,
+	coherence, wealth, settlement_id, settlement_name, faction_id, faction_name, memories_json`
+
+func (r graveRow) grave() (engine.Grave, error) {
+	g := engine.Grave{
+		AgentID: agents.AgentID(r.AgentID), Name: r.Name, Sex: agents.Sex(r.Sex), Age: r.Age,
+		BornTick: r.BornTick, DiedTick: r.DiedTick, Cause: r.Cause,
+		Occupation: agents.Occupation(r.Occupation), State: agents.StateOfBeing(r.State),
+		Coherence: r.Coherence, Wealth: r.Wealth,
+		SettlementID: r.SettlementID, SettlementName: r.SettlementName,
+		FactionID: r.FactionID, FactionName: r.FactionName,
+	}
+	if r.MemoriesJSON != "" {
+		if err := json.Unmarshal([]byte(r.MemoriesJSON), &g.Memories); err != nil {
+			return g, fmt.Errorf("grave %d memories: %w", r.AgentID, err)
+		}
+	}
+	return g, nil
+}
+
+// SaveGraves writes graves to the graveyard. An agent already buried keeps
+// their first grave.
+func (db *DB) SaveGraves(graves []engine.Grave) error {
+	if len(graves) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, g := range graves {
+		mems := ""
+		if len(g.Memories) > 0 {
+			b, err := json.Marshal(g.Memories)
+			if err != nil {
+				return fmt.Errorf("marshal grave %d memories: %w", g.AgentID, err)
+			}
+			mems = string(b)
+		}
+		if _, err := tx.Exec(`INSERT INTO graveyard (`+graveColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(agent_id) DO NOTHING`,
+			g.AgentID, g.Name, g.Sex, g.Age, g.BornTick, g.DiedTick, g.Cause, g.Occupation, g.State,
+			g.Coherence, g.Wealth, g.SettlementID, g.SettlementName, g.FactionID, g.FactionName, mems,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadGrave returns agent id's grave, or nil if they are not buried.
+func (db *DB) LoadGrave(id uint64) (*engine.Grave, error) {
+	var rows []graveRow
+	if err := db.conn.Select(&rows, `SELECT `+graveColumns+` FROM graveyard WHERE agent_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("load grave: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	g, err := rows[0].grave()
+	return &g, err
+}
+
+// QueryGraves returns one page of graves matching q, in q's sort order, from
+// unsaved (the tick loop's graves not yet written) and the graveyard table.
+// The returned cursor fetches the next page; it is nil on the last one.
+func (db *DB) QueryGraves(q GraveQuery, unsaved []engine.Grave) ([]engine.Grave, *KeyCursor, error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	k, err := q.keyset()
+	if err != nil {
+		return nil, nil, err
+	}
+	conds := []string{"died_tick >= ?", "died_tick <= ?"}
+	args := []any{q.FromTick, q.upperTick()}
+	for _, f := range []struct {
+		cond string
+		arg  any
+		ok   bool
+	}{
+		{"cause = ?", q.Cause, q.Cause != ""},
+		{"settlement_id = ?", q.SettlementID, q.SettlementID != 0},
+		{"faction_id = ?", q.FactionID, q.FactionID != 0},
+		{"occupation = ?", q.Occupation, q.Occupation != nil},
+		{"state = ?", q.State, q.State != nil},
+	} {
+		if f.ok {
+			conds, args = append(conds, f.cond), append(args, f.arg)
+		}
+	}
+	conds, args = q.Age.where("age", conds, args)
+	conds, args = q.Wealth.where("wealth", conds, args)
+	if c := q.Cursor; c != nil {
+		cond, cargs := k.where(*c)
+		conds, args = append(conds, cond), append(args, cargs...)
+	}
+	var rows []graveRow
+	if err := db.conn.Select(&rows, `SELECT `+graveColumns+` FROM graveyard WHERE `+
+		strings.Join(conds, " AND ")+` `+k.orderBy()+` LIMIT ?`,
+		append(args, q.Limit+1)...); err != nil {
+		return nil, nil, fmt.Errorf("query graveyard: %w", err)
+	}
+	found := make([]engine.Grave, 0, len(rows))
+	for _, r := range rows {
+		g, err := r.grave()
+		if err != nil {
+			return nil, nil, err
+		}
+		found = append(found, g)
+	}
+	page, next := q.page(found, unsaved, k)
+	return page, next, nil
+}
+
+// ── In memory ───────────────────────────────────────────────────────
+
+func (m *MemStore) SaveGraves(graves []engine.Grave) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveGraves(graves)
+	return nil
+}
+
+func (m *MemStore) saveGraves(graves []engine.Grave) {
+	for _, g := range graves {
+		if _, ok := m.graves[g.AgentID]; !ok {
+			g.Memories = slices.Clone(g.Memories)
+			m.graves[g.AgentID] = g
+		}
+	}
+}
+
+func (m *MemStore) LoadGrave(id uint64) (*engine.Grave, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.graves[agents.AgentID(id)]
+	if !ok {
+		return nil, nil
+	}
+	g.Memories = slices.Clone(g.Memories)
+	return &g, nil
+}
+
+func (m *MemStore) QueryGraves(q GraveQuery, unsaved []engine.Grave) ([]engine.Grave, *KeyCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	k, err := q.keyset()
+	if err != nil {
+		return nil, nil, err
+	}
+	var found []engine.Grave
+	for _, g := range m.graves {
+		if q.matches(g, k) {
+			g.Memories = slices.Clone(g.Memories)
+			found = append(found, g)
+		}
+	}
+	page, next := q.page(found, unsaved, k)
+	return page, next, nil
+}
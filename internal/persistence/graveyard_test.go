@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// graves returns n graves, one death per 10 ticks, alternating causes,
+// settlements 1 and 2, four occupations and five ages.
+func graves(n int) []engine.Grave {
+	var out []engine.Grave
+	for i := range n {
+		sett := uint64(i%2 + 1)
+		out = append(out, engine.Grave{
+			AgentID: agents.AgentID(100 + i), Name: fmt.Sprintf("g%d", i), DiedTick: uint64(10 * i),
+			Cause: []string{"age", "illness", "battle"}[i%3], SettlementID: &sett, Wealth: uint64(i),
+			Occupation: agents.Occupation(i % 4), Age: uint16(i % 5),
+			Memories: []agents.Memory{{Tick: 1, Content: "saw the sea", Importance: 0.9}},
+		})
+	}
+	return out
+}
+
+// TestGraveyard saves most graves, keeps the newest unsaved, and pages
+// filtered queries across both in each store.
+func TestGraveyard(t *testing.T) {
+	for name, s := range stores(t) {
+		all := graves(20)
+		sim := &engine.Simulation{UnsavedGraves: slices.Clone(all[:16])}
+		if err := s.SaveHistory(sim); err != nil {
+			t.Fatal(err)
+		}
+		if sim.UnsavedGraves != nil {
+			t.Errorf("%s: history save left %d graves", name, len(sim.UnsavedGraves))
+		}
+		// The view can still hold graves the save already wrote.
+		unsaved := all[14:]
+		miner := agents.OccupationMiner
+		one, five, nine := 1.0, 5.0, 9.0
+
+		g, err := s.LoadGrave(105)
+		if err != nil || g == nil || g.Name != "g5" || g.Cause != "battle" || len(g.Memories) != 1 {
+			t.Errorf("%s: grave 105 = %+v, %v", name, g, err)
+		}
+		if g, _ := s.LoadGrave(119); g != nil {
+			t.Errorf("%s: unsaved grave loaded from the store", name)
+		}
+
+		for _, tc := range []struct {
+			q    GraveQuery
+			want []int
+		}{
+			{GraveQuery{Limit: 100}, []int{19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+			{GraveQuery{Cause: "illness", Limit: 100}, []int{19, 16, 13, 10, 7, 4, 1}},
+			{GraveQuery{SettlementID: 2, FromTick: 50, ToTick: 150, Limit: 100}, []int{15, 13, 11, 9, 7, 5}},
+			{GraveQuery{Cause: "age", SettlementID: 1, Limit: 2}, []int{18, 12, 6, 0}},
+			{GraveQuery{Occupation: &miner, Limit: 2}, []int{17, 13, 9, 5, 1}},
+			{GraveQuery{Sort: "wealth", Asc: true, Wealth: Range{Min: &five, Max: &nine}, Limit: 2}, []int{5, 6, 7, 8, 9}},
+			{GraveQuery{Sort: "age", Age: Range{Max: &one}, Limit: 3}, []int{16, 11, 6, 1, 15, 10, 5, 0}},
+			// Every grave ties on sex; the ID keeps pages apart.
+			{GraveQuery{Sort: "sex", Limit: 3}, []int{19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		} {
+			var got []int
+			q := tc.q
+			for pages := 0; ; pages++ {
+				if pages > 20 {
+					t.Fatal("cursor never ended")
+				}
+				page, next, err := s.QueryGraves(q, unsaved)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page) > q.Limit {
+					t.Fatalf("%s: page of %d, limit %d", name, len(page), q.Limit)
+				}
+				for _, g := range page {
+					got = append(got, int(g.AgentID)-100)
+				}
+				if next == nil {
+					break
+				}
+				c, err := ParseKeyCursor(next.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				q.Cursor = &c
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("%s: %+v = %v, want %v", name, tc.q, got, tc.want)
+			}
+		}
+		if _, _, err := s.QueryGraves(GraveQuery{Sort: "name"}, unsaved); !errors.Is(err, ErrBadSort) {
+			t.Errorf("%s: sort by name = %v, want ErrBadSort", name, err)
+		}
+	}
+}
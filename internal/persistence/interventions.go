@@ -0,0 +1,62 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// SaveInterventions upserts ledger records. The API writes through on every
+// submit and cancel, the tick loop on every scheduled application, and
+// SaveWorldState covers whatever is in memory.
+func (db *DB) SaveInterventions(records []*engine.InterventionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, rec := range records {
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("marshal intervention %d: %w", rec.ID, err)
+		}
+		_, err = tx.Exec(
+			`INSERT OR REPLACE INTO interventions (id, status, actor, type, submitted_tick, apply_at, record_json)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			rec.ID, rec.Status, rec.Actor, rec.Request.Type, rec.SubmittedTick, rec.ApplyAt, string(b),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadInterventions returns the ledger records to keep in memory on startup:
+// every pending one plus the most recent settled ones, oldest first.
+func (db *DB) LoadInterventions(settledLimit int) ([]*engine.InterventionRecord, error) {
+	var rows []string
+	err := db.conn.Select(&rows,
+		`SELECT record_json FROM interventions
+		 WHERE status = ? OR id IN (SELECT id FROM interventions WHERE status != ? ORDER BY id DESC LIMIT ?)
+		 ORDER BY id`,
+		engine.InterventionPending, engine.InterventionPending, settledLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*engine.InterventionRecord, 0, len(rows))
+	for _, row := range rows {
+		var rec engine.InterventionRecord
+		if err := json.Unmarshal([]byte(row), &rec); err != nil {
+			return nil, fmt.Errorf("decode intervention: %w", err)
+		}
+		records = append(records, &rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ── Sorted pages ────────────────────────────────────────────────────
+//
+// The graveyard and the settlement archive page in the order of any numeric
+// column. Rows are ordered by that column and then by columns that together
+// identify a row, all ascending or all descending, so ties keep a stable
+// order and a KeyCursor — the last row's values — says exactly where the
+// next page starts.
+
+// ErrBadSort is returned for a sort field a query does not support.
+var ErrBadSort = errors.New("unsupported sort field")
+
+// Range bounds a numeric column, inclusive. A nil end is open.
+type Range struct {
+	Min, Max *float64
+}
+
+// Has reports whether v is in r.
+func (r Range) Has(v float64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+// where appends r's conditions on col to conds and args.
+func (r Range) where(col string, conds []string, args []any) ([]string, []any) {
+	if r.Min != nil {
+		conds, args = append(conds, col+" >= ?"), append(args, *r.Min)
+	}
+	if r.Max != nil {
+		conds, args = append(conds, col+" <= ?"), append(args, *r.Max)
+	}
+	return conds, args
+}
+
+// KeyCursor is a position in a sorted stream: the next page starts with the
+// rows after the one whose sort and tie-break columns hold Keys.
+type KeyCursor struct {
+	Keys []Key
+}
+
+// Key is one column's value in a KeyCursor. Integer columns keep their exact
+// value: as float64s, IDs and ticks above 2^53 would round into each other
+// and a page could repeat or skip the rows between them.
+type Key struct {
+	kind keyKind
+	bits uint64 // the value; an int64's two's complement, a float64's bits
+}
+
+type keyKind uint8
+
+const (
+	keyUint keyKind = iota
+	keyInt
+	keyFloat
+)
+
+// The three kinds of key, one per kind of numeric column.
+func UintKey(v uint64) Key   { return Key{keyUint, v} }
+func IntKey(v int64) Key     { return Key{keyInt, uint64(v)} }
+func FloatKey(v float64) Key { return Key{keyFloat, math.Float64bits(v)} }
+
+// Compare orders a and b, keys of the same column.
+func (a Key) Compare(b Key) int {
+	if a.kind != b.kind {
+		return cmp.Compare(a.kind, b.kind)
+	}
+	switch a.kind {
+	case keyInt:
+		return cmp.Compare(int64(a.bits), int64(b.bits))
+	case keyFloat:
+		return cmp.Compare(math.Float64frombits(a.bits), math.Float64frombits(b.bits))
+	}
+	return cmp.Compare(a.bits, b.bits)
+}
+
+// arg is k as an SQL argument. SQLite integers are signed, so an unsigned
+// key past them is passed as a float, still above every stored value.
+func (k Key) arg() any {
+	switch {
+	case k.kind == keyInt:
+		return int64(k.bits)
+	case k.kind == keyFloat:
+		return math.Float64frombits(k.bits)
+	case k.bits > math.MaxInt64:
+		return float64(k.bits)
+	}
+	return int64(k.bits)
+}
+
+// The kinds' prefixes in a cursor; unsigned keys have none.
+const intPrefix, floatPrefix = "i", "f"
+
+func (k Key) String() string {
+	switch k.kind {
+	case keyInt:
+		return intPrefix + strconv.FormatInt(int64(k.bits), 10)
+	case keyFloat:
+		return floatPrefix + strconv.FormatFloat(math.Float64frombits(k.bits), 'g', -1, 64)
+	}
+	return strconv.FormatUint(k.bits, 10)
+}
+
+func parseKey(s string) (Key, error) {
+	if v, ok := strings.CutPrefix(s, intPrefix); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		return IntKey(n), err
+	}
+	if v, ok := strings.CutPrefix(s, floatPrefix); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		return FloatKey(f), err
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return UintKey(n), err
+}
+
+// String encodes the cursor for use in a URL.
+func (c KeyCursor) String() string {
+	parts := make([]string, len(c.Keys))
+	for i, k := range c.Keys {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, "_")
+}
+
+// ParseKeyCursor decodes a cursor made by KeyCursor.String.
+func ParseKeyCursor(s string) (KeyCursor, error) {
+	var c KeyCursor
+	for part := range strings.SplitSeq(s, "_") {
+		k, err := parseKey(part)
+		if err != nil {
+			return KeyCursor{}, ErrBadCursor
+		}
+		c.Keys = append(c.Keys, k)
+	}
+	return c, nil
+}
+
+// sortKey is a column rows of T can be sorted by, and its value in a row.
+type sortKey[T any] struct {
+	col string
+	of  func(T) Key
+}
+
+// keyset is one sort order over rows of T: by a column, then by ties,
+// columns that identify a row.
+type keyset[T any] struct {
+	keys []sortKey[T]
+	asc  bool
+}
+
+// newKeyset sorts by field of sorts, or def when field is empty, and checks
+// that cursor, if any, is a position in that order.
+func newKeyset[T any](sorts map[string]sortKey[T], field, def string, asc bool, cursor *KeyCursor, ties ...sortKey[T]) (keyset[T], error) {
+	if field == "" {
+		field = def
+	}
+	key, ok := sorts[field]
+	if !ok {
+		return keyset[T]{}, fmt.Errorf("%w %q", ErrBadSort, field)
+	}
+	k := keyset[T]{keys: append([]sortKey[T]{key}, ties...), asc: asc}
+	if cursor != nil && len(cursor.Keys) != len(k.keys) {
+		return k, ErrBadCursor
+	}
+	return k, nil
+}
+
+// sortFields lists the fields of sorts, alphabetically.
+func sortFields[T any](sorts map[string]sortKey[T]) []string {
+	return slices.Sorted(maps.Keys(sorts))
+}
+
+// cursor is the position of row.
+func (k keyset[T]) cursor(row T) KeyCursor {
+	c := KeyCursor{Keys: make([]Key, len(k.keys))}
+	for i, key := range k.keys {
+		c.Keys[i] = key.of(row)
+	}
+	return c
+}
+
+// compare orders positions a and b as the pages list them.
+func (k keyset[T]) compare(a, b KeyCursor) int {
+	c := slices.CompareFunc(a.Keys, b.Keys, Key.Compare)
+	if !k.asc {
+		return -c
+	}
+	return c
+}
+
+// after reports whether row comes after c.
+func (k keyset[T]) after(row T, c KeyCursor) bool {
+	return k.compare(k.cursor(row), c) > 0
+}
+
+// where is the SQL condition for the rows after c.
+func (k keyset[T]) where(c KeyCursor) (string, []any) {
+	op := "<"
+	if k.asc {
+		op = ">"
+	}
+	// (a > ? OR (a = ? AND (b > ? OR (b = ? AND c > ?))))
+	i := len(k.keys) - 1
+	cond, args := fmt.Sprintf("%s %s ?", k.keys[i].col, op), []any{c.Keys[i].arg()}
+	for i--; i >= 0; i-- {
+		cond = fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s))", k.keys[i].col, op, cond)
+		args = append([]any{c.Keys[i].arg(), c.Keys[i].arg()}, args...)
+	}
+	return cond, args
+}
+
+func (k keyset[T]) orderBy() string {
+	dir := " DESC"
+	if k.asc {
+		dir = " ASC"
+	}
+	cols := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		cols[i] = key.col + dir
+	}
+	return "ORDER BY " + strings.Join(cols, ", ")
+}
+
+// page sorts found, cuts the first limit rows and returns the cursor after
+// them, nil if there are no more.
+func (k keyset[T]) page(found []T, limit int) ([]T, *KeyCursor) {
+	slices.SortFunc(found, func(a, b T) int { return k.compare(k.cursor(a), k.cursor(b)) })
+	if len(found) <= limit {
+		return found, nil
+	}
+	c := k.cursor(found[limit-1])
+	return found[:limit], &c
+}
@@ -0,0 +1,540 @@
+package persistence
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// ── Lineage ─────────────────────────────────────────────────────────
+//
+// The family tree: everyone who has had a child or a spouse, with the
+// parent and spouse edges between them. It is written from the engine's
+// lineage records in SaveHistory and never pruned, so it outlives the agents
+// compactDeadAgents drops. Parent edges point from parent to child; spouse
+// edges from the lower ID to the higher.
+//
+// Family and Dynasties read it through two Store methods (LineageKin for a
+// walk one generation at a time, LoadLineage for everything) with the
+// records the tick loop has not saved yet laid over the top, the way
+// QueryEvents reads unsaved events.
+
+// Lineage edge kinds.
+const (
+	EdgeParent = "parent"
+	EdgeSpouse = "spouse"
+)
+
+// LineagePerson is someone in the family tree.
+type LineagePerson struct {
+	ID         uint64  `json:"id" db:"agent_id"`
+	Name       string  `json:"name" db:"name"`
+	BornTick   uint64  `json:"born_tick" db:"born_tick"`
+	DiedTick   *uint64 `json:"died_tick,omitempty" db:"died_tick"`
+	DeathCause string  `json:"death_cause,omitempty" db:"death_cause"`
+	Alive      bool    `json:"alive" db:"-"`
+}
+
+// LineageEdge is a parent→child or spouse edge.
+type LineageEdge struct {
+	Kind string `json:"kind" db:"kind"`
+	From uint64 `json:"from" db:"from_id"`
+	To   uint64 `json:"to" db:"to_id"`
+	Tick uint64 `json:"tick" db:"tick"`
+}
+
+// lineageWriter is what applying a lineage record writes to.
+type lineageWriter struct {
+	person func(p LineagePerson) error // adds p unless already known
+	edge   func(e LineageEdge) error   // adds e unless already known
+	died   func(id, tick uint64, cause string) error
+}
+
+// apply writes r: the people it names, the edges between them, a death.
+func (w lineageWriter) apply(r engine.LineageRecord) error {
+	person := func(k engine.Kin) error {
+		return w.person(LineagePerson{ID: uint64(k.ID), Name: k.Name, BornTick: k.BornTick})
+	}
+	switch r.Kind {
+	case engine.LineageBirth, engine.LineageMarriage:
+		if err := person(r.Person); err != nil {
+			return err
+		}
+		for _, k := range r.Kin {
+			if err := person(k); err != nil {
+				return err
+			}
+			e := LineageEdge{Kind: EdgeParent, From: uint64(k.ID), To: uint64(r.Person.ID), Tick: r.Tick}
+			if r.Kind == engine.LineageMarriage {
+				e = LineageEdge{Kind: EdgeSpouse, From: min(e.From, e.To), To: max(e.From, e.To), Tick: r.Tick}
+			}
+			if err := w.edge(e); err != nil {
+				return err
+			}
+		}
+	case engine.LineageDeath:
+		return w.died(uint64(r.Person.ID), r.Tick, r.Cause)
+	}
+	return nil
+}
+
+// ── SQLite ──────────────────────────────────────────────────────────
+
+// SaveLineage writes lineage records to the family tree.
+func (db *DB) SaveLineage(records []engine.LineageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	w := lineageWriter{
+		person: func(p LineagePerson) error {
+			_, err := tx.Exec(`INSERT INTO lineage_people (agent_id, name, born_tick) VALUES (?, ?, ?)
+				ON CONFLICT(agent_id) DO NOTHING`, p.ID, p.Name, p.BornTick)
+			return err
+		},
+		edge: func(e LineageEdge) error {
+			_, err := tx.Exec(`INSERT INTO lineage_edges (kind, from_id, to_id, tick) VALUES (?, ?, ?, ?)
+				ON CONFLICT DO NOTHING`, e.Kind, e.From, e.To, e.Tick)
+			return err
+		},
+		died: func(id, tick uint64, cause string) error {
+			_, err := tx.Exec(`UPDATE lineage_people SET died_tick = ?, death_cause = ?
+				WHERE agent_id = ? AND died_tick IS NULL`, tick, cause, id)
+			return err
+		},
+	}
+	for _, r := range records {
+		if err := w.apply(r); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LineageKin returns the edges touching ids and everyone at either end.
+func (db *DB) LineageKin(ids []uint64) ([]LineagePerson, []LineageEdge, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	query, args, err := sqlx.In(`SELECT kind, from_id, to_id, tick FROM lineage_edges
+		WHERE from_id IN (?) OR to_id IN (?)`, ids, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	var edges []LineageEdge
+	if err := db.conn.Select(&edges, query, args...); err != nil {
+		return nil, nil, fmt.Errorf("load lineage edges: %w", err)
+	}
+	want := slices.Clone(ids)
+	for _, e := range edges {
+		want = append(want, e.From, e.To)
+	}
+	slices.Sort(want)
+	query, args, err = sqlx.In(`SELECT agent_id, name, born_tick, died_tick, death_cause
+		FROM lineage_people WHERE agent_id IN (?)`, slices.Compact(want))
+	if err != nil {
+		return nil, nil, err
+	}
+	var people []LineagePerson
+	if err := db.conn.Select(&people, query, args...); err != nil {
+		return nil, nil, fmt.Errorf("load lineage people: %w", err)
+	}
+	return people, edges, nil
+}
+
+// LoadLineage returns the whole family tree.
+func (db *DB) LoadLineage() ([]LineagePerson, []LineageEdge, error) {
+	var people []LineagePerson
+	if err := db.conn.Select(&people, `SELECT agent_id, name, born_tick, died_tick, death_cause
+		FROM lineage_people`); err != nil {
+		return nil, nil, fmt.Errorf("load lineage people: %w", err)
+	}
+	var edges []LineageEdge
+	if err := db.conn.Select(&edges, `SELECT kind, from_id, to_id, tick FROM lineage_edges`); err != nil {
+		return nil, nil, fmt.Errorf("load lineage edges: %w", err)
+	}
+	return people, edges, nil
+}
+
+// ── In memory ───────────────────────────────────────────────────────
+
+// lineageTree is a family tree held in memory: a MemStore's, or the tick
+// loop's unsaved records.
+type lineageTree struct {
+	people map[uint64]LineagePerson
+	edges  map[LineageEdge]uint64 // keyed with Tick zeroed, to the first tick recorded
+
+	// Deaths of people the tree does not hold, kept only for unsaved records:
+	// the person is in the saved tree.
+	deaths map[uint64]LineagePerson
+}
+
+func newLineageTree() *lineageTree {
+	return &lineageTree{people: map[uint64]LineagePerson{}, edges: map[LineageEdge]uint64{}}
+}
+
+func (t *lineageTree) writer() lineageWriter {
+	return lineageWriter{
+		person: func(p LineagePerson) error {
+			if _, ok := t.people[p.ID]; !ok {
+				t.people[p.ID] = p
+			}
+			return nil
+		},
+		edge: func(e LineageEdge) error {
+			key := LineageEdge{Kind: e.Kind, From: e.From, To: e.To}
+			if _, ok := t.edges[key]; !ok {
+				t.edges[key] = e.Tick
+			}
+			return nil
+		},
+		died: func(id, tick uint64, cause string) error {
+			p, ok := t.people[id]
+			switch {
+			case ok && p.DiedTick == nil:
+				p.DiedTick, p.DeathCause = &tick, cause
+				t.people[id] = p
+			case !ok && t.deaths != nil:
+				t.deaths[id] = LineagePerson{ID: id, DiedTick: &tick, DeathCause: cause}
+			}
+			return nil
+		},
+	}
+}
+
+func (t *lineageTree) add(records []engine.LineageRecord) {
+	w := t.writer()
+	for _, r := range records {
+		w.apply(r)
+	}
+}
+
+// kin is LineageKin over t.
+func (t *lineageTree) kin(ids []uint64) ([]LineagePerson, []LineageEdge) {
+	want := map[uint64]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	var edges []LineageEdge
+	for e, tick := range t.edges {
+		if want[e.From] || want[e.To] {
+			e.Tick = tick
+			edges = append(edges, e)
+		}
+	}
+	for _, e := range edges {
+		want[e.From], want[e.To] = true, true
+	}
+	var people []LineagePerson
+	for id := range want {
+		if p, ok := t.people[id]; ok {
+			people = append(people, p)
+		}
+	}
+	return people, edges
+}
+
+func (t *lineageTree) all() ([]LineagePerson, []LineageEdge) {
+	people := make([]LineagePerson, 0, len(t.people))
+	for _, p := range t.people {
+		people = append(people, p)
+	}
+	edges := make([]LineageEdge, 0, len(t.edges))
+	for e, tick := range t.edges {
+		e.Tick = tick
+		edges = append(edges, e)
+	}
+	return people, edges
+}
+
+func (m *MemStore) SaveLineage(records []engine.LineageRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lineage.add(records)
+	return nil
+}
+
+func (m *MemStore) LineageKin(ids []uint64) ([]LineagePerson, []LineageEdge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	people, edges := m.lineage.kin(ids)
+	return people, edges, nil
+}
+
+func (m *MemStore) LoadLineage() ([]LineagePerson, []LineageEdge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	people, edges := m.lineage.all()
+	return people, edges, nil
+}
+
+// ── Reading the tree ────────────────────────────────────────────────
+
+// lineageView is a store's family tree with unsaved records on top.
+type lineageView struct {
+	store   Store
+	unsaved *lineageTree
+	alive   func(id uint64) bool
+}
+
+func newLineageView(s Store, unsaved []engine.LineageRecord, alive func(id uint64) bool) *lineageView {
+	t := newLineageTree()
+	t.deaths = map[uint64]LineagePerson{}
+	t.add(unsaved)
+	return &lineageView{store: s, unsaved: t, alive: alive}
+}
+
+// merge lays the unsaved tree's people and edges over saved ones.
+func (v *lineageView) merge(people []LineagePerson, edges []LineageEdge,
+	morePeople []LineagePerson, moreEdges []LineageEdge) (map[uint64]LineagePerson, []LineageEdge) {
+	byID := make(map[uint64]LineagePerson, len(people)+len(morePeople))
+	for _, p := range people {
+		byID[p.ID] = p
+	}
+	for _, p := range morePeople {
+		if saved, ok := byID[p.ID]; ok {
+			if saved.DiedTick == nil && p.DiedTick != nil {
+				saved.DiedTick, saved.DeathCause = p.DiedTick, p.DeathCause
+			}
+			p = saved
+		}
+		byID[p.ID] = p
+	}
+	for id, p := range byID {
+		// Died since the save.
+		if d, ok := v.unsaved.deaths[id]; ok && p.DiedTick == nil {
+			p.DiedTick, p.DeathCause = d.DiedTick, d.DeathCause
+		}
+		if v.alive != nil {
+			p.Alive = v.alive(id)
+		} else {
+			p.Alive = p.DiedTick == nil
+		}
+		byID[id] = p
+	}
+	seen := make(map[LineageEdge]bool, len(edges)+len(moreEdges))
+	var out []LineageEdge
+	for _, e := range slices.Concat(edges, moreEdges) {
+		key := LineageEdge{Kind: e.Kind, From: e.From, To: e.To}
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, e)
+		}
+	}
+	return byID, out
+}
+
+func (v *lineageView) kin(ids []uint64) (map[uint64]LineagePerson, []LineageEdge, error) {
+	people, edges, err := v.store.LineageKin(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	morePeople, moreEdges := v.unsaved.kin(ids)
+	byID, all := v.merge(people, edges, morePeople, moreEdges)
+	return byID, all, nil
+}
+
+// FamilyMember is one person in a Family, with their own parents (walking
+// up) or children (walking down).
+type FamilyMember struct {
+	LineagePerson
+	Parents  []*FamilyMember `json:"parents,omitempty"`
+	Children []*FamilyMember `json:"children,omitempty"`
+}
+
+// Family is an agent's family tree to a number of generations either way.
+type Family struct {
+	Agent       LineagePerson   `json:"agent"`
+	Generations int             `json:"generations"`
+	Spouses     []LineagePerson `json:"spouses"`
+	Ancestors   []*FamilyMember `json:"ancestors"`   // the agent's parents, each with theirs
+	Descendants []*FamilyMember `json:"descendants"` // the agent's children, each with theirs
+}
+
+// LoadFamily returns agent id's spouses, ancestors and descendants to
+// generations generations, from s and the tick loop's unsaved records. alive
+// says whether someone is still living; nil trusts the recorded deaths. It
+// returns nil if the tree does not know the agent.
+func LoadFamily(s Store, unsaved []engine.LineageRecord, alive func(id uint64) bool,
+	id uint64, generations int) (*Family, error) {
+	v := newLineageView(s, unsaved, alive)
+	people, edges, err := v.kin([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+	self, ok := people[id]
+	if !ok {
+		return nil, nil
+	}
+	fam := &Family{Agent: self, Generations: generations, Spouses: []LineagePerson{},
+		Ancestors: []*FamilyMember{}, Descendants: []*FamilyMember{}}
+	for _, e := range edges {
+		if e.Kind != EdgeSpouse {
+			continue
+		}
+		other := e.From
+		if other == id {
+			other = e.To
+		}
+		if p, ok := people[other]; ok {
+			fam.Spouses = append(fam.Spouses, p)
+		}
+	}
+	slices.SortFunc(fam.Spouses, func(a, b LineagePerson) int { return cmp.Compare(a.ID, b.ID) })
+
+	// Walk one generation per round, up and down together.
+	type step struct {
+		node *FamilyMember
+		up   bool
+	}
+	root := &FamilyMember{LineagePerson: self}
+	frontier := []step{{root, true}, {root, false}}
+	for gen := 0; gen < generations && len(frontier) > 0; gen++ {
+		ids := make([]uint64, 0, len(frontier))
+		for _, st := range frontier {
+			ids = append(ids, st.node.ID)
+		}
+		if gen > 0 {
+			if people, edges, err = v.kin(ids); err != nil {
+				return nil, err
+			}
+		}
+		var next []step
+		for _, st := range frontier {
+			for _, e := range edges {
+				if e.Kind != EdgeParent {
+					continue
+				}
+				var relative uint64
+				switch {
+				case st.up && e.To == st.node.ID:
+					relative = e.From
+				case !st.up && e.From == st.node.ID:
+					relative = e.To
+				default:
+					continue
+				}
+				p, ok := people[relative]
+				if !ok {
+					continue
+				}
+				m := &FamilyMember{LineagePerson: p}
+				if st.up {
+					st.node.Parents = append(st.node.Parents, m)
+				} else {
+					st.node.Children = append(st.node.Children, m)
+				}
+				next = append(next, step{m, st.up})
+			}
+			byID := func(a, b *FamilyMember) int { return cmp.Compare(a.ID, b.ID) }
+			slices.SortFunc(st.node.Parents, byID)
+			slices.SortFunc(st.node.Children, byID)
+		}
+		frontier = next
+	}
+	if root.Parents != nil {
+		fam.Ancestors = root.Parents
+	}
+	if root.Children != nil {
+		fam.Descendants = root.Children
+	}
+	return fam, nil
+}
+
+// Dynasty is a founder and everyone descended from them. A founder is
+// someone with children but no recorded parents.
+type Dynasty struct {
+	Founder     LineagePerson `json:"founder"`
+	Members     int           `json:"members"` // the founder and every descendant
+	Living      int           `json:"living"`
+	Generations int           `json:"generations"` // the founder's is the first
+	SinceTick   uint64        `json:"since_tick"`  // the first birth in the line
+	UntilTick   uint64        `json:"until_tick"`  // now, or the last death once the line has died out
+}
+
+// Span is how long the line has lasted, in ticks.
+func (d Dynasty) Span() uint64 { return d.UntilTick - d.SinceTick }
+
+// DynastyStats ranks the dynasties two ways.
+type DynastyStats struct {
+	Largest []Dynasty `json:"largest"` // most living members
+	Longest []Dynasty `json:"longest"` // longest span
+}
+
+// Dynasties ranks the dynasties in s and the unsaved records as of tick now,
+// keeping the top limit of each ranking. alive is as for LoadFamily.
+func Dynasties(s Store, unsaved []engine.LineageRecord, alive func(id uint64) bool,
+	now uint64, limit int) (*DynastyStats, error) {
+	v := newLineageView(s, unsaved, alive)
+	people, edges, err := s.LoadLineage()
+	if err != nil {
+		return nil, err
+	}
+	morePeople, moreEdges := v.unsaved.all()
+	byID, edges := v.merge(people, edges, morePeople, moreEdges)
+
+	children := map[uint64][]LineageEdge{}
+	hasParent := map[uint64]bool{}
+	for _, e := range edges {
+		if e.Kind == EdgeParent {
+			children[e.From] = append(children[e.From], e)
+			hasParent[e.To] = true
+		}
+	}
+
+	var all []Dynasty
+	for id, founder := range byID {
+		if hasParent[id] || len(children[id]) == 0 {
+			continue
+		}
+		d := Dynasty{Founder: founder, SinceTick: now}
+		lastDeath := uint64(0)
+		seen := map[uint64]bool{id: true}
+		level := []uint64{id}
+		for len(level) > 0 {
+			d.Generations++
+			var next []uint64
+			for _, pid := range level {
+				p := byID[pid]
+				d.Members++
+				if p.Alive {
+					d.Living++
+				} else if p.DiedTick != nil {
+					lastDeath = max(lastDeath, *p.DiedTick)
+				}
+				for _, e := range children[pid] {
+					d.SinceTick = min(d.SinceTick, e.Tick)
+					if !seen[e.To] {
+						seen[e.To] = true
+						next = append(next, e.To)
+					}
+				}
+			}
+			level = next
+		}
+		d.UntilTick = now
+		if d.Living == 0 {
+			d.UntilTick = max(lastDeath, d.SinceTick)
+		}
+		all = append(all, d)
+	}
+
+	stats := &DynastyStats{}
+	byFounder := func(a, b Dynasty) int { return cmp.Compare(a.Founder.ID, b.Founder.ID) }
+	slices.SortFunc(all, func(a, b Dynasty) int {
+		return cmp.Or(cmp.Compare(b.Living, a.Living), cmp.Compare(b.Members, a.Members), byFounder(a, b))
+	})
+	stats.Largest = slices.Clone(all[:min(limit, len(all))])
+	slices.SortFunc(all, func(a, b Dynasty) int {
+		return cmp.Or(cmp.Compare(b.Span(), a.Span()), cmp.Compare(b.Generations, a.Generations), byFounder(a, b))
+	})
+	stats.Longest = slices.Clone(all[:min(limit, len(all))])
+	return stats, nil
+}
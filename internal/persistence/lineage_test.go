@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// threeGenerations records Ada and Bram marrying, their children Cora and
+// Dell, Cora's marriage to Eli and their son Finn, and Bram's death. A
+// separate founder, Gus, has one child, Hal, and both are dead.
+func threeGenerations() (saved, unsaved []engine.LineageRecord) {
+	kin := func(id agents.AgentID, name string, born uint64) engine.Kin {
+		return engine.Kin{ID: id, Name: name, BornTick: born}
+	}
+	ada, bram, cora, dell := kin(1, "Ada", 0), kin(2, "Bram", 0), kin(3, "Cora", 100), kin(4, "Dell", 150)
+	eli, finn, gus, hal := kin(5, "Eli", 0), kin(6, "Finn", 400), kin(7, "Gus", 0), kin(8, "Hal", 50)
+	saved = []engine.LineageRecord{
+		{Tick: 90, Kind: engine.LineageMarriage, Person: bram, Kin: []engine.Kin{ada}},
+		{Tick: 100, Kind: engine.LineageBirth, Person: cora, Kin: []engine.Kin{ada, bram}},
+		{Tick: 150, Kind: engine.LineageBirth, Person: dell, Kin: []engine.Kin{ada, bram}},
+		{Tick: 50, Kind: engine.LineageBirth, Person: hal, Kin: []engine.Kin{gus}},
+		{Tick: 200, Kind: engine.LineageDeath, Person: gus, Cause: "age"},
+		{Tick: 260, Kind: engine.LineageDeath, Person: hal, Cause: "illness"},
+		{Tick: 300, Kind: engine.LineageDeath, Person: eli, Cause: "age"}, // not in the tree yet
+		{Tick: 350, Kind: engine.LineageMarriage, Person: cora, Kin: []engine.Kin{eli}},
+	}
+	unsaved = []engine.LineageRecord{
+		{Tick: 400, Kind: engine.LineageBirth, Person: finn, Kin: []engine.Kin{cora, eli}},
+		{Tick: 410, Kind: engine.LineageDeath, Person: bram, Cause: "age"},
+		{Tick: 420, Kind: engine.LineageMarriage, Person: cora, Kin: []engine.Kin{eli}}, // again
+	}
+	return saved, unsaved
+}
+
+// TestLineage saves half the records, leaves the rest unsaved, and reads
+// families and dynasties back from both stores.
+func TestLineage(t *testing.T) {
+	results := map[string]string{}
+	for name, s := range stores(t) {
+		saved, unsaved := threeGenerations()
+		if err := s.SaveLineage(saved[:4]); err != nil {
+			t.Fatal(err)
+		}
+		sim := &engine.Simulation{UnsavedLineage: saved[4:]}
+		if err := s.SaveHistory(sim); err != nil {
+			t.Fatal(err)
+		}
+		if sim.UnsavedLineage != nil {
+			t.Errorf("%s: history save left %d lineage records", name, len(sim.UnsavedLineage))
+		}
+
+		fam, err := LoadFamily(s, unsaved, nil, 3, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fam.Ancestors) != 2 || fam.Ancestors[1].Name != "Bram" || fam.Ancestors[1].Alive {
+			t.Errorf("%s: Cora's parents %+v", name, fam.Ancestors)
+		}
+		if len(fam.Spouses) != 1 || fam.Spouses[0].Name != "Eli" || fam.Spouses[0].DiedTick != nil {
+			// Eli's death came before Eli was in the tree; it is not recorded.
+			t.Errorf("%s: Cora's spouses %+v", name, fam.Spouses)
+		}
+		if len(fam.Descendants) != 1 || fam.Descendants[0].Name != "Finn" {
+			t.Errorf("%s: Cora's children %+v", name, fam.Descendants)
+		}
+
+		grand, err := LoadFamily(s, unsaved, nil, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(grand.Descendants) != 2 || len(grand.Descendants[0].Children) != 1 {
+			t.Errorf("%s: Ada's descendants %+v", name, grand.Descendants)
+		}
+		if short, _ := LoadFamily(s, unsaved, nil, 1, 1); len(short.Descendants[0].Children) != 0 {
+			t.Errorf("%s: one generation went further", name)
+		}
+		if none, _ := LoadFamily(s, unsaved, nil, 99, 3); none != nil {
+			t.Errorf("%s: family for a stranger", name)
+		}
+
+		stats, err := Dynasties(s, unsaved, nil, 1000, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(stats)
+		results[name] = string(b)
+		if len(stats.Largest) != 4 { // Ada's, Bram's, Eli's and Gus's
+			t.Fatalf("%s: dynasties %s", name, b)
+		}
+		first := stats.Largest[0]
+		if first.Founder.Name != "Ada" || first.Living != 4 || first.Members != 4 || first.Generations != 3 {
+			t.Errorf("%s: largest dynasty %+v", name, first)
+		}
+		last := stats.Longest[3]
+		if last.Founder.Name != "Gus" || last.Living != 0 || last.SinceTick != 50 || last.UntilTick != 260 {
+			t.Errorf("%s: shortest dynasty %+v", name, last)
+		}
+	}
+	if results["sqlite"] != results["memory"] {
+		t.Errorf("stores disagree:\nsqlite: %s\nmemory: %s", results["sqlite"], results["memory"])
+	}
+}
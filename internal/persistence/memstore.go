@@ -0,0 +1,380 @@
+package persistence
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/checkpoint"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// MemStore is a Store that keeps everything in memory, for tests that save
+// and boot worlds or serve history without a database file. It keeps the
+// DB's semantics where callers can see them: only living agents are saved,
+// LoadAgents returns agents without memories or relationships, world_meta
+// holds the same keys, and history queries page the same way. Events are
+// never archived; all of them stay queryable as they are.
+//
+// Agents, settlements and factions are held in checkpoint encoding, so what
+// a caller loads never shares memory with what it saved.
+type MemStore struct {
+	mu sync.Mutex
+
+	agents        []byte // checkpoint-encoded living agents; nil until saved
+	agentCount    int
+	settlements   []byte
+	factions      []byte
+	factionCount  int
+	memories      map[agents.AgentID][]agents.Memory
+	relationships map[agents.AgentID][]agents.Relationship
+	meta          metaMap
+
+	events        []engine.Event // oldest first
+	interventions map[uint64][]byte
+	stats         map[uint64]StatsRow
+	settStats     map[[2]uint64]SettlementStatsRow // keyed by tick, settlement ID
+	prices        map[priceKey]engine.PriceBar
+	biographies   map[uint64]BiographyRow
+	lineage       *lineageTree
+	graves        map[agents.AgentID]engine.Grave
+	archives      map[[2]uint64]engine.SettlementArchive // keyed by settlement ID, abandoned tick
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		meta:          metaMap{},
+		interventions: map[uint64][]byte{},
+		stats:         map[uint64]StatsRow{},
+		settStats:     map[[2]uint64]SettlementStatsRow{},
+		prices:        map[priceKey]engine.PriceBar{},
+		biographies:   map[uint64]BiographyRow{},
+		lineage:       newLineageTree(),
+		graves:        map[agents.AgentID]engine.Grave{},
+		archives:      map[[2]uint64]engine.SettlementArchive{},
+	}
+}
+
+// ── World state ─────────────────────────────────────────────────────
+
+func (m *MemStore) SaveWorldState(sim *engine.Simulation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var alive []*agents.Agent
+	for _, a := range sim.Agents {
+		if a.Alive {
+			alive = append(alive, a)
+		}
+	}
+	m.agents, m.agentCount = encodeAgents(alive), len(alive)
+	m.settlements = encodeSettlements(sim.Settlements)
+	m.factions, m.factionCount = encodeFactions(sim.Factions), len(sim.Factions)
+	if err := m.saveHistory(sim); err != nil {
+		return err
+	}
+	return saveWorldMeta(sim, m.meta)
+}
+
+func (m *MemStore) SaveWorldStateFull(sim *engine.Simulation) error {
+	if err := m.SaveWorldState(sim); err != nil {
+		return err
+	}
+	return m.SaveSocial(sim.Agents)
+}
+
+func (m *MemStore) SaveSocial(agentList []*agents.Agent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.memories = make(map[agents.AgentID][]agents.Memory)
+	m.relationships = make(map[agents.AgentID][]agents.Relationship)
+	for _, a := range agentList {
+		if !a.Alive {
+			continue
+		}
+		if len(a.Memories) > 0 {
+			m.memories[a.ID] = slices.Clone(a.Memories)
+		}
+		if len(a.Relationships) > 0 {
+			m.relationships[a.ID] = slices.Clone(a.Relationships)
+		}
+	}
+	return nil
+}
+
+func (m *MemStore) SaveTuning(sim *engine.Simulation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return saveTuning(sim, m.meta)
+}
+
+func (m *MemStore) SaveMeta(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.meta.SaveMeta(key, value)
+}
+
+func (m *MemStore) GetMeta(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.meta.GetMeta(key)
+}
+
+func (m *MemStore) HasWorldState() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.agentCount > 0
+}
+
+func (m *MemStore) HasFactions() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.factionCount > 0
+}
+
+// decode runs fn over data, a section encoded by SaveWorldState.
+func decode(data []byte, what string, fn func(*checkpoint.Decoder)) error {
+	d := checkpoint.NewDecoder(data)
+	fn(d)
+	if err := d.Finish(); err != nil {
+		return fmt.Errorf("decode saved %s: %w", what, err)
+	}
+	return nil
+}
+
+func (m *MemStore) LoadAgents() ([]*agents.Agent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.agents == nil {
+		return nil, nil
+	}
+	var list []*agents.Agent
+	err := decode(m.agents, "agents", func(d *checkpoint.Decoder) { list = decodeAgents(d, ckptAgentsV) })
+	for _, a := range list {
+		a.Memories, a.Relationships = nil, nil
+	}
+	return list, err
+}
+
+func (m *MemStore) LoadSettlements() ([]*social.Settlement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.settlements == nil {
+		return nil, nil
+	}
+	var list []*social.Settlement
+	err := decode(m.settlements, "settlements", func(d *checkpoint.Decoder) { list = decodeSettlements(d) })
+	return list, err
+}
+
+func (m *MemStore) LoadFactions() ([]*social.Faction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.factions == nil {
+		return nil, nil
+	}
+	var list []*social.Faction
+	err := decode(m.factions, "factions", func(d *checkpoint.Decoder) { list = decodeFactions(d) })
+	return list, err
+}
+
+func (m *MemStore) LoadMemories(agentIndex map[agents.AgentID]*agents.Agent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, mems := range m.memories {
+		if a, ok := agentIndex[id]; ok {
+			a.Memories = append(a.Memories, mems...)
+		}
+	}
+	return nil
+}
+
+func (m *MemStore) LoadRelationships(agentIndex map[agents.AgentID]*agents.Agent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, rels := range m.relationships {
+		if a, ok := agentIndex[id]; ok {
+			a.Relationships = append(a.Relationships, rels...)
+		}
+	}
+	return nil
+}
+
+func (m *MemStore) RestoreLatePersisted(sim *engine.Simulation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	restoreLatePersisted(sim, m.meta)
+}
+
+// ── Events and interventions ────────────────────────────────────────
+
+func (m *MemStore) SaveHistory(sim *engine.Simulation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saveHistory(sim)
+}
+
+func (m *MemStore) saveHistory(sim *engine.Simulation) error {
+	for _, e := range sim.UnsavedEventTail() {
+		// Keep what the events table keeps: the subjects, not the rest of Meta.
+		agentID, settlementID := eventSubjects(e)
+		h := hotEvent{Tick: e.Tick, Description: e.Description, Category: string(e.Category),
+			Narrated: e.NarratedDescription, AgentID: agentID, SettlementID: settlementID}
+		m.events = append(m.events, h.event())
+	}
+	sim.UnsavedEvents = 0
+	m.lineage.add(sim.UnsavedLineage)
+	sim.UnsavedLineage = nil
+	m.saveGraves(sim.UnsavedGraves)
+	sim.UnsavedGraves = nil
+	m.saveArchives(sim.UnsavedArchives)
+	sim.UnsavedArchives = nil
+	return m.saveInterventions(sim.Interventions)
+}
+
+func (m *MemStore) RecentEvents(limit int) ([]engine.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []engine.Event
+	for i := len(m.events) - 1; i >= 0 && len(out) < limit; i-- {
+		e := m.events[i]
+		e.Meta = nil
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (m *MemStore) QueryEvents(q EventQuery, unsaved []engine.Event) ([]engine.Event, *EventCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	need := q.need()
+	found := q.newest(nil, unsaved, need)
+	found = q.newest(found, m.events, len(found)+need)
+	page, next := q.page(found)
+	return page, next, nil
+}
+
+// ArchiveOldEvents archives nothing: a MemStore keeps every event as is.
+func (m *MemStore) ArchiveOldEvents(currentTick, keepTicks uint64) (int64, error) {
+	return 0, nil
+}
+
+func (m *MemStore) SaveInterventions(records []*engine.InterventionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saveInterventions(records)
+}
+
+func (m *MemStore) saveInterventions(records []*engine.InterventionRecord) error {
+	for _, rec := range records {
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("marshal intervention %d: %w", rec.ID, err)
+		}
+		m.interventions[rec.ID] = b
+	}
+	return nil
+}
+
+func (m *MemStore) LoadInterventions(settledLimit int) ([]*engine.InterventionRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all []*engine.InterventionRecord
+	for _, id := range slices.Sorted(maps.Keys(m.interventions)) {
+		var rec engine.InterventionRecord
+		if err := json.Unmarshal(m.interventions[id], &rec); err != nil {
+			return nil, fmt.Errorf("decode intervention: %w", err)
+		}
+		all = append(all, &rec)
+	}
+	// Every pending record plus the newest settledLimit settled ones.
+	settled := 0
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Status == engine.InterventionPending {
+			continue
+		}
+		if settled++; settled > settledLimit {
+			all[i] = nil
+		}
+	}
+	return slices.DeleteFunc(all, func(r *engine.InterventionRecord) bool { return r == nil }), nil
+}
+
+// ── Stats and settlement history ────────────────────────────────────
+
+func (m *MemStore) SaveStatsSnapshot(row StatsRow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats[row.Tick] = row
+	return nil
+}
+
+func (m *MemStore) LoadStatsHistory(fromTick, toTick uint64, limit int) ([]StatsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit <= 0 {
+		limit = 30
+	}
+	var rows []StatsRow
+	for tick, row := range m.stats {
+		if tick >= fromTick && tick <= toTick {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b StatsRow) int { return cmp.Compare(b.Tick, a.Tick) })
+	return rows[:min(limit, len(rows))], nil
+}
+
+func (m *MemStore) SaveSettlementStats(rows []SettlementStatsRow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rows {
+		m.settStats[[2]uint64{r.Tick, r.SettlementID}] = r
+	}
+	return nil
+}
+
+func (m *MemStore) LoadSettlementHistory(settlementID uint64, limit int) ([]SettlementStatsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit <= 0 {
+		limit = 30
+	}
+	var rows []SettlementStatsRow
+	for _, r := range m.settStats {
+		if r.SettlementID == settlementID {
+			rows = append(rows, r)
+		}
+	}
+	slices.SortFunc(rows, func(a, b SettlementStatsRow) int { return cmp.Compare(b.Tick, a.Tick) })
+	return rows[:min(limit, len(rows))], nil
+}
+
+// ── Biographies ─────────────────────────────────────────────────────
+
+func (m *MemStore) SaveBiography(agentID uint64, biography, generatedAt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.biographies[agentID] = BiographyRow{AgentID: agentID, Biography: biography, GeneratedAt: generatedAt}
+	return nil
+}
+
+func (m *MemStore) LoadBiographies() ([]BiographyRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rows []BiographyRow
+	for _, id := range slices.Sorted(maps.Keys(m.biographies)) {
+		rows = append(rows, m.biographies[id])
+	}
+	return rows, nil
+}
+
+func (m *MemStore) Close() error { return nil }
@@ -0,0 +1,725 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ── Schema migrations ───────────────────────────────────────────────
+//
+// The schema is built by numbered, forward-only migrations. Each one runs in
+// its own transaction together with its row in schema_migrations, so a
+// database is always at exactly one version. Open applies whatever is
+// pending; `worldsim migrate` shows and checks the state by hand.
+//
+// Databases from before this table existed record nothing, yet may already
+// have any prefix of the schema. Every migration therefore checks before it
+// changes anything (CREATE ... IF NOT EXISTS, addColumns), and the first Open
+// of such a database runs them all, changing only what is missing.
+//
+// To change the schema, append a migration. Never edit or reorder one that
+// has shipped: deployed databases have already recorded it.
+
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sqlx.Tx) error
+}
+
+var migrations = []migration{
+	{1, "initial_schema", execAll(`
+	CREATE TABLE IF NOT EXISTS agents (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		age INTEGER NOT NULL,
+		sex INTEGER NOT NULL,
+		health REAL NOT NULL,
+		pos_q INTEGER NOT NULL,
+		pos_r INTEGER NOT NULL,
+		home_settlement_id INTEGER,
+		occupation INTEGER NOT NULL,
+		wealth INTEGER NOT NULL,
+		tier INTEGER NOT NULL,
+		mood REAL NOT NULL,
+		alive INTEGER NOT NULL,
+		born_tick INTEGER NOT NULL,
+		role INTEGER NOT NULL,
+		faction_id INTEGER,
+		archetype TEXT,
+		skills_json TEXT NOT NULL,
+		needs_json TEXT NOT NULL,
+		soul_json TEXT NOT NULL,
+		inventory_json TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS settlements (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		pos_q INTEGER NOT NULL,
+		pos_r INTEGER NOT NULL,
+		population INTEGER NOT NULL,
+		governance INTEGER NOT NULL,
+		tax_rate REAL NOT NULL,
+		treasury INTEGER NOT NULL,
+		governance_score REAL NOT NULL,
+		cultural_memory REAL NOT NULL,
+		culture_tradition REAL NOT NULL,
+		culture_openness REAL NOT NULL,
+		culture_militarism REAL NOT NULL,
+		wall_level INTEGER NOT NULL,
+		road_level INTEGER NOT NULL,
+		market_level INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tick INTEGER NOT NULL,
+		description TEXT NOT NULL,
+		category TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS world_meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS biographies (
+		agent_id INTEGER PRIMARY KEY,
+		biography TEXT NOT NULL,
+		generated_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS memories (
+		agent_id INTEGER NOT NULL,
+		tick INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		importance REAL NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS stats_history (
+		tick INTEGER PRIMARY KEY,
+		population INTEGER NOT NULL,
+		total_wealth INTEGER NOT NULL,
+		avg_mood REAL NOT NULL,
+		avg_survival REAL NOT NULL,
+		births INTEGER NOT NULL,
+		deaths INTEGER NOT NULL,
+		trade_volume INTEGER NOT NULL,
+		avg_coherence REAL NOT NULL,
+		settlement_count INTEGER NOT NULL,
+		gini REAL NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS relationships (
+		agent_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		sentiment REAL NOT NULL,
+		trust REAL NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_events_tick ON events(tick);
+	CREATE INDEX IF NOT EXISTS idx_agents_settlement ON agents(home_settlement_id);
+	CREATE INDEX IF NOT EXISTS idx_agents_alive ON agents(alive);
+	CREATE INDEX IF NOT EXISTS idx_memories_agent ON memories(agent_id);
+	CREATE INDEX IF NOT EXISTS idx_relationships_agent ON relationships(agent_id);
+	`)},
+	{2, "event_narration", addColumns("events", "narrated TEXT NOT NULL DEFAULT ''")},
+	// Added in Phase 5 tuning.
+	{3, "factions", execAll(`
+	CREATE TABLE IF NOT EXISTS factions (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		kind INTEGER NOT NULL,
+		leader_id INTEGER,
+		treasury INTEGER NOT NULL,
+		tax_preference REAL NOT NULL,
+		trade_preference REAL NOT NULL,
+		military_preference REAL NOT NULL,
+		influence_json TEXT NOT NULL,
+		relations_json TEXT NOT NULL
+	)`)},
+	{4, "settlement_abandoned", addColumns("settlements", "abandoned INTEGER NOT NULL DEFAULT 0")},
+	{5, "satisfaction_alignment", func(tx *sqlx.Tx) error {
+		if err := addColumns("agents",
+			"satisfaction REAL NOT NULL DEFAULT 0",
+			"alignment REAL NOT NULL DEFAULT 0")(tx); err != nil {
+			return err
+		}
+		return addColumns("stats_history",
+			"avg_satisfaction REAL NOT NULL DEFAULT 0",
+			"avg_alignment REAL NOT NULL DEFAULT 0")(tx)
+	}},
+	{6, "agent_production", addColumns("agents",
+		"last_work_tick INTEGER NOT NULL DEFAULT 0",
+		"production_progress REAL NOT NULL DEFAULT 0")},
+	{7, "stats_occupations", addColumns("stats_history", "occupation_json TEXT NOT NULL DEFAULT ''")},
+	{8, "settlement_stats_history", execAll(`
+	CREATE TABLE IF NOT EXISTS settlement_stats_history (
+		tick INTEGER NOT NULL,
+		settlement_id INTEGER NOT NULL,
+		population INTEGER NOT NULL,
+		treasury INTEGER NOT NULL,
+		avg_satisfaction REAL NOT NULL,
+		trade_volume INTEGER NOT NULL,
+		governance TEXT NOT NULL,
+		governance_score REAL NOT NULL,
+		carrying_capacity REAL NOT NULL,
+		population_pressure REAL NOT NULL,
+		PRIMARY KEY (tick, settlement_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_settlement_stats_id ON settlement_stats_history(settlement_id);
+	`)},
+	{9, "event_subjects", func(tx *sqlx.Tx) error {
+		if err := addColumns("events", "agent_id INTEGER", "settlement_id INTEGER")(tx); err != nil {
+			return err
+		}
+		return execAll(`
+		CREATE INDEX IF NOT EXISTS idx_events_agent ON events(agent_id);
+		CREATE INDEX IF NOT EXISTS idx_events_settlement ON events(settlement_id);
+		`)(tx)
+	}},
+	{10, "agent_age_months", addColumns("agents", "age_months INTEGER NOT NULL DEFAULT 0")},
+	{11, "wealth_shares", addColumns("stats_history",
+		"bottom_50_share REAL NOT NULL DEFAULT 0",
+		"top_10_share REAL NOT NULL DEFAULT 0")},
+	// Intervention ledger (see engine/intervention_queue.go). The record is
+	// stored whole as JSON; the other columns are for querying.
+	{12, "interventions", execAll(`
+	CREATE TABLE IF NOT EXISTS interventions (
+		id INTEGER PRIMARY KEY,
+		status TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		type TEXT NOT NULL,
+		submitted_tick INTEGER NOT NULL,
+		apply_at INTEGER NOT NULL,
+		record_json TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_interventions_status ON interventions(status);
+	`)},
+	// Long-term event history (see event_archive.go).
+	{13, "event_archive", execAll(`
+	CREATE TABLE IF NOT EXISTS event_archive_segments (
+		id INTEGER PRIMARY KEY,
+		season INTEGER NOT NULL,
+		first_tick INTEGER NOT NULL,
+		last_tick INTEGER NOT NULL,
+		events INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_event_archive_segments_season ON event_archive_segments(season);
+
+	CREATE TABLE IF NOT EXISTS event_archive (
+		event_id INTEGER PRIMARY KEY,
+		segment_id INTEGER NOT NULL,
+		seq INTEGER NOT NULL,
+		tick INTEGER NOT NULL,
+		category TEXT NOT NULL,
+		agent_id INTEGER,
+		settlement_id INTEGER
+	);
+	CREATE INDEX IF NOT EXISTS idx_event_archive_tick ON event_archive(tick);
+	CREATE INDEX IF NOT EXISTS idx_event_archive_category ON event_archive(category, tick);
+	CREATE INDEX IF NOT EXISTS idx_event_archive_agent ON event_archive(agent_id, tick);
+	CREATE INDEX IF NOT EXISTS idx_event_archive_settlement ON event_archive(settlement_id, tick);
+	`)},
+	// The family tree (see lineage.go).
+	{14, "lineage", execAll(`
+	CREATE TABLE IF NOT EXISTS lineage_people (
+		agent_id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		born_tick INTEGER NOT NULL,
+		died_tick INTEGER,
+		death_cause TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS lineage_edges (
+		kind TEXT NOT NULL,
+		from_id INTEGER NOT NULL,
+		to_id INTEGER NOT NULL,
+		tick INTEGER NOT NULL,
+		PRIMARY KEY (from_id, to_id, kind)
+	);
+	CREATE INDEX IF NOT EXISTS idx_lineage_edges_to ON lineage_edges(to_id);
+	`)},
+	// Final snapshots of the dead (see graveyard.go).
+	{15, "graveyard", execAll(`
+	CREATE TABLE IF NOT EXISTS graveyard (
+		agent_id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		sex INTEGER NOT NULL,
+		age INTEGER NOT NULL,
+		born_tick INTEGER NOT NULL,
+		died_tick INTEGER NOT NULL,
+		cause TEXT NOT NULL,
+		occupation INTEGER NOT NULL,
+		state INTEGER NOT NULL,
+		coherence REAL NOT NULL,
+		wealth INTEGER NOT NULL,
+		settlement_id INTEGER,
+		settlement_name TEXT NOT NULL DEFAULT '',
+		faction_id INTEGER,
+		faction_name TEXT NOT NULL DEFAULT '',
+		memories_json TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_graveyard_died ON graveyard(died_tick);
+	CREATE INDEX IF NOT EXISTS idx_graveyard_cause ON graveyard(cause, died_tick);
+	CREATE INDEX IF NOT EXISTS idx_graveyard_settlement ON graveyard(settlement_id, died_tick);
+	`)},
+	{16, "settlement_archive", execAll(`
+	CREATE TABLE IF NOT EXISTS settlement_archive (
+		settlement_id INTEGER NOT NULL,
+		abandoned_tick INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		q INTEGER NOT NULL,
+		r INTEGER NOT NULL,
+		governance INTEGER NOT NULL,
+		founded_tick INTEGER NOT NULL,
+		parent_id INTEGER,
+		parent_name TEXT NOT NULL DEFAULT '',
+		peak_population INTEGER NOT NULL,
+		peak_tick INTEGER NOT NULL,
+		cause TEXT NOT NULL,
+		founders_json TEXT NOT NULL DEFAULT '',
+		governments_json TEXT NOT NULL DEFAULT '',
+		successors_json TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (settlement_id, abandoned_tick)
+	);
+	CREATE INDEX IF NOT EXISTS idx_settlement_archive_abandoned ON settlement_archive(abandoned_tick);
+	CREATE INDEX IF NOT EXISTS idx_settlement_archive_cause ON settlement_archive(cause, abandoned_tick);
+	`)},
+	{17, "price_history", execAll(`
+	CREATE TABLE IF NOT EXISTS price_history (
+		settlement_id INTEGER NOT NULL,
+		good INTEGER NOT NULL,
+		resolution TEXT NOT NULL,
+		tick INTEGER NOT NULL,
+		open REAL NOT NULL,
+		high REAL NOT NULL,
+		low REAL NOT NULL,
+		close REAL NOT NULL,
+		volume INTEGER NOT NULL,
+		PRIMARY KEY (settlement_id, resolution, good, tick)
+	);
+	CREATE INDEX IF NOT EXISTS idx_price_history_resolution ON price_history(resolution, tick);
+	`)},
+	// Full-text search (see search.go). The indexes refer to rows by rowid,
+	// which VACUUM may renumber in a table without an INTEGER PRIMARY KEY,
+	// so memories is rebuilt with one first, and with the content key that
+	// search pages memories by (memory rows are rewritten on save, so their
+	// IDs do not last). Archived events are indexed from their segments.
+	{18, "search", func(tx *sqlx.Tx) error {
+		cols, err := tableColumns(tx, "memories")
+		if err != nil {
+			return err
+		}
+		if _, ok := cols["content_key"]; !ok {
+			if err := execAll(`
+			CREATE TABLE memories_new (
+				id INTEGER PRIMARY KEY,
+				agent_id INTEGER NOT NULL,
+				tick INTEGER NOT NULL,
+				content TEXT NOT NULL,
+				importance REAL NOT NULL,
+				content_key INTEGER NOT NULL DEFAULT 0
+			);
+			INSERT INTO memories_new (agent_id, tick, content, importance)
+				SELECT agent_id, tick, content, importance FROM memories;
+			DROP TABLE memories;
+			ALTER TABLE memories_new RENAME TO memories;
+			CREATE INDEX idx_memories_agent ON memories(agent_id);
+			`)(tx); err != nil {
+				return err
+			}
+			var rows []struct {
+				ID      int64  `db:"id"`
+				Content string `db:"content"`
+			}
+			if err := tx.Select(&rows, "SELECT id, content FROM memories"); err != nil {
+				return err
+			}
+			for _, r := range rows {
+				if _, err := tx.Exec("UPDATE memories SET content_key = ? WHERE id = ?", memoryKey(r.Content), r.ID); err != nil {
+					return err
+				}
+			}
+		}
+		if err := execAll(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(
+			description, narrated, content='events', content_rowid='id');
+		CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+			INSERT INTO events_fts (rowid, description, narrated) VALUES (new.id, new.description, new.narrated);
+		END;
+		CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+			INSERT INTO events_fts (events_fts, rowid, description, narrated)
+				VALUES ('delete', old.id, old.description, old.narrated);
+		END;
+		CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE OF description, narrated ON events BEGIN
+			INSERT INTO events_fts (events_fts, rowid, description, narrated)
+				VALUES ('delete', old.id, old.description, old.narrated);
+			INSERT INTO events_fts (rowid, description, narrated) VALUES (new.id, new.description, new.narrated);
+		END;
+		INSERT INTO events_fts (events_fts) VALUES ('rebuild');
+
+		CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
+			content, content='memories', content_rowid='id');
+		CREATE TRIGGER IF NOT EXISTS memories_fts_insert AFTER INSERT ON memories BEGIN
+			INSERT INTO memories_fts (rowid, content) VALUES (new.id, new.content);
+		END;
+		CREATE TRIGGER IF NOT EXISTS memories_fts_delete AFTER DELETE ON memories BEGIN
+			INSERT INTO memories_fts (memories_fts, rowid, content) VALUES ('delete', old.id, old.content);
+		END;
+		INSERT INTO memories_fts (memories_fts) VALUES ('rebuild');
+
+		CREATE VIRTUAL TABLE IF NOT EXISTS event_archive_fts USING fts5(
+			description, narrated, content='');
+		`)(tx); err != nil {
+			return err
+		}
+		return indexArchive(tx)
+	}},
+	// State the checkpoint always kept and a database boot dropped: a
+	// settlement's leader, and a merchant's trip and cargo (see agentTrade).
+	{19, "restart_state", func(tx *sqlx.Tx) error {
+		if err := addColumns("settlements", "leader_id INTEGER")(tx); err != nil {
+			return err
+		}
+		return addColumns("agents",
+			"trade_json TEXT NOT NULL DEFAULT '{}'",
+			"practice_boost REAL NOT NULL DEFAULT 0")(tx)
+	}},
+}
+
+// indexArchive adds every archived event's text to event_archive_fts.
+func indexArchive(tx *sqlx.Tx) error {
+	var segs []struct {
+		ID   int64  `db:"id"`
+		Data []byte `db:"data"`
+	}
+	if err := tx.Select(&segs, "SELECT id, data FROM event_archive_segments"); err != nil {
+		return err
+	}
+	for _, seg := range segs {
+		texts, err := decompressSegment(seg.Data)
+		if err != nil {
+			return fmt.Errorf("archive segment %d: %w", seg.ID, err)
+		}
+		var rows []struct {
+			EventID int64 `db:"event_id"`
+			Seq     int   `db:"seq"`
+		}
+		if err := tx.Select(&rows, "SELECT event_id, seq FROM event_archive WHERE segment_id = ?", seg.ID); err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if r.Seq >= len(texts) {
+				return fmt.Errorf("archived event %d: segment %d has %d events, want index %d", r.EventID, seg.ID, len(texts), r.Seq)
+			}
+			t := texts[r.Seq]
+			if _, err := tx.Exec("INSERT INTO event_archive_fts (rowid, description, narrated) VALUES (?, ?, ?)",
+				r.EventID, t.Description, t.Narrated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// execAll returns a migration step that runs sql as is.
+func execAll(sql string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(sql)
+		return err
+	}
+}
+
+// addColumns returns a migration step that adds each column ("name decl")
+// to table unless the table already has a column of that name.
+func addColumns(table string, cols ...string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		have, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, col := range cols {
+			name, _, _ := strings.Cut(col, " ")
+			if _, ok := have[name]; ok {
+				continue
+			}
+			if _, err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + col); err != nil {
+				return fmt.Errorf("add %s.%s: %w", table, name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// columnInfo is one row of PRAGMA table_info.
+type columnInfo struct {
+	CID     int     `db:"cid"`
+	Name    string  `db:"name"`
+	Type    string  `db:"type"`
+	NotNull bool    `db:"notnull"`
+	Default *string `db:"dflt_value"`
+	PK      int     `db:"pk"`
+}
+
+func (c columnInfo) String() string {
+	s := c.Type
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	if c.Default != nil {
+		s += " DEFAULT " + *c.Default
+	}
+	if c.PK > 0 {
+		s += " PRIMARY KEY"
+	}
+	return s
+}
+
+// tableColumns returns table's columns by name; none if it does not exist.
+func tableColumns(q sqlx.Queryer, table string) (map[string]columnInfo, error) {
+	var cols []columnInfo
+	if err := sqlx.Select(q, &cols, "PRAGMA table_info("+table+")"); err != nil {
+		return nil, fmt.Errorf("columns of %s: %w", table, err)
+	}
+	out := make(map[string]columnInfo, len(cols))
+	for _, c := range cols {
+		out[c.Name] = c
+	}
+	return out, nil
+}
+
+// SchemaVersion is the version the migrations in this build bring a
+// database to.
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// ErrSchemaTooNew is returned when a database has migrations this build does
+// not know, i.e. it was last opened by a newer worldsim. Migrations only go
+// forward, so going back to an older build means restoring a backup.
+var ErrSchemaTooNew = errors.New("database schema is newer than this build")
+
+// Migration is one schema migration and whether the database has it.
+type Migration struct {
+	Version   int    `db:"version"`
+	Name      string `db:"name"`
+	AppliedAt string `db:"applied_at"` // RFC 3339; empty while pending
+}
+
+func (db *DB) ensureMigrationTable() error {
+	_, err := db.conn.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	return err
+}
+
+// Migrations lists every migration this build knows in order, with when it
+// was applied, followed by any the database records that this build does not
+// know. It does not write to the database.
+func (db *DB) Migrations() ([]Migration, error) {
+	var applied []Migration
+	var tables int
+	if err := db.conn.Get(&tables, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"); err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+	if tables > 0 { // otherwise nothing is recorded yet
+		if err := db.conn.Select(&applied, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version"); err != nil {
+			return nil, fmt.Errorf("read schema_migrations: %w", err)
+		}
+	}
+	byVersion := make(map[int]Migration, len(applied))
+	for _, m := range applied {
+		byVersion[m.Version] = m
+	}
+	out := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		row := Migration{Version: m.version, Name: m.name}
+		if a, ok := byVersion[m.version]; ok {
+			row.AppliedAt = a.AppliedAt
+			delete(byVersion, m.version)
+		}
+		out = append(out, row)
+	}
+	var unknown []Migration
+	for _, m := range byVersion {
+		unknown = append(unknown, m)
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+	return append(out, unknown...), nil
+}
+
+// Migrate applies every pending migration in order, each in its own
+// transaction, and returns the ones it applied. It fails with
+// ErrSchemaTooNew, before changing anything, if the database has migrations
+// this build does not know. Open calls it.
+func (db *DB) Migrate() ([]Migration, error) {
+	if err := db.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	state, err := db.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if last := state[len(state)-1]; last.Version > SchemaVersion() {
+		return nil, fmt.Errorf("%w: database is at version %d (%s), this build knows up to %d",
+			ErrSchemaTooNew, last.Version, last.Name, SchemaVersion())
+	}
+
+	var done []Migration
+	for i, m := range migrations {
+		if state[i].AppliedAt != "" {
+			continue
+		}
+		applied, err := db.apply(m)
+		if err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		slog.Info("schema migration applied", "version", m.version, "name", m.name)
+		done = append(done, applied)
+	}
+	return done, nil
+}
+
+func (db *DB) apply(m migration) (Migration, error) {
+	rec := Migration{Version: m.version, Name: m.name, AppliedAt: time.Now().UTC().Format(time.RFC3339)}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return rec, err
+	}
+	defer tx.Rollback()
+	if err := m.up(tx); err != nil {
+		return rec, err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		rec.Version, rec.Name, rec.AppliedAt); err != nil {
+		return rec, err
+	}
+	return rec, tx.Commit()
+}
+
+// VerifySchema compares the database's tables, columns and indexes with what
+// the migrations build in an empty database, and checks every migration is
+// recorded. It returns one line per difference; none means the schema is
+// current.
+func (db *DB) VerifySchema() ([]string, error) {
+	var problems []string
+	state, err := db.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range state {
+		switch {
+		case m.Version > SchemaVersion():
+			problems = append(problems, fmt.Sprintf("migration %d (%s) is unknown to this build", m.Version, m.Name))
+		case m.AppliedAt == "":
+			problems = append(problems, fmt.Sprintf("migration %d (%s) is pending", m.Version, m.Name))
+		}
+	}
+
+	ref, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer ref.Close()
+	ref.SetMaxOpenConns(1) // each connection would get its own empty database
+	want := &DB{conn: ref}
+	if err := want.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if _, err := want.apply(m); err != nil {
+			return nil, fmt.Errorf("build reference schema: migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	wantObjs, err := schemaObjects(want.conn)
+	if err != nil {
+		return nil, err
+	}
+	haveObjs, err := schemaObjects(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(wantObjs) {
+		w := wantObjs[name]
+		h, ok := haveObjs[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s %s is missing", w.Type, name))
+			continue
+		case h.Type != w.Type || h.Table != w.Table:
+			problems = append(problems, fmt.Sprintf("%s is a %s on %s, want a %s on %s", name, h.Type, h.Table, w.Type, w.Table))
+			continue
+		case w.Type != "table":
+			continue
+		}
+		wantCols, err := tableColumns(want.conn, name)
+		if err != nil {
+			return nil, err
+		}
+		haveCols, err := tableColumns(db.conn, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range sortedKeys(wantCols) {
+			hc, ok := haveCols[col]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("column %s.%s is missing", name, col))
+			} else if hc.String() != wantCols[col].String() {
+				problems = append(problems, fmt.Sprintf("column %s.%s is %s, want %s", name, col, hc, wantCols[col]))
+			}
+		}
+		for _, col := range sortedKeys(haveCols) {
+			if _, ok := wantCols[col]; !ok {
+				problems = append(problems, fmt.Sprintf("column %s.%s is not in the schema", name, col))
+			}
+		}
+	}
+	for _, name := range sortedKeys(haveObjs) {
+		if _, ok := wantObjs[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s %s is not in the schema", haveObjs[name].Type, name))
+		}
+	}
+	return problems, nil
+}
+
+type schemaObject struct {
+	Type  string `db:"type"`
+	Name  string `db:"name"`
+	Table string `db:"tbl_name"`
+}
+
+// schemaObjects returns the user tables and indexes in the database by name,
+// leaving out SQLite's own.
+func schemaObjects(q sqlx.Queryer) (map[string]schemaObject, error) {
+	var objs []schemaObject
+	if err := sqlx.Select(q, &objs, `SELECT type, name, tbl_name FROM sqlite_master
+		WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%'`); err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+	out := make(map[string]schemaObject, len(objs))
+	for _, o := range objs {
+		out[o.Name] = o
+	}
+	return out, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
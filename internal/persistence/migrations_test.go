@@ -0,0 +1,160 @@
+package persistence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// fixtureDB builds a database from an SQL file in testdata, the way an older
+// worldsim left it: no schema_migrations table and only part of the schema.
+func fixtureDB(t *testing.T, fixture string) string {
+	t.Helper()
+	sql, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "world.db")
+	db, err := OpenUnmigrated(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.conn.Exec(string(sql)); err != nil {
+		t.Fatalf("load %s: %v", fixture, err)
+	}
+	return path
+}
+
+func verifyClean(t *testing.T, db *DB) {
+	t.Helper()
+	problems, err := db.VerifySchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Error(p)
+	}
+}
+
+func TestMigrateFresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyClean(t, db)
+	db.Close()
+
+	// A second Open has nothing to do.
+	db, err = OpenUnmigrated(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if done, err := db.Migrate(); err != nil || len(done) != 0 {
+		t.Errorf("second migrate applied %v, %v", done, err)
+	}
+}
+
+// TestMigrateLegacy upgrades databases written before schema_migrations
+// existed and checks they end up with the current schema and their rows.
+func TestMigrateLegacy(t *testing.T) {
+	for _, fixture := range []string{"pre_factions.sql", "pre_settlement_stats.sql"} {
+		t.Run(fixture, func(t *testing.T) {
+			path := fixtureDB(t, fixture)
+
+			db, err := OpenUnmigrated(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if problems, err := db.VerifySchema(); err != nil || len(problems) == 0 {
+				t.Errorf("verify before migrating: %v, %v", problems, err)
+			}
+			db.Close()
+
+			db, err = Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			verifyClean(t, db)
+			state, err := db.Migrations()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(state) != len(migrations) {
+				t.Errorf("%d migrations recorded, want %d", len(state), len(migrations))
+			}
+
+			agents, err := db.LoadAgents()
+			if err != nil || len(agents) != 2 {
+				t.Fatalf("agents: %d, %v", len(agents), err)
+			}
+			setts, err := db.LoadSettlements()
+			if err != nil || len(setts) != 1 || setts[0].Name != "Oldmere" {
+				t.Fatalf("settlements: %v, %v", setts, err)
+			}
+			events, err := db.RecentEvents(10)
+			if err != nil || len(events) != 2 {
+				t.Fatalf("events: %d, %v", len(events), err)
+			}
+			// The search migration indexes rows that were already there.
+			if hits, _, err := db.Search(SearchQuery{Text: "market"}); err != nil || len(hits) != 1 || hits[0].Tick != 200 {
+				t.Errorf("search of old events: %+v, %v", hits, err)
+			}
+			if v, _ := db.GetMeta("last_tick"); v != "200" {
+				t.Errorf("last_tick = %q", v)
+			}
+			rows, err := db.LoadStatsHistory(0, 10_000, 10)
+			if err != nil || len(rows) != 1 {
+				t.Fatalf("stats history: %d, %v", len(rows), err)
+			}
+			if err := db.SaveSettlementStats([]SettlementStatsRow{{Tick: 1440, SettlementID: 1, Governance: "council"}}); err != nil {
+				t.Errorf("new table not writable: %v", err)
+			}
+		})
+	}
+}
+
+// TestMigrateIndexesArchive runs the search migration over a database that
+// already has archived events and checks it indexes their text.
+func TestMigrateIndexesArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SaveEvents([]engine.Event{{Tick: 10, Description: "The well ran dry"}, {Tick: 5000, Description: "Rain at last"}})
+	if n, err := db.ArchiveOldEvents(5000, 1000); err != nil || n != 1 {
+		t.Fatalf("archived %d, %v", n, err)
+	}
+	db.conn.MustExec("DROP TABLE event_archive_fts")
+	db.conn.MustExec("DELETE FROM schema_migrations WHERE name = 'search'")
+	db.Close()
+
+	if db, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if hits, _, err := db.Search(SearchQuery{Text: "dry"}); err != nil || len(hits) != 1 || hits[0].Snippet != "The well ran <mark>dry</mark>" {
+		t.Errorf("search of archived events: %+v, %v", hits, err)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.conn.MustExec("INSERT INTO schema_migrations VALUES (?, 'from_the_future', '')", SchemaVersion()+1)
+	db.Close()
+
+	if _, err := Open(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Open = %v, want ErrSchemaTooNew", err)
+	}
+}
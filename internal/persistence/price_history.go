@@ -0,0 +1,283 @@
+package persistence
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// ── Price history ───────────────────────────────────────────────────
+//
+// price_history holds the engine's hourly market bars (engine.PriceBar) for
+// the retention window, then daily bars for good: DownsamplePrices, run
+// weekly, folds each whole sim-day of hourly bars older than the window into
+// one daily bar. Daily queries over the window aggregate the hourly bars on
+// the fly, so a daily series runs unbroken up to the last hour.
+
+// Price resolutions.
+const (
+	PriceHourly = "hour"
+	PriceDaily  = "day"
+)
+
+// PriceQuery selects bars for QueryPrices.
+type PriceQuery struct {
+	SettlementID uint64
+	Good         *agents.GoodType // nil selects every good
+	Resolution   string           // PriceHourly or PriceDaily
+	FromTick     uint64           // inclusive, on the bar's tick
+	ToTick       uint64           // inclusive; 0 means no upper bound
+}
+
+func (q PriceQuery) upperTick() uint64 {
+	if q.ToTick == 0 {
+		return math.MaxInt64 // SQLite integers are signed
+	}
+	return q.ToTick
+}
+
+func (q PriceQuery) wants(b engine.PriceBar) bool {
+	return b.SettlementID == q.SettlementID && (q.Good == nil || b.Good == *q.Good)
+}
+
+// dayOf returns the first tick of the sim-day holding tick.
+func dayOf(tick uint64) uint64 {
+	return tick / engine.TicksPerSimDay * engine.TicksPerSimDay
+}
+
+func priceOrder(a, b engine.PriceBar) int {
+	return cmp.Or(cmp.Compare(a.SettlementID, b.SettlementID), cmp.Compare(a.Good, b.Good), cmp.Compare(a.Tick, b.Tick))
+}
+
+// dailyBars folds hourly bars into one bar per settlement, good and sim-day.
+func dailyBars(hourly []engine.PriceBar) []engine.PriceBar {
+	hourly = slices.Clone(hourly)
+	slices.SortFunc(hourly, priceOrder)
+	var days []engine.PriceBar
+	for _, h := range hourly {
+		if n := len(days); n > 0 {
+			d := &days[n-1]
+			if d.SettlementID == h.SettlementID && d.Good == h.Good && d.Tick == dayOf(h.Tick) {
+				d.High = max(d.High, h.High)
+				d.Low = min(d.Low, h.Low)
+				d.Close = h.Close
+				d.Volume += h.Volume
+				continue
+			}
+		}
+		h.Tick = dayOf(h.Tick)
+		days = append(days, h)
+	}
+	return days
+}
+
+// daily merges stored daily bars with the days aggregated from hourly bars
+// and keeps the days in q's range. A day stored whole wins over a partial
+// aggregate.
+func (q PriceQuery) daily(stored, hourly []engine.PriceBar) []engine.PriceBar {
+	type key struct {
+		good agents.GoodType
+		tick uint64
+	}
+	have := make(map[key]bool, len(stored))
+	for _, d := range stored {
+		have[key{d.Good, d.Tick}] = true
+	}
+	for _, d := range dailyBars(hourly) {
+		if !have[key{d.Good, d.Tick}] && d.Tick >= q.FromTick && d.Tick <= q.upperTick() {
+			stored = append(stored, d)
+		}
+	}
+	slices.SortFunc(stored, priceOrder)
+	return stored
+}
+
+// downsampleCutoff returns the first tick DownsamplePrices keeps hourly: the
+// start of the sim-day keepTicks before currentTick. 0 keeps everything.
+func downsampleCutoff(currentTick, keepTicks uint64) uint64 {
+	if currentTick <= keepTicks {
+		return 0
+	}
+	return dayOf(currentTick - keepTicks)
+}
+
+// ── SQLite ──────────────────────────────────────────────────────────
+
+const priceColumns = `settlement_id, good, tick, open, high, low, close, volume`
+
+// priceRow is a price_history row, less its resolution.
+type priceRow struct {
+	SettlementID uint64  `db:"settlement_id"`
+	Good         uint8   `db:"good"`
+	Tick         uint64  `db:"tick"`
+	Open         float64 `db:"open"`
+	High         float64 `db:"high"`
+	Low          float64 `db:"low"`
+	Close        float64 `db:"close"`
+	Volume       int     `db:"volume"`
+}
+
+func (r priceRow) bar() engine.PriceBar {
+	return engine.PriceBar{
+		SettlementID: r.SettlementID, Good: agents.GoodType(r.Good), Tick: r.Tick,
+		Open: r.Open, High: r.High, Low: r.Low, Close: r.Close, Volume: r.Volume,
+	}
+}
+
+// SavePriceBars writes hourly bars, replacing any already saved for the same
+// hour.
+func (db *DB) SavePriceBars(bars []engine.PriceBar) error {
+	if len(bars) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Preparex(`INSERT OR REPLACE INTO price_history (resolution, ` + priceColumns + `)
+		VALUES ('` + PriceHourly + `', ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, b := range bars {
+		if _, err := stmt.Exec(b.SettlementID, b.Good, b.Tick, b.Open, b.High, b.Low, b.Close, b.Volume); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// selectPrices returns the bars of one resolution in q's settlement and
+// goods with ticks in [from, to].
+func (db *DB) selectPrices(q PriceQuery, resolution string, from, to uint64) ([]engine.PriceBar, error) {
+	query := `SELECT ` + priceColumns + ` FROM price_history
+		WHERE settlement_id = ? AND resolution = ? AND tick >= ? AND tick <= ?`
+	args := []any{q.SettlementID, resolution, from, to}
+	if q.Good != nil {
+		query += ` AND good = ?`
+		args = append(args, *q.Good)
+	}
+	var rows []priceRow
+	if err := db.conn.Select(&rows, query+` ORDER BY good, tick`, args...); err != nil {
+		return nil, fmt.Errorf("query price history: %w", err)
+	}
+	bars := make([]engine.PriceBar, len(rows))
+	for i, r := range rows {
+		bars[i] = r.bar()
+	}
+	return bars, nil
+}
+
+// QueryPrices returns q's bars, oldest first per good. Hourly bars reach back
+// as far as the retention window; daily bars cover all history.
+func (db *DB) QueryPrices(q PriceQuery) ([]engine.PriceBar, error) {
+	if q.Resolution != PriceDaily {
+		return db.selectPrices(q, PriceHourly, q.FromTick, q.upperTick())
+	}
+	stored, err := db.selectPrices(q, PriceDaily, q.FromTick, q.upperTick())
+	if err != nil {
+		return nil, err
+	}
+	hourly, err := db.selectPrices(q, PriceHourly, dayOf(q.FromTick), q.upperTick())
+	if err != nil {
+		return nil, err
+	}
+	return q.daily(stored, hourly), nil
+}
+
+// DownsamplePrices folds the hourly bars of every whole sim-day ending more
+// than keepTicks before currentTick into daily bars, and returns how many
+// hourly bars it folded.
+func (db *DB) DownsamplePrices(currentTick, keepTicks uint64) (int64, error) {
+	cutoff := downsampleCutoff(currentTick, keepTicks)
+	if cutoff == 0 {
+		return 0, nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO price_history (resolution, `+priceColumns+`)
+		SELECT DISTINCT '`+PriceDaily+`', settlement_id, good, day,
+			FIRST_VALUE(open) OVER w, MAX(high) OVER w, MIN(low) OVER w,
+			LAST_VALUE(close) OVER w, SUM(volume) OVER w
+		FROM (SELECT *, tick / ? * ? AS day FROM price_history WHERE resolution = ? AND tick < ?)
+		WINDOW w AS (PARTITION BY settlement_id, good, day ORDER BY tick
+			ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)`,
+		engine.TicksPerSimDay, engine.TicksPerSimDay, PriceHourly, cutoff); err != nil {
+		return 0, fmt.Errorf("fold hourly prices: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM price_history WHERE resolution = ? AND tick < ?`, PriceHourly, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("drop folded hourly prices: %w", err)
+	}
+	folded, _ := res.RowsAffected()
+	return folded, tx.Commit()
+}
+
+// ── In memory ───────────────────────────────────────────────────────
+
+type priceKey struct {
+	resolution   string
+	settlementID uint64
+	good         agents.GoodType
+	tick         uint64
+}
+
+func (m *MemStore) SavePriceBars(bars []engine.PriceBar) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range bars {
+		m.prices[priceKey{PriceHourly, b.SettlementID, b.Good, b.Tick}] = b
+	}
+	return nil
+}
+
+// selectPrices is DB.selectPrices over the map.
+func (m *MemStore) selectPrices(q PriceQuery, resolution string, from, to uint64) []engine.PriceBar {
+	var bars []engine.PriceBar
+	for k, b := range m.prices {
+		if k.resolution == resolution && q.wants(b) && b.Tick >= from && b.Tick <= to {
+			bars = append(bars, b)
+		}
+	}
+	slices.SortFunc(bars, priceOrder)
+	return bars
+}
+
+func (m *MemStore) QueryPrices(q PriceQuery) ([]engine.PriceBar, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.Resolution != PriceDaily {
+		return m.selectPrices(q, PriceHourly, q.FromTick, q.upperTick()), nil
+	}
+	stored := m.selectPrices(q, PriceDaily, q.FromTick, q.upperTick())
+	return q.daily(stored, m.selectPrices(q, PriceHourly, dayOf(q.FromTick), q.upperTick())), nil
+}
+
+func (m *MemStore) DownsamplePrices(currentTick, keepTicks uint64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := downsampleCutoff(currentTick, keepTicks)
+	var old []engine.PriceBar
+	for k, b := range m.prices {
+		if k.resolution == PriceHourly && k.tick < cutoff {
+			old = append(old, b)
+			delete(m.prices, k)
+		}
+	}
+	for _, d := range dailyBars(old) {
+		k := priceKey{PriceDaily, d.SettlementID, d.Good, d.Tick}
+		if _, ok := m.prices[k]; !ok {
+			m.prices[k] = d
+		}
+	}
+	return int64(len(old)), nil
+}
@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// TestPriceHistory saves three sim-days of hourly bars for two goods, reads
+// them hourly and daily, folds the first two days, and checks the daily
+// series reads the same across the fold in each store.
+func TestPriceHistory(t *testing.T) {
+	const day = engine.TicksPerSimDay
+	for name, s := range stores(t) {
+		var bars []engine.PriceBar
+		for h := range uint64(72) {
+			for _, good := range []agents.GoodType{agents.GoodGrain, agents.GoodTools} {
+				p := float64(h + 1)
+				bars = append(bars, engine.PriceBar{SettlementID: 3, Good: good, Tick: h * 60,
+					Open: p - 1, High: p + 0.5, Low: p - 1.5, Close: p, Volume: 2})
+			}
+		}
+		bars = append(bars, engine.PriceBar{SettlementID: 4, Good: agents.GoodGrain, Tick: 60, Open: 9, High: 9, Low: 9, Close: 9})
+		if err := s.SavePriceBars(bars); err != nil {
+			t.Fatal(err)
+		}
+
+		grain := agents.GoodGrain
+		hourly, err := s.QueryPrices(PriceQuery{SettlementID: 3, Good: &grain, FromTick: 60, ToTick: 180})
+		if err != nil || len(hourly) != 3 || hourly[0].Tick != 60 || hourly[2].Close != 4 {
+			t.Errorf("%s: hourly = %+v, %v", name, hourly, err)
+		}
+
+		daily := func() []engine.PriceBar {
+			t.Helper()
+			d, err := s.QueryPrices(PriceQuery{SettlementID: 3, Resolution: PriceDaily})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return d
+		}
+		before := daily()
+		if len(before) != 6 {
+			t.Fatalf("%s: %d daily bars, want 3 days of 2 goods", name, len(before))
+		}
+		if d := before[1]; d.Good != agents.GoodGrain || d.Tick != day || d.Open != 24 || d.Close != 48 ||
+			d.High != 48.5 || d.Low != 23.5 || d.Volume != 48 {
+			t.Errorf("%s: grain day 1 = %+v", name, d)
+		}
+
+		// At the end of day 3 with a day's window, days 0 and 1 fold.
+		folded, err := s.DownsamplePrices(3*day+30, day)
+		if err != nil || folded != 2*2*24+1 {
+			t.Errorf("%s: folded %d, %v", name, folded, err)
+		}
+		if after := daily(); len(after) != len(before) {
+			t.Errorf("%s: %d daily bars after folding, %d before", name, len(after), len(before))
+		} else {
+			for i := range after {
+				if after[i] != before[i] {
+					t.Errorf("%s: daily bar %d = %+v after folding, %+v before", name, i, after[i], before[i])
+				}
+			}
+		}
+		if h, _ := s.QueryPrices(PriceQuery{SettlementID: 3, Good: &grain, ToTick: 2*day - 1}); len(h) != 0 {
+			t.Errorf("%s: %d hourly bars left in folded days", name, len(h))
+		}
+		if d, _ := s.QueryPrices(PriceQuery{SettlementID: 3, Good: &grain, Resolution: PriceDaily, FromTick: day, ToTick: 2 * day}); len(d) != 2 || d[0].Tick != day {
+			t.Errorf("%s: daily range = %+v", name, d)
+		}
+	}
+}
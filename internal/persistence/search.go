@@ -0,0 +1,449 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/talgya/mini-world/eventproto"
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/checkpoint"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// ── Full-text search ────────────────────────────────────────────────
+//
+// events_fts indexes the description and narration of the events table and
+// memories_fts the content of the memories table. Both are FTS5 tables over
+// the rows they index, kept in step by triggers, so SaveEvents, SaveMemories,
+// the incremental SaveSocial and the archive pass update them in the same
+// transaction as the rows. The archive pass also adds the events it moves to
+// event_archive_fts, which keeps no copy of their text. Search therefore sees
+// what is saved: every saved event, archived or not, and the living agents'
+// memories as of the last save. Hits come newest first, paged with a
+// KeyCursor.
+
+// Search types.
+const (
+	SearchEvents   = "events"
+	SearchMemories = "memories"
+)
+
+// snippet() marks matches with these and cuts text longer than
+// snippetTokens words.
+const (
+	markOpen      = "<mark>"
+	markClose     = "</mark>"
+	ellipsis      = "…"
+	snippetTokens = 12
+)
+
+// ErrBadSearch is returned for search text with no words in it.
+var ErrBadSearch = errors.New("search text has no words")
+
+// SearchQuery selects hits for Search. Zero filters do not filter.
+type SearchQuery struct {
+	Text         string   // words that must all appear; "a b" is a phrase, a trailing * a prefix
+	Type         string   // SearchEvents (the default) or SearchMemories
+	SettlementID uint64   // events about it, or memories of agents living there as of the last save
+	AgentIDs     []uint64 // memories only: of just these agents; nil does not filter
+	FromTick     uint64   // inclusive
+	ToTick       uint64   // inclusive; 0 means no upper bound
+	Cursor       *KeyCursor
+	Limit        int // default 50
+}
+
+// SearchHit is an event or memory matching a search.
+type SearchHit struct {
+	Type         string              `json:"type"`               // "event" or "memory"
+	EventID      uint64              `json:"event_id,omitempty"` // events only
+	Tick         uint64              `json:"tick"`
+	Category     eventproto.Category `json:"category,omitempty"`
+	AgentID      *uint64             `json:"agent_id,omitempty"` // the event's subject, or whose memory it is
+	SettlementID *uint64             `json:"settlement_id,omitempty"`
+	Text         string              `json:"text"` // the event's description or the memory
+	Narrated     string              `json:"narrated,omitempty"`
+	Importance   float32             `json:"importance,omitempty"`
+	Snippet      string              `json:"snippet"` // the best-matching passage, matches in <mark>
+
+	key uint64 // the event's ID or the memory's content key, after the tick and agent
+}
+
+var (
+	hitTick  = sortKey[SearchHit]{"c.tick", func(h SearchHit) Key { return UintKey(h.Tick) }}
+	hitAgent = sortKey[SearchHit]{"c.agent_id", func(h SearchHit) Key { return UintKey(*h.AgentID) }}
+	hitRow   = sortKey[SearchHit]{"c.id", func(h SearchHit) Key { return UintKey(h.key) }}
+	hitKey   = sortKey[SearchHit]{"c.content_key", func(h SearchHit) Key { return UintKey(h.key) }}
+)
+
+// prepare checks q and returns its terms and sort order.
+func (q *SearchQuery) prepare() ([]searchTerm, keyset[SearchHit], error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	ties := []sortKey[SearchHit]{hitRow}
+	switch q.Type {
+	case "", SearchEvents:
+		q.Type = SearchEvents
+	case SearchMemories:
+		// Memory rows are rewritten on save under new IDs, so a memory is
+		// placed by its agent and content instead. An agent's identical
+		// memories at one tick tie, and a page may end between them.
+		ties = []sortKey[SearchHit]{hitAgent, hitKey}
+	default:
+		return nil, keyset[SearchHit]{}, fmt.Errorf("unknown search type %q", q.Type)
+	}
+	terms, err := parseSearch(q.Text)
+	if err != nil {
+		return nil, keyset[SearchHit]{}, err
+	}
+	k, err := newKeyset(map[string]sortKey[SearchHit]{"tick": hitTick}, "", "tick", false, q.Cursor, ties...)
+	return terms, k, err
+}
+
+func (q SearchQuery) upperTick() uint64 {
+	if q.ToTick == 0 {
+		return math.MaxInt64 // SQLite integers are signed
+	}
+	return q.ToTick
+}
+
+// ── Query text ──────────────────────────────────────────────────────
+
+// searchTerm is one term of the search text: a word or quoted phrase,
+// already split into the words FTS5's tokenizer would make of it.
+type searchTerm struct {
+	words  []string
+	prefix bool // the last word matches any word it begins
+}
+
+// parseSearch splits text into terms. Punctuation only separates words, so
+// no input is an FTS5 syntax error.
+func parseSearch(text string) ([]searchTerm, error) {
+	var terms []searchTerm
+	add := func(s string, prefix bool) {
+		t := searchTerm{prefix: prefix}
+		t.words = words(s)
+		if len(t.words) > 0 {
+			terms = append(terms, t)
+		}
+	}
+	parts := strings.Split(text, `"`)
+	for i, part := range parts {
+		if i%2 == 1 { // inside quotes
+			add(part, i+1 < len(parts) && strings.HasPrefix(parts[i+1], "*"))
+			continue
+		}
+		for _, f := range strings.Fields(part) {
+			add(f, strings.HasSuffix(f, "*"))
+		}
+	}
+	if len(terms) == 0 {
+		return nil, ErrBadSearch
+	}
+	return terms, nil
+}
+
+// matchExpr is the FTS5 query for terms, every one required.
+func matchExpr(terms []searchTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = `"` + strings.Join(t.words, " ") + `"`
+		if t.prefix {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// words splits s into lower-cased runs of letters and digits, as FTS5's
+// default tokenizer does.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// in reports whether t appears in text, a text's words.
+func (t searchTerm) in(text []string) bool {
+	for i := 0; i+len(t.words) <= len(text); i++ {
+		match := true
+		for j, w := range t.words {
+			got := text[i+j]
+			last := j == len(t.words)-1
+			if got != w && !(last && t.prefix && strings.HasPrefix(got, w)) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// matchText reports whether every term appears in one of cols, and returns
+// as the snippet the first column any term appears in.
+func matchText(terms []searchTerm, cols ...string) (string, bool) {
+	split := make([][]string, len(cols))
+	for i, col := range cols {
+		split[i] = words(col)
+	}
+	snippet := -1
+	for _, t := range terms {
+		found := false
+		for i, text := range split {
+			if t.in(text) {
+				found = true
+				if snippet < 0 || i < snippet {
+					snippet = i
+				}
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return cols[snippet], true
+}
+
+// ── SQLite ──────────────────────────────────────────────────────────
+
+// snippetArgs are the arguments of snippet() after the table and column.
+var snippetArgs = fmt.Sprintf("'%s', '%s', '%s', %d", markOpen, markClose, ellipsis, snippetTokens)
+
+type eventHitRow struct {
+	hotEvent
+	Snippet string `db:"snippet"`
+}
+
+type memoryHitRow struct {
+	AgentID    uint64  `db:"agent_id"`
+	Tick       uint64  `db:"tick"`
+	Content    string  `db:"content"`
+	Importance float32 `db:"importance"`
+	ContentKey uint64  `db:"content_key"`
+	Snippet    string  `db:"snippet"`
+}
+
+// Search returns one page of the events or memories matching q, newest
+// first. The returned cursor fetches the next page; it is nil on the last
+// one.
+func (db *DB) Search(q SearchQuery) ([]SearchHit, *KeyCursor, error) {
+	terms, k, err := q.prepare()
+	if err != nil {
+		return nil, nil, err
+	}
+	match := matchExpr(terms)
+	conds := []string{"c.tick >= ?", "c.tick <= ?"}
+	args := []any{q.FromTick, q.upperTick()}
+	if q.SettlementID != 0 {
+		if q.Type == SearchEvents {
+			conds = append(conds, "c.settlement_id = ?")
+		} else {
+			conds = append(conds, "c.agent_id IN (SELECT id FROM agents WHERE home_settlement_id = ?)")
+		}
+		args = append(args, q.SettlementID)
+	}
+	if q.Type == SearchMemories && q.AgentIDs != nil {
+		ids, err := json.Marshal(q.AgentIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		conds, args = append(conds, "c.agent_id IN (SELECT value FROM json_each(?))"), append(args, string(ids))
+	}
+	if c := q.Cursor; c != nil {
+		cond, cargs := k.where(*c)
+		conds, args = append(conds, cond), append(args, cargs...)
+	}
+	where := strings.Join(conds, " AND ") + " " + k.orderBy() + " LIMIT ?"
+	args = append([]any{match}, append(args, q.Limit+1)...)
+
+	var found []SearchHit
+	if q.Type == SearchEvents {
+		var rows []eventHitRow
+		if err := db.conn.Select(&rows, `SELECT c.id, c.tick, c.description, c.category, c.narrated,
+			c.agent_id, c.settlement_id, snippet(events_fts, -1, `+snippetArgs+`) AS snippet
+			FROM events_fts JOIN events c ON c.id = events_fts.rowid
+			WHERE events_fts MATCH ? AND `+where, args...); err != nil {
+			return nil, nil, fmt.Errorf("search events: %w", err)
+		}
+		for _, r := range rows {
+			found = append(found, eventHit(r.hotEvent.event(), uint64(r.ID), r.Snippet))
+		}
+		archived, err := db.searchArchive(match, where, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		found = append(found, archived...)
+	} else {
+		var rows []memoryHitRow
+		if err := db.conn.Select(&rows, `SELECT c.agent_id, c.tick, c.content, c.importance, c.content_key,
+			snippet(memories_fts, 0, `+snippetArgs+`) AS snippet
+			FROM memories_fts JOIN memories c ON c.id = memories_fts.rowid
+			WHERE memories_fts MATCH ? AND `+where, args...); err != nil {
+			return nil, nil, fmt.Errorf("search memories: %w", err)
+		}
+		for _, r := range rows {
+			m := agents.Memory{Tick: r.Tick, Content: r.Content, Importance: r.Importance}
+			found = append(found, memoryHit(r.AgentID, m, r.ContentKey, r.Snippet))
+		}
+	}
+	page, next := k.page(found, q.Limit)
+	return page, next, nil
+}
+
+// searchArchive returns the archived events matching where, as Search's
+// event query does for the events table. The archive index keeps no text,
+// so the hits' text comes from their segments and their snippets from a
+// temporary index of just those texts.
+func (db *DB) searchArchive(match, where string, args []any) ([]SearchHit, error) {
+	var rows []struct {
+		hotEvent
+		SegmentID int64 `db:"segment_id"`
+		Seq       int   `db:"seq"`
+	}
+	if err := db.conn.Select(&rows, `SELECT c.id, c.segment_id, c.seq, c.tick, c.category, c.agent_id, c.settlement_id,
+		'' AS description, '' AS narrated
+		FROM event_archive_fts JOIN (
+			SELECT event_id AS id, segment_id, seq, tick, category, agent_id, settlement_id FROM event_archive
+		) c ON c.id = event_archive_fts.rowid
+		WHERE event_archive_fts MATCH ? AND `+where, args...); err != nil {
+		return nil, fmt.Errorf("search event archive: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // the temporary index goes with it
+	if _, err := tx.Exec("CREATE VIRTUAL TABLE temp.snippet_fts USING fts5(description, narrated)"); err != nil {
+		return nil, fmt.Errorf("search event archive: %w", err)
+	}
+	for i := range rows {
+		r := &rows[i]
+		texts, err := db.segment(r.SegmentID)
+		if err != nil {
+			return nil, err
+		}
+		if r.Seq >= len(texts) {
+			return nil, fmt.Errorf("archived event %d: segment %d has %d events, want index %d", r.ID, r.SegmentID, len(texts), r.Seq)
+		}
+		r.Description, r.Narrated = texts[r.Seq].Description, texts[r.Seq].Narrated
+		if _, err := tx.Exec("INSERT INTO temp.snippet_fts (rowid, description, narrated) VALUES (?, ?, ?)",
+			i, r.Description, r.Narrated); err != nil {
+			return nil, fmt.Errorf("search event archive: %w", err)
+		}
+	}
+	var snippets []struct {
+		Row     int    `db:"rowid"`
+		Snippet string `db:"snippet"`
+	}
+	if err := tx.Select(&snippets, `SELECT rowid, snippet(snippet_fts, -1, `+snippetArgs+`) AS snippet
+		FROM temp.snippet_fts WHERE snippet_fts MATCH ?`, match); err != nil {
+		return nil, fmt.Errorf("search event archive: %w", err)
+	}
+	hits := make([]SearchHit, len(rows))
+	for i, r := range rows {
+		hits[i] = eventHit(r.hotEvent.event(), uint64(r.ID), "")
+	}
+	for _, s := range snippets {
+		hits[s.Row].Snippet = s.Snippet
+	}
+	return hits, nil
+}
+
+func eventHit(e engine.Event, id uint64, snippet string) SearchHit {
+	agentID, settlementID := eventSubjects(e)
+	return SearchHit{
+		Type: "event", EventID: id, Tick: e.Tick, Category: e.Category,
+		AgentID: agentID, SettlementID: settlementID,
+		Text: e.Description, Narrated: e.NarratedDescription, Snippet: snippet, key: id,
+	}
+}
+
+func memoryHit(agentID uint64, m agents.Memory, key uint64, snippet string) SearchHit {
+	return SearchHit{
+		Type: "memory", Tick: m.Tick, AgentID: &agentID,
+		Text: m.Content, Importance: m.Importance, Snippet: snippet, key: key,
+	}
+}
+
+// ── In memory ───────────────────────────────────────────────────────
+
+// Search matches whole words, ignoring case, as the SQLite store does, less
+// its folding of diacritics. Snippets are the matching text as is, unmarked
+// and uncut. Events are numbered from 1 in save order, as the events table
+// numbers them.
+func (m *MemStore) Search(q SearchQuery) ([]SearchHit, *KeyCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	terms, k, err := q.prepare()
+	if err != nil {
+		return nil, nil, err
+	}
+	inRange := func(tick uint64) bool { return tick >= q.FromTick && tick <= q.upperTick() }
+	keep := func(h SearchHit) bool { return q.Cursor == nil || k.after(h, *q.Cursor) }
+
+	var found []SearchHit
+	if q.Type == SearchEvents {
+		for i, e := range m.events {
+			_, settlementID := eventSubjects(e)
+			if !inRange(e.Tick) || q.SettlementID != 0 && (settlementID == nil || *settlementID != q.SettlementID) {
+				continue
+			}
+			if s, ok := matchText(terms, e.Description, e.NarratedDescription); ok {
+				if h := eventHit(e, uint64(i+1), s); keep(h) {
+					found = append(found, h)
+				}
+			}
+		}
+		page, next := k.page(found, q.Limit)
+		return page, next, nil
+	}
+
+	var home map[agents.AgentID]bool
+	if q.SettlementID != 0 {
+		home = map[agents.AgentID]bool{}
+		if m.agents != nil {
+			var list []*agents.Agent
+			if err := decode(m.agents, "agents", func(d *checkpoint.Decoder) { list = decodeAgents(d, ckptAgentsV) }); err != nil {
+				return nil, nil, err
+			}
+			for _, a := range list {
+				home[a.ID] = a.HomeSettID != nil && *a.HomeSettID == q.SettlementID
+			}
+		}
+	}
+	var only map[uint64]bool
+	if q.AgentIDs != nil {
+		only = make(map[uint64]bool, len(q.AgentIDs))
+		for _, id := range q.AgentIDs {
+			only[id] = true
+		}
+	}
+	for id, mems := range m.memories {
+		if home != nil && !home[id] || only != nil && !only[uint64(id)] {
+			continue
+		}
+		for _, mem := range mems {
+			if !inRange(mem.Tick) {
+				continue
+			}
+			if s, ok := matchText(terms, mem.Content); ok {
+				if h := memoryHit(uint64(id), mem, memoryKey(mem.Content), s); keep(h) {
+					found = append(found, h)
+				}
+			}
+		}
+	}
+	page, next := k.page(found, q.Limit)
+	return page, next, nil
+}
@@ -0,0 +1,257 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// TestSearch saves events and memories and searches them in each store by
+// words, phrases and prefixes, with the settlement and tick filters, paging
+// through the hits.
+func TestSearch(t *testing.T) {
+	for name, s := range stores(t) {
+		sim, _ := checkpointWorld(t, 8, 300)
+		sim.Events, sim.UnsavedEvents = nil, 0
+		for i, d := range []string{
+			"Famine strikes Thornwall", // 0
+			"A feast in Ashford",
+			"Thornwall mourns the famine dead",
+			"Famine spreads beyond Thornwall's walls",
+			"Thornfield harvest is plentiful",
+			"The famine in Ashford ends", // 5
+			"Thornwall rebuilds",
+			"Famine struck Thornwall again",
+		} {
+			sim.EmitEvent(engine.Event{Tick: uint64(10 * i), Description: d, Category: "economy",
+				Meta: map[string]any{"settlement_id": uint64(i%2 + 1)}})
+		}
+		home := func(a *agents.Agent) uint64 { return *a.HomeSettID }
+		var here, there []*agents.Agent
+		for _, a := range sim.Agents {
+			a.Memories = nil
+			if home(a) == 1 {
+				here = append(here, a)
+			} else {
+				there = append(there, a)
+			}
+		}
+		agents.AddMemory(here[0], 5, "Saw the battle at Thornwall", 0.9)
+		agents.AddMemory(here[0], 7, "Lost a friend in the battle", 0.8)
+		agents.AddMemory(here[1], 7, "Heard of a battle far away", 0.2)
+		agents.AddMemory(there[0], 9, "Fought in the battle of the ford", 0.9)
+		if err := s.SaveWorldStateFull(sim); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			q    SearchQuery
+			want []uint64 // event IDs; agent IDs for memories
+		}{
+			{SearchQuery{Text: "thornwall famine", Limit: 2}, []uint64{8, 4, 3, 1}},
+			{SearchQuery{Text: `"famine strikes"`}, []uint64{1}},
+			{SearchQuery{Text: "thorn* famine OR", Limit: 1}, nil},
+			{SearchQuery{Text: "thorn*"}, []uint64{8, 7, 5, 4, 3, 1}},
+			{SearchQuery{Text: "famine", SettlementID: 2}, []uint64{8, 6, 4}},
+			{SearchQuery{Text: "famine", FromTick: 20, ToTick: 50}, []uint64{6, 4, 3}},
+			{SearchQuery{Text: "battle", Type: SearchMemories, Limit: 1},
+				[]uint64{uint64(there[0].ID), uint64(here[1].ID), uint64(here[0].ID), uint64(here[0].ID)}},
+			{SearchQuery{Text: "battle", Type: SearchMemories, SettlementID: 1},
+				[]uint64{uint64(here[1].ID), uint64(here[0].ID), uint64(here[0].ID)}},
+			{SearchQuery{Text: "battle thornwall", Type: SearchMemories}, []uint64{uint64(here[0].ID)}},
+			{SearchQuery{Text: "battle", Type: SearchMemories, AgentIDs: []uint64{uint64(here[0].ID), uint64(there[0].ID)}},
+				[]uint64{uint64(there[0].ID), uint64(here[0].ID), uint64(here[0].ID)}},
+			{SearchQuery{Text: "battle", Type: SearchMemories, AgentIDs: []uint64{}}, nil},
+		} {
+			q := tc.q
+			var got []uint64
+			for pages := 0; ; pages++ {
+				if pages > 10 {
+					t.Fatalf("%s: %+v never ended", name, tc.q)
+				}
+				hits, next, err := s.Search(q)
+				if err != nil {
+					t.Fatalf("%s: %+v: %v", name, tc.q, err)
+				}
+				if len(hits) > max(q.Limit, 50) {
+					t.Errorf("%s: %+v: page of %d", name, tc.q, len(hits))
+				}
+				for _, h := range hits {
+					if h.Type == "event" {
+						got = append(got, h.EventID)
+					} else {
+						got = append(got, *h.AgentID)
+					}
+				}
+				if next == nil {
+					break
+				}
+				q.Cursor = next
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("%s: %+v = %v, want %v", name, tc.q, got, tc.want)
+			}
+		}
+
+		hits, _, err := s.Search(SearchQuery{Text: "rebuilds"})
+		if err != nil || len(hits) != 1 {
+			t.Fatalf("%s: rebuilds: %+v, %v", name, hits, err)
+		}
+		if h := hits[0]; h.Text != "Thornwall rebuilds" || h.Tick != 60 || h.Category != "economy" ||
+			h.SettlementID == nil || *h.SettlementID != 1 {
+			t.Errorf("%s: hit = %+v", name, h)
+		}
+
+		// Saves keep the index in step: a forgotten memory is not found.
+		here[0].Memories = here[0].Memories[1:]
+		here[0].Dirty |= agents.DirtyMemories
+		if err := s.SaveSocial(sim.Agents); err != nil {
+			t.Fatal(err)
+		}
+		if hits, _, _ := s.Search(SearchQuery{Text: "thornwall", Type: SearchMemories}); len(hits) != 0 {
+			t.Errorf("%s: forgotten memory found: %+v", name, hits)
+		}
+
+		for _, text := range []string{"", "  ", `"…"`, "--"} {
+			if _, _, err := s.Search(SearchQuery{Text: text}); !errors.Is(err, ErrBadSearch) {
+				t.Errorf("%s: search %q = %v, want ErrBadSearch", name, text, err)
+			}
+		}
+		if _, _, err := s.Search(SearchQuery{Text: "famine", Type: "graves"}); err == nil {
+			t.Errorf("%s: unknown type searched", name)
+		}
+		bad := KeyCursor{Keys: []Key{UintKey(1)}}
+		if _, _, err := s.Search(SearchQuery{Text: "famine", Cursor: &bad}); !errors.Is(err, ErrBadCursor) {
+			t.Errorf("%s: short cursor = %v", name, err)
+		}
+	}
+}
+
+// TestSearchMemoryCursor pages memory hits across a full save, which rewrites
+// every memory row, and checks the second page neither skips nor repeats.
+func TestSearchMemoryCursor(t *testing.T) {
+	for name, s := range stores(t) {
+		sim, _ := checkpointWorld(t, 8, 300)
+		for i, a := range sim.Agents[:10] {
+			a.Memories = nil
+			agents.AddMemory(a, uint64(i%3), fmt.Sprintf("crossed the river %d", i), 0.5)
+			agents.AddMemory(a, uint64(i%3), "crossed the river again", 0.5)
+		}
+		if err := s.SaveWorldStateFull(sim); err != nil {
+			t.Fatal(err)
+		}
+		q := SearchQuery{Text: "river", Type: SearchMemories}
+		want, _, err := s.Search(q)
+		if err != nil || len(want) != 20 {
+			t.Fatalf("%s: all hits = %d, %v", name, len(want), err)
+		}
+		q.Limit = 7
+		got, next, err := s.Search(q)
+		if err != nil || next == nil {
+			t.Fatalf("%s: first page: %v", name, err)
+		}
+		// Older memories first, so the rows the cursor saw move furthest.
+		for _, a := range sim.Agents[:10] {
+			slices.Reverse(a.Memories)
+		}
+		if err := s.SaveWorldStateFull(sim); err != nil {
+			t.Fatal(err)
+		}
+		for q.Cursor = next; q.Cursor != nil; {
+			var hits []SearchHit
+			if hits, q.Cursor, err = s.Search(q); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			got = append(got, hits...)
+		}
+		same := func(a, b SearchHit) bool { return *a.AgentID == *b.AgentID && a.Tick == b.Tick && a.Text == b.Text }
+		if !slices.EqualFunc(got, want, same) {
+			t.Errorf("%s: paged across a save = %d hits, want the same %d", name, len(got), len(want))
+		}
+	}
+}
+
+// TestSearchSnippets checks the SQLite store's snippets: matches marked,
+// phrases as one mark, and long text cut around the hits, at a sentence
+// start if one is close.
+func TestSearchSnippets(t *testing.T) {
+	db := stores(t)["sqlite"].(*DB)
+	var events []engine.Event
+	for i, d := range []string{
+		"Thornwall rebuilds",
+		"Famine struck Thornwall again",
+		"On the ninth day of the long winter the riders of the north came down to Thornwall and burned the granary",
+		"The ninth winter was long and the stores ran low in every house of the valley. Then the riders of the north came down to Thornwall and burned the granary",
+		"For nine long days and nights the rain fell on the hills and the river rose until the riders of the north could not ford it and turned back",
+	} {
+		events = append(events, engine.Event{Tick: uint64(100 + i), Description: d, Category: "military"})
+	}
+	if err := db.SaveEvents(events); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _, _ := db.Search(SearchQuery{Text: "rebuilds"}); len(hits) != 1 || hits[0].Snippet != "Thornwall <mark>rebuilds</mark>" {
+		t.Errorf("word hit = %+v", hits)
+	}
+	if hits, _, _ := db.Search(SearchQuery{Text: `"famine struck" thornwall`}); len(hits) != 1 ||
+		hits[0].Snippet != "<mark>Famine struck</mark> <mark>Thornwall</mark> again" {
+		t.Errorf("phrase hit = %+v", hits)
+	}
+	hits, _, _ := db.Search(SearchQuery{Text: "riders"})
+	var snippets []string
+	for _, h := range hits {
+		snippets = append(snippets, h.Snippet)
+	}
+	if want := []string{
+		"…the river rose until the <mark>riders</mark> of the north could not ford…",
+		"…Then the <mark>riders</mark> of the north came down to Thornwall and burned…",
+		"On the ninth day of the long winter the <mark>riders</mark> of the…",
+	}; !slices.Equal(snippets, want) {
+		t.Errorf("long snippets = %q, want %q", snippets, want)
+	}
+}
+
+// TestSearchArchivedEvents checks archived events are still found, with
+// snippets, beside the ones left in the events table.
+func TestSearchArchivedEvents(t *testing.T) {
+	db := stores(t)["sqlite"].(*DB)
+	var events []engine.Event
+	for i := range 4 {
+		events = append(events, engine.Event{Tick: uint64(1000 * i), Description: fmt.Sprintf("flood %d", i), Category: "disaster"})
+	}
+	if err := db.SaveEvents(events); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ArchiveOldEvents(3000, 1500); err != nil {
+		t.Fatal(err)
+	}
+	q := SearchQuery{Text: "flood", Limit: 3}
+	hits, next, err := db.Search(q)
+	if err != nil || next == nil {
+		t.Fatalf("first page: %v", err)
+	}
+	q.Cursor = next
+	more, next, err := db.Search(q)
+	if err != nil || next != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	hits = append(hits, more...)
+	var got []string
+	for _, h := range hits {
+		got = append(got, fmt.Sprintf("%d %s %s", h.Tick, h.Text, h.Snippet))
+	}
+	if want := []string{
+		"3000 flood 3 <mark>flood</mark> 3",
+		"2000 flood 2 <mark>flood</mark> 2",
+		"1000 flood 1 <mark>flood</mark> 1",
+		"0 flood 0 <mark>flood</mark> 0",
+	}; !slices.Equal(got, want) {
+		t.Errorf("hits after archiving = %q, want %q", got, want)
+	}
+	if hits, _, _ := db.Search(SearchQuery{Text: "flood", FromTick: 500, ToTick: 1500}); len(hits) != 1 || hits[0].Tick != 1000 {
+		t.Errorf("archived hits by tick = %+v", hits)
+	}
+}
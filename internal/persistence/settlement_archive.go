@@ -0,0 +1,304 @@
+package persistence
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// ── Settlement archive ──────────────────────────────────────────────
+//
+// One row per abandoned settlement, written in SaveHistory once the weekly
+// compaction drops it from the world. Settlement IDs are reused after
+// compaction, so a row is keyed by the settlement and the tick it was
+// abandoned; lookups by ID return the most recent. Queries read the tick
+// loop's unsaved entries and the table as one stream, most recently abandoned
+// first unless sorted by another column, paged with a KeyCursor.
+
+// archiveSorts are the SettlementArchive fields QuerySettlementArchives
+// sorts by, named as in its JSON.
+var archiveSorts = map[string]sortKey[engine.SettlementArchive]{
+	"id":              {"settlement_id", func(a engine.SettlementArchive) Key { return UintKey(a.ID) }},
+	"abandoned_tick":  {"abandoned_tick", func(a engine.SettlementArchive) Key { return UintKey(a.AbandonedTick) }},
+	"founded_tick":    {"founded_tick", func(a engine.SettlementArchive) Key { return UintKey(a.FoundedTick) }},
+	"peak_population": {"peak_population", func(a engine.SettlementArchive) Key { return UintKey(uint64(a.PeakPopulation)) }},
+	"peak_tick":       {"peak_tick", func(a engine.SettlementArchive) Key { return UintKey(a.PeakTick) }},
+	"governance":      {"governance", func(a engine.SettlementArchive) Key { return UintKey(uint64(a.Governance)) }},
+	"q":               {"q", func(a engine.SettlementArchive) Key { return IntKey(int64(a.Q)) }},
+	"r":               {"r", func(a engine.SettlementArchive) Key { return IntKey(int64(a.R)) }},
+}
+
+// ArchiveSorts lists the fields ArchiveQuery.Sort accepts.
+func ArchiveSorts() []string { return sortFields(archiveSorts) }
+
+// ArchiveQuery selects archive entries for QuerySettlementArchives. Zero
+// fields do not filter.
+type ArchiveQuery struct {
+	Cause        string
+	SettlementID uint64
+	FromTick     uint64     // inclusive, on the tick of abandonment
+	ToTick       uint64     // inclusive; 0 means no upper bound
+	Sort         string     // an ArchiveSorts field; "" is abandoned_tick
+	Asc          bool       // lowest first; the default is highest first
+	Cursor       *KeyCursor // nil starts at the first entry in sort order
+	Limit        int        // default 50
+}
+
+func (q ArchiveQuery) keyset() (keyset[engine.SettlementArchive], error) {
+	// A settlement ID can be archived more than once.
+	return newKeyset(archiveSorts, q.Sort, "abandoned_tick", q.Asc, q.Cursor, archiveSorts["abandoned_tick"], archiveSorts["id"])
+}
+
+func (q ArchiveQuery) upperTick() uint64 {
+	if q.ToTick == 0 {
+		return math.MaxInt64 // SQLite integers are signed
+	}
+	return q.ToTick
+}
+
+func (q ArchiveQuery) matches(a engine.SettlementArchive, k keyset[engine.SettlementArchive]) bool {
+	switch {
+	case q.Cause != "" && a.Cause != q.Cause,
+		q.SettlementID != 0 && a.ID != q.SettlementID,
+		a.AbandonedTick < q.FromTick,
+		a.AbandonedTick > q.upperTick(),
+		q.Cursor != nil && !k.after(a, *q.Cursor):
+		return false
+	}
+	return true
+}
+
+func archiveKey(a engine.SettlementArchive) [2]uint64 {
+	return [2]uint64{a.ID, a.AbandonedTick}
+}
+
+// page merges entries found in the store with matching unsaved ones and cuts
+// the first page. found must hold every stored match up to one past a page.
+func (q ArchiveQuery) page(found, unsaved []engine.SettlementArchive, k keyset[engine.SettlementArchive]) ([]engine.SettlementArchive, *KeyCursor) {
+	seen := make(map[[2]uint64]bool, len(found))
+	for _, a := range found {
+		seen[archiveKey(a)] = true
+	}
+	for _, a := range unsaved {
+		// The API's view can trail a save by up to a sim-hour.
+		if q.matches(a, k) && !seen[archiveKey(a)] {
+			found = append(found, a)
+		}
+	}
+	return k.page(found, q.Limit)
+}
+
+// cloneArchive copies a's slices, so the MemStore's copy is its own.
+func cloneArchive(a engine.SettlementArchive) engine.SettlementArchive {
+	a.Founders = slices.Clone(a.Founders)
+	a.Governments = slices.Clone(a.Governments)
+	a.Successors = slices.Clone(a.Successors)
+	return a
+}
+
+// ── SQLite ──────────────────────────────────────────────────────────
+
+// archiveRow is a settlement_archive row.
+type archiveRow struct {
+	SettlementID    uint64  `db:"settlement_id"`
+	AbandonedTick   uint64  `db:"abandoned_tick"`
+	Name            string  `db:"name"`
+	Q               int     `db:"q"`
+	R               int     `db:"r"`
+	Governance      uint8   `db:"governance"`
+	FoundedTick     uint64  `db:"founded_tick"`
+	ParentID        *uint64 `db:"parent_id"`
+	ParentName      string  `db:"parent_name"`
+	PeakPopulation  uint32  `db:"peak_population"`
+	PeakTick        uint64  `db:"peak_tick"`
+	Cause           string  `db:"cause"`
+	FoundersJSON    string  `db:"founders_json"`
+	GovernmentsJSON string  `db:"governments_json"`
+	SuccessorsJSON  string  `db:"successors_json"`
+}
+
+const archiveColumns = `settlement_id, abandoned_tick, name, q, r, governance, founded_tick, parent_id,
+	parent_name, peak_population, peak_tick, cause, founders_json, governments_json, successors_json`
+
+func (r archiveRow) archive() (engine.SettlementArchive, error) {
+	a := engine.SettlementArchive{
+		SettlementChronicle: engine.SettlementChronicle{
+			FoundedTick: r.FoundedTick, ParentID: r.ParentID, ParentName: r.ParentName,
+			PeakPopulation: r.PeakPopulation, PeakTick: r.PeakTick,
+			AbandonedTick: r.AbandonedTick, Cause: r.Cause,
+		},
+		ID: r.SettlementID, Name: r.Name, Q: r.Q, R: r.R,
+		Governance: social.GovernanceType(r.Governance), Archived: true,
+	}
+	for _, f := range []struct {
+		name string
+		src  string
+		dst  any
+	}{
+		{"founders", r.FoundersJSON, &a.Founders},
+		{"governments", r.GovernmentsJSON, &a.Governments},
+		{"successors", r.SuccessorsJSON, &a.Successors},
+	} {
+		if f.src == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(f.src), f.dst); err != nil {
+			return a, fmt.Errorf("settlement %d archive %s: %w", r.SettlementID, f.name, err)
+		}
+	}
+	return a, nil
+}
+
+// marshalNonEmpty encodes v as JSON, or "" when n is 0.
+func marshalNonEmpty(v any, n int) (string, error) {
+	if n == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// SaveSettlementArchives writes archive entries. An entry already archived
+// keeps its first row.
+func (db *DB) SaveSettlementArchives(archives []engine.SettlementArchive) error {
+	if len(archives) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, a := range archives {
+		founders, err1 := marshalNonEmpty(a.Founders, len(a.Founders))
+		govs, err2 := marshalNonEmpty(a.Governments, len(a.Governments))
+		succs, err3 := marshalNonEmpty(a.Successors, len(a.Successors))
+		if err := cmp.Or(err1, err2, err3); err != nil {
+			return fmt.Errorf("marshal settlement %d archive: %w", a.ID, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO settlement_archive (`+archiveColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(settlement_id, abandoned_tick) DO NOTHING`,
+			a.ID, a.AbandonedTick, a.Name, a.Q, a.R, a.Governance, a.FoundedTick, a.ParentID,
+			a.ParentName, a.PeakPopulation, a.PeakTick, a.Cause, founders, govs, succs,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadSettlementArchive returns the archive entry of the settlement most
+// recently abandoned under id, or nil if none was.
+func (db *DB) LoadSettlementArchive(id uint64) (*engine.SettlementArchive, error) {
+	var rows []archiveRow
+	if err := db.conn.Select(&rows, `SELECT `+archiveColumns+` FROM settlement_archive
+		WHERE settlement_id = ? ORDER BY abandoned_tick DESC LIMIT 1`, id); err != nil {
+		return nil, fmt.Errorf("load settlement archive: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	a, err := rows[0].archive()
+	return &a, err
+}
+
+// QuerySettlementArchives returns one page of archive entries matching q,
+// in q's sort order, from unsaved (the tick loop's entries not yet written)
+// and the settlement_archive table. The returned cursor fetches the next
+// page; it is nil on the last one.
+func (db *DB) QuerySettlementArchives(q ArchiveQuery, unsaved []engine.SettlementArchive) ([]engine.SettlementArchive, *KeyCursor, error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	k, err := q.keyset()
+	if err != nil {
+		return nil, nil, err
+	}
+	conds := []string{"abandoned_tick >= ?", "abandoned_tick <= ?"}
+	args := []any{q.FromTick, q.upperTick()}
+	if q.Cause != "" {
+		conds = append(conds, "cause = ?")
+		args = append(args, q.Cause)
+	}
+	if q.SettlementID != 0 {
+		conds = append(conds, "settlement_id = ?")
+		args = append(args, q.SettlementID)
+	}
+	if c := q.Cursor; c != nil {
+		cond, cargs := k.where(*c)
+		conds, args = append(conds, cond), append(args, cargs...)
+	}
+	var rows []archiveRow
+	if err := db.conn.Select(&rows, `SELECT `+archiveColumns+` FROM settlement_archive WHERE `+
+		strings.Join(conds, " AND ")+` `+k.orderBy()+` LIMIT ?`,
+		append(args, q.Limit+1)...); err != nil {
+		return nil, nil, fmt.Errorf("query settlement archive: %w", err)
+	}
+	found := make([]engine.SettlementArchive, 0, len(rows))
+	for _, r := range rows {
+		a, err := r.archive()
+		if err != nil {
+			return nil, nil, err
+		}
+		found = append(found, a)
+	}
+	page, next := q.page(found, unsaved, k)
+	return page, next, nil
+}
+
+// ── In memory ───────────────────────────────────────────────────────
+
+func (m *MemStore) SaveSettlementArchives(archives []engine.SettlementArchive) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveArchives(archives)
+	return nil
+}
+
+func (m *MemStore) saveArchives(archives []engine.SettlementArchive) {
+	for _, a := range archives {
+		if _, ok := m.archives[archiveKey(a)]; !ok {
+			m.archives[archiveKey(a)] = cloneArchive(a)
+		}
+	}
+}
+
+func (m *MemStore) LoadSettlementArchive(id uint64) (*engine.SettlementArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var latest *engine.SettlementArchive
+	for _, a := range m.archives {
+		if a.ID == id && (latest == nil || a.AbandonedTick > latest.AbandonedTick) {
+			c := cloneArchive(a)
+			latest = &c
+		}
+	}
+	return latest, nil
+}
+
+func (m *MemStore) QuerySettlementArchives(q ArchiveQuery, unsaved []engine.SettlementArchive) ([]engine.SettlementArchive, *KeyCursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	k, err := q.keyset()
+	if err != nil {
+		return nil, nil, err
+	}
+	var found []engine.SettlementArchive
+	for _, a := range m.archives {
+		if q.matches(a, k) {
+			found = append(found, cloneArchive(a))
+		}
+	}
+	page, next := q.page(found, unsaved, k)
+	return page, next, nil
+}
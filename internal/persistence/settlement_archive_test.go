@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// TestSettlementArchive saves archive entries, including one settlement ID
+// abandoned twice, and pages filtered queries across saved and unsaved
+// entries in each store.
+func TestSettlementArchive(t *testing.T) {
+	for name, s := range stores(t) {
+		var all []engine.SettlementArchive
+		for i := range 10 {
+			parent := uint64(1)
+			all = append(all, engine.SettlementArchive{
+				ID: uint64(100 + i%8), Name: "s", Archived: true,
+				SettlementChronicle: engine.SettlementChronicle{
+					FoundedTick: uint64(10 * i), AbandonedTick: uint64(100 * (i + 1)), ParentID: &parent,
+					Cause:       []string{engine.AbandonDepopulated, engine.AbandonExodus}[i%2],
+					Founders:    []engine.Kin{{ID: 7, Name: "Wren"}},
+					Governments: []engine.GovernanceEra{{Governance: 1, FromTick: 5}},
+					Successors:  []engine.Successor{{ID: 1, Name: "Ashford", People: 4}},
+				},
+			})
+		}
+		sim := &engine.Simulation{UnsavedArchives: slices.Clone(all[:8])}
+		if err := s.SaveHistory(sim); err != nil {
+			t.Fatal(err)
+		}
+		if sim.UnsavedArchives != nil {
+			t.Errorf("%s: history save left %d entries", name, len(sim.UnsavedArchives))
+		}
+		if err := s.SaveSettlementArchives(all[8:9]); err != nil {
+			t.Fatal(err)
+		}
+		unsaved := all[7:]
+
+		a, err := s.LoadSettlementArchive(100)
+		if err != nil || a == nil || a.AbandonedTick != 900 || !a.Archived || a.ParentID == nil ||
+			len(a.Founders) != 1 || len(a.Governments) != 1 || a.Successors[0].People != 4 {
+			t.Errorf("%s: archive 100 = %+v, %v", name, a, err)
+		}
+		if a, _ := s.LoadSettlementArchive(42); a != nil {
+			t.Errorf("%s: unknown settlement archived", name)
+		}
+
+		for _, tc := range []struct {
+			q    ArchiveQuery
+			want []uint64 // abandoned ticks
+		}{
+			{ArchiveQuery{Limit: 100}, []uint64{1000, 900, 800, 700, 600, 500, 400, 300, 200, 100}},
+			{ArchiveQuery{Cause: engine.AbandonExodus, Limit: 2}, []uint64{1000, 800, 600, 400, 200}},
+			{ArchiveQuery{FromTick: 300, ToTick: 700, Limit: 3}, []uint64{700, 600, 500, 400, 300}},
+			{ArchiveQuery{SettlementID: 101, Limit: 1}, []uint64{1000, 200}},
+			// Settlements 100 and 101 were archived twice; abandonment
+			// orders their entries.
+			{ArchiveQuery{Sort: "id", Asc: true, Limit: 3}, []uint64{100, 900, 200, 1000, 300, 400, 500, 600, 700, 800}},
+		} {
+			var got []uint64
+			q := tc.q
+			for pages := 0; ; pages++ {
+				if pages > 20 {
+					t.Fatal("cursor never ended")
+				}
+				page, next, err := s.QuerySettlementArchives(q, unsaved)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, a := range page {
+					got = append(got, a.AbandonedTick)
+				}
+				if next == nil {
+					break
+				}
+				c, err := ParseKeyCursor(next.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				q.Cursor = &c
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("%s: %+v = %v, want %v", name, tc.q, got, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// ── Named snapshots ─────────────────────────────────────────────────
+//
+// A named snapshot is a standalone SQLite file holding everything a boot
+// needs: agents, settlements, factions, hex health and resources, the
+// persistedFields registry, the intervention ledger, memories and
+// relationships. Its own world_meta also records the map parameters, because
+// the map is regenerated at boot rather than stored. Each label can be
+// snapshotted any number of times. Versions count up per label:
+// <label>-v1.db, <label>-v2.db, … A <name>.json file beside each one holds
+// the same metadata, so listing does not have to open every database.
+//
+// Writing happens in two halves. CaptureSnapshot runs on the tick loop. It
+// does the fast save into the live database, copies that with VACUUM INTO,
+// and detaches copies of the agents. Finish runs afterwards on any goroutine.
+// It writes memories and relationships into the copy, the slow part that
+// would otherwise stall the loop for minutes.
+
+// SnapshotFormat is bumped when the snapshot layout changes incompatibly.
+const SnapshotFormat = 1
+
+// SnapshotMeta describes one named snapshot.
+type SnapshotMeta struct {
+	Name      string          `json:"name"` // file stem: <label>-v<version>
+	Label     string          `json:"label"`
+	Version   int             `json:"version"`
+	Format    int             `json:"format"`
+	Tick      uint64          `json:"tick"`
+	SimTime   string          `json:"sim_time"`
+	CreatedAt time.Time       `json:"created_at"`
+	Gen       world.GenConfig `json:"gen"`
+	Size      int64           `json:"size,omitempty"` // bytes; filled in by ListSnapshots
+}
+
+// ErrBadSnapshotLabel rejects labels that are unsafe as file names.
+var ErrBadSnapshotLabel = errors.New("snapshot label must be 1-64 letters, digits, '-' or '_'")
+
+var snapshotLabelRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// PendingSnapshot is a snapshot captured on the tick loop that still needs
+// Finish.
+type PendingSnapshot struct {
+	meta    SnapshotMeta
+	dir     string
+	tmpPath string
+	agents  []*agents.Agent // detached copies for memories and relationships
+}
+
+// CaptureSnapshot starts a named snapshot of sim into dir. Must run on the
+// tick-loop goroutine. Call Finish on the result (off the loop is fine).
+func (db *DB) CaptureSnapshot(sim *engine.Simulation, dir, label string, gen world.GenConfig) (*PendingSnapshot, error) {
+	if !snapshotLabelRe.MatchString(label) {
+		return nil, ErrBadSnapshotLabel
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+	version, err := nextSnapshotVersion(dir, label)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.SaveWorldState(sim); err != nil {
+		return nil, fmt.Errorf("save world state: %w", err)
+	}
+	name := fmt.Sprintf("%s-v%d", label, version)
+	tmpPath := filepath.Join(dir, name+".db.partial")
+	os.Remove(tmpPath) // leftover from a crashed attempt; VACUUM INTO needs a fresh path
+	if err := db.BackupTo(tmpPath); err != nil {
+		return nil, err
+	}
+
+	copies := make([]*agents.Agent, 0, len(sim.Agents))
+	for _, a := range sim.Agents {
+		if a.Alive {
+			copies = append(copies, a.Clone())
+		}
+	}
+	return &PendingSnapshot{
+		meta: SnapshotMeta{
+			Name:      name,
+			Label:     label,
+			Version:   version,
+			Format:    SnapshotFormat,
+			Tick:      sim.CurrentTick(),
+			SimTime:   engine.SimTime(sim.CurrentTick()),
+			CreatedAt: time.Now().UTC(),
+			Gen:       gen,
+		},
+		dir:     dir,
+		tmpPath: tmpPath,
+		agents:  copies,
+	}, nil
+}
+
+// Finish writes the rest of the snapshot and moves it into place.
+func (p *PendingSnapshot) Finish() (SnapshotMeta, error) {
+	fail := func(err error) (SnapshotMeta, error) {
+		os.Remove(p.tmpPath)
+		return SnapshotMeta{}, err
+	}
+	snap, err := Open(p.tmpPath)
+	if err != nil {
+		return fail(err)
+	}
+	metaJSON, _ := json.Marshal(p.meta)
+	err = snap.SaveMemories(p.agents)
+	if err == nil {
+		err = snap.SaveRelationships(p.agents)
+	}
+	if err == nil {
+		err = snap.SaveMeta("snapshot", string(metaJSON))
+	}
+	if err == nil {
+		// Fold the WAL back in so the file is complete on its own.
+		_, err = snap.conn.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	}
+	if cerr := snap.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fail(fmt.Errorf("write snapshot %s: %w", p.meta.Name, err))
+	}
+
+	path := filepath.Join(p.dir, p.meta.Name+".db")
+	if err := os.Rename(p.tmpPath, path); err != nil {
+		return fail(err)
+	}
+	if err := os.WriteFile(filepath.Join(p.dir, p.meta.Name+".json"), metaJSON, 0644); err != nil {
+		return SnapshotMeta{}, err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		os.Remove(p.tmpPath + suffix)
+	}
+	slog.Info("snapshot written", "name", p.meta.Name, "tick", p.meta.Tick, "path", path)
+	return p.meta, nil
+}
+
+// ListSnapshots returns the snapshots in dir, oldest first. A missing dir is
+// an empty list.
+func ListSnapshots(dir string) ([]SnapshotMeta, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []SnapshotMeta
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var m SnapshotMeta
+		if err := json.Unmarshal(b, &m); err != nil {
+			slog.Warn("skipping unreadable snapshot metadata", "path", p, "error", err)
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(dir, m.Name+".db"))
+		if err != nil {
+			continue // metadata without its database
+		}
+		m.Size = fi.Size()
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// SnapshotPath returns the database file of the named snapshot in dir, or
+// an error if there is no such snapshot. Names come from ListSnapshots, so a
+// request path can never reach outside dir.
+func SnapshotPath(dir, name string) (string, error) {
+	snaps, err := ListSnapshots(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range snaps {
+		if m.Name == name {
+			return filepath.Join(dir, m.Name+".db"), nil
+		}
+	}
+	return "", fmt.Errorf("snapshot %q not found", name)
+}
+
+// ReadSnapshotMeta reads the metadata stored inside a snapshot file.
+func ReadSnapshotMeta(path string) (SnapshotMeta, error) {
+	if _, err := os.Stat(path); err != nil {
+		return SnapshotMeta{}, err
+	}
+	db, err := Open(path)
+	if err != nil {
+		return SnapshotMeta{}, err
+	}
+	defer db.Close()
+	s, err := db.GetMeta("snapshot")
+	if err != nil {
+		return SnapshotMeta{}, fmt.Errorf("%s is not a named snapshot: %w", path, err)
+	}
+	var m SnapshotMeta
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return SnapshotMeta{}, fmt.Errorf("parse snapshot metadata: %w", err)
+	}
+	if m.Format > SnapshotFormat {
+		return SnapshotMeta{}, fmt.Errorf("snapshot format %d is newer than this build (%d)", m.Format, SnapshotFormat)
+	}
+	return m, nil
+}
+
+// nextSnapshotVersion returns one past the highest version of label in dir,
+// counting snapshots still being finished. Only <label>-v<N>.db (and its
+// .partial) counts: labels may contain "-v", so a glob on the label would
+// also match another label's files.
+func nextSnapshotVersion(dir, label string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, label+"-v*.db*"))
+	if err != nil {
+		return 0, err
+	}
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(label) + `-v([0-9]+)\.db(\.partial)?$`)
+	version := 1
+	for _, p := range paths {
+		m := re.FindStringSubmatch(filepath.Base(p))
+		if m == nil {
+			continue
+		}
+		if v, err := strconv.Atoi(m[1]); err == nil && v >= version {
+			version = v + 1
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSnapshotDirectory checks versioning and listing against a hand-built
+// snapshot directory (no database needed).
+func TestSnapshotDirectory(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	meta := func(name, label string, version int, created time.Time) {
+		t.Helper()
+		b, _ := json.Marshal(SnapshotMeta{Name: name, Label: label, Version: version, CreatedAt: created})
+		write(name+".json", string(b))
+	}
+
+	if v, _ := nextSnapshotVersion(dir, "spring"); v != 1 {
+		t.Errorf("empty dir: version %d, want 1", v)
+	}
+	if snaps, err := ListSnapshots(filepath.Join(dir, "missing")); err != nil || len(snaps) != 0 {
+		t.Errorf("missing dir: %v, %v", snaps, err)
+	}
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	write("spring-v1.db", "one")
+	meta("spring-v1", "spring", 1, t0.Add(time.Hour))
+	write("spring-v3.db", "three!")
+	meta("spring-v3", "spring", 3, t0.Add(2*time.Hour))
+	write("spring-v4.db.partial", "") // still being finished
+	write("springtime-v9.db", "")     // another label
+	write("spring-v7-v1.db", "")      // label "spring-v7"
+	write("spring-v8.db-journal", "") // not a snapshot
+	write("autumn-v1.db", "a")
+	meta("autumn-v1", "autumn", 1, t0)
+	meta("orphan-v1", "orphan", 1, t0) // metadata without a database
+
+	if v, _ := nextSnapshotVersion(dir, "spring"); v != 5 {
+		t.Errorf("next spring version %d, want 5", v)
+	}
+
+	snaps, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range snaps {
+		names = append(names, m.Name)
+	}
+	if len(names) != 3 || names[0] != "autumn-v1" || names[1] != "spring-v1" || names[2] != "spring-v3" {
+		t.Fatalf("listed %v", names)
+	}
+	if snaps[2].Size != 6 {
+		t.Errorf("spring-v3 size %d, want 6", snaps[2].Size)
+	}
+
+	if p, err := SnapshotPath(dir, "spring-v3"); err != nil || p != filepath.Join(dir, "spring-v3.db") {
+		t.Errorf("SnapshotPath = %q, %v", p, err)
+	}
+	for _, bad := range []string{"orphan-v1", "../spring-v3", "spring-v4"} {
+		if _, err := SnapshotPath(dir, bad); err == nil {
+			t.Errorf("SnapshotPath(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"encoding/json"
+
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// govNames maps social.GovernanceType to the label stored in
+// settlement_stats_history.
+var govNames = [4]string{"Monarchy", "Council", "Merchant Republic", "Commune"}
+
+// BuildStatsRow captures the world-level daily statistics at tick. Call it on
+// the tick-loop goroutine right after TickDay so sim.Stats is fresh.
+func BuildStatsRow(sim *engine.Simulation, tick uint64) StatsRow {
+	occData := struct {
+		Counts           [10]int     `json:"counts"`
+		Sat              [10]float32 `json:"sat"`
+		ProducersWorking int         `json:"producers_working"`
+		ProducersIdle    int         `json:"producers_idle"`
+	}{
+		Counts:           sim.Stats.OccupationCounts,
+		Sat:              sim.Stats.OccupationSat,
+		ProducersWorking: sim.Stats.ProducersWorking,
+		ProducersIdle:    sim.Stats.ProducersIdle,
+	}
+	occJSON, _ := json.Marshal(occData)
+
+	bottom50, top10 := sim.WealthDistribution()
+	return StatsRow{
+		Tick:            tick,
+		Population:      sim.Stats.TotalPopulation,
+		TotalWealth:     sim.Stats.TotalWealth,
+		AvgMood:         float64(sim.Stats.AvgMood),
+		AvgSurvival:     float64(sim.Stats.AvgSurvival),
+		Births:          sim.Stats.Births,
+		Deaths:          sim.Stats.Deaths,
+		TradeVolume:     sim.Stats.TradeVolume,
+		AvgCoherence:    sim.AvgCoherence(),
+		SettlementCount: len(sim.Settlements),
+		Gini:            sim.GiniCoefficient(),
+		AvgSatisfaction: float64(sim.Stats.AvgSatisfaction),
+		AvgAlignment:    float64(sim.Stats.AvgAlignment),
+		OccupationJSON:  string(occJSON),
+		Bottom50Share:   bottom50,
+		Top10Share:      top10,
+	}
+}
+
+// BuildSettlementStatsRows captures one daily row per settlement at tick, in
+// sim.Settlements order.
+func BuildSettlementStatsRows(sim *engine.Simulation, tick uint64) []SettlementStatsRow {
+	rows := make([]SettlementStatsRow, 0, len(sim.Settlements))
+	for _, sett := range sim.Settlements {
+		agents := sim.SettlementAgents[sett.ID]
+		pop := 0
+		totalSat := float64(0)
+		for _, a := range agents {
+			if a.Alive {
+				pop++
+				totalSat += float64(a.Wellbeing.Satisfaction)
+			}
+		}
+		avgSat := float64(0)
+		if pop > 0 {
+			avgSat = totalSat / float64(pop)
+		}
+		cap, pressure := sim.SettlementCarryingCapacity(sett.ID)
+		// Count trade volume from relations.
+		tradeVol := 0
+		for key, rel := range sim.Relations {
+			if key.A == sett.ID || key.B == sett.ID {
+				tradeVol += int(rel.Trade)
+			}
+		}
+		govName := "Unknown"
+		if int(sett.Governance) < len(govNames) {
+			govName = govNames[sett.Governance]
+		}
+		rows = append(rows, SettlementStatsRow{
+			Tick:               tick,
+			SettlementID:       sett.ID,
+			Population:         pop,
+			Treasury:           sett.Treasury,
+			AvgSatisfaction:    avgSat,
+			TradeVolume:        tradeVol,
+			Governance:         govName,
+			GovernanceScore:    sett.GovernanceScore,
+			CarryingCapacity:   cap,
+			PopulationPressure: pressure,
+		})
+	}
+	return rows
+}
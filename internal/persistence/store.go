@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// Store is what the server and the tick loop need from persistence: world
+// state to save and boot from, the event history, stats, settlement and
+// price history, biographies, the family tree, the graveyard, the settlement
+// archive, the intervention ledger and full-text search. DB is the SQLite implementation;
+// MemStore keeps everything in memory for tests.
+//
+// Operations that only make sense for a database file (migrations, backups,
+// named snapshots) stay on DB.
+type Store interface {
+	MetaStore
+
+	// World state.
+	SaveWorldState(sim *engine.Simulation) error
+	SaveWorldStateFull(sim *engine.Simulation) error
+	SaveSocial(agentList []*agents.Agent) error
+	SaveTuning(sim *engine.Simulation) error
+	HasWorldState() bool
+	HasFactions() bool
+	LoadAgents() ([]*agents.Agent, error)
+	LoadSettlements() ([]*social.Settlement, error)
+	LoadFactions() ([]*social.Faction, error)
+	LoadMemories(agentIndex map[agents.AgentID]*agents.Agent) error
+	LoadRelationships(agentIndex map[agents.AgentID]*agents.Agent) error
+	RestoreLatePersisted(sim *engine.Simulation)
+
+	// Events and the intervention ledger.
+	SaveHistory(sim *engine.Simulation) error
+	RecentEvents(limit int) ([]engine.Event, error)
+	QueryEvents(q EventQuery, unsaved []engine.Event) ([]engine.Event, *EventCursor, error)
+	ArchiveOldEvents(currentTick, keepTicks uint64) (int64, error)
+	SaveInterventions(records []*engine.InterventionRecord) error
+	LoadInterventions(settledLimit int) ([]*engine.InterventionRecord, error)
+
+	// Stats, settlement and price history.
+	SaveStatsSnapshot(row StatsRow) error
+	LoadStatsHistory(fromTick, toTick uint64, limit int) ([]StatsRow, error)
+	SaveSettlementStats(rows []SettlementStatsRow) error
+	LoadSettlementHistory(settlementID uint64, limit int) ([]SettlementStatsRow, error)
+	SavePriceBars(bars []engine.PriceBar) error
+	QueryPrices(q PriceQuery) ([]engine.PriceBar, error)
+	DownsamplePrices(currentTick, keepTicks uint64) (int64, error)
+
+	// The family tree (see lineage.go). SaveHistory writes it too.
+	SaveLineage(records []engine.LineageRecord) error
+	LineageKin(ids []uint64) ([]LineagePerson, []LineageEdge, error)
+	LoadLineage() ([]LineagePerson, []LineageEdge, error)
+
+	// The graveyard (see graveyard.go). SaveHistory writes it too.
+	SaveGraves(graves []engine.Grave) error
+	LoadGrave(id uint64) (*engine.Grave, error)
+	QueryGraves(q GraveQuery, unsaved []engine.Grave) ([]engine.Grave, *KeyCursor, error)
+
+	// The settlement archive (see settlement_archive.go). SaveHistory writes
+	// it too.
+	SaveSettlementArchives(archives []engine.SettlementArchive) error
+	LoadSettlementArchive(id uint64) (*engine.SettlementArchive, error)
+	QuerySettlementArchives(q ArchiveQuery, unsaved []engine.SettlementArchive) ([]engine.SettlementArchive, *KeyCursor, error)
+
+	// Full-text search over saved events and memories (see search.go).
+	Search(q SearchQuery) ([]SearchHit, *KeyCursor, error)
+
+	// Biographies.
+	SaveBiography(agentID uint64, biography, generatedAt string) error
+	LoadBiographies() ([]BiographyRow, error)
+
+	Close() error
+}
+
+var (
+	_ Store = (*DB)(nil)
+	_ Store = (*MemStore)(nil)
+)
@@ -0,0 +1,192 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+)
+
+// stores returns one of each Store implementation, empty.
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "world.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Store{"sqlite": db, "memory": NewMemStore()}
+}
+
+// storeOps drives a store through a save, a boot and the history reads the
+// API serves, recording what comes back. Both implementations must record
+// the same.
+func storeOps(t *testing.T, s Store) []string {
+	t.Helper()
+	var out []string
+	note := func(format string, args ...any) { out = append(out, fmt.Sprintf(format, args...)) }
+
+	sim, _ := checkpointWorld(t, 8, 300)
+	befriend(sim.Agents)
+	sim.Agents[3].Alive = false
+	sim.Events, sim.UnsavedEvents = nil, 0
+	for i := range 40 {
+		sim.EmitEvent(engine.Event{Tick: uint64(i / 4), Description: fmt.Sprintf("e%d", i), Category: "social",
+			Meta: map[string]any{"agent_id": uint64(i%5 + 1)}})
+	}
+	sim.Interventions = []*engine.InterventionRecord{
+		{ID: 1, Status: engine.InterventionApplied}, {ID: 2, Status: engine.InterventionPending},
+		{ID: 3, Status: engine.InterventionFailed},
+	}
+	note("has world before save: %v", s.HasWorldState())
+	if err := s.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+	note("has world: %v, factions: %v, unsaved after save: %d", s.HasWorldState(), s.HasFactions(), sim.UnsavedEvents)
+
+	// Boot the way cmd/worldsim does.
+	loaded, err := s.LoadAgents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := make(map[agents.AgentID]*agents.Agent)
+	for _, a := range loaded {
+		index[a.ID] = a
+	}
+	if err := s.LoadMemories(index); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadRelationships(index); err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(loaded, func(a, b *agents.Agent) int { return int(a.ID) - int(b.ID) })
+	for _, a := range loaded {
+		note("agent %d %s wealth=%d needs=%.3f mems=%d rels=%d", a.ID, a.Name, a.Wealth, a.Needs.Survival,
+			len(a.Memories), len(a.Relationships))
+	}
+	setts, err := s.LoadSettlements()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range setts {
+		note("settlement %d %s pop=%d treasury=%d", st.ID, st.Name, st.Population, st.Treasury)
+	}
+	factions, err := s.LoadFactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	note("factions: %d", len(factions))
+	for _, key := range []string{"last_tick", "season", "spawner"} {
+		v, err := s.GetMeta(key)
+		note("meta %s=%q %v", key, v, err == nil)
+	}
+	records, err := s.LoadInterventions(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		note("intervention %d %s", r.ID, r.Status)
+	}
+
+	// History.
+	recent, err := s.RecentEvents(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range recent {
+		note("recent %d %s %v", e.Tick, e.Description, e.Meta)
+	}
+	sim.EmitEvent(engine.Event{Tick: 10, Description: "unsaved", Category: "social", Meta: map[string]any{"agent_id": uint64(2)}})
+	q := EventQuery{AgentID: 2, Limit: 3}
+	for {
+		page, next, err := s.QueryEvents(q, sim.UnsavedEventTail())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range page {
+			note("page %d %s %v", e.Tick, e.Description, e.Meta)
+		}
+		if next == nil {
+			break
+		}
+		q.Cursor = next
+	}
+
+	for tick := range uint64(5) {
+		if err := s.SaveStatsSnapshot(StatsRow{Tick: tick * 1440, Population: int(tick)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveSettlementStats([]SettlementStatsRow{{Tick: tick * 1440, SettlementID: 1, Population: int(tick)},
+			{Tick: tick * 1440, SettlementID: 2}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.SaveStatsSnapshot(StatsRow{Tick: 1440, Population: 99}) // replaces
+	stats, _ := s.LoadStatsHistory(1440, 4*1440, 3)
+	note("stats %+v", stats)
+	hist, _ := s.LoadSettlementHistory(1, 2)
+	note("settlement history %+v", hist)
+
+	s.SaveBiography(7, "first", "t1")
+	s.SaveBiography(5, "other", "t1")
+	s.SaveBiography(7, "second", "t2")
+	bios, _ := s.LoadBiographies()
+	slices.SortFunc(bios, func(a, b BiographyRow) int { return int(a.AgentID) - int(b.AgentID) })
+	note("biographies %+v", bios)
+	return out
+}
+
+// TestStoresAgree runs the same operations against the SQLite store and
+// the in-memory one.
+func TestStoresAgree(t *testing.T) {
+	results := map[string][]string{}
+	for name, s := range stores(t) {
+		results[name] = storeOps(t, s)
+	}
+	sqlite, memory := results["sqlite"], results["memory"]
+	if reflect.DeepEqual(sqlite, memory) {
+		return
+	}
+	for i := range max(len(sqlite), len(memory)) {
+		var a, b string
+		if i < len(sqlite) {
+			a = sqlite[i]
+		}
+		if i < len(memory) {
+			b = memory[i]
+		}
+		if a != b {
+			t.Fatalf("line %d differs:\nsqlite: %s\nmemory: %s", i, a, b)
+		}
+	}
+}
+
+// TestMemStoreDetachesAgents checks a MemStore hands out copies: changing
+// a loaded or saved agent does not reach what it stores.
+func TestMemStoreDetachesAgents(t *testing.T) {
+	sim, _ := checkpointWorld(t, 8, 300)
+	befriend(sim.Agents)
+	s := NewMemStore()
+	if err := s.SaveWorldStateFull(sim); err != nil {
+		t.Fatal(err)
+	}
+	first := sim.Agents[0]
+	first.Wealth = 12345
+	first.Relationships[0].Trust = 0.99
+
+	loaded, _ := s.LoadAgents()
+	index := map[agents.AgentID]*agents.Agent{loaded[0].ID: loaded[0]}
+	s.LoadRelationships(index)
+	if loaded[0].Wealth == 12345 || loaded[0].Relationships[0].Trust == 0.99 {
+		t.Fatal("saved agent shares memory with the live one")
+	}
+	loaded[0].Wealth = 54321
+	again, _ := s.LoadAgents()
+	if again[0].Wealth == 54321 {
+		t.Fatal("loaded agent shares memory with the store")
+	}
+}
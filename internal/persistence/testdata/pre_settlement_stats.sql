@@ -0,0 +1,133 @@
+-- Layout of a database written after factions but before
+-- settlement_stats_history, event subjects, age_months and the wealth shares.
+CREATE TABLE agents (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	age INTEGER NOT NULL,
+	sex INTEGER NOT NULL,
+	health REAL NOT NULL,
+	pos_q INTEGER NOT NULL,
+	pos_r INTEGER NOT NULL,
+	home_settlement_id INTEGER,
+	occupation INTEGER NOT NULL,
+	wealth INTEGER NOT NULL,
+	tier INTEGER NOT NULL,
+	mood REAL NOT NULL,
+	alive INTEGER NOT NULL,
+	born_tick INTEGER NOT NULL,
+	role INTEGER NOT NULL,
+	faction_id INTEGER,
+	archetype TEXT,
+	skills_json TEXT NOT NULL,
+	needs_json TEXT NOT NULL,
+	soul_json TEXT NOT NULL,
+	inventory_json TEXT NOT NULL,
+	satisfaction REAL NOT NULL DEFAULT 0,
+	alignment REAL NOT NULL DEFAULT 0,
+	last_work_tick INTEGER NOT NULL DEFAULT 0,
+	production_progress REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE settlements (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	pos_q INTEGER NOT NULL,
+	pos_r INTEGER NOT NULL,
+	population INTEGER NOT NULL,
+	governance INTEGER NOT NULL,
+	tax_rate REAL NOT NULL,
+	treasury INTEGER NOT NULL,
+	governance_score REAL NOT NULL,
+	cultural_memory REAL NOT NULL,
+	culture_tradition REAL NOT NULL,
+	culture_openness REAL NOT NULL,
+	culture_militarism REAL NOT NULL,
+	wall_level INTEGER NOT NULL,
+	road_level INTEGER NOT NULL,
+	market_level INTEGER NOT NULL,
+	abandoned INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tick INTEGER NOT NULL,
+	description TEXT NOT NULL,
+	category TEXT NOT NULL,
+	narrated TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE world_meta (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE biographies (
+	agent_id INTEGER PRIMARY KEY,
+	biography TEXT NOT NULL,
+	generated_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE memories (
+	agent_id INTEGER NOT NULL,
+	tick INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	importance REAL NOT NULL
+);
+
+CREATE TABLE stats_history (
+	tick INTEGER PRIMARY KEY,
+	population INTEGER NOT NULL,
+	total_wealth INTEGER NOT NULL,
+	avg_mood REAL NOT NULL,
+	avg_survival REAL NOT NULL,
+	births INTEGER NOT NULL,
+	deaths INTEGER NOT NULL,
+	trade_volume INTEGER NOT NULL,
+	avg_coherence REAL NOT NULL,
+	settlement_count INTEGER NOT NULL,
+	gini REAL NOT NULL,
+	avg_satisfaction REAL NOT NULL DEFAULT 0,
+	avg_alignment REAL NOT NULL DEFAULT 0,
+	occupation_json TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE relationships (
+	agent_id INTEGER NOT NULL,
+	target_id INTEGER NOT NULL,
+	sentiment REAL NOT NULL,
+	trust REAL NOT NULL
+);
+
+CREATE TABLE factions (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	kind INTEGER NOT NULL,
+	leader_id INTEGER,
+	treasury INTEGER NOT NULL,
+	tax_preference REAL NOT NULL,
+	trade_preference REAL NOT NULL,
+	military_preference REAL NOT NULL,
+	influence_json TEXT NOT NULL,
+	relations_json TEXT NOT NULL
+);
+
+CREATE INDEX idx_events_tick ON events(tick);
+CREATE INDEX idx_agents_settlement ON agents(home_settlement_id);
+CREATE INDEX idx_agents_alive ON agents(alive);
+CREATE INDEX idx_memories_agent ON memories(agent_id);
+CREATE INDEX idx_relationships_agent ON relationships(agent_id);
+
+INSERT INTO agents VALUES
+	(1, 'Ada', 30, 1, 0.9, 0, 0, 1, 2, 120, 0, 0.6, 1, 0, 0, 1, NULL, '{}', '{}', '{}', '{}', 0.55, 0.3, 90, 0.5),
+	(2, 'Bram', 41, 0, 0.7, 0, 0, 1, 1, 80, 0, 0.4, 1, 0, 0, NULL, NULL, '{}', '{}', '{}', '{}', 0.4, 0.2, 0, 0);
+INSERT INTO settlements VALUES
+	(1, 'Oldmere', 0, 0, 2, 0, 0.1, 500, 0.6, 0, 0.5, 0.5, 0.5, 0, 0, 1, 0);
+INSERT INTO factions VALUES
+	(1, 'Merchants Guild', 1, 1, 300, 0.3, 0.8, 0.1, '{}', '{}');
+INSERT INTO events (tick, description, category) VALUES
+	(100, 'Ada was born', 'birth'),
+	(200, 'Oldmere held a market', 'economy');
+INSERT INTO world_meta VALUES ('last_tick', '200');
+INSERT INTO stats_history (tick, population, total_wealth, avg_mood, avg_survival, births, deaths,
+	trade_volume, avg_coherence, settlement_count, gini, avg_satisfaction) VALUES
+	(1440, 2, 200, 0.5, 0.8, 1, 0, 3, 0.4, 1, 0.2, 0.5);
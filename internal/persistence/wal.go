@@ -0,0 +1,354 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/checkpoint"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+)
+
+// ── Write-ahead journal ─────────────────────────────────────────────
+//
+// The world is saved once a sim-day. Between saves the engine reports its
+// domain changes (births, deaths, foundings, abandonments, transfers between
+// treasuries, hex captures; see engine/changes.go) and the WAL appends each
+// one to a file beside the database, fsyncing about once a second. On the
+// next start RecoverWAL replays the file on top of the save, so a kill -9 or
+// an OOM costs at most the last second of those changes. Every successful
+// save truncates the file.
+//
+// Changes carry the sequence number the simulation gave them, and the save
+// records the last one it covers (ChangeSeq, in world_meta). Recovery skips
+// what the save already holds, so a crash between a save and the truncation
+// that follows it replays nothing twice.
+//
+// File layout (integers little-endian):
+//
+//	header  magic "WSIMWAL1" | agents schema uint16
+//	record  length uint32 | crc32c uint32 | payload
+//	...
+//
+// A record whose length runs past the end of the file or whose checksum does
+// not match is a torn write from the crash. Recovery stops there.
+
+var walMagic = [8]byte{'W', 'S', 'I', 'M', 'W', 'A', 'L', '1'}
+
+const (
+	walHeaderLen = 10
+	walFrameLen  = 8
+	walMaxRecord = 64 << 20 // bounds a record, so a corrupt length is caught
+)
+
+// DefaultWALSync is how often a WAL fsyncs.
+const DefaultWALSync = time.Second
+
+var walCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// WAL appends domain changes to the journal file. It is the engine's
+// ChangeLog; all methods run on the tick-loop goroutine except Close, which
+// runs after the loop stops.
+type WAL struct {
+	path string
+	f    *os.File
+	w    *bufio.Writer
+
+	syncEvery time.Duration
+	lastSync  time.Time
+	err       error // first write failure; journaling stops there
+}
+
+var _ engine.ChangeLog = (*WAL)(nil)
+
+// OpenWAL opens the journal at path for appending, creating it if needed.
+// Recover from it first: records already in the file stay.
+func OpenWAL(path string) (*WAL, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w := &WAL{path: path, f: f, w: bufio.NewWriterSize(f, 64<<10), syncEvery: DefaultWALSync, lastSync: time.Now()}
+	if fi.Size() == 0 {
+		w.writeHeader()
+		if err := w.Sync(); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (w *WAL) writeHeader() {
+	var h [walHeaderLen]byte
+	copy(h[:], walMagic[:])
+	binary.LittleEndian.PutUint16(h[8:], ckptAgentsV)
+	w.w.Write(h[:])
+}
+
+// Record appends c. Nothing reaches the disk until the next sync.
+func (w *WAL) Record(c engine.Change) {
+	if w.err != nil {
+		return
+	}
+	payload := encodeChange(c)
+	var frame [walFrameLen]byte
+	binary.LittleEndian.PutUint32(frame[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.Checksum(payload, walCRC))
+	w.w.Write(frame[:])
+	if _, err := w.w.Write(payload); err != nil {
+		w.fail(err)
+	}
+}
+
+func (w *WAL) fail(err error) {
+	w.err = err
+	slog.Error("journal write failed; changes until the next save are not crash-safe",
+		"path", w.path, "error", err)
+}
+
+// MaybeSync syncs when the last sync is more than the sync interval ago.
+// Call it every tick.
+func (w *WAL) MaybeSync() {
+	if w.w.Buffered() == 0 || time.Since(w.lastSync) < w.syncEvery {
+		return
+	}
+	if err := w.Sync(); err != nil && w.err == nil {
+		w.fail(err)
+	}
+}
+
+// Sync writes buffered records to the file and fsyncs it.
+func (w *WAL) Sync() error {
+	w.lastSync = time.Now()
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("flush journal: %w", err)
+	}
+	if err := w.f.Sync(); err != nil {
+		return fmt.Errorf("sync journal: %w", err)
+	}
+	return nil
+}
+
+// Reset empties the journal once a save holds everything in it. A write
+// failure is cleared: journaling starts over.
+func (w *WAL) Reset() error {
+	w.w.Reset(w.f)
+	if err := w.f.Truncate(0); err != nil {
+		return fmt.Errorf("truncate journal: %w", err)
+	}
+	w.err = nil
+	w.writeHeader()
+	return w.Sync()
+}
+
+// Close syncs and closes the journal.
+func (w *WAL) Close() error {
+	err := w.Sync()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// RecoveryReport describes what RecoverWAL found in a journal.
+type RecoveryReport struct {
+	Path         string         `json:"path"`
+	Records      int            `json:"records"`       // intact records in the journal
+	AlreadySaved int            `json:"already_saved"` // covered by the save booted from
+	Applied      map[string]int `json:"applied"`       // replayed, by change kind
+	Unchanged    int            `json:"unchanged"`     // newer than the save, but already reflected
+	FromTick     uint64         `json:"from_tick"`     // the save's tick
+	ToTick       uint64         `json:"to_tick"`       // tick of the last replayed change
+	SkippedTicks uint64         `json:"skipped_ticks"` // ticks the clock jumped without simulating them
+	TornBytes    int64          `json:"torn_bytes"`    // incomplete tail discarded
+	RecoveredAt  time.Time      `json:"recovered_at"`
+	Took         string         `json:"took"`
+}
+
+// Recovered returns how many changes were replayed.
+func (r *RecoveryReport) Recovered() int {
+	n := 0
+	for _, c := range r.Applied {
+		n += c
+	}
+	return n
+}
+
+// RecoverWAL replays the journal at path onto sim, a world just booted from
+// the last save. Changes the save already holds are skipped, and the clock
+// moves on to the tick of the last replayed change, where the crashed run
+// stopped. A missing journal is an empty one. A torn tail is reported and cut
+// off; only a file that is not a journal at all is an error.
+//
+// Recovery restores the journaled changes, not the ticks between them. The
+// ticks from the save to the last change are not re-run: hunger, markets,
+// relationships and everything else the journal does not carry stay as they
+// were at the save, and the clock jumps over them (report.SkippedTicks).
+// Re-running them instead would simulate the recovered births and deaths a
+// second time, differently.
+func RecoverWAL(path string, sim *engine.Simulation) (*RecoveryReport, error) {
+	start := time.Now()
+	report := &RecoveryReport{Path: path, Applied: map[string]int{}, FromTick: sim.LastTick,
+		ToTick: sim.LastTick, RecoveredAt: start.UTC()}
+	defer func() { report.Took = time.Since(start).Round(time.Millisecond).String() }()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	if len(data) < walHeaderLen {
+		// Cut short while being reset: nothing in it yet.
+		report.TornBytes = int64(len(data))
+		return report, os.Truncate(path, 0)
+	}
+	if [8]byte(data[:8]) != walMagic {
+		return nil, fmt.Errorf("%s is not a world journal", path)
+	}
+	agentsV := binary.LittleEndian.Uint16(data[8:])
+	if agentsV > ckptAgentsV {
+		return nil, fmt.Errorf("journal agents schema %d is newer than this build (%d)", agentsV, ckptAgentsV)
+	}
+
+	saved := sim.ChangeSeq
+	off := walHeaderLen
+	for off < len(data) {
+		if len(data)-off < walFrameLen {
+			break
+		}
+		n := int(binary.LittleEndian.Uint32(data[off:]))
+		sum := binary.LittleEndian.Uint32(data[off+4:])
+		end := off + walFrameLen + n
+		if n > walMaxRecord || end > len(data) {
+			break
+		}
+		payload := data[off+walFrameLen : end]
+		if crc32.Checksum(payload, walCRC) != sum {
+			break
+		}
+		c, err := decodeChange(payload, agentsV)
+		if err != nil {
+			break
+		}
+		off = end
+		report.Records++
+
+		if c.Seq <= saved {
+			report.AlreadySaved++
+			continue
+		}
+		if sim.ApplyChange(c) {
+			report.Applied[c.Kind.String()]++
+		} else {
+			report.Unchanged++
+		}
+		report.ToTick = max(report.ToTick, c.Tick)
+	}
+	report.TornBytes = int64(len(data) - off)
+	report.SkippedTicks = report.ToTick - report.FromTick
+	sim.LastTick = report.ToTick
+	if report.TornBytes > 0 {
+		// New records must follow the last intact one, not the torn tail.
+		if err := os.Truncate(path, int64(off)); err != nil {
+			return report, fmt.Errorf("cut torn journal tail: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// ── Change encoding ─────────────────────────────────────────────────
+
+func encodeChange(c engine.Change) []byte {
+	e := checkpoint.NewEncoder(64)
+	e.Uint(c.Seq)
+	e.Uint(c.Tick)
+	e.Uint8(uint8(c.Kind))
+	switch c.Kind {
+	case engine.ChangeAgentBorn:
+		encodeAgent(e, c.Agent)
+		e.String(c.Cause)
+	case engine.ChangeAgentDied, engine.ChangeAgentRemoved:
+		e.Uint(uint64(c.AgentID))
+		e.String(c.Cause)
+	case engine.ChangeSettlementFounded:
+		e.RawBytes(encodeSettlements([]*social.Settlement{c.Settlement}))
+		e.Uint(uint64(len(c.Founders)))
+		for _, id := range c.Founders {
+			e.Uint(uint64(id))
+		}
+	case engine.ChangeSettlementAbandoned:
+		e.Uint(c.SettlementID)
+	case engine.ChangeTreasuryTransfer:
+		e.Uint(c.Amount)
+		e.Uint(uint64(len(c.Balances)))
+		for _, b := range c.Balances {
+			e.Bool(b.Faction)
+			e.Uint(b.ID)
+			e.Uint(b.Balance)
+		}
+	case engine.ChangeHexClaimed:
+		encodeCoord(e, c.Hex)
+		e.OptUint(c.ClaimedBy)
+	}
+	return e.Bytes()
+}
+
+func decodeChange(payload []byte, agentsV uint16) (engine.Change, error) {
+	d := checkpoint.NewDecoder(payload)
+	var c engine.Change
+	c.Seq = d.Uint()
+	c.Tick = d.Uint()
+	c.Kind = engine.ChangeKind(d.Uint8())
+	switch c.Kind {
+	case engine.ChangeAgentBorn:
+		c.Agent = decodeAgent(d, agentsV)
+		c.Cause = d.String()
+	case engine.ChangeAgentDied, engine.ChangeAgentRemoved:
+		c.AgentID = agents.AgentID(d.Uint())
+		c.Cause = d.String()
+	case engine.ChangeSettlementFounded:
+		setts := decodeSettlements(checkpoint.NewDecoder(d.RawBytes()))
+		if len(setts) == 1 {
+			c.Settlement = setts[0]
+		}
+		c.Founders = make([]agents.AgentID, d.Len("founders"))
+		for i := range c.Founders {
+			c.Founders[i] = agents.AgentID(d.Uint())
+		}
+	case engine.ChangeSettlementAbandoned:
+		c.SettlementID = d.Uint()
+	case engine.ChangeTreasuryTransfer:
+		c.Amount = d.Uint()
+		c.Balances = make([]engine.TreasuryBalance, d.Len("balances"))
+		for i := range c.Balances {
+			c.Balances[i] = engine.TreasuryBalance{Faction: d.Bool(), ID: d.Uint(), Balance: d.Uint()}
+		}
+	case engine.ChangeHexClaimed:
+		c.Hex = decodeCoord(d)
+		c.ClaimedBy = d.OptUint()
+	default:
+		return c, fmt.Errorf("unknown change kind %d", c.Kind)
+	}
+	if err := d.Finish(); err != nil {
+		return c, err
+	}
+	if c.Kind == engine.ChangeSettlementFounded && c.Settlement == nil {
+		return c, errors.New("founded settlement missing")
+	}
+	return c, nil
+}
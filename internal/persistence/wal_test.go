@@ -0,0 +1,287 @@
+package persistence
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/social"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// walWorld is a journaled world and a copy of it as last saved.
+func walWorld(t *testing.T) (live, saved *engine.Simulation, path string) {
+	t.Helper()
+	live, _ = checkpointWorld(t, 8, 300)
+	saved, _ = checkpointWorld(t, 8, 300)
+	path = filepath.Join(t.TempDir(), "world.wal")
+	wal, err := OpenWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { wal.Close() })
+	live.ChangeLog = wal
+	return live, saved, path
+}
+
+// aliveIDs lists the living agents of sim, sorted.
+func aliveIDs(sim *engine.Simulation) []agents.AgentID {
+	var ids []agents.AgentID
+	for _, a := range sim.Agents {
+		if a.Alive {
+			ids = append(ids, a.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// buried lists the agents in sim's unsaved graves, sorted.
+func buried(sim *engine.Simulation) []agents.AgentID {
+	var ids []agents.AgentID
+	for _, g := range sim.UnsavedGraves {
+		ids = append(ids, g.AgentID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// lineageDeaths lists the deaths in sim's unsaved lineage records, sorted.
+func lineageDeaths(sim *engine.Simulation) []agents.AgentID {
+	var ids []agents.AgentID
+	for _, r := range sim.UnsavedLineage {
+		if r.Kind == engine.LineageDeath {
+			ids = append(ids, r.Person.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// claims lists who holds each claimed hex.
+func claims(sim *engine.Simulation) map[world.HexCoord]uint64 {
+	out := map[world.HexCoord]uint64{}
+	for _, h := range sim.WorldMap.Hexes {
+		if h.ClaimedBy != nil {
+			out[h.Coord] = *h.ClaimedBy
+		}
+	}
+	return out
+}
+
+// TestWALRecovery runs a journaled world for a few sim-days without saving,
+// "crashes", and replays the journal onto the world as saved before the run:
+// compared with the world that never crashed, the same agents must be alive,
+// in the same settlements, holding the same land, with the same dead buried
+// and in the family tree. The run ends with a treasury grant and a revert of
+// an immigrant spawn, so neither may come undone.
+func TestWALRecovery(t *testing.T) {
+	live, saved, path := walWorld(t)
+	eng := engine.NewEngine()
+	eng.OnTick = live.TickMinute
+	eng.OnHour = live.TickHour
+	eng.OnDay = live.TickDay
+	eng.OnWeek = live.TickWeek
+	for eng.Tick < 3*engine.TicksPerSimDay {
+		eng.Step()
+	}
+	home := live.Settlements[0]
+	if _, err := live.ApplyIntervention(engine.InterventionRequest{Type: engine.InterventionSpawn,
+		Settlement: home.Name, Count: 4}); err != nil {
+		t.Fatal(err)
+	}
+	spawn, err := live.SubmitIntervention("admin", engine.InterventionRequest{Type: engine.InterventionSpawn,
+		Settlement: home.Name, Count: 3}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.SubmitIntervention("admin", engine.InterventionRequest{Type: engine.InterventionWealth,
+		Settlement: home.Name, Amount: 250}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.RevertIntervention("admin", spawn.ID); err != nil {
+		t.Fatal(err)
+	}
+	live.ChangeLog.(*WAL).Sync()
+
+	report, err := RecoverWAL(path, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", report)
+	if report.Applied["agent_born"] == 0 || report.TornBytes != 0 || report.AlreadySaved != 0 {
+		t.Errorf("report %+v", report)
+	}
+	if saved.LastTick != report.ToTick || report.ToTick == 0 || report.ToTick > live.LastTick {
+		t.Errorf("clock at %d after recovery, last change at %d, crash at %d", saved.LastTick, report.ToTick, live.LastTick)
+	}
+	if got, want := aliveIDs(saved), aliveIDs(live); !slices.Equal(got, want) {
+		t.Errorf("%d agents alive after recovery, want %d", len(got), len(want))
+	}
+	if report.Applied["agent_died"] == 0 {
+		t.Fatal("no deaths in the journal; the test no longer covers them")
+	}
+	if report.Applied["agent_removed"] != 3 {
+		t.Errorf("%d reverted immigrants removed, want 3", report.Applied["agent_removed"])
+	}
+	if got := saved.SettlementIndex[home.ID].Treasury; got != home.Treasury {
+		t.Errorf("%s treasury %d after recovery, want %d", home.Name, got, home.Treasury)
+	}
+	if report.SkippedTicks != report.ToTick-report.FromTick {
+		t.Errorf("skipped %d ticks, recovered from %d to %d", report.SkippedTicks, report.FromTick, report.ToTick)
+	}
+	for _, sett := range saved.Settlements {
+		var members []agents.AgentID
+		for _, a := range saved.SettlementAgents[sett.ID] {
+			if a.Alive {
+				members = append(members, a.ID)
+			}
+		}
+		var want []agents.AgentID
+		for _, a := range live.Agents {
+			if a.Alive && a.HomeSettID != nil && *a.HomeSettID == sett.ID {
+				want = append(want, a.ID)
+			}
+		}
+		slices.Sort(members)
+		slices.Sort(want)
+		if !slices.Equal(members, want) || int(sett.Population) != len(want) {
+			t.Errorf("%s: population %d, %d members, want %d", sett.Name, sett.Population, len(members), len(want))
+		}
+	}
+	if got, want := buried(saved), buried(live); !slices.Equal(got, want) {
+		t.Errorf("%d graves after recovery, want %d", len(got), len(want))
+	}
+	if got, want := lineageDeaths(saved), lineageDeaths(live); !slices.Equal(got, want) {
+		t.Errorf("%d deaths in the family tree after recovery, want %d", len(got), len(want))
+	}
+	for _, sett := range live.Settlements {
+		got, ok := saved.SettlementIndex[sett.ID]
+		if !ok || got.Name != sett.Name {
+			t.Errorf("settlement %d %s not recovered", sett.ID, sett.Name)
+		}
+	}
+	if got, want := claims(saved), claims(live); len(got) != len(want) {
+		t.Errorf("%d hexes claimed after recovery, want %d", len(got), len(want))
+	}
+	if saved.ChangeSeq != live.ChangeSeq {
+		t.Errorf("change seq %d, want %d", saved.ChangeSeq, live.ChangeSeq)
+	}
+	if saved.Spawner.NextID() != live.Spawner.NextID() {
+		t.Errorf("spawner at %d, want %d", saved.Spawner.NextID(), live.Spawner.NextID())
+	}
+
+	// Booting the recovered world again replays nothing: the save that
+	// follows recovery holds every change in the journal.
+	again, err := RecoverWAL(path, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Recovered() != 0 || again.AlreadySaved != report.Records {
+		t.Errorf("second recovery %+v", again)
+	}
+}
+
+// TestWALTornTail cuts the journal mid-record, as a crash mid-write would,
+// and checks recovery keeps every intact record, reports the torn bytes and
+// cuts them off so the next record follows the last intact one.
+func TestWALTornTail(t *testing.T) {
+	live, saved, path := walWorld(t)
+	wal := live.ChangeLog.(*WAL)
+	for i := range 3 {
+		if _, err := live.ApplyIntervention(engine.InterventionRequest{Type: engine.InterventionSpawn,
+			Settlement: live.Settlements[i].Name, Count: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wal.Sync()
+	fi, _ := os.Stat(path)
+	if err := os.Truncate(path, fi.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := RecoverWAL(path, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Records != 2 || report.Applied["agent_born"] != 2 || report.TornBytes == 0 {
+		t.Fatalf("report %+v", report)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() != fi.Size()-5-report.TornBytes {
+		t.Errorf("journal is %d bytes after recovery, want %d", after.Size(), fi.Size()-5-report.TornBytes)
+	}
+
+	// A journal that is not one stops the boot.
+	os.WriteFile(path, []byte("definitely not a journal"), 0644)
+	if _, err := RecoverWAL(path, saved); err == nil {
+		t.Error("recovered from a file that is not a journal")
+	}
+}
+
+// TestChangeRoundTrip encodes one change of each kind, decodes it, and
+// applies it twice: the first time changes the world, the second finds it
+// already there.
+func TestChangeRoundTrip(t *testing.T) {
+	sim, _ := checkpointWorld(t, 8, 300)
+	var site world.HexCoord
+	for _, h := range sim.WorldMap.Hexes {
+		if h.Terrain != world.TerrainOcean && h.SettlementID == nil && h.ClaimedBy == nil {
+			site = h.Coord
+			break
+		}
+	}
+	first := slices.Clone(sim.SettlementAgents[1])
+	claimant := uint64(3)
+	changes := []engine.Change{
+		{Kind: engine.ChangeAgentBorn, Agent: sim.Spawner.SpawnPopulation(1, sim.Settlements[0].Position, 1, world.TerrainPlains)[0], Cause: "birth"},
+		{Kind: engine.ChangeAgentDied, AgentID: first[0].ID, Cause: "age"},
+		{Kind: engine.ChangeSettlementFounded, Settlement: &social.Settlement{ID: 99, Name: "Newhold",
+			Position: site, Population: 2, Treasury: 40}, Founders: []agents.AgentID{first[1].ID, first[2].ID}},
+		{Kind: engine.ChangeSettlementAbandoned, SettlementID: 2},
+		{Kind: engine.ChangeTreasuryTransfer, Amount: 5, Balances: []engine.TreasuryBalance{
+			{ID: 3, Balance: 7}, {Faction: true, ID: uint64(sim.Factions[0].ID), Balance: 11}}},
+		{Kind: engine.ChangeHexClaimed, Hex: sim.Settlements[0].Position.Neighbors()[0], ClaimedBy: &claimant},
+		{Kind: engine.ChangeAgentRemoved, AgentID: first[3].ID, Cause: "reverted"},
+	}
+	for i, c := range changes {
+		c.Seq, c.Tick = uint64(i+1), 100
+		got, err := decodeChange(encodeChange(c), ckptAgentsV)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Kind, err)
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Fatalf("%s decoded as\n%+v\nwant\n%+v", c.Kind, got, c)
+		}
+		if !sim.ApplyChange(got) {
+			t.Errorf("%s changed nothing", c.Kind)
+		}
+		if sim.ApplyChange(got) {
+			t.Errorf("%s applied twice", c.Kind)
+		}
+	}
+
+	if h := sim.WorldMap.Get(site); h.SettlementID == nil || *h.SettlementID != 99 {
+		t.Error("founded settlement not on its hex")
+	}
+	if id := first[1].HomeSettID; id == nil || *id != 99 {
+		t.Error("founder did not move")
+	}
+	if held := claims(sim); slices.Contains(slices.Collect(maps.Values(held)), 2) {
+		t.Error("abandoned settlement still holds land")
+	}
+	if sim.SettlementIndex[3].Treasury != 7 || sim.Factions[0].Treasury != 11 {
+		t.Error("treasury balances not applied")
+	}
+	if sim.AgentIndex[first[3].ID] != nil || slices.Contains(sim.SettlementAgents[1], first[3]) {
+		t.Error("removed agent still in the world")
+	}
+	if sim.ChangeSeq != uint64(len(changes)) {
+		t.Errorf("change seq %d, want %d", sim.ChangeSeq, len(changes))
+	}
+}
@@ -21,11 +21,16 @@
 package persistence
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 
+	"github.com/talgya/mini-world/internal/agents"
+	"github.com/talgya/mini-world/internal/economy"
 	"github.com/talgya/mini-world/internal/engine"
 )
 
@@ -37,19 +42,46 @@ type PersistedField struct {
 	// Save reads from sim and writes a string value via db.SaveMeta.
 	// Returns nil even when there's nothing meaningful to write (the field
 	// stays absent from world_meta until non-empty).
-	Save func(*engine.Simulation, *DB) error
+	Save func(*engine.Simulation, MetaStore) error
 	// Load reads a string value via db.GetMeta and applies it to sim. Called
 	// only when the meta key exists; safe to leave sim unchanged if the
 	// stored value is malformed (logged at warn level).
-	Load func(*engine.Simulation, *DB)
+	Load func(*engine.Simulation, MetaStore)
+}
+
+// MetaStore is the key/value store registry fields save to and load from:
+// the world_meta table, or the world_meta section of a checkpoint.
+type MetaStore interface {
+	SaveMeta(key, value string) error
+	GetMeta(key string) (string, error)
+}
+
+// metaMap is an in-memory MetaStore.
+type metaMap map[string]string
+
+func (m metaMap) SaveMeta(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m metaMap) GetMeta(key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return v, nil
 }
 
 // persistedFields is the single source of truth for late-restoration world
 // state. Add a new entry here to persist new state; you cannot accidentally
 // ship a Save without a Load (or vice versa).
 var persistedFields = []PersistedField{
+	// First: it restores agents the fields below may refer to.
+	{Name: "dead_agents", Save: saveDeadAgents, Load: loadDeadAgents},
+	{Name: "agent_order", Save: saveAgentOrder, Load: loadAgentOrder},
 	{Name: "non_viable_weeks", Save: saveNonViableWeeks, Load: loadNonViableWeeks},
 	{Name: "abandoned_weeks", Save: saveAbandonedWeeks, Load: loadAbandonedWeeks},
+	{Name: "settlement_chronicles", Save: saveChronicles, Load: loadChronicles},
 	{Name: "settlement_relations", Save: saveSettlementRelations, Load: loadSettlementRelations},
 	{Name: "trade_routes", Save: saveTradeRoutes, Load: loadTradeRoutes},
 	{Name: "trade_tracker", Save: saveTradeTracker, Load: loadTradeTracker},
@@ -62,15 +94,62 @@ var persistedFields = []PersistedField{
 	{Name: "heat_streak_hours", Save: saveHeatStreakHours, Load: loadHeatStreakHours},
 	{Name: "last_newspaper", Save: saveLastNewspaper, Load: loadLastNewspaper},
 	{Name: "liberated_spirits_pool", Save: saveLiberatedSpiritsPool, Load: loadLiberatedSpiritsPool},
+	{Name: "tuning", Save: saveTuning, Load: loadTuning},
+	{Name: "active_boosts", Save: saveActiveBoosts, Load: loadActiveBoosts},
+	{Name: "doctrine_fail_weeks", Save: saveDoctrineFailWeeks, Load: loadDoctrineFailWeeks},
+	{Name: "oracle_visions", Save: saveOracleVisions, Load: loadOracleVisions},
+	{Name: "archetypes", Save: saveArchetypes, Load: loadArchetypes},
+	// After tuning: restored markets keep the base prices they were saved
+	// with rather than the ones loadTuning just applied.
+	{Name: "markets", Save: saveMarkets, Load: loadMarkets},
+	{Name: "spawner", Save: saveSpawner, Load: loadSpawner},
+	{Name: "settlement_members", Save: saveSettlementMembers, Load: loadSettlementMembers},
+	{Name: "change_seq", Save: saveChangeSeq, Load: loadChangeSeq},
+}
+
+// Tuning knobs changed at runtime (POST /api/v1/tuning) or by the config file
+// survive restarts. Only overrides are stored, and always — an empty object
+// clears knobs that were set back to their defaults.
+func saveTuning(sim *engine.Simulation, db MetaStore) error {
+	b, _ := json.Marshal(sim.Tuning.Overrides())
+	return db.SaveMeta("tuning", string(b))
+}
+
+// SaveTuning writes the tuning overrides immediately, so a runtime change does
+// not wait for the next autosave.
+func (db *DB) SaveTuning(sim *engine.Simulation) error {
+	return saveTuning(sim, db)
+}
+
+func loadTuning(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("tuning")
+	if err != nil {
+		return
+	}
+	var overrides map[string]float64
+	if err := json.Unmarshal([]byte(s), &overrides); err != nil {
+		slog.Warn("failed to parse tuning", "error", err)
+		return
+	}
+	// Apply knob by knob: one retired or out-of-bounds knob must not drop
+	// the rest.
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		if err := sim.ApplyTuning(map[string]float64{name: overrides[name]}); err != nil {
+			slog.Warn("ignoring saved tuning knob", "knob", name, "error", err)
+		}
+	}
+	if len(overrides) > 0 {
+		slog.Info("tuning restored", "overrides", len(overrides))
+	}
 }
 
 // R90 (Doc 25 Layer 3): persist the LiberatedSpiritsPool counter so
 // reincarnation cadence carries across restarts.
-func saveLiberatedSpiritsPool(sim *engine.Simulation, db *DB) error {
+func saveLiberatedSpiritsPool(sim *engine.Simulation, db MetaStore) error {
 	return db.SaveMeta("liberated_spirits_pool", strconv.Itoa(sim.LiberatedSpiritsPool))
 }
 
-func loadLiberatedSpiritsPool(sim *engine.Simulation, db *DB) {
+func loadLiberatedSpiritsPool(sim *engine.Simulation, db MetaStore) {
 	v, err := db.GetMeta("liberated_spirits_pool")
 	if err != nil {
 		return
@@ -80,12 +159,312 @@ func loadLiberatedSpiritsPool(sim *engine.Simulation, db *DB) {
 	}
 }
 
+// ─── Engine state that used to reset on restart ──────────────────────────
+//
+// These are saved on every call, empty or not: an empty value must overwrite
+// the last non-empty one, or an expired boost would come back at boot.
+
+// Agents who died since the last weekly compaction are still in sim.Agents,
+// holding their place in it and their relationships until compaction prunes
+// them. The agents table keeps only the living, so they are saved whole here.
+func saveDeadAgents(sim *engine.Simulation, db MetaStore) error {
+	dead := []*agents.Agent{}
+	for _, a := range sim.Agents {
+		if !a.Alive {
+			dead = append(dead, a)
+		}
+	}
+	b, err := json.Marshal(dead)
+	if err != nil {
+		return err
+	}
+	return db.SaveMeta("dead_agents", string(b))
+}
+
+// loadDeadAgents appends the saved dead; agent_order puts them back in place.
+// A checkpoint already holds them, so agents present are kept.
+func loadDeadAgents(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("dead_agents")
+	if err != nil {
+		return
+	}
+	var dead []*agents.Agent
+	if err := json.Unmarshal([]byte(s), &dead); err != nil {
+		slog.Warn("failed to parse dead agents", "error", err)
+		return
+	}
+	for _, a := range dead {
+		if _, ok := sim.AgentIndex[a.ID]; ok {
+			continue
+		}
+		sim.Agents = append(sim.Agents, a)
+		sim.AgentIndex[a.ID] = a
+	}
+}
+
+// The order of sim.Agents. The engine shuffles it every hour and walks it in
+// that order, so a database boot, which reads agents in ID order, went first
+// with different agents and drew its random numbers differently.
+func saveAgentOrder(sim *engine.Simulation, db MetaStore) error {
+	ids := make([]agents.AgentID, len(sim.Agents))
+	for i, a := range sim.Agents {
+		ids[i] = a.ID
+	}
+	b, _ := json.Marshal(ids)
+	return db.SaveMeta("agent_order", string(b))
+}
+
+func loadAgentOrder(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("agent_order")
+	if err != nil {
+		return
+	}
+	var ids []agents.AgentID
+	if err := json.Unmarshal([]byte(s), &ids); err != nil {
+		slog.Warn("failed to parse agent order", "error", err)
+		return
+	}
+	current := make(map[agents.AgentID]*agents.Agent, len(sim.Agents))
+	for _, a := range sim.Agents {
+		current[a.ID] = a
+	}
+	ordered := make([]*agents.Agent, 0, len(sim.Agents))
+	placed := make(map[agents.AgentID]bool, len(sim.Agents))
+	for _, id := range ids {
+		if a := current[id]; a != nil && !placed[id] {
+			ordered = append(ordered, a)
+			placed[id] = true
+		}
+	}
+	// Agents the saved order does not know keep their boot order at the end.
+	for _, a := range sim.Agents {
+		if !placed[a.ID] {
+			ordered = append(ordered, a)
+		}
+	}
+	sim.Agents = ordered
+}
+
+func saveActiveBoosts(sim *engine.Simulation, db MetaStore) error {
+	b, _ := json.Marshal(sim.ActiveBoosts)
+	return db.SaveMeta("active_boosts", string(b))
+}
+
+func loadActiveBoosts(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("active_boosts")
+	if err != nil {
+		return
+	}
+	var boosts []engine.ProductionBoost
+	if err := json.Unmarshal([]byte(s), &boosts); err != nil {
+		slog.Warn("failed to parse active boosts", "error", err)
+		return
+	}
+	sim.ActiveBoosts = boosts
+	if len(boosts) > 0 {
+		slog.Info("production boosts restored", "count", len(boosts))
+	}
+}
+
+func saveDoctrineFailWeeks(sim *engine.Simulation, db MetaStore) error {
+	b, _ := json.Marshal(sim.DoctrineFailWeeks)
+	return db.SaveMeta("doctrine_fail_weeks", string(b))
+}
+
+func loadDoctrineFailWeeks(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("doctrine_fail_weeks")
+	if err != nil {
+		return
+	}
+	var weeks map[agents.AgentID]uint8
+	if err := json.Unmarshal([]byte(s), &weeks); err != nil {
+		slog.Warn("failed to parse doctrine fail weeks", "error", err)
+		return
+	}
+	if weeks == nil {
+		weeks = make(map[agents.AgentID]uint8)
+	}
+	sim.DoctrineFailWeeks = weeks
+}
+
+// Cached oracle visions: without them every settlement's oracle calls the
+// LLM again on the first TickWeek after a restart.
+func saveOracleVisions(sim *engine.Simulation, db MetaStore) error {
+	b, _ := json.Marshal(sim.LastVisions)
+	return db.SaveMeta("oracle_visions", string(b))
+}
+
+func loadOracleVisions(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("oracle_visions")
+	if err != nil {
+		return
+	}
+	var visions map[uint64]engine.CachedVision
+	if err := json.Unmarshal([]byte(s), &visions); err != nil {
+		slog.Warn("failed to parse oracle visions", "error", err)
+		return
+	}
+	sim.LastVisions = visions
+	if len(visions) > 0 {
+		slog.Info("oracle visions restored", "settlements", len(visions))
+	}
+}
+
+// The archetype templates are LLM-tuned once a season. Saving the season
+// alone would skip the refresh but leave the defaults in place, so the
+// templates are saved with it.
+type archetypeState struct {
+	Season    uint8                              `json:"season"`
+	Templates map[string]agents.BehaviorTemplate `json:"templates"`
+}
+
+func saveArchetypes(sim *engine.Simulation, db MetaStore) error {
+	b, _ := json.Marshal(archetypeState{Season: sim.LastArchetypeSeason, Templates: agents.ArchetypeTemplates()})
+	return db.SaveMeta("archetypes", string(b))
+}
+
+func loadArchetypes(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("archetypes")
+	if err != nil {
+		return
+	}
+	var st archetypeState
+	if err := json.Unmarshal([]byte(s), &st); err != nil {
+		slog.Warn("failed to parse archetypes", "error", err)
+		return
+	}
+	for name, tmpl := range st.Templates {
+		agents.SetArchetypeTemplate(name, tmpl)
+	}
+	sim.RestoreArchetypeSeason(st.Season)
+}
+
+// Markets carry supply, demand and price per good. Rebuilding them with
+// NewMarket snapped every price back to base on each deploy.
+func saveMarkets(sim *engine.Simulation, db MetaStore) error {
+	markets := make(map[uint64]*economy.Market, len(sim.Settlements))
+	for _, sett := range sim.Settlements {
+		if sett.Market != nil {
+			markets[sett.ID] = sett.Market
+		}
+	}
+	b, _ := json.Marshal(markets)
+	return db.SaveMeta("markets", string(b))
+}
+
+func loadMarkets(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("markets")
+	if err != nil {
+		return
+	}
+	var markets map[uint64]*economy.Market
+	if err := json.Unmarshal([]byte(s), &markets); err != nil {
+		slog.Warn("failed to parse markets", "error", err)
+		return
+	}
+	restored := 0
+	for _, sett := range sim.Settlements {
+		if m, ok := markets[sett.ID]; ok && m != nil && len(m.Entries) > 0 {
+			m.SettlementID = sett.ID
+			sett.Market = m
+			restored++
+		}
+	}
+	slog.Info("markets restored", "settlements", restored)
+}
+
+// The spawner's random stream and ID counter. Reseeding at boot replayed the
+// genesis stream for every newborn after a deploy, and deriving the next ID
+// from the highest living agent could hand out a dead agent's ID again.
+type spawnerState struct {
+	NextID agents.AgentID `json:"next_id"`
+	Draws  uint64         `json:"draws"`
+}
+
+func saveSpawner(sim *engine.Simulation, db MetaStore) error {
+	if sim.Spawner == nil {
+		return nil
+	}
+	b, _ := json.Marshal(spawnerState{NextID: sim.Spawner.NextID(), Draws: sim.Spawner.Draws()})
+	return db.SaveMeta("spawner", string(b))
+}
+
+func loadSpawner(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("spawner")
+	if err != nil || sim.Spawner == nil {
+		return
+	}
+	var st spawnerState
+	if err := json.Unmarshal([]byte(s), &st); err != nil {
+		slog.Warn("failed to parse spawner state", "error", err)
+		return
+	}
+	if st.NextID > sim.Spawner.NextID() {
+		sim.Spawner.SetNextID(st.NextID)
+	}
+	sim.Spawner.Skip(st.Draws)
+}
+
+// Each settlement's member list, in order. The engine walks these lists when
+// it settles trades, births and migrations, so rebuilding them in agent order
+// at boot changed who went first.
+func saveSettlementMembers(sim *engine.Simulation, db MetaStore) error {
+	members := make(map[uint64][]agents.AgentID, len(sim.SettlementAgents))
+	for id, list := range sim.SettlementAgents {
+		ids := make([]agents.AgentID, len(list))
+		for i, a := range list {
+			ids[i] = a.ID
+		}
+		members[id] = ids
+	}
+	b, _ := json.Marshal(members)
+	return db.SaveMeta("settlement_members", string(b))
+}
+
+func loadSettlementMembers(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("settlement_members")
+	if err != nil {
+		return
+	}
+	var members map[uint64][]agents.AgentID
+	if err := json.Unmarshal([]byte(s), &members); err != nil {
+		slog.Warn("failed to parse settlement members", "error", err)
+		return
+	}
+	for settID, current := range sim.SettlementAgents {
+		saved, ok := members[settID]
+		if !ok {
+			continue
+		}
+		ordered := make([]*agents.Agent, 0, len(current))
+		placed := make(map[agents.AgentID]bool, len(current))
+		for _, id := range saved {
+			if a := sim.AgentIndex[id]; a != nil && a.HomeSettID != nil && *a.HomeSettID == settID && !placed[id] {
+				ordered = append(ordered, a)
+				placed[id] = true
+			}
+		}
+		// Members the saved list does not know (a database boot after a
+		// partial save) keep their boot order at the end.
+		for _, a := range current {
+			if !placed[a.ID] {
+				ordered = append(ordered, a)
+			}
+		}
+		sim.SettlementAgents[settID] = ordered
+	}
+}
+
 // SaveLatePersisted iterates the registry and saves every late field. Called
 // from SaveWorldState after the inline early fields. Returns the first
 // non-nil save error (consistent with prior behavior).
 func (db *DB) SaveLatePersisted(sim *engine.Simulation) error {
+	return saveLatePersisted(sim, db)
+}
+
+func saveLatePersisted(sim *engine.Simulation, store MetaStore) error {
 	for _, f := range persistedFields {
-		if err := f.Save(sim, db); err != nil {
+		if err := f.Save(sim, store); err != nil {
 			return fmt.Errorf("save %s: %w", f.Name, err)
 		}
 	}
@@ -97,14 +476,18 @@ func (db *DB) SaveLatePersisted(sim *engine.Simulation) error {
 // field's Load handles its own missing-key/malformed-value behavior; one
 // field's failure does not block others.
 func (db *DB) RestoreLatePersisted(sim *engine.Simulation) {
+	restoreLatePersisted(sim, db)
+}
+
+func restoreLatePersisted(sim *engine.Simulation, store MetaStore) {
 	for _, f := range persistedFields {
-		f.Load(sim, db)
+		f.Load(sim, store)
 	}
 }
 
 // ─── Save closures ────────────────────────────────────────────────────────
 
-func saveNonViableWeeks(sim *engine.Simulation, db *DB) error {
+func saveNonViableWeeks(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.NonViableWeeks) == 0 {
 		return nil
 	}
@@ -112,7 +495,7 @@ func saveNonViableWeeks(sim *engine.Simulation, db *DB) error {
 	return db.SaveMeta("non_viable_weeks", string(b))
 }
 
-func saveAbandonedWeeks(sim *engine.Simulation, db *DB) error {
+func saveAbandonedWeeks(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.AbandonedWeeks) == 0 {
 		return nil
 	}
@@ -120,6 +503,14 @@ func saveAbandonedWeeks(sim *engine.Simulation, db *DB) error {
 	return db.SaveMeta("abandoned_weeks", string(b))
 }
 
+func saveChronicles(sim *engine.Simulation, db MetaStore) error {
+	if len(sim.Chronicles) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(sim.Chronicles)
+	return db.SaveMeta("settlement_chronicles", string(b))
+}
+
 type relEntry struct {
 	A uint64  `json:"a"`
 	B uint64  `json:"b"`
@@ -127,7 +518,7 @@ type relEntry struct {
 	T float64 `json:"t"` // trade
 }
 
-func saveSettlementRelations(sim *engine.Simulation, db *DB) error {
+func saveSettlementRelations(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.Relations) == 0 {
 		return nil
 	}
@@ -153,7 +544,7 @@ type routeEntry struct {
 	WT float64 `json:"wt"`
 }
 
-func saveTradeRoutes(sim *engine.Simulation, db *DB) error {
+func saveTradeRoutes(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.TradeRoutes) == 0 {
 		return nil
 	}
@@ -178,7 +569,7 @@ type ttEntry struct {
 	V float64 `json:"v"`
 }
 
-func saveTradeTracker(sim *engine.Simulation, db *DB) error {
+func saveTradeTracker(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.TradeTracker) == 0 {
 		return nil
 	}
@@ -198,7 +589,7 @@ type agreeEntry struct {
 	FT uint64 `json:"ft"`
 }
 
-func saveAgreements(sim *engine.Simulation, db *DB) error {
+func saveAgreements(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.Agreements) == 0 {
 		return nil
 	}
@@ -229,7 +620,7 @@ type peaceEntry struct {
 	FT uint64 `json:"ft"`
 }
 
-func savePeaceTreaties(sim *engine.Simulation, db *DB) error {
+func savePeaceTreaties(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.PeaceTreaties) == 0 {
 		return nil
 	}
@@ -249,7 +640,7 @@ type raidEntry struct {
 	C int    `json:"c"`
 }
 
-func saveRaidCounts(sim *engine.Simulation, db *DB) error {
+func saveRaidCounts(sim *engine.Simulation, db MetaStore) error {
 	if len(sim.RaidCounts) == 0 {
 		return nil
 	}
@@ -266,23 +657,29 @@ func saveRaidCounts(sim *engine.Simulation, db *DB) error {
 	return db.SaveMeta("raid_counts", string(b))
 }
 
-func saveBirths(sim *engine.Simulation, db *DB) error {
+func saveBirths(sim *engine.Simulation, db MetaStore) error {
 	return db.SaveMeta("births", fmt.Sprintf("%d", sim.Stats.Births))
 }
 
-func saveTradeVolume(sim *engine.Simulation, db *DB) error {
+func saveTradeVolume(sim *engine.Simulation, db MetaStore) error {
 	return db.SaveMeta("trade_volume", fmt.Sprintf("%d", sim.Stats.TradeVolume))
 }
 
-func saveDeaths(sim *engine.Simulation, db *DB) error {
+func saveDeaths(sim *engine.Simulation, db MetaStore) error {
 	return db.SaveMeta("deaths", fmt.Sprintf("%d", sim.Stats.Deaths))
 }
 
-func saveHeatStreakHours(sim *engine.Simulation, db *DB) error {
+func saveHeatStreakHours(sim *engine.Simulation, db MetaStore) error {
 	return db.SaveMeta("heat_streak_hours", fmt.Sprintf("%d", sim.HeatStreakHours))
 }
 
-func saveLastNewspaper(sim *engine.Simulation, db *DB) error {
+// The last domain change the save covers (see wal.go): journal recovery
+// replays only the changes after it.
+func saveChangeSeq(sim *engine.Simulation, db MetaStore) error {
+	return db.SaveMeta("change_seq", strconv.FormatUint(sim.ChangeSeq, 10))
+}
+
+func saveLastNewspaper(sim *engine.Simulation, db MetaStore) error {
 	if sim.LastNewspaperContent == "" {
 		return nil
 	}
@@ -291,7 +688,7 @@ func saveLastNewspaper(sim *engine.Simulation, db *DB) error {
 
 // ─── Load closures ────────────────────────────────────────────────────────
 
-func loadNonViableWeeks(sim *engine.Simulation, db *DB) {
+func loadNonViableWeeks(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("non_viable_weeks")
 	if err != nil {
 		return
@@ -303,7 +700,7 @@ func loadNonViableWeeks(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadAbandonedWeeks(sim *engine.Simulation, db *DB) {
+func loadAbandonedWeeks(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("abandoned_weeks")
 	if err != nil {
 		return
@@ -315,7 +712,19 @@ func loadAbandonedWeeks(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadSettlementRelations(sim *engine.Simulation, db *DB) {
+func loadChronicles(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("settlement_chronicles")
+	if err != nil {
+		return
+	}
+	var c map[uint64]*engine.SettlementChronicle
+	if json.Unmarshal([]byte(s), &c) == nil && len(c) > 0 {
+		sim.Chronicles = c
+		slog.Info("settlement chronicles restored", "settlements", len(c))
+	}
+}
+
+func loadSettlementRelations(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("settlement_relations")
 	if err != nil {
 		return
@@ -331,7 +740,7 @@ func loadSettlementRelations(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadTradeRoutes(sim *engine.Simulation, db *DB) {
+func loadTradeRoutes(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("trade_routes")
 	if err != nil {
 		return
@@ -350,7 +759,7 @@ func loadTradeRoutes(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadTradeTracker(sim *engine.Simulation, db *DB) {
+func loadTradeTracker(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("trade_tracker")
 	if err != nil {
 		return
@@ -366,7 +775,7 @@ func loadTradeTracker(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadAgreements(sim *engine.Simulation, db *DB) {
+func loadAgreements(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("agreements")
 	if err != nil {
 		return
@@ -384,7 +793,7 @@ func loadAgreements(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadPeaceTreaties(sim *engine.Simulation, db *DB) {
+func loadPeaceTreaties(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("peace_treaties")
 	if err != nil {
 		return
@@ -402,7 +811,7 @@ func loadPeaceTreaties(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadRaidCounts(sim *engine.Simulation, db *DB) {
+func loadRaidCounts(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("raid_counts")
 	if err != nil {
 		return
@@ -418,7 +827,7 @@ func loadRaidCounts(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadBirths(sim *engine.Simulation, db *DB) {
+func loadBirths(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("births")
 	if err != nil {
 		return
@@ -429,7 +838,7 @@ func loadBirths(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadTradeVolume(sim *engine.Simulation, db *DB) {
+func loadTradeVolume(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("trade_volume")
 	if err != nil {
 		return
@@ -440,7 +849,7 @@ func loadTradeVolume(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadDeaths(sim *engine.Simulation, db *DB) {
+func loadDeaths(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("deaths")
 	if err != nil {
 		return
@@ -451,7 +860,7 @@ func loadDeaths(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadHeatStreakHours(sim *engine.Simulation, db *DB) {
+func loadHeatStreakHours(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("heat_streak_hours")
 	if err != nil {
 		return
@@ -462,7 +871,17 @@ func loadHeatStreakHours(sim *engine.Simulation, db *DB) {
 	}
 }
 
-func loadLastNewspaper(sim *engine.Simulation, db *DB) {
+func loadChangeSeq(sim *engine.Simulation, db MetaStore) {
+	s, err := db.GetMeta("change_seq")
+	if err != nil {
+		return
+	}
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		sim.ChangeSeq = v
+	}
+}
+
+func loadLastNewspaper(sim *engine.Simulation, db MetaStore) {
 	s, err := db.GetMeta("last_newspaper")
 	if err != nil {
 		return
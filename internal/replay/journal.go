@@ -0,0 +1,403 @@
+// Package replay records every external input a live run consumes — entropy
+// floats, weather conditions, LLM completions and admin interventions — into
+// an append-only journal, and plays a journal back against the world snapshot
+// it was recorded from. Replays assert the engine's end-of-day StateHash
+// matches the recording bit-for-bit, which turns "why did the world do that?"
+// into a reproducible, bisectable run.
+//
+// A journal is a JSON-lines file. The first line is the Header; every further
+// line is one record. The base snapshot named in the header is a SQLite copy of
+// the world taken immediately before the first recorded tick.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/weather"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// FormatVersion is bumped whenever the record layout changes incompatibly.
+const FormatVersion = 1
+
+// Header is the first line of every journal.
+type Header struct {
+	Version    int             `json:"version"`
+	StartTick  uint64          `json:"start_tick"`
+	Gen        world.GenConfig `json:"gen"`                  // map generation parameters (the map is not in the snapshot)
+	Base       string          `json:"base"`                 // base snapshot file, relative to the journal's directory
+	Checkpoint string          `json:"checkpoint,omitempty"` // base checkpoint of the world as booted; older journals may lack one
+	StartHash  string          `json:"start_hash"`           // StateHash of the world at StartTick, before any recorded tick
+	LLM        bool            `json:"llm"`                  // live run had an LLM client
+	Weather    bool            `json:"weather"`              // live run had a weather client
+	Created    string          `json:"created"`
+}
+
+// Record kinds.
+const (
+	kindEntropy      = "entropy"
+	kindWeather      = "weather"
+	kindLLM          = "llm"
+	kindIntervention = "intervention"
+	kindHash         = "hash"
+)
+
+// record is one journal line after the header. Short keys keep the file small:
+// entropy records dominate (a handful per sim-hour).
+type record struct {
+	Kind         string                      `json:"k"`
+	Tick         uint64                      `json:"t,omitempty"`
+	Float        float64                     `json:"f,omitempty"`
+	Weather      *weather.Conditions         `json:"w,omitempty"`
+	Tag          string                      `json:"tag,omitempty"`
+	Text         string                      `json:"text,omitempty"`
+	Err          string                      `json:"err,omitempty"`
+	Intervention *engine.InterventionRequest `json:"iv,omitempty"`
+	Hash         string                      `json:"h,omitempty"`
+}
+
+func formatHash(h uint64) string { return fmt.Sprintf("%016x", h) }
+
+func parseHash(s string) (uint64, error) { return strconv.ParseUint(s, 16, 64) }
+
+// ── Recording ────────────────────────────────────────────────────────
+
+// Recorder appends a live run's inputs to a journal. It implements both
+// engine.Journal and llm.Recorder and is safe for concurrent use (LLM calls
+// arrive from API goroutines as well as the tick loop).
+type Recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error // first write error; recording stops after it
+}
+
+// Create starts a new journal at path, writing h as its header. The caller
+// owns placing the base snapshot next to it.
+func Create(path string, h Header) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("create journal: %w", err)
+	}
+	h.Version = FormatVersion
+	if h.Created == "" {
+		h.Created = time.Now().UTC().Format(time.RFC3339)
+	}
+	r := &Recorder{f: f, w: bufio.NewWriterSize(f, 64<<10)}
+	r.enc = json.NewEncoder(r.w)
+	if err := r.enc.Encode(h); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write journal header: %w", err)
+	}
+	return r, nil
+}
+
+func (r *Recorder) write(rec record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(rec)
+}
+
+// Entropy implements engine.Journal.
+func (r *Recorder) Entropy(tick uint64, live func() float64) float64 {
+	v := live()
+	r.write(record{Kind: kindEntropy, Tick: tick, Float: v})
+	return v
+}
+
+// Weather implements engine.Journal. Fetch failures are recorded too: the
+// simulation keeps its previous weather on failure, and replay must as well.
+func (r *Recorder) Weather(tick uint64, live func() (*weather.Conditions, error)) (*weather.Conditions, error) {
+	c, err := live()
+	rec := record{Kind: kindWeather, Tick: tick, Weather: c}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+	r.write(rec)
+	return c, err
+}
+
+// Intervention implements engine.Journal.
+func (r *Recorder) Intervention(tick uint64, req engine.InterventionRequest) {
+	r.write(record{Kind: kindIntervention, Tick: tick, Intervention: &req})
+}
+
+// DayHash implements engine.Journal. The buffer is flushed at each day
+// boundary so a crashed run still leaves a replayable prefix.
+func (r *Recorder) DayHash(tick uint64, hash uint64) error {
+	r.write(record{Kind: kindHash, Tick: tick, Hash: formatHash(hash)})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// Completion implements llm.Recorder.
+func (r *Recorder) Completion(tag string, live func() (string, error)) (string, error) {
+	text, err := live()
+	rec := record{Kind: kindLLM, Tag: tag, Text: text}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+	r.write(rec)
+	return text, err
+}
+
+// Close flushes and closes the journal, returning the first write error.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if err := r.f.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// ── Playback ─────────────────────────────────────────────────────────
+
+// DivergenceError reports the first point where a replay stopped matching
+// its recording.
+type DivergenceError struct {
+	Tick   uint64
+	Reason string
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("replay diverged at tick %d (%s): %s", e.Tick, engine.SimTime(e.Tick), e.Reason)
+}
+
+// Player serves a recorded journal back to the simulation. It implements
+// engine.Journal and llm.Recorder. The first divergence — an input requested
+// at a different tick than recorded, a stream running dry, or a day hash
+// mismatch — is latched and reported by Err.
+type Player struct {
+	Header Header
+	dir    string
+
+	mu            sync.Mutex
+	entropy       []record
+	weather       []record
+	llm           map[string][]record
+	interventions map[uint64][]engine.InterventionRequest
+	hashes        map[uint64]uint64
+	endTick       uint64
+	tick          uint64 // latest tick seen in any journal call
+	err           error
+}
+
+// Open reads a whole journal into memory. A crash can tear the final line,
+// which Open drops; a malformed line anywhere earlier is an error.
+func Open(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer f.Close()
+
+	p := &Player{
+		dir:           filepath.Dir(path),
+		llm:           make(map[string][]record),
+		interventions: make(map[uint64][]engine.InterventionRequest),
+		hashes:        make(map[uint64]uint64),
+	}
+	rd := bufio.NewReaderSize(f, 64<<10)
+	head, err := rd.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read journal header: %w", err)
+	}
+	if err := json.Unmarshal(head, &p.Header); err != nil {
+		return nil, fmt.Errorf("read journal header: %w", err)
+	}
+	if p.Header.Version != FormatVersion {
+		return nil, fmt.Errorf("journal format version %d, this build reads %d", p.Header.Version, FormatVersion)
+	}
+	p.endTick = p.Header.StartTick
+
+	for line := 2; ; line++ {
+		b, readErr := rd.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("read journal line %d: %w", line, readErr)
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			if readErr == io.EOF {
+				break
+			}
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			// Every record the recorder writes ends in a newline, so only
+			// the final, unterminated line can be torn by a crash. A bad
+			// line anywhere before it is corruption, and replaying past it
+			// would verify a different run than the one recorded.
+			if readErr == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		switch rec.Kind {
+		case kindEntropy:
+			p.entropy = append(p.entropy, rec)
+		case kindWeather:
+			p.weather = append(p.weather, rec)
+		case kindLLM:
+			p.llm[rec.Tag] = append(p.llm[rec.Tag], rec)
+		case kindIntervention:
+			if rec.Intervention != nil {
+				p.interventions[rec.Tick] = append(p.interventions[rec.Tick], *rec.Intervention)
+			}
+		case kindHash:
+			h, err := parseHash(rec.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("journal line %d: bad hash %q", line, rec.Hash)
+			}
+			p.hashes[rec.Tick] = h
+			if rec.Tick > p.endTick {
+				p.endTick = rec.Tick
+			}
+		default:
+			return nil, fmt.Errorf("journal line %d: unknown record kind %q", line, rec.Kind)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	return p, nil
+}
+
+// BasePath returns the base snapshot's path.
+func (p *Player) BasePath() string {
+	return filepath.Join(p.dir, p.Header.Base)
+}
+
+// CheckpointPath returns the base checkpoint's path, or "" if the journal
+// predates base checkpoints and replays from the database alone.
+func (p *Player) CheckpointPath() string {
+	if p.Header.Checkpoint == "" {
+		return ""
+	}
+	return filepath.Join(p.dir, p.Header.Checkpoint)
+}
+
+// EndTick returns the tick of the last recorded day hash — the furthest point
+// a replay can verify.
+func (p *Player) EndTick() uint64 { return p.endTick }
+
+// InterventionsAt returns the interventions the live run applied after tick,
+// in application order.
+func (p *Player) InterventionsAt(tick uint64) []engine.InterventionRequest {
+	return p.interventions[tick]
+}
+
+// Err returns the first divergence, or nil.
+func (p *Player) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *Player) diverge(tick uint64, format string, args ...any) {
+	if p.err == nil {
+		p.err = &DivergenceError{Tick: tick, Reason: fmt.Sprintf(format, args...)}
+	}
+}
+
+// Entropy implements engine.Journal.
+func (p *Player) Entropy(tick uint64, live func() float64) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tick = tick
+	if len(p.entropy) == 0 {
+		p.diverge(tick, "entropy requested but the recorded stream is exhausted")
+		return 0
+	}
+	rec := p.entropy[0]
+	p.entropy = p.entropy[1:]
+	if rec.Tick != tick {
+		p.diverge(tick, "entropy requested; next recorded draw was at tick %d", rec.Tick)
+	}
+	return rec.Float
+}
+
+// Weather implements engine.Journal.
+func (p *Player) Weather(tick uint64, live func() (*weather.Conditions, error)) (*weather.Conditions, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tick = tick
+	if len(p.weather) == 0 {
+		p.diverge(tick, "weather requested but the recorded stream is exhausted")
+		return nil, errors.New("replay: weather stream exhausted")
+	}
+	rec := p.weather[0]
+	p.weather = p.weather[1:]
+	if rec.Tick != tick {
+		p.diverge(tick, "weather requested; next recorded fetch was at tick %d", rec.Tick)
+	}
+	if rec.Err != "" {
+		return nil, errors.New(rec.Err)
+	}
+	return rec.Weather, nil
+}
+
+// Intervention implements engine.Journal. Replays re-apply the recorded
+// interventions themselves (see InterventionsAt), so there is nothing to note.
+func (p *Player) Intervention(tick uint64, req engine.InterventionRequest) {}
+
+// DayHash implements engine.Journal: it compares the replayed state hash with
+// the recorded one. Days past the end of the recording are not checked.
+func (p *Player) DayHash(tick uint64, hash uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tick = tick
+	if p.err != nil {
+		return p.err
+	}
+	want, ok := p.hashes[tick]
+	if !ok {
+		return nil
+	}
+	if hash != want {
+		p.diverge(tick, "end-of-day state hash %s, recorded %s", formatHash(hash), formatHash(want))
+	}
+	return p.err
+}
+
+// Completion implements llm.Recorder.
+func (p *Player) Completion(tag string, live func() (string, error)) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	queue := p.llm[tag]
+	if len(queue) == 0 {
+		// The tick loop only asks for a completion where the live run did;
+		// API-side tags (newspaper, biography) are never replayed.
+		// Completions carry no tick; attribute to the latest one seen.
+		p.diverge(p.tick, "LLM completion %q requested but none recorded", tag)
+		return "", errors.New("replay: no recorded completion")
+	}
+	rec := queue[0]
+	p.llm[tag] = queue[1:]
+	if rec.Err != "" {
+		return "", errors.New(rec.Err)
+	}
+	return rec.Text, nil
+}
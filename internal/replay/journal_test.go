@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/talgya/mini-world/internal/engine"
+	"github.com/talgya/mini-world/internal/weather"
+	"github.com/talgya/mini-world/internal/world"
+)
+
+// TestRecordThenPlay verifies a Player serves back exactly what a Recorder
+// saw live — entropy floats bit-for-bit, weather (including failures), LLM
+// completions per tag regardless of cross-tag interleaving, and interventions
+// keyed by tick — and accepts matching day hashes.
+func TestRecordThenPlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal-0.jsonl")
+	rec, err := Create(path, Header{StartTick: 0, Gen: world.GenConfig{Radius: 5, Seed: 42}, Base: "base-0.db", LLM: true, Weather: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	floats := []float64{0.1, 0.30000000000000004, 0.9999999999999999}
+	for i, f := range floats {
+		if got := rec.Entropy(uint64(60*(i+1)), func() float64 { return f }); got != f {
+			t.Fatalf("recorder altered entropy: %v != %v", got, f)
+		}
+	}
+	storm := &weather.Conditions{Temp: 12.5, Description: "thunderstorm", IsStorm: true}
+	rec.Weather(60, func() (*weather.Conditions, error) { return storm, nil })
+	rec.Weather(120, func() (*weather.Conditions, error) { return nil, errors.New("timeout") })
+	rec.Completion("narration", func() (string, error) { return "The river rose.", nil })
+	rec.Completion("newspaper", func() (string, error) { return "EXTRA", nil })
+	rec.Completion("narration", func() (string, error) { return "", errors.New("rate limit") })
+	rec.Intervention(1440, engine.InterventionRequest{Type: "wealth", Settlement: "Ashford", Amount: 500})
+	if err := rec.DayHash(1440, 0xdeadbeefcafef00d); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Header.Gen.Seed != 42 || !p.Header.LLM || p.EndTick() != 1440 {
+		t.Fatalf("header round-trip: %+v end=%d", p.Header, p.EndTick())
+	}
+	for i, want := range floats {
+		got := p.Entropy(uint64(60*(i+1)), func() float64 { t.Fatal("replay called live entropy"); return 0 })
+		if got != want {
+			t.Errorf("entropy %d: got %v, want %v", i, got, want)
+		}
+	}
+	noLive := func() (*weather.Conditions, error) { t.Fatal("replay called live weather"); return nil, nil }
+	if c, err := p.Weather(60, noLive); err != nil || *c != *storm {
+		t.Errorf("weather 1: got %+v, %v", c, err)
+	}
+	if _, err := p.Weather(120, noLive); err == nil || err.Error() != "timeout" {
+		t.Errorf("weather 2: want recorded failure, got %v", err)
+	}
+	// The tick loop only ever asks for its own tags; the interleaved
+	// newspaper call must not shift the narration queue.
+	if text, err := p.Completion("narration", nil); err != nil || text != "The river rose." {
+		t.Errorf("narration 1: %q, %v", text, err)
+	}
+	if _, err := p.Completion("narration", nil); err == nil {
+		t.Error("narration 2: want recorded failure")
+	}
+	if ivs := p.InterventionsAt(1440); len(ivs) != 1 || ivs[0].Amount != 500 {
+		t.Errorf("interventions at 1440: %+v", ivs)
+	}
+	if err := p.DayHash(1440, 0xdeadbeefcafef00d); err != nil {
+		t.Errorf("matching day hash reported: %v", err)
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("unexpected divergence: %v", err)
+	}
+}
+
+// TestPlayerReportsFirstDivergence verifies hash mismatches and out-of-order
+// input requests latch as a DivergenceError naming the first bad tick.
+func TestPlayerReportsFirstDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		play     func(p *Player)
+		wantTick uint64
+	}{
+		{
+			name:     "day hash mismatch",
+			play:     func(p *Player) { p.Entropy(60, nil); p.DayHash(1440, 1) },
+			wantTick: 1440,
+		},
+		{
+			name:     "entropy drawn at a different tick",
+			play:     func(p *Player) { p.Entropy(120, nil); p.DayHash(1440, 1) },
+			wantTick: 120,
+		},
+		{
+			name:     "entropy stream exhausted",
+			play:     func(p *Player) { p.Entropy(60, nil); p.Entropy(61, nil) },
+			wantTick: 61,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			rec, err := Create(path, Header{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec.Entropy(60, func() float64 { return 0.5 })
+			rec.DayHash(1440, 2)
+			rec.Close()
+
+			p, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.play(p)
+			var div *DivergenceError
+			if !errors.As(p.Err(), &div) {
+				t.Fatalf("want DivergenceError, got %v", p.Err())
+			}
+			if div.Tick != tt.wantTick {
+				t.Errorf("divergence tick = %d, want %d (%v)", div.Tick, tt.wantTick, div)
+			}
+		})
+	}
+}
+
+// TestOpenToleratesTornTail verifies a journal cut off mid-line by a crash
+// still opens, keeping every complete record before the tear.
+func TestOpenToleratesTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	rec, err := Create(path, Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.DayHash(1440, 7)
+	rec.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"k":"hash","t":2880,"h":"00`)
+	f.Close()
+
+	p, err := Open(path)
+	if err != nil {
+		t.Fatalf("torn journal should open: %v", err)
+	}
+	if p.EndTick() != 1440 {
+		t.Errorf("EndTick = %d, want 1440 (last complete record)", p.EndTick())
+	}
+}
+
+// TestOpenRejectsCorruptLine verifies a bad line with records after it is an
+// error rather than the end of the journal.
+func TestOpenRejectsCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	rec, err := Create(path, Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.DayHash(1440, 7)
+	rec.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{\"k\":\"hash\",\"t\":2880,\"h\":\"00\n{\"k\":\"hash\",\"t\":4320,\"h\":\"0000000000000007\"}\n")
+	f.Close()
+
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Open = %v, want an error at line 3", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 
 	"github.com/talgya/mini-world/internal/phi"
+	"github.com/talgya/mini-world/pkg/worldclient"
 )
 
 // Level represents a health check severity.
@@ -86,7 +87,7 @@ func extractMetrics(snap *WorldSnapshot) HealthSnapshot {
 		WorkRate:     snap.Economy.ProducerHealth.WorkRate,
 		MarketHealth: snap.Economy.AvgMarketHealth,
 		GovScore:     snap.Social.Governance.AvgScore,
-		Satisfaction: snap.Status.AvgSatisfaction,
+		Satisfaction: float64(snap.Status.AvgSatisfaction),
 		Population:   snap.Status.Population,
 	}
 
@@ -143,7 +144,7 @@ func populationNearCap(pop int) bool {
 
 // computeDeathBirthRatio calculates the D:B ratio from history deltas.
 // History is sorted by tick DESC, so [0] is newest.
-func computeDeathBirthRatio(history []StatsHistoryRow) float64 {
+func computeDeathBirthRatio(history []worldclient.StatsRow) float64 {
 	if len(history) < 2 {
 		return 1.0
 	}
@@ -184,7 +185,7 @@ func computeDeathBirthRatio(history []StatsHistoryRow) float64 {
 // peaked at ~5,277/sim-day (rate ~0.014).
 const ticksPerSimDay = 1440
 
-func computeMortalityRate(history []StatsHistoryRow) float64 {
+func computeMortalityRate(history []worldclient.StatsRow) float64 {
 	if len(history) < 2 {
 		return 0
 	}
@@ -593,11 +594,12 @@ func occupationSatisfactionGap(snap *WorldSnapshot) float64 {
 		if occ.Count == 0 {
 			continue
 		}
-		if occ.AvgSatisfaction < minSat {
-			minSat = occ.AvgSatisfaction
+		sat := float64(occ.AvgSatisfaction)
+		if sat < minSat {
+			minSat = sat
 		}
-		if occ.AvgSatisfaction > maxSat {
-			maxSat = occ.AvgSatisfaction
+		if sat > maxSat {
+			maxSat = sat
 		}
 	}
 	if minSat == math.MaxFloat64 {
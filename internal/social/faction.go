@@ -2,6 +2,8 @@
 // See design doc Section 6.1.
 package social
 
+import "maps"
+
 // FactionID is a unique identifier for a faction.
 type FactionID uint64
 
@@ -34,6 +36,18 @@ type Faction struct {
 	MilitaryPreference float64 `json:"military_preference"` // -1 pacifist, +1 militarist
 }
 
+// Clone returns a deep copy of f.
+func (f *Faction) Clone() *Faction {
+	c := *f
+	c.Influence = maps.Clone(f.Influence)
+	c.Relations = maps.Clone(f.Relations)
+	if f.LeaderID != nil {
+		id := *f.LeaderID
+		c.LeaderID = &id
+	}
+	return &c
+}
+
 // SeedFactions creates the 5 initial factions for the world.
 func SeedFactions() []*Faction {
 	return []*Faction{
@@ -48,12 +48,25 @@ type Settlement struct {
 	GovernanceScore float64 `json:"governance_score"` // 0.0–1.0, effectiveness
 
 	// Economy
-	Market *economy.Market `json:"-"` // Settlement market (rebuilt on restart)
+	Market *economy.Market `json:"-"` // Settlement market (saved in world_meta "markets")
 
 	// Wheeler integration
 	CulturalMemory float64 `json:"cultural_memory"` // Accumulated from wise agents
 }
 
+// Clone returns a deep copy of s, including its market.
+func (s *Settlement) Clone() *Settlement {
+	c := *s
+	if s.LeaderID != nil {
+		id := *s.LeaderID
+		c.LeaderID = &id
+	}
+	if s.Market != nil {
+		c.Market = s.Market.Clone()
+	}
+	return &c
+}
+
 // ChargingPressure implements phi.ConjugateField — production and tax revenue.
 func (s *Settlement) ChargingPressure() float64 {
 	return float64(s.Population)*0.1 + float64(s.Treasury)*0.001
@@ -45,6 +45,13 @@ func NewClient(apiKey, location string) *Client {
 	}
 }
 
+// NewOfflineClient returns a client that never reaches the network: Fetch
+// always fails. Replay attaches one so the simulation takes its live-weather
+// branch while the journal supplies the recorded conditions.
+func NewOfflineClient() *Client {
+	return &Client{}
+}
+
 // Conditions holds parsed weather data from the API.
 type Conditions struct {
 	Temp        float64 `json:"temp"`         // Celsius
@@ -57,6 +64,10 @@ type Conditions struct {
 
 // Fetch retrieves current weather conditions, using cache if fresh.
 func (c *Client) Fetch() (*Conditions, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("weather client is offline")
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
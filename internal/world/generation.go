@@ -12,10 +12,35 @@ import (
 
 // GenConfig holds world generation parameters.
 type GenConfig struct {
-	Radius      int     // Hex grid radius (~22 for ~2000 hexes)
-	Seed        int64   // Random seed (0 = random)
-	SeaLevel    float64 // Elevation threshold for ocean (0.0–1.0)
-	MountainLvl float64 // Elevation threshold for mountains (0.0–1.0)
+	Radius      int         `json:"radius"`         // Hex grid radius (~22 for ~2000 hexes)
+	Seed        int64       `json:"seed"`           // Random seed (0 = random)
+	SeaLevel    float64     `json:"sea_level"`      // Elevation threshold for ocean (0.0–1.0)
+	MountainLvl float64     `json:"mountain_level"` // Elevation threshold for mountains (0.0–1.0)
+	Noise       NoiseParams `json:"noise"`          // Octave noise shaping (zero value = DefaultNoiseParams)
+}
+
+// NoiseParams shapes the three multi-octave simplex layers. Scale is the base
+// frequency in hex units (smaller = broader features); each further octave
+// doubles frequency and multiplies amplitude by Persistence.
+type NoiseParams struct {
+	ElevOctaves int     `json:"elev_octaves"`
+	ElevScale   float64 `json:"elev_scale"`
+	RainOctaves int     `json:"rain_octaves"`
+	RainScale   float64 `json:"rain_scale"`
+	TempOctaves int     `json:"temp_octaves"`
+	TempScale   float64 `json:"temp_scale"`
+	Persistence float64 `json:"persistence"`
+}
+
+// DefaultNoiseParams returns the layer shaping every existing world was
+// generated with. Changing it changes the map for a given seed.
+func DefaultNoiseParams() NoiseParams {
+	return NoiseParams{
+		ElevOctaves: 4, ElevScale: 0.08,
+		RainOctaves: 3, RainScale: 0.06,
+		TempOctaves: 3, TempScale: 0.05,
+		Persistence: 0.5,
+	}
 }
 
 // DefaultGenConfig returns a reasonable starting configuration.
@@ -26,6 +51,7 @@ func DefaultGenConfig() GenConfig {
 		Seed:        0,
 		SeaLevel:    0.25,
 		MountainLvl: 0.56, // Was 0.60 (17 hexes) — ~53 mountains (3.5%) to support mining economy
+		Noise:       DefaultNoiseParams(),
 	}
 }
 
@@ -36,6 +62,7 @@ func SmallTestConfig() GenConfig {
 		Seed:        42,
 		SeaLevel:    0.30,
 		MountainLvl: 0.75,
+		Noise:       DefaultNoiseParams(),
 	}
 }
 
@@ -51,6 +78,11 @@ func Generate(cfg GenConfig) *Map {
 	rainNoise := opensimplex.NewNormalized(seed + 1)
 	tempNoise := opensimplex.NewNormalized(seed + 2)
 
+	noise := cfg.Noise
+	if noise == (NoiseParams{}) {
+		noise = DefaultNoiseParams()
+	}
+
 	m := NewMap(cfg.Radius)
 
 	// Generate each hex within radius.
@@ -78,9 +110,9 @@ func Generate(cfg GenConfig) *Map {
 			y := float64(r) * math.Sqrt(3.0) / 2.0
 
 			// Multi-octave noise for natural-looking terrain.
-			elev := octaveNoise(elevNoise, x, y, 4, 0.08, 0.5)
-			rain := octaveNoise(rainNoise, x, y, 3, 0.06, 0.5)
-			temp := octaveNoise(tempNoise, x, y, 3, 0.05, 0.5)
+			elev := octaveNoise(elevNoise, x, y, noise.ElevOctaves, noise.ElevScale, noise.Persistence)
+			rain := octaveNoise(rainNoise, x, y, noise.RainOctaves, noise.RainScale, noise.Persistence)
+			temp := octaveNoise(tempNoise, x, y, noise.TempOctaves, noise.TempScale, noise.Persistence)
 
 			// Continental shaping: reduce elevation near edges to create ocean border.
 			distFromCenter := math.Sqrt(x*x+y*y) / float64(cfg.Radius)
@@ -219,8 +251,8 @@ func placeRivers(m *Map, seed int64) {
 
 	// Find mountain/highland hexes as river sources.
 	var sources []HexCoord
-	for coord, hex := range m.Hexes {
-		if hex.Elevation > 0.65 && hex.Terrain != TerrainOcean {
+	for _, coord := range m.Coords() {
+		if hex := m.Hexes[coord]; hex.Elevation > 0.65 && hex.Terrain != TerrainOcean {
 			sources = append(sources, coord)
 		}
 	}
@@ -3,6 +3,8 @@
 // See design doc Section 3.
 package world
 
+import "maps"
+
 // HexCoord represents a position on the hex grid using axial coordinates.
 // The third cube coordinate s is derived: s = -q - r.
 type HexCoord struct {
@@ -60,6 +62,21 @@ type Hex struct {
 	ClaimedBy         *uint64 `json:"claimed_by,omitempty"` // Settlement ID that claims this hex
 }
 
+// Clone returns a deep copy of h.
+func (h *Hex) Clone() *Hex {
+	c := *h
+	c.Resources = maps.Clone(h.Resources)
+	if h.SettlementID != nil {
+		id := *h.SettlementID
+		c.SettlementID = &id
+	}
+	if h.ClaimedBy != nil {
+		id := *h.ClaimedBy
+		c.ClaimedBy = &id
+	}
+	return &c
+}
+
 // ResourceType enumerates primary resources harvestable from terrain.
 type ResourceType uint8
 
@@ -1,6 +1,10 @@
 package world
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
 
 // Map holds the complete hex grid world state.
 type Map struct {
@@ -18,6 +22,15 @@ func NewMap(radius int) *Map {
 	return m
 }
 
+// Clone returns a deep copy of m.
+func (m *Map) Clone() *Map {
+	c := &Map{Hexes: make(map[HexCoord]*Hex, len(m.Hexes)), Radius: m.Radius}
+	for coord, h := range m.Hexes {
+		c.Hexes[coord] = h.Clone()
+	}
+	return c
+}
+
 // Get returns the hex at the given coordinate, or nil if out of bounds.
 func (m *Map) Get(coord HexCoord) *Hex {
 	return m.Hexes[coord]
@@ -52,6 +65,23 @@ func (m *Map) InBounds(coord HexCoord) bool {
 	return max <= m.Radius
 }
 
+// Coords returns every coordinate on the map ordered by (Q, R). Generation
+// steps that draw from a seeded RNG walk hexes in this order rather than
+// ranging Hexes, whose iteration order changes from run to run.
+func (m *Map) Coords() []HexCoord {
+	coords := make([]HexCoord, 0, len(m.Hexes))
+	for c := range m.Hexes {
+		coords = append(coords, c)
+	}
+	slices.SortFunc(coords, func(a, b HexCoord) int {
+		if c := cmp.Compare(a.Q, b.Q); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.R, b.R)
+	})
+	return coords
+}
+
 // HexCount returns the total number of hexes in the map.
 func (m *Map) HexCount() int {
 	return len(m.Hexes)
@@ -37,7 +37,8 @@ func PlaceSettlements(m *Map, seed int64) []SettlementSeed {
 	}
 	var candidates []scored
 
-	for coord, hex := range m.Hexes {
+	for _, coord := range m.Coords() {
+		hex := m.Hexes[coord]
 		if hex.Terrain == TerrainOcean {
 			continue
 		}
@@ -47,8 +48,9 @@ func PlaceSettlements(m *Map, seed int64) []SettlementSeed {
 		}
 	}
 
-	// Sort by score descending.
-	sort.Slice(candidates, func(i, j int) bool {
+	// Sort by score descending (stable, so ties keep coordinate order and the
+	// same seed always places the same settlements).
+	sort.SliceStable(candidates, func(i, j int) bool {
 		return candidates[i].score > candidates[j].score
 	})
 
@@ -0,0 +1,294 @@
+// Package worldclient is a typed Go client for the worldsim HTTP API. Its
+// request and response types (types.go) are the ones the server's handlers
+// encode, so a change on either side breaks the other's build rather than
+// decoding to zero values.
+//
+// Reads retry network errors, 429s and 5xx responses with exponential
+// backoff. Writes are never retried: a timed-out intervention may still have
+// been applied. Admin endpoints send AdminKey, the event stream RelayKey.
+package worldclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client talks to one worldsim server.
+type Client struct {
+	BaseURL    string // e.g. http://localhost:8080, without a trailing slash
+	AdminKey   string // bearer token for admin endpoints
+	RelayKey   string // bearer token for the event stream
+	Actor      string // sent as X-Worldsim-Actor on writes, for the intervention ledger
+	HTTPClient *http.Client
+
+	// Retries is how many times a failed read is retried. The first retry
+	// waits Backoff, each later one twice as long, up to MaxBackoff.
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// New returns a Client for the server at baseURL with a 30-second request
+// timeout and three retries starting at one second.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Retries:    3,
+		Backoff:    time.Second,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// APIError is a response the server answered with an error status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// retryable reports whether a failed request is worth another try.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// nextBackoff doubles d, capped at MaxBackoff.
+func (c *Client) nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if c.MaxBackoff > 0 && d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	return d
+}
+
+// sleep waits d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// httpClient returns the HTTPClient, or the default one if it is unset.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// do sends one request and returns the response if its status is 200.
+func (c *Client) do(ctx context.Context, method, path, token string, body []byte) (*http.Response, error) {
+	return c.send(ctx, c.httpClient(), method, path, token, body)
+}
+
+func (c *Client) send(ctx context.Context, hc *http.Client, method, path, token string, body []byte) (*http.Response, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, rd)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.Actor != "" && method != http.MethodGet {
+		req.Header.Set("X-Worldsim-Actor", c.Actor)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(msg))}
+	}
+	return resp, nil
+}
+
+// get GETs path and decodes the JSON response into target, retrying as the
+// Client is configured.
+func (c *Client) get(ctx context.Context, path string, target any) error {
+	_, err := c.getPage(ctx, path, target)
+	return err
+}
+
+// getPage is get for a list endpoint: it also returns the response's
+// X-Next-Cursor, "" on the last page.
+func (c *Client) getPage(ctx context.Context, path string, target any) (string, error) {
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, http.MethodGet, path, "", nil)
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(target)
+			resp.Body.Close()
+			if err != nil {
+				return "", fmt.Errorf("decode %s: %w", path, err)
+			}
+			return resp.Header.Get("X-Next-Cursor"), nil
+		}
+		if attempt >= c.Retries || !retryable(err) {
+			return "", err
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return "", err
+		}
+		backoff = c.nextBackoff(backoff)
+	}
+}
+
+// post POSTs body as JSON with the admin key and decodes the response into
+// target. It does not retry.
+func (c *Client) post(ctx context.Context, path string, body, target any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", path, err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, path, c.AdminKey, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// fetch GETs path and decodes the response as a T.
+func fetch[T any](ctx context.Context, c *Client, path string) (T, error) {
+	var v T
+	if err := c.get(ctx, path, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// fetchAll GETs every page of the list at path, following X-Next-Cursor.
+func fetchAll[T any](ctx context.Context, c *Client, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	var all []T
+	for next := ""; ; {
+		p := path
+		if next != "" {
+			p += sep + "cursor=" + url.QueryEscape(next)
+		}
+		var page []T
+		cursor, err := c.getPage(ctx, p, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if cursor == "" {
+			return all, nil
+		}
+		next = cursor
+	}
+}
+
+// Status returns the world's headline numbers.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	return fetch[*Status](ctx, c, "/api/v1/status")
+}
+
+// Settlements returns every settlement.
+func (c *Client) Settlements(ctx context.Context) ([]SettlementSummary, error) {
+	return fetch[[]SettlementSummary](ctx, c, "/api/v1/settlements")
+}
+
+// Agents returns the Tier 2 agents.
+func (c *Client) Agents(ctx context.Context) ([]AgentSummary, error) {
+	return fetchAll[AgentSummary](ctx, c, "/api/v1/agents?limit=500")
+}
+
+// AgentsOfTier returns the agents of one tier (0, 1 or 2). Tier 0 is most of
+// the population.
+func (c *Client) AgentsOfTier(ctx context.Context, tier int) ([]AgentSummary, error) {
+	return fetchAll[AgentSummary](ctx, c, "/api/v1/agents?limit=500&tier="+strconv.Itoa(tier))
+}
+
+// Factions returns every faction with its five most influenced settlements.
+func (c *Client) Factions(ctx context.Context) ([]FactionSummary, error) {
+	return fetch[[]FactionSummary](ctx, c, "/api/v1/factions")
+}
+
+// Economy returns the world economy's summary.
+func (c *Client) Economy(ctx context.Context) (*Economy, error) {
+	return fetch[*Economy](ctx, c, "/api/v1/economy")
+}
+
+// Social returns the world's factions, governance and relationships summary.
+func (c *Client) Social(ctx context.Context) (*Social, error) {
+	return fetch[*Social](ctx, c, "/api/v1/social")
+}
+
+// StatsHistory returns the most recent daily stats snapshots, newest first.
+// limit is capped at 1000 by the server; 0 takes its default of 30.
+func (c *Client) StatsHistory(ctx context.Context, limit int) ([]StatsRow, error) {
+	path := "/api/v1/stats/history"
+	if limit > 0 {
+		path += "?" + url.Values{"limit": {strconv.Itoa(limit)}}.Encode()
+	}
+	return fetch[[]StatsRow](ctx, c, path)
+}
+
+// Intervene submits an intervention. It needs AdminKey.
+func (c *Client) Intervene(ctx context.Context, req InterventionRequest) (*InterventionResult, error) {
+	var res InterventionResult
+	if err := c.post(ctx, "/api/v1/intervention", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WaitReady polls the status endpoint until the server answers, backing off
+// from Backoff to MaxBackoff between attempts, or until ctx is done.
+func (c *Client) WaitReady(ctx context.Context) error {
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for {
+		resp, err := c.do(ctx, http.MethodGet, "/api/v1/status", "", nil)
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return fmt.Errorf("worldsim API not ready: %w", err)
+		}
+		backoff = c.nextBackoff(backoff)
+	}
+}
@@ -0,0 +1,89 @@
+package worldclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRetries retries reads through server errors but not client errors,
+// and never retries a write.
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	failures := int32(2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case r.URL.Path == "/api/v1/factions":
+			http.Error(w, "no such thing", http.StatusNotFound)
+		case n <= failures:
+			http.Error(w, "database not available", http.StatusServiceUnavailable)
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"success": true, "id": 3}`)
+		default:
+			fmt.Fprint(w, `{"tick": 42}`)
+		}
+	}))
+	defer ts.Close()
+	c := New(ts.URL)
+	c.Backoff = time.Millisecond
+	ctx := context.Background()
+
+	if s, err := c.Status(ctx); err != nil || s.Tick != 42 || calls.Load() != 3 {
+		t.Errorf("status = %+v, %v after %d calls", s, err, calls.Load())
+	}
+
+	calls.Store(0)
+	var apiErr *APIError
+	if _, err := c.Factions(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || calls.Load() != 1 {
+		t.Errorf("404 retried: %v after %d calls", err, calls.Load())
+	}
+
+	calls.Store(0)
+	c.Retries = 1
+	if _, err := c.Status(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || calls.Load() != 2 {
+		t.Errorf("retries exhausted: %v after %d calls", err, calls.Load())
+	}
+
+	calls.Store(0)
+	if _, err := c.Intervene(ctx, InterventionRequest{Type: "event"}); err == nil || calls.Load() != 1 {
+		t.Errorf("write retried: %v after %d calls", err, calls.Load())
+	}
+}
+
+// TestSubscribe parses server-sent events across heartbeats and multi-line
+// data, sending the relay key.
+func TestSubscribe(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer relay" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: birth\ndata: {\"tick\": 1, \"description\": \"a\", \"category\": \"birth\"}\n\n")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "event: death\ndata: {\"tick\": 2,\ndata: \"description\": \"b\", \"category\": \"death\"}\n\n")
+	}))
+	defer ts.Close()
+	c := New(ts.URL)
+
+	var apiErr *APIError
+	if err := c.Subscribe(context.Background(), func(Event) error { return nil }); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("stream without the relay key: %v", err)
+	}
+
+	c.RelayKey = "relay"
+	var got []Event
+	err := c.Subscribe(context.Background(), func(e Event) error {
+		got = append(got, e)
+		return nil
+	})
+	if err != nil || len(got) != 2 || got[0].Description != "a" || got[1].Tick != 2 || got[1].Category != "death" {
+		t.Errorf("stream = %+v, %v", got, err)
+	}
+}
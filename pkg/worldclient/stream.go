@@ -0,0 +1,63 @@
+package worldclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Subscribe connects to the event stream (GET /api/v1/stream, authorised by
+// RelayKey) and calls fn with each event, starting with the server's catch-up
+// of its most recent events. It returns when ctx is done, the server closes
+// the stream, or fn returns an error, which Subscribe returns.
+//
+// Subscribe does not reconnect: each connection replays the catch-up, so a
+// caller that reconnects should expect to see those events again.
+func (c *Client) Subscribe(ctx context.Context, fn func(Event) error) error {
+	hc := *c.httpClient()
+	hc.Timeout = 0 // the stream stays open; ctx ends it
+	resp, err := c.send(ctx, &hc, http.MethodGet, "/api/v1/stream", c.RelayKey, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	var data strings.Builder
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			// A blank line ends an event.
+			if data.Len() == 0 {
+				continue
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return fmt.Errorf("decode stream event: %w", err)
+			}
+			data.Reset()
+			if err := fn(e); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Comments (the heartbeat) and event: lines carry nothing the data
+		// does not; the category is in the event itself.
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package worldclient
+
+import "github.com/talgya/mini-world/eventproto"
+
+// These are the wire types of the public API. The server's handlers build
+// their responses from them, so a field changed here changes the server and
+// every client together.
+
+// Status is GET /api/v1/status.
+type Status struct {
+	Name                 string                     `json:"name"`
+	Tick                 uint64                     `json:"tick"`
+	SimTime              string                     `json:"sim_time"`
+	Season               string                     `json:"season"`
+	Speed                float64                    `json:"speed"`
+	Running              bool                       `json:"running"`
+	Population           int                        `json:"population"`
+	Deaths               int                        `json:"deaths"`
+	Births               int                        `json:"births"`
+	Settlements          int                        `json:"settlements"`
+	Factions             int                        `json:"factions"`
+	AvgMood              float32                    `json:"avg_mood"`
+	AvgSatisfaction      float32                    `json:"avg_satisfaction"`
+	AvgAlignment         float32                    `json:"avg_alignment"`
+	TotalWealth          uint64                     `json:"total_wealth"`
+	Weather              Weather                    `json:"weather"`
+	Occupations          map[string]OccupationStats `json:"occupations"` // keyed by occupation name
+	UnaffiliatedAdults   int                        `json:"unaffiliated_adults"`
+	UnaffiliatedChildren int                        `json:"unaffiliated_children"`
+
+	// The server's effective configuration and what startup replayed from
+	// the journal, when it has them. Clients see them as decoded JSON.
+	Config   any `json:"config,omitempty"`
+	Recovery any `json:"recovery,omitempty"`
+}
+
+// Weather is the current weather.
+type Weather struct {
+	Description  string  `json:"description"`
+	TempModifier float32 `json:"temp_modifier"` // -1 cold to +1 hot
+}
+
+// OccupationStats is one occupation's head count and average needs.
+type OccupationStats struct {
+	Count           int     `json:"count"`
+	AvgSatisfaction float32 `json:"avg_satisfaction"`
+	AvgSurvival     float32 `json:"avg_survival"`
+	AvgSafety       float32 `json:"avg_safety"`
+	AvgBelonging    float32 `json:"avg_belonging"`
+	AvgPurpose      float32 `json:"avg_purpose"`
+	AvgEsteem       float32 `json:"avg_esteem"`
+}
+
+// SettlementSummary is an item of GET /api/v1/settlements.
+type SettlementSummary struct {
+	ID                 uint64  `json:"id"`
+	Name               string  `json:"name"`
+	Q                  int     `json:"q"`
+	R                  int     `json:"r"`
+	Population         uint32  `json:"population"`
+	Governance         string  `json:"governance"`
+	Treasury           uint64  `json:"treasury"`
+	Health             float64 `json:"health"`
+	CarryingCapacity   float64 `json:"carrying_capacity"`
+	PopulationPressure float64 `json:"population_pressure"`
+	OccupationHHI      float64 `json:"occupation_hhi"`
+}
+
+// AgentSummary is an item of GET /api/v1/agents.
+type AgentSummary struct {
+	ID           uint64  `json:"id"`
+	Name         string  `json:"name"`
+	Age          uint16  `json:"age"`
+	Occupation   string  `json:"occupation"`
+	Tier         int     `json:"tier"`
+	Coherence    float32 `json:"coherence"`
+	EffMood      float32 `json:"effective_mood"` // Effective mood (blended)
+	Satisfaction float32 `json:"satisfaction"`   // Material needs satisfaction
+	Alignment    float32 `json:"alignment"`      // Coherence-derived harmony
+	Wealth       uint64  `json:"wealth"`
+	Alive        bool    `json:"alive"`
+}
+
+// FactionSummary is an item of GET /api/v1/factions.
+type FactionSummary struct {
+	ID        uint64             `json:"id"`
+	Name      string             `json:"name"`
+	Members   int                `json:"members"`
+	Treasury  uint64             `json:"treasury"`
+	Influence map[string]float64 `json:"top_influence"` // settlement name → influence
+}
+
+// Economy is GET /api/v1/economy.
+type Economy struct {
+	TotalCrowns        uint64             `json:"total_crowns"`
+	AgentWealth        uint64             `json:"agent_wealth"`
+	TreasuryWealth     uint64             `json:"treasury_wealth"`
+	AvgMarketHealth    float64            `json:"avg_market_health"`
+	TradeVolume        uint64             `json:"trade_volume"`
+	MostInflated       []PriceDeviation   `json:"most_inflated"` // top 5, highest ratio first
+	MostDeflated       []PriceDeviation   `json:"most_deflated"` // top 5, lowest ratio first
+	WealthDistribution WealthDistribution `json:"wealth_distribution"`
+	ProducerHealth     ProducerHealth     `json:"producer_health"`
+	TradeRoutes        TradeRoutes        `json:"trade_routes"`
+}
+
+// PriceDeviation is a market price away from its base price.
+type PriceDeviation struct {
+	Good       string  `json:"good"`
+	Settlement string  `json:"settlement"`
+	Price      float64 `json:"price"`
+	BasePrice  float64 `json:"base_price"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// WealthDistribution is the share of agent wealth held at each end.
+type WealthDistribution struct {
+	Poorest50PctShare float64 `json:"poorest_50_pct_share"`
+	Richest10PctShare float64 `json:"richest_10_pct_share"`
+}
+
+// ProducerHealth counts the producers who have and have not worked.
+type ProducerHealth struct {
+	Total    int     `json:"total"`
+	Working  int     `json:"working"`
+	Idle     int     `json:"idle"`
+	WorkRate float64 `json:"work_rate"`
+}
+
+// TradeRoutes lists the established trade routes.
+type TradeRoutes struct {
+	Count  int          `json:"count"`
+	Routes []TradeRoute `json:"routes"`
+}
+
+// TradeRoute is an established route between two settlements.
+type TradeRoute struct {
+	Name        string  `json:"name"`
+	Level       uint8   `json:"level"`
+	LevelName   string  `json:"level_name"`
+	WeeklyTrade float64 `json:"weekly_trade"`
+	SettAID     uint64  `json:"settlement_a_id"`
+	SettAName   string  `json:"settlement_a_name"`
+	SettBID     uint64  `json:"settlement_b_id"`
+	SettBName   string  `json:"settlement_b_name"`
+}
+
+// Social is GET /api/v1/social.
+type Social struct {
+	Factions              []SocialFaction  `json:"factions"`
+	Governance            GovernanceHealth `json:"governance"`
+	Relationships         Relationships    `json:"relationships"`
+	TierDistribution      map[string]int   `json:"tier_distribution"`      // tier_0, tier_1, tier_2
+	CoherenceDistribution map[string]int   `json:"coherence_distribution"` // embodied, centered, liberated
+	RecentPoliticalEvents []Event          `json:"recent_political_events"`
+	Diplomacy             map[string]any   `json:"diplomacy"`
+}
+
+// SocialFaction is a faction as GET /api/v1/social summarises it.
+type SocialFaction struct {
+	Name           string             `json:"name"`
+	Treasury       uint64             `json:"treasury"`
+	TotalMembers   int                `json:"total_members"`
+	TopSettlements map[string]float64 `json:"top_settlements"` // top 3 by influence
+}
+
+// GovernanceHealth is the world's average governance score and the
+// settlements scoring under 0.3.
+type GovernanceHealth struct {
+	AvgScore          float64  `json:"avg_score"`
+	AtRiskSettlements []string `json:"at_risk_settlements"`
+}
+
+// Relationships summarises the living agents' relationships.
+type Relationships struct {
+	AvgSentiment float32 `json:"avg_sentiment"`
+	Families     int     `json:"families"`
+	Rivalries    int     `json:"rivalries"`
+}
+
+// StatsRow is an item of GET /api/v1/stats/history: one daily snapshot.
+type StatsRow struct {
+	Tick            uint64  `json:"tick"`
+	Population      int     `json:"population"`
+	TotalWealth     uint64  `json:"total_wealth"`
+	AvgMood         float64 `json:"avg_mood"`
+	AvgSurvival     float64 `json:"avg_survival"`
+	Births          int     `json:"births"`
+	Deaths          int     `json:"deaths"`
+	TradeVolume     uint64  `json:"trade_volume"`
+	AvgCoherence    float64 `json:"avg_coherence"`
+	SettlementCount int     `json:"settlement_count"`
+	Gini            float64 `json:"gini"`
+	AvgSatisfaction float64 `json:"avg_satisfaction"`
+	AvgAlignment    float64 `json:"avg_alignment"`
+	OccupationJSON  string  `json:"occupation_json,omitempty"`
+	Bottom50Share   float64 `json:"bottom_50_share"`
+	Top10Share      float64 `json:"top_10_share"`
+}
+
+// Event is a world event, as the events endpoints and the stream carry it.
+type Event struct {
+	Tick                uint64              `json:"tick"`
+	Description         string              `json:"description"`
+	NarratedDescription string              `json:"narrated_description,omitempty"` // LLM-narrated prose (major events only)
+	Category            eventproto.Category `json:"category"`
+	Meta                map[string]any      `json:"meta,omitempty"`
+}
+
+// InterventionRequest is the body of POST /api/v1/intervention. Fields
+// beyond Type are interpreted per type; unused ones are ignored.
+type InterventionRequest struct {
+	Type         string   `json:"type"`
+	Description  string   `json:"description,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Settlement   string   `json:"settlement,omitempty"`
+	Amount       int64    `json:"amount,omitempty"`
+	Count        int      `json:"count,omitempty"`
+	Good         string   `json:"good,omitempty"`
+	Quantity     int      `json:"quantity,omitempty"`
+	Multiplier   float64  `json:"multiplier,omitempty"`
+	DurationDays int      `json:"duration_days,omitempty"`
+	Knob         string   `json:"knob,omitempty"`
+	Value        *float64 `json:"value,omitempty"`    // pointer: zero is a valid knob value
+	ApplyAt      uint64   `json:"apply_at,omitempty"` // schedule for this tick; 0 applies now
+}
+
+// InterventionResult is the response to POST /api/v1/intervention.
+type InterventionResult struct {
+	Success bool   `json:"success"`
+	Details string `json:"details"`
+	ID      uint64 `json:"id"`     // intervention ledger ID
+	Status  string `json:"status"` // ledger status: applied, pending, ...
+}